@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 	parserApp "github.com/vpedrosa/pen2pdf/internal/parser/application"
 	parserInfra "github.com/vpedrosa/pen2pdf/internal/parser/infrastructure"
+	resolverDomain "github.com/vpedrosa/pen2pdf/internal/resolver/domain"
 	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
 )
 
@@ -39,6 +41,11 @@ func runInfo(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("parse error: %w", err)
 	}
 
+	// Expand component instances so definitions are not listed as pages
+	if err := resolverDomain.NewComponentExpander().Resolve(doc); err != nil {
+		return fmt.Errorf("resolve error: %w", err)
+	}
+
 	cmd.Printf("File:    %s\n", inputPath)
 	cmd.Printf("Version: %s\n", doc.Version)
 	cmd.Println()
@@ -51,6 +58,19 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	}
 	cmd.Println()
 
+	if len(doc.Themes) > 0 {
+		cmd.Printf("Themes (%d):\n", len(doc.Themes))
+		axes := make([]string, 0, len(doc.Themes))
+		for axis := range doc.Themes {
+			axes = append(axes, axis)
+		}
+		sort.Strings(axes)
+		for _, axis := range axes {
+			cmd.Printf("  %-20s %s\n", axis, strings.Join(doc.Themes[axis], ", "))
+		}
+		cmd.Println()
+	}
+
 	if len(doc.Variables) > 0 {
 		cmd.Printf("Variables (%d):\n", len(doc.Variables))
 		names := make([]string, 0, len(doc.Variables))
@@ -60,7 +80,11 @@ func runInfo(cmd *cobra.Command, args []string) error {
 		sort.Strings(names)
 		for _, name := range names {
 			v := doc.Variables[name]
-			cmd.Printf("  %-20s %s = %v\n", name, v.Type, v.Value)
+			cmd.Printf("  %-20s %s = %v", name, v.Type, v.Value)
+			for _, themed := range v.Themed {
+				cmd.Printf(", %v (%s)", themed.Value, formatTheme(themed.Theme))
+			}
+			cmd.Println()
 		}
 		cmd.Println()
 	}
@@ -75,3 +99,13 @@ func runInfo(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// formatTheme returns a theme as sorted axis=value pairs.
+func formatTheme(theme shared.Theme) string {
+	pairs := make([]string, 0, len(theme))
+	for axis, value := range theme {
+		pairs = append(pairs, axis+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
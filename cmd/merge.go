@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	layoutDomain "github.com/vpedrosa/pen2pdf/internal/layout/domain"
+	mergeApp "github.com/vpedrosa/pen2pdf/internal/merge/application"
+	mergeDomain "github.com/vpedrosa/pen2pdf/internal/merge/domain"
+	mergeInfra "github.com/vpedrosa/pen2pdf/internal/merge/infrastructure"
+	resolverApp "github.com/vpedrosa/pen2pdf/internal/resolver/application"
+	resolverDomain "github.com/vpedrosa/pen2pdf/internal/resolver/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+var (
+	dataPath    string
+	mergeOutput string
+	mergeSingle bool
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge [template.pen]",
+	Short: "Render a .pen template once per data record",
+	Long: "Fills the {{field}} placeholders of a .pen template with each record of a CSV or JSON file " +
+		"and renders one PDF per record, or a single PDF with --single.",
+	Args: cobra.ExactArgs(1),
+	RunE: runMerge,
+}
+
+func init() {
+	mergeCmd.Flags().StringVar(&dataPath, "data", "", "CSV or JSON file with one record per row or object")
+	mergeCmd.Flags().StringVarP(&mergeOutput, "output", "o", "", "output file pattern with {{field}} and {{index}} placeholders (default: template-{{index}}.pdf), or the output file with --single")
+	mergeCmd.Flags().BoolVar(&mergeSingle, "single", false, "write all records to a single PDF")
+	mergeCmd.Flags().StringVar(&pagesFlag, "pages", "", "comma-separated page names to render (default: all)")
+	mergeCmd.Flags().StringSliceVar(&themeFlag, "theme", nil, "theme to render as axis=value pairs, e.g. mode=dark (default: document theme)")
+	mergeCmd.Flags().StringArrayVar(&varFlags, "var", nil, "override a variable as name=value (repeatable)")
+	mergeCmd.Flags().StringVar(&varsFile, "vars", "", "JSON file of variable overrides, e.g. {\"primary-color\": \"#00AA00\"}")
+	mergeCmd.Flags().StringVar(&localeFlag, "locale", "", "locale to translate text to, e.g. es (default: document locale)")
+	mergeCmd.Flags().BoolVar(&allowVars, "allow-undeclared-vars", false, "allow --var and --vars to add variables the document does not declare")
+	mergeCmd.Flags().BoolVar(&noPrompt, "no-prompt", false, "skip interactive prompts (for CI/scripts)")
+	_ = mergeCmd.MarkFlagRequired("data")
+	rootCmd.AddCommand(mergeCmd)
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	inputPath := args[0]
+	base := strings.TrimSuffix(inputPath, filepath.Ext(inputPath))
+
+	records, err := loadRecords(dataPath)
+	if err != nil {
+		return err
+	}
+
+	// Determine output paths
+	var outputs []string
+	if mergeSingle {
+		output := mergeOutput
+		if output == "" {
+			output = base + ".pdf"
+		}
+		outputs = []string{output}
+	} else {
+		pattern := mergeOutput
+		if pattern == "" {
+			pattern = base + "-{{index}}.pdf"
+		}
+		if outputs, err = outputNames(pattern, records); err != nil {
+			return err
+		}
+	}
+
+	// 1. Parse the template once
+	p := newPipeline(inputPath)
+	template, err := p.load(inputPath)
+	if err != nil {
+		return err
+	}
+	tables, err := loadLocales(inputPath)
+	if err != nil {
+		return err
+	}
+
+	// 2. Fill a copy of the template with each record
+	docs := make([]*shared.Document, len(records))
+	for i, record := range records {
+		resolveSvc := resolverApp.NewResolveService(
+			resolverDomain.NewComponentExpander(),
+			resolverDomain.NewLocaleResolver(localeFlag, tables),
+			resolverDomain.NewRepeatExpander(),
+			resolverDomain.NewRecordBinder(record),
+			resolverDomain.NewVariableResolver(),
+		)
+		docs[i] = template.Clone()
+		if err := resolveSvc.Resolve(docs[i]); err != nil {
+			return fmt.Errorf("record %d: resolve: %w", i+1, err)
+		}
+	}
+
+	// 3. Detect and download missing fonts, once for all records
+	if err := p.ensureFonts(cmd, docs...); err != nil {
+		return err
+	}
+
+	// 4. Lay out and render
+	var all []layoutDomain.Page
+	for i, doc := range docs {
+		pages, err := p.layout(doc)
+		if err != nil {
+			return fmt.Errorf("record %d: %w", i+1, err)
+		}
+		if mergeSingle {
+			all = append(all, pages...)
+			continue
+		}
+		result, err := p.write(pages, outputs[i])
+		if err != nil {
+			return fmt.Errorf("record %d: %w", i+1, err)
+		}
+		cmd.Printf("PDF written to %s (%d pages)\n", outputs[i], result.PageCount)
+	}
+
+	if mergeSingle {
+		result, err := p.write(all, outputs[0])
+		if err != nil {
+			return err
+		}
+		cmd.Printf("PDF written to %s (%d pages, %d records)\n", outputs[0], result.PageCount, len(records))
+		return nil
+	}
+	cmd.Printf("%d PDF(s) written\n", len(outputs))
+	return nil
+}
+
+// loadRecords reads the records of a CSV or JSON file, chosen by extension.
+func loadRecords(path string) ([]shared.Record, error) {
+	var loader mergeDomain.RecordLoader
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		loader = mergeInfra.NewCSVRecordLoader()
+	case ".json":
+		loader = mergeInfra.NewJSONRecordLoader()
+	default:
+		return nil, fmt.Errorf("unsupported data file %q, expected .csv or .json", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open data: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	records, err := mergeApp.NewRecordService(loader).Load(f)
+	if err != nil {
+		return nil, fmt.Errorf("data %s: %w", path, err)
+	}
+	return records, nil
+}
+
+// outputNames fills the output pattern for each record. {{index}} is the
+// 1-based record number unless the record has an index field, and slashes
+// in values are replaced so a field cannot pick another directory.
+func outputNames(pattern string, records []shared.Record) ([]string, error) {
+	sanitize := strings.NewReplacer("/", "-", "\\", "-")
+	names := make([]string, len(records))
+	seen := make(map[string]int, len(records))
+	for i, record := range records {
+		fields := shared.Record{"index": strconv.Itoa(i + 1)}
+		for field, value := range record {
+			fields[field] = sanitize.Replace(value)
+		}
+		name, err := fields.Expand(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("output pattern: record %d: %w", i+1, err)
+		}
+		if prev, ok := seen[name]; ok {
+			return nil, fmt.Errorf("output pattern: records %d and %d are both written to %s", prev, i+1, name)
+		}
+		seen[name] = i + 1
+		names[i] = name
+	}
+	return names, nil
+}
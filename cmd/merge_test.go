@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestMergeCommandRegistered(t *testing.T) {
+	found := false
+	for _, c := range rootCmd.Commands() {
+		if c.Use == "merge [template.pen]" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("merge command not registered")
+	}
+}
+
+func TestMergeCommandFlags(t *testing.T) {
+	for _, name := range []string{"data", "output", "single", "pages", "theme", "var", "vars", "locale", "allow-undeclared-vars", "no-prompt"} {
+		if mergeCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag", name)
+		}
+	}
+}
+
+func TestOutputNames(t *testing.T) {
+	records := []shared.Record{
+		{"name": "Ada Lovelace"},
+		{"name": "AC/DC"},
+	}
+	names, err := outputNames("out/{{index}}-{{ name }}.pdf", records)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names[0] != "out/1-Ada Lovelace.pdf" || names[1] != "out/2-AC-DC.pdf" {
+		t.Errorf("unexpected names %v", names)
+	}
+
+	names, err = outputNames("{{index}}.pdf", []shared.Record{{"index": "A7"}})
+	if err != nil || names[0] != "A7.pdf" {
+		t.Errorf("expected the record index field, got %v (%v)", names, err)
+	}
+}
+
+func TestOutputNamesErrors(t *testing.T) {
+	records := []shared.Record{{"name": "Ada"}, {"name": "Ada"}}
+	tests := map[string]string{
+		"{{name}}.pdf":  "records 1 and 2",
+		"{{email}}.pdf": `unknown field "email"`,
+	}
+	for pattern, want := range tests {
+		_, err := outputNames(pattern, records)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected %q, got %v", pattern, want, err)
+		}
+	}
+}
+
+func TestLoadRecordsRejectsUnknownFormat(t *testing.T) {
+	if _, err := loadRecords("people.xlsx"); err == nil {
+		t.Error("expected error for an unsupported data file")
+	}
+}
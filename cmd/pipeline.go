@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	assetApp "github.com/vpedrosa/pen2pdf/internal/asset/application"
+	assetInfra "github.com/vpedrosa/pen2pdf/internal/asset/infrastructure"
+	layoutApp "github.com/vpedrosa/pen2pdf/internal/layout/application"
+	layoutDomain "github.com/vpedrosa/pen2pdf/internal/layout/domain"
+	layoutInfra "github.com/vpedrosa/pen2pdf/internal/layout/infrastructure"
+	localeApp "github.com/vpedrosa/pen2pdf/internal/locale/application"
+	localeInfra "github.com/vpedrosa/pen2pdf/internal/locale/infrastructure"
+	parserApp "github.com/vpedrosa/pen2pdf/internal/parser/application"
+	parserInfra "github.com/vpedrosa/pen2pdf/internal/parser/infrastructure"
+	rendererApp "github.com/vpedrosa/pen2pdf/internal/renderer/application"
+	rendererInfra "github.com/vpedrosa/pen2pdf/internal/renderer/infrastructure"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// pipeline holds the services that turn a .pen file into PDF. They are built
+// once per command, so fonts and images are loaded once however many
+// documents are rendered.
+type pipeline struct {
+	fontsDir  string
+	parseSvc  *parserApp.ParseService
+	fontSvc   *assetApp.FontService
+	layoutSvc *layoutApp.LayoutService
+	renderSvc *rendererApp.RenderService
+}
+
+func newPipeline(inputPath string) *pipeline {
+	// Build infrastructure
+	baseDir := filepath.Dir(inputPath)
+	fontsDir := filepath.Join(baseDir, "fonts")
+
+	fontDirs := []string{fontsDir, "/usr/share/fonts", "/usr/local/share/fonts"}
+	if home, err := os.UserHomeDir(); err == nil {
+		fontDirs = append(fontDirs, filepath.Join(home, ".local", "share", "fonts"))
+	}
+
+	fontLoader := assetInfra.NewCachingFontLoader(assetInfra.NewFSFontLoader(fontDirs...))
+	imageLoader := assetInfra.NewCachingImageLoader(assetInfra.NewFSImageLoader(baseDir))
+	measurer := layoutInfra.NewGopdfTextMeasurer(fontLoader)
+	pdfRenderer := rendererInfra.NewPDFRenderer(imageLoader, fontLoader)
+
+	// Build application services (inject ports via DI)
+	return &pipeline{
+		fontsDir:  fontsDir,
+		parseSvc:  parserApp.NewParseService(parserInfra.NewJSONParser()),
+		fontSvc:   assetApp.NewFontService(fontLoader),
+		layoutSvc: layoutApp.NewLayoutService(layoutDomain.NewFlexboxEngine(layoutInfra.NewAssetImageMeasurer(imageLoader)), measurer),
+		renderSvc: rendererApp.NewRenderService(pdfRenderer),
+	}
+}
+
+// load parses the input and applies the --theme, --var and --vars flags.
+func (p *pipeline) load(inputPath string) (*shared.Document, error) {
+	theme, err := shared.ParseTheme(themeFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	inputFile, err := os.Open(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("open input: %w", err)
+	}
+	doc, err := p.parseSvc.Parse(inputFile)
+	inputFile.Close() //nolint:errcheck
+	if err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+	doc.Theme = doc.Theme.Merge(theme)
+
+	overrides, err := loadVariableOverrides(varsFile, varFlags)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.OverrideVariables(overrides, allowVars); err != nil {
+		return nil, fmt.Errorf("override: %w", err)
+	}
+	return doc, nil
+}
+
+// ensureFonts detects the fonts missing from resolved documents and offers
+// to download them, once for all documents.
+func (p *pipeline) ensureFonts(cmd *cobra.Command, docs ...*shared.Document) error {
+	seen := make(map[shared.FontRef]bool)
+	var missing []shared.FontRef
+	for _, doc := range docs {
+		for _, ref := range p.fontSvc.DetectMissingFonts(doc) {
+			if !seen[ref] {
+				seen[ref] = true
+				missing = append(missing, ref)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return promptAndDownloadFonts(cmd, missing, p.fontsDir)
+}
+
+// layout keeps the pages selected with --pages and lays them out.
+func (p *pipeline) layout(doc *shared.Document) ([]layoutDomain.Page, error) {
+	if pagesFlag != "" {
+		var err error
+		doc.Children, err = shared.FilterPagesByName(doc.Children, pagesFlag)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pages, err := p.layoutSvc.Layout(doc)
+	if err != nil {
+		return nil, fmt.Errorf("layout: %w", err)
+	}
+	return pages, nil
+}
+
+// write renders pages to a PDF file.
+func (p *pipeline) write(pages []layoutDomain.Page, output string) (*rendererApp.RenderResult, error) {
+	outputFile, err := os.Create(output)
+	if err != nil {
+		return nil, fmt.Errorf("create output: %w", err)
+	}
+	defer outputFile.Close() //nolint:errcheck
+
+	result, err := p.renderSvc.Render(pages, outputFile)
+	if err != nil {
+		return nil, fmt.Errorf("render: %w", err)
+	}
+	return result, nil
+}
+
+// loadLocales reads the string tables of the locales directory next to the
+// input, keyed by locale. A missing directory has none.
+func loadLocales(inputPath string) (map[string]shared.Translations, error) {
+	store := localeInfra.NewFSLocaleStore(filepath.Join(filepath.Dir(inputPath), "locales"))
+	tables, err := localeApp.NewLocaleService(store).LoadAll()
+	if err != nil {
+		return nil, fmt.Errorf("locales: %w", err)
+	}
+	return tables, nil
+}
+
+// documentLocales returns the sorted locales a document can be rendered in:
+// those with a string table, and its source locale.
+func documentLocales(doc *shared.Document, tables map[string]shared.Translations) []string {
+	locales := []string{doc.TextLocale()}
+	for locale := range tables {
+		if locale != doc.TextLocale() {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	return locales
+}
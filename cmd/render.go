@@ -2,21 +2,14 @@ package cmd
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
-	assetApp "github.com/vpedrosa/pen2pdf/internal/asset/application"
 	assetInfra "github.com/vpedrosa/pen2pdf/internal/asset/infrastructure"
-	layoutApp "github.com/vpedrosa/pen2pdf/internal/layout/application"
-	layoutDomain "github.com/vpedrosa/pen2pdf/internal/layout/domain"
-	layoutInfra "github.com/vpedrosa/pen2pdf/internal/layout/infrastructure"
-	parserApp "github.com/vpedrosa/pen2pdf/internal/parser/application"
-	parserInfra "github.com/vpedrosa/pen2pdf/internal/parser/infrastructure"
-	rendererApp "github.com/vpedrosa/pen2pdf/internal/renderer/application"
-	rendererInfra "github.com/vpedrosa/pen2pdf/internal/renderer/infrastructure"
 	resolverApp "github.com/vpedrosa/pen2pdf/internal/resolver/application"
 	resolverDomain "github.com/vpedrosa/pen2pdf/internal/resolver/domain"
 	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
@@ -26,6 +19,13 @@ var (
 	outputPath string
 	pagesFlag  string
 	noPrompt   bool
+	themeFlag  []string
+	varFlags   []string
+	varsFile   string
+	allowVars  bool
+	renderData string
+	localeFlag string
+	allLocales bool
 )
 
 var renderCmd = &cobra.Command{
@@ -39,6 +39,14 @@ var renderCmd = &cobra.Command{
 func init() {
 	renderCmd.Flags().StringVarP(&outputPath, "output", "o", "", "output PDF file path (default: input with .pdf extension)")
 	renderCmd.Flags().StringVar(&pagesFlag, "pages", "", "comma-separated page names to render (default: all)")
+	renderCmd.Flags().StringSliceVar(&themeFlag, "theme", nil, "theme to render as axis=value pairs, e.g. mode=dark (default: document theme)")
+	renderCmd.Flags().StringArrayVar(&varFlags, "var", nil, "override a variable as name=value (repeatable)")
+	renderCmd.Flags().StringVar(&varsFile, "vars", "", "JSON file of variable overrides, e.g. {\"primary-color\": \"#00AA00\"}")
+	renderCmd.Flags().StringVar(&renderData, "data", "", "JSON file of data for repeaters, e.g. {\"prices\": [...]}")
+	renderCmd.Flags().StringVar(&localeFlag, "locale", "", "locale to translate text to, e.g. es (default: document locale)")
+	renderCmd.Flags().BoolVar(&allLocales, "all-locales", false, "render one PDF per locale, named after the output with a -<locale> suffix")
+	renderCmd.MarkFlagsMutuallyExclusive("locale", "all-locales")
+	renderCmd.Flags().BoolVar(&allowVars, "allow-undeclared-vars", false, "allow --var and --vars to add variables the document does not declare")
 	renderCmd.Flags().BoolVar(&noPrompt, "no-prompt", false, "skip interactive prompts (for CI/scripts)")
 	rootCmd.AddCommand(renderCmd)
 }
@@ -53,78 +61,121 @@ func runRender(cmd *cobra.Command, args []string) error {
 		output = strings.TrimSuffix(inputPath, ext) + ".pdf"
 	}
 
-	// Build infrastructure
-	baseDir := filepath.Dir(inputPath)
-	fontsDir := filepath.Join(baseDir, "fonts")
+	p := newPipeline(inputPath)
 
-	fontDirs := []string{fontsDir, "/usr/share/fonts", "/usr/local/share/fonts"}
-	if home, err := os.UserHomeDir(); err == nil {
-		fontDirs = append(fontDirs, filepath.Join(home, ".local", "share", "fonts"))
-	}
-
-	fontLoader := assetInfra.NewFSFontLoader(fontDirs...)
-	imageLoader := assetInfra.NewFSImageLoader(baseDir)
-	measurer := layoutInfra.NewGopdfTextMeasurer(fontLoader)
-	pdfRenderer := rendererInfra.NewPDFRenderer(imageLoader, fontLoader)
-
-	// Build application services (inject ports via DI)
-	parseSvc := parserApp.NewParseService(parserInfra.NewJSONParser())
-	resolveSvc := resolverApp.NewResolveService(resolverDomain.NewVariableResolver())
-	fontSvc := assetApp.NewFontService(fontLoader)
-	layoutSvc := layoutApp.NewLayoutService(layoutDomain.NewFlexboxEngine(), measurer)
-	renderSvc := rendererApp.NewRenderService(pdfRenderer)
-
-	// 1. Parse
-	inputFile, err := os.Open(inputPath)
+	// 1. Parse and apply theme and variable overrides
+	doc, err := p.load(inputPath)
 	if err != nil {
-		return fmt.Errorf("open input: %w", err)
+		return err
 	}
-	doc, err := parseSvc.Parse(inputFile)
-	inputFile.Close() //nolint:errcheck
+	if renderData != "" {
+		if err := loadDocumentData(doc, renderData); err != nil {
+			return err
+		}
+	}
+	tables, err := loadLocales(inputPath)
 	if err != nil {
-		return fmt.Errorf("parse: %w", err)
+		return err
 	}
 
-	// 2. Resolve variables
-	if err := resolveSvc.Resolve(doc); err != nil {
-		return fmt.Errorf("resolve: %w", err)
+	// 2. Expand components, translate text and resolve variables, in a copy
+	// of the document per locale with --all-locales
+	locales := []string{localeFlag}
+	outputs := []string{output}
+	if allLocales {
+		locales = documentLocales(doc, tables)
+		outputs = make([]string, len(locales))
+		for i, locale := range locales {
+			outputs[i] = localizedOutput(output, locale)
+		}
+	}
+	docs := make([]*shared.Document, len(locales))
+	for i, locale := range locales {
+		resolveSvc := resolverApp.NewResolveService(
+			resolverDomain.NewComponentExpander(),
+			resolverDomain.NewLocaleResolver(locale, tables),
+			resolverDomain.NewRepeatExpander(),
+			resolverDomain.NewVariableResolver(),
+		)
+		docs[i] = doc
+		if allLocales {
+			docs[i] = doc.Clone()
+		}
+		if err := resolveSvc.Resolve(docs[i]); err != nil {
+			if allLocales {
+				return fmt.Errorf("locale %s: resolve: %w", locale, err)
+			}
+			return fmt.Errorf("resolve: %w", err)
+		}
 	}
 
 	// 3. Detect and download missing fonts (interactive CLI concern)
-	missing := fontSvc.DetectMissingFonts(doc)
-	if len(missing) > 0 {
-		if err := promptAndDownloadFonts(cmd, missing, fontsDir); err != nil {
-			return err
-		}
+	if err := p.ensureFonts(cmd, docs...); err != nil {
+		return err
 	}
 
-	// 4. Filter pages
-	if pagesFlag != "" {
-		doc.Children, err = shared.FilterPagesByName(doc.Children, pagesFlag)
+	// 4. Filter pages, lay them out and render
+	for i, doc := range docs {
+		pages, err := p.layout(doc)
+		if err != nil {
+			return err
+		}
+		result, err := p.write(pages, outputs[i])
 		if err != nil {
 			return err
 		}
+		cmd.Printf("PDF written to %s (%d pages)\n", outputs[i], result.PageCount)
 	}
+	return nil
+}
 
-	// 5. Layout
-	pages, err := layoutSvc.Layout(doc)
-	if err != nil {
-		return fmt.Errorf("layout: %w", err)
-	}
+// localizedOutput adds a locale suffix to an output path, so report.pdf
+// becomes report-es.pdf.
+func localizedOutput(output, locale string) string {
+	ext := filepath.Ext(output)
+	return strings.TrimSuffix(output, ext) + "-" + locale + ext
+}
 
-	// 6. Render
-	outputFile, err := os.Create(output)
-	if err != nil {
-		return fmt.Errorf("create output: %w", err)
+// loadVariableOverrides reads the variable overrides of a JSON file, if any,
+// and of name=value flags, which take precedence.
+func loadVariableOverrides(path string, flags []string) (map[string]any, error) {
+	overrides := make(map[string]any)
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read variables: %w", err)
+		}
+		if err := json.Unmarshal(data, &overrides); err != nil {
+			return nil, fmt.Errorf("read variables %s: %w", path, err)
+		}
+	}
+	for _, flag := range flags {
+		name, value, ok := strings.Cut(flag, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable %q, expected name=value", flag)
+		}
+		overrides[strings.TrimPrefix(name, "$")] = value
 	}
-	defer outputFile.Close() //nolint:errcheck
+	return overrides, nil
+}
 
-	result, err := renderSvc.Render(pages, outputFile)
+// loadDocumentData adds the top-level values of a JSON file to the data
+// repeaters iterate over, replacing those of the document.
+func loadDocumentData(doc *shared.Document, path string) error {
+	raw, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("render: %w", err)
+		return fmt.Errorf("read data: %w", err)
+	}
+	var data map[string]any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("read data %s: %w", path, err)
+	}
+	if doc.Data == nil {
+		doc.Data = make(map[string]any, len(data))
+	}
+	for name, value := range data {
+		doc.Data[name] = value
 	}
-
-	cmd.Printf("PDF written to %s (%d pages)\n", output, result.PageCount)
 	return nil
 }
 
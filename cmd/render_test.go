@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
 )
 
 func TestRenderCommandRegistered(t *testing.T) {
@@ -47,3 +52,82 @@ func TestRenderCommandHasNoPromptFlag(t *testing.T) {
 		t.Error("expected --no-prompt flag")
 	}
 }
+
+func TestRenderCommandHasThemeFlag(t *testing.T) {
+	f := renderCmd.Flags().Lookup("theme")
+	if f == nil {
+		t.Error("expected --theme flag")
+	}
+}
+
+func TestRenderCommandHasVariableFlags(t *testing.T) {
+	for _, name := range []string{"var", "vars", "allow-undeclared-vars"} {
+		if renderCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag", name)
+		}
+	}
+}
+
+func TestRenderCommandHasDataFlag(t *testing.T) {
+	if renderCmd.Flags().Lookup("data") == nil {
+		t.Error("expected --data flag")
+	}
+}
+
+func TestRenderCommandHasLocaleFlags(t *testing.T) {
+	for _, name := range []string{"locale", "all-locales"} {
+		if renderCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag", name)
+		}
+	}
+}
+
+func TestLocalizedOutput(t *testing.T) {
+	if got := localizedOutput("out/brochure.pdf", "pt-BR"); got != "out/brochure-pt-BR.pdf" {
+		t.Errorf("unexpected output %q", got)
+	}
+}
+
+func TestDocumentLocales(t *testing.T) {
+	tables := map[string]shared.Translations{"fr": {}, "es": {}, "en": {}}
+	if got := documentLocales(&shared.Document{}, tables); strings.Join(got, ",") != "en,es,fr" {
+		t.Errorf("expected en,es,fr, got %v", got)
+	}
+	if got := documentLocales(&shared.Document{Locale: "de"}, nil); strings.Join(got, ",") != "de" {
+		t.Errorf("expected the document locale, got %v", got)
+	}
+}
+
+func TestLoadDocumentData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(path, []byte(`{"prices": [{"name": "Tea"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := &shared.Document{Data: map[string]any{"prices": []any{}, "title": "Menu"}}
+	if err := loadDocumentData(doc, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prices, ok := doc.Data["prices"].([]any); !ok || len(prices) != 1 || doc.Data["title"] != "Menu" {
+		t.Errorf("expected file data over document data, got %v", doc.Data)
+	}
+}
+
+func TestLoadVariableOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overrides.json")
+	if err := os.WriteFile(path, []byte(`{"primary-color": "#0000FF", "gap": 12}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	overrides, err := loadVariableOverrides(path, []string{"primary-color=#00AA00", "$title=Spring = sale"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if overrides["primary-color"] != "#00AA00" || overrides["gap"] != 12.0 || overrides["title"] != "Spring = sale" {
+		t.Errorf("expected flags over file values, got %v", overrides)
+	}
+
+	if _, err := loadVariableOverrides("", []string{"gap"}); err == nil {
+		t.Error("expected error for a flag without value")
+	}
+}
@@ -9,12 +9,13 @@ import (
 	parserInfra "github.com/vpedrosa/pen2pdf/internal/parser/infrastructure"
 	resolverApp "github.com/vpedrosa/pen2pdf/internal/resolver/application"
 	resolverDomain "github.com/vpedrosa/pen2pdf/internal/resolver/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
 )
 
 var validateCmd = &cobra.Command{
 	Use:   "validate [input.pen]",
 	Short: "Validate a .pen file without rendering",
-	Long:  "Parses and validates a .pen file, checking for syntax errors and undefined variable references.",
+	Long:  "Parses and validates a .pen file, checking for syntax errors, undefined variable references and missing translations.",
 	Args:  cobra.ExactArgs(1),
 	RunE:  runValidate,
 }
@@ -33,13 +34,30 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	defer inputFile.Close() //nolint:errcheck
 
 	parseSvc := parserApp.NewParseService(parserInfra.NewJSONParser())
-	resolveSvc := resolverApp.NewResolveService(resolverDomain.NewVariableResolver())
 
 	doc, err := parseSvc.Parse(inputFile)
 	if err != nil {
 		return fmt.Errorf("parse error: %w", err)
 	}
+	tables, err := loadLocales(inputPath)
+	if err != nil {
+		return err
+	}
+
+	// Translation keys are checked once components are expanded, since
+	// instances can reference them in their overrides
+	if err := resolverDomain.NewComponentExpander().Resolve(doc); err != nil {
+		return fmt.Errorf("resolve error: %w", err)
+	}
+	if missing := reportMissingTranslations(cmd, doc, tables); missing > 0 {
+		return fmt.Errorf("%d missing translation(s)", missing)
+	}
 
+	resolveSvc := resolverApp.NewResolveService(
+		resolverDomain.NewLocaleResolver("", tables),
+		resolverDomain.NewRepeatExpander(),
+		resolverDomain.NewVariableResolver(),
+	)
 	if err := resolveSvc.Resolve(doc); err != nil {
 		return fmt.Errorf("resolve error: %w", err)
 	}
@@ -47,3 +65,20 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	cmd.Printf("Valid: %s (%d pages, %d variables)\n", inputPath, len(doc.Children), len(doc.Variables))
 	return nil
 }
+
+// reportMissingTranslations prints the translation keys each locale lacks
+// and returns how many are missing.
+func reportMissingTranslations(cmd *cobra.Command, doc *shared.Document, tables map[string]shared.Translations) int {
+	keys := shared.CollectTranslationKeys(doc)
+	if len(keys) == 0 {
+		return 0
+	}
+	count := 0
+	for _, locale := range documentLocales(doc, tables) {
+		for _, key := range tables[locale].MissingTranslations(keys) {
+			cmd.Printf("Missing translation %q in locale %s\n", key, locale)
+			count++
+		}
+	}
+	return count
+}
@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"bytes"
+	"strings"
 	"testing"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
 )
 
 func TestValidateCommandRegistered(t *testing.T) {
@@ -23,3 +27,23 @@ func TestValidateCommandRequiresArg(t *testing.T) {
 		t.Error("expected error for missing argument")
 	}
 }
+
+func TestReportMissingTranslations(t *testing.T) {
+	doc := &shared.Document{Children: []shared.Node{
+		&shared.Text{ID: "title", Content: "@{title} @{subtitle}"},
+	}}
+	tables := map[string]shared.Translations{
+		"en": {"title": "Welcome", "subtitle": "Spring"},
+		"es": {"title": "Bienvenida"},
+	}
+	var out bytes.Buffer
+	validateCmd.SetOut(&out)
+	defer validateCmd.SetOut(nil)
+
+	if n := reportMissingTranslations(validateCmd, doc, tables); n != 1 {
+		t.Errorf("expected 1 missing translation, got %d", n)
+	}
+	if !strings.Contains(out.String(), `Missing translation "subtitle" in locale es`) {
+		t.Errorf("unexpected report %q", out.String())
+	}
+}
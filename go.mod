@@ -5,6 +5,7 @@ go 1.25.5
 require (
 	github.com/signintech/gopdf v0.36.0
 	github.com/spf13/cobra v1.10.2
+	golang.org/x/image v0.35.0
 )
 
 require (
@@ -12,5 +13,4 @@ require (
 	github.com/phpdave11/gofpdi v1.0.14-0.20211212211723-1f10f9844311 // indirect
 	github.com/pkg/errors v0.8.1 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
-	golang.org/x/image v0.35.0 // indirect
 )
@@ -1,11 +1,34 @@
 package domain
 
+import (
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
 // ImageData holds a loaded image ready for use by the renderer.
 type ImageData struct {
 	Path   string
 	Width  int
 	Height int
 	Data   []byte
+	Vector *VectorImage // shapes of SVG images; nil for raster images
+}
+
+// VectorImage is a vector image, such as an SVG document, flattened into the
+// shapes it paints from bottom to top. Shapes are in image coordinates, with
+// the origin at the top-left corner and y pointing down.
+type VectorImage struct {
+	Width  float64
+	Height float64
+	Shapes []VectorShape
+}
+
+// VectorShape is a filled and stroked outline of a vector image. Gradient
+// fills are relative to the bounds of the geometry; strokes are centered.
+type VectorShape struct {
+	Geometry *shared.PathGeometry
+	FillRule shared.FillRule
+	Fill     *shared.Fill
+	Stroke   *shared.Stroke
 }
 
 // ImageLoader abstracts image loading from the filesystem.
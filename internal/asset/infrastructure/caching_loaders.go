@@ -0,0 +1,61 @@
+package infrastructure
+
+import (
+	"sync"
+
+	asset "github.com/vpedrosa/pen2pdf/internal/asset/domain"
+)
+
+// CachingFontLoader remembers the fonts loaded by another FontLoader, so
+// rendering many documents reads each font file once. Failures are not
+// cached: a missing font may be downloaded later.
+type CachingFontLoader struct {
+	loader asset.FontLoader
+	mu     sync.Mutex
+	fonts  map[string]*asset.FontData
+}
+
+func NewCachingFontLoader(loader asset.FontLoader) *CachingFontLoader {
+	return &CachingFontLoader{loader: loader, fonts: make(map[string]*asset.FontData)}
+}
+
+func (l *CachingFontLoader) LoadFont(family, weight, style string) (*asset.FontData, error) {
+	key := family + "\x00" + weight + "\x00" + style
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if font, ok := l.fonts[key]; ok {
+		return font, nil
+	}
+	font, err := l.loader.LoadFont(family, weight, style)
+	if err != nil {
+		return nil, err
+	}
+	l.fonts[key] = font
+	return font, nil
+}
+
+// CachingImageLoader remembers the images loaded by another ImageLoader, so
+// an image shared by many documents is read and decoded once.
+type CachingImageLoader struct {
+	loader asset.ImageLoader
+	mu     sync.Mutex
+	images map[string]*asset.ImageData
+}
+
+func NewCachingImageLoader(loader asset.ImageLoader) *CachingImageLoader {
+	return &CachingImageLoader{loader: loader, images: make(map[string]*asset.ImageData)}
+}
+
+func (l *CachingImageLoader) LoadImage(path string) (*asset.ImageData, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if img, ok := l.images[path]; ok {
+		return img, nil
+	}
+	img, err := l.loader.LoadImage(path)
+	if err != nil {
+		return nil, err
+	}
+	l.images[path] = img
+	return img, nil
+}
@@ -0,0 +1,78 @@
+package infrastructure_test
+
+import (
+	"fmt"
+	"testing"
+
+	asset "github.com/vpedrosa/pen2pdf/internal/asset/domain"
+	"github.com/vpedrosa/pen2pdf/internal/asset/infrastructure"
+)
+
+type countingFontLoader struct {
+	calls int
+	err   error
+}
+
+func (l *countingFontLoader) LoadFont(family, weight, _ string) (*asset.FontData, error) {
+	l.calls++
+	if l.err != nil {
+		return nil, l.err
+	}
+	return &asset.FontData{Family: family, Weight: weight}, nil
+}
+
+type countingImageLoader struct {
+	calls int
+}
+
+func (l *countingImageLoader) LoadImage(path string) (*asset.ImageData, error) {
+	l.calls++
+	return &asset.ImageData{Path: path}, nil
+}
+
+func TestCachingLoadersImplementPorts(t *testing.T) {
+	var _ asset.FontLoader = infrastructure.NewCachingFontLoader(nil)
+	var _ asset.ImageLoader = infrastructure.NewCachingImageLoader(nil)
+}
+
+func TestCachingFontLoader(t *testing.T) {
+	inner := &countingFontLoader{}
+	l := infrastructure.NewCachingFontLoader(inner)
+	first, _ := l.LoadFont("Inter", "700", "")
+	second, _ := l.LoadFont("Inter", "700", "")
+	if _, err := l.LoadFont("Inter", "400", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != second {
+		t.Error("expected the cached font")
+	}
+	if inner.calls != 2 {
+		t.Errorf("expected 2 loads, got %d", inner.calls)
+	}
+}
+
+func TestCachingFontLoaderRetriesFailures(t *testing.T) {
+	inner := &countingFontLoader{err: fmt.Errorf("font not found")}
+	l := infrastructure.NewCachingFontLoader(inner)
+	if _, err := l.LoadFont("Inter", "700", ""); err == nil {
+		t.Fatal("expected error")
+	}
+
+	inner.err = nil
+	if _, err := l.LoadFont("Inter", "700", ""); err != nil {
+		t.Errorf("expected a font found later to load, got %v", err)
+	}
+}
+
+func TestCachingImageLoader(t *testing.T) {
+	inner := &countingImageLoader{}
+	l := infrastructure.NewCachingImageLoader(inner)
+	for range 3 {
+		if _, err := l.LoadImage("logo.png"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("expected 1 load, got %d", inner.calls)
+	}
+}
@@ -0,0 +1,46 @@
+package infrastructure
+
+import (
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// FallbackFontFamily is the family name under which the embedded Go fonts
+// are registered when a requested font cannot be loaded.
+const FallbackFontFamily = "GoFont"
+
+// fallbackFonts maps style keys to embedded Go font TTF data.
+var fallbackFonts = map[string][]byte{
+	"regular":    goregular.TTF,
+	"bold":       gobold.TTF,
+	"italic":     goitalic.TTF,
+	"bolditalic": gobolditalic.TTF,
+}
+
+// FallbackFont returns the embedded Go font variant closest to the requested
+// weight and style, together with its variant key (e.g. "bold").
+// Renderer and text measurer share it so fallback text measures exactly as
+// it is drawn.
+func FallbackFont(weight, style string) (string, []byte) {
+	key := fallbackStyleKey(weight, style)
+	return key, fallbackFonts[key]
+}
+
+// fallbackStyleKey maps weight/style to a Go font variant key.
+func fallbackStyleKey(weight, style string) string {
+	isBold := weight == "700" || weight == "800" || weight == "900"
+	isItalic := style == "italic"
+
+	switch {
+	case isBold && isItalic:
+		return "bolditalic"
+	case isBold:
+		return "bold"
+	case isItalic:
+		return "italic"
+	default:
+		return "regular"
+	}
+}
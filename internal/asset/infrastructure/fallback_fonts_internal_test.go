@@ -43,3 +43,13 @@ func TestFallbackStyleKeyEmptyWeight(t *testing.T) {
 		t.Errorf("expected 'regular', got '%s'", got)
 	}
 }
+
+func TestFallbackFontReturnsVariantData(t *testing.T) {
+	key, data := FallbackFont("700", "italic")
+	if key != "bolditalic" {
+		t.Errorf("expected 'bolditalic', got '%s'", key)
+	}
+	if len(data) == 0 {
+		t.Error("expected embedded font data")
+	}
+}
@@ -1,17 +1,22 @@
 package infrastructure
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	_ "image/jpeg"
 	_ "image/png"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 
 	asset "github.com/vpedrosa/pen2pdf/internal/asset/domain"
 )
 
 // FSImageLoader loads images from the filesystem relative to a base directory.
+// PNG and JPEG images are decoded for their size; SVG images are parsed
+// into vector shapes.
 type FSImageLoader struct {
 	baseDir string
 }
@@ -31,6 +36,20 @@ func (l *FSImageLoader) LoadImage(path string) (*asset.ImageData, error) {
 		return nil, fmt.Errorf("load image %q: %w", path, err)
 	}
 
+	if isSVG(absPath, data) {
+		vector, err := parseSVG(data)
+		if err != nil {
+			return nil, fmt.Errorf("decode svg %q: %w", path, err)
+		}
+		return &asset.ImageData{
+			Path:   absPath,
+			Width:  int(math.Round(vector.Width)),
+			Height: int(math.Round(vector.Height)),
+			Data:   data,
+			Vector: vector,
+		}, nil
+	}
+
 	f, err := os.Open(absPath)
 	if err != nil {
 		return nil, fmt.Errorf("open image %q: %w", path, err)
@@ -49,3 +68,13 @@ func (l *FSImageLoader) LoadImage(path string) (*asset.ImageData, error) {
 		Data:   data,
 	}, nil
 }
+
+// isSVG reports whether a file is an SVG document, by its extension or by an
+// <svg> tag near the start of the file.
+func isSVG(path string, data []byte) bool {
+	if strings.EqualFold(filepath.Ext(path), ".svg") {
+		return true
+	}
+	head := bytes.TrimPrefix(data[:min(len(data), 1024)], []byte("\xef\xbb\xbf"))
+	return bytes.HasPrefix(bytes.TrimSpace(head), []byte("<")) && bytes.Contains(head, []byte("<svg"))
+}
@@ -68,6 +68,58 @@ func TestLoadImageMissing(t *testing.T) {
 	}
 }
 
+func TestLoadImageSVG(t *testing.T) {
+	dir := t.TempDir()
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" width="64" viewBox="0 0 32 16"><rect width="32" height="16" fill="#0A84FF"/></svg>`
+	if err := os.WriteFile(filepath.Join(dir, "logo.svg"), []byte(svg), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	loader := infrastructure.NewFSImageLoader(dir)
+	img, err := loader.LoadImage("logo.svg")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if img.Width != 64 || img.Height != 32 {
+		t.Errorf("expected 64x32, got %dx%d", img.Width, img.Height)
+	}
+	if img.Vector == nil || len(img.Vector.Shapes) != 1 {
+		t.Fatalf("expected one vector shape, got %+v", img.Vector)
+	}
+	if img.Vector.Width != 64 || img.Vector.Height != 32 {
+		t.Errorf("expected vector size 64x32, got %vx%v", img.Vector.Width, img.Vector.Height)
+	}
+}
+
+func TestLoadImageInvalidSVG(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.svg"), []byte(`<svg><path d="X"/></svg>`), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	loader := infrastructure.NewFSImageLoader(dir)
+	_, err := loader.LoadImage("broken.svg")
+	if err == nil {
+		t.Fatal("expected error for invalid svg")
+	}
+	if !strings.Contains(err.Error(), "decode svg") {
+		t.Errorf("expected 'decode svg' in error, got: %s", err)
+	}
+}
+
+func TestLoadImageRasterHasNoVector(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "bg.png"), 10, 10)
+
+	img, err := infrastructure.NewFSImageLoader(dir).LoadImage("bg.png")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if img.Vector != nil {
+		t.Error("expected no vector content for a PNG")
+	}
+}
+
 func createTestPNG(t *testing.T, path string, w, h int) {
 	t.Helper()
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
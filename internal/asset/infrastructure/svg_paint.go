@@ -0,0 +1,362 @@
+package infrastructure
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// fill returns the fill of a shape, or nil when it paints nothing. local and
+// final are the bounds of the shape before and after the transform ctm.
+func (p *svgParser) fill(style svgStyle, local shared.PathBounds, ctm svgMatrix, final shared.PathBounds) *shared.Fill {
+	alpha := style.fillOpacity * style.opacity
+	paint, fallback := splitPaint(style.fill)
+	if id, ok := paintReference(paint); ok {
+		if el := p.gradient(id); el != nil {
+			return p.gradientFill(el, alpha, local, ctm, final)
+		}
+		paint = fallback
+	}
+
+	color, ok := svgColor(paint, style.color, alpha)
+	if !ok {
+		return nil
+	}
+	return shared.SolidFill(color)
+}
+
+// stroke returns the stroke of a shape, or nil when it paints nothing. The
+// width and dashes are scaled by the transform. Gradient strokes are drawn
+// in the color of their first stop.
+func (p *svgParser) stroke(style svgStyle, ctm svgMatrix) *shared.Stroke {
+	width := style.strokeWidth * ctm.scale()
+	if width <= 0 {
+		return nil
+	}
+	alpha := style.strokeOpacity * style.opacity
+
+	paint, fallback := splitPaint(style.stroke)
+	if id, ok := paintReference(paint); ok {
+		paint = fallback
+		if el := p.gradient(id); el != nil {
+			if stops := p.gradientStops(el, 1); len(stops) > 0 {
+				paint = stops[0].Color
+			}
+		}
+	}
+	color, ok := svgColor(paint, style.color, alpha)
+	if !ok {
+		return nil
+	}
+
+	stroke := shared.SolidStroke(color, width, shared.StrokeCenter)
+	for _, d := range style.dash {
+		stroke.Dash = append(stroke.Dash, d*ctm.scale())
+	}
+	return stroke
+}
+
+// splitPaint separates a paint reference from its fallback color.
+func splitPaint(paint string) (string, string) {
+	if !strings.HasPrefix(paint, "url(") {
+		return paint, ""
+	}
+	end := strings.Index(paint, ")")
+	if end < 0 {
+		return paint, ""
+	}
+	return paint[:end+1], strings.TrimSpace(paint[end+1:])
+}
+
+// paintReference returns the element ID of a url(#id) paint.
+func paintReference(paint string) (string, bool) {
+	if !strings.HasPrefix(paint, "url(") || !strings.HasSuffix(paint, ")") {
+		return "", false
+	}
+	ref := strings.Trim(paint[4:len(paint)-1], ` '"`)
+	return strings.TrimPrefix(ref, "#"), true
+}
+
+// gradient returns the gradient element with the given ID.
+func (p *svgParser) gradient(id string) *svgElement {
+	el := p.ids[id]
+	if el == nil || (el.name != "linearGradient" && el.name != "radialGradient") {
+		return nil
+	}
+	return el
+}
+
+// gradientAttr returns an attribute of a gradient, inherited through its
+// chain of href templates.
+func (p *svgParser) gradientAttr(el *svgElement, name string) (string, bool) {
+	for i := 0; el != nil && i < maxSVGDepth; i++ {
+		if v, ok := el.attrs[name]; ok {
+			return v, true
+		}
+		el = p.gradient(strings.TrimPrefix(el.attrs["href"], "#"))
+	}
+	return "", false
+}
+
+// gradientStops returns the stops of a gradient, or of the first template
+// that has any, with their colors scaled by alpha.
+func (p *svgParser) gradientStops(el *svgElement, alpha float64) []shared.ColorStop {
+	for i := 0; el != nil && i < maxSVGDepth; i++ {
+		var stops []shared.ColorStop
+		last := 0.0
+		for _, child := range el.children {
+			if child.name != "stop" {
+				continue
+			}
+			// Offsets are clamped and never decrease
+			offset, _ := svgOpacity(child.attrs["offset"])
+			offset = math.Max(offset, last)
+			last = offset
+
+			stopAlpha := alpha
+			if v, ok := svgOpacity(child.attrs["stop-opacity"]); ok {
+				stopAlpha *= v
+			}
+			value := child.attrs["stop-color"]
+			if value == "" {
+				value = "black"
+			}
+			color, ok := svgColor(value, child.attrs["color"], stopAlpha)
+			if !ok {
+				color = "#00000000"
+			}
+			stops = append(stops, shared.ColorStop{Color: color, Position: offset})
+		}
+		if len(stops) > 0 {
+			return stops
+		}
+		el = p.gradient(strings.TrimPrefix(el.attrs["href"], "#"))
+	}
+	return nil
+}
+
+// gradientFill converts a gradient element to a fill relative to the final
+// bounds of the shape. Gradient points are mapped through the gradient
+// transform, the bounding box (for objectBoundingBox units) and the shape
+// transform. Skewed mappings are approximated: the lines of a linear
+// gradient stay perpendicular to its axis.
+func (p *svgParser) gradientFill(el *svgElement, alpha float64, local shared.PathBounds, ctm svgMatrix, final shared.PathBounds) *shared.Fill {
+	stops := p.gradientStops(el, alpha)
+	if len(stops) == 0 {
+		return nil
+	}
+	if len(stops) == 1 {
+		return shared.SolidFill(stops[0].Color)
+	}
+
+	fw, fh := final.Width(), final.Height()
+	if fw <= 0 || fh <= 0 {
+		return nil
+	}
+
+	units, _ := p.gradientAttr(el, "gradientUnits")
+	transform, _ := p.gradientAttr(el, "gradientTransform")
+	toFinal := ctm
+	if units != "userSpaceOnUse" {
+		toFinal = toFinal.multiply(svgMatrix{local.Width(), 0, 0, local.Height(), local.MinX, local.MinY})
+	}
+	toFinal = toFinal.multiply(parseTransform(transform))
+
+	// toUnit maps a gradient point to the unit square of the final bounds
+	toUnit := func(x, y float64) shared.PathPoint {
+		pt := toFinal.apply(shared.PathPoint{X: x, Y: y})
+		return shared.PathPoint{X: (pt.X - final.MinX) / fw, Y: (pt.Y - final.MinY) / fh}
+	}
+	coord := func(name string, fallback float64) float64 {
+		v, ok := p.gradientAttr(el, name)
+		if !ok {
+			return fallback
+		}
+		if strings.HasSuffix(v, "%") {
+			f, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+			if err != nil {
+				return fallback
+			}
+			return f / 100
+		}
+		f, ok := svgLength(v)
+		if !ok {
+			return fallback
+		}
+		return f
+	}
+
+	if el.name == "radialGradient" {
+		cx, cy, r := coord("cx", 0.5), coord("cy", 0.5), coord("r", 0.5)
+		if r <= 0 {
+			return shared.SolidFill(stops[len(stops)-1].Color)
+		}
+		center := toUnit(cx, cy)
+		rx, ry := toUnit(cx+r, cy), toUnit(cx, cy+r)
+		fill := shared.GradientFill(shared.FillRadialGradient, stops)
+		fill.Center = shared.GradientPoint{X: center.X, Y: center.Y}
+		fill.Size = shared.GradientSize{
+			Width:  2 * math.Hypot(rx.X-center.X, rx.Y-center.Y),
+			Height: 2 * math.Hypot(ry.X-center.X, ry.Y-center.Y),
+		}
+		return fill
+	}
+
+	start := toUnit(coord("x1", 0), coord("y1", 0))
+	end := toUnit(coord("x2", 1), coord("y2", 0))
+	dx, dy := end.X-start.X, end.Y-start.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return shared.SolidFill(stops[len(stops)-1].Color)
+	}
+	fill := shared.GradientFill(shared.FillLinearGradient, stops)
+	fill.Center = shared.GradientPoint{X: (start.X + end.X) / 2, Y: (start.Y + end.Y) / 2}
+	fill.Size = shared.GradientSize{Width: 1, Height: length}
+	// The gradient runs downwards at 0°, rotated clockwise
+	fill.Rotation = math.Atan2(-dx, dy) * 180 / math.Pi
+	return fill
+}
+
+// svgColor converts a color to #RRGGBBAA with its alpha scaled, resolving
+// currentColor to current. It reports false for none and unknown colors.
+func svgColor(value, current string, alpha float64) (string, bool) {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, "currentColor") {
+		value = strings.TrimSpace(current)
+	}
+	rgba, ok := parseSVGColor(strings.ToLower(value))
+	if !ok {
+		return "", false
+	}
+	a := math.Round(math.Min(math.Max(rgba.A*alpha, 0), 1) * 255)
+	return fmt.Sprintf("#%02X%02X%02X%02X", rgba.R, rgba.G, rgba.B, uint8(a)), true
+}
+
+func parseSVGColor(value string) (shared.RGBA, bool) {
+	switch {
+	case value == "" || value == "none":
+		return shared.RGBA{}, false
+	case value == "transparent":
+		return shared.RGBA{}, true
+	case strings.HasPrefix(value, "#"):
+		hex := value[1:]
+		if len(hex) == 3 || len(hex) == 4 {
+			var expanded strings.Builder
+			for _, c := range hex {
+				expanded.WriteRune(c)
+				expanded.WriteRune(c)
+			}
+			hex = expanded.String()
+		}
+		rgba, err := shared.ParseHexColor("#" + hex)
+		return rgba, err == nil
+	case strings.HasPrefix(value, "rgb"):
+		return parseRGBFunction(value)
+	}
+	hex, ok := svgNamedColors[value]
+	if !ok {
+		return shared.RGBA{}, false
+	}
+	rgba, err := shared.ParseHexColor(hex)
+	return rgba, err == nil
+}
+
+// parseRGBFunction parses rgb() and rgba() colors, with comma or space
+// separated channels in numbers or percentages.
+func parseRGBFunction(value string) (shared.RGBA, bool) {
+	open, end := strings.Index(value, "("), strings.LastIndex(value, ")")
+	if open < 0 || end < open {
+		return shared.RGBA{}, false
+	}
+	fields := strings.FieldsFunc(value[open+1:end], func(r rune) bool {
+		return r == ',' || r == ' ' || r == '/' || r == '\t'
+	})
+	if len(fields) != 3 && len(fields) != 4 {
+		return shared.RGBA{}, false
+	}
+
+	var channels [3]uint8
+	for i := range channels {
+		scale := 1.0
+		field := fields[i]
+		if strings.HasSuffix(field, "%") {
+			field, scale = strings.TrimSuffix(field, "%"), 2.55
+		}
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return shared.RGBA{}, false
+		}
+		channels[i] = uint8(math.Round(math.Min(math.Max(v*scale, 0), 255)))
+	}
+	alpha := 1.0
+	if len(fields) == 4 {
+		a, ok := svgOpacity(fields[3])
+		if !ok {
+			return shared.RGBA{}, false
+		}
+		alpha = a
+	}
+	return shared.RGBA{R: channels[0], G: channels[1], B: channels[2], A: alpha}, true
+}
+
+// svgNamedColors holds the CSS basic colors and the extended colors most
+// often found in exported artwork.
+var svgNamedColors = map[string]string{
+	"black":       "#000000",
+	"silver":      "#C0C0C0",
+	"gray":        "#808080",
+	"grey":        "#808080",
+	"white":       "#FFFFFF",
+	"maroon":      "#800000",
+	"red":         "#FF0000",
+	"purple":      "#800080",
+	"fuchsia":     "#FF00FF",
+	"magenta":     "#FF00FF",
+	"green":       "#008000",
+	"lime":        "#00FF00",
+	"olive":       "#808000",
+	"yellow":      "#FFFF00",
+	"navy":        "#000080",
+	"blue":        "#0000FF",
+	"teal":        "#008080",
+	"aqua":        "#00FFFF",
+	"cyan":        "#00FFFF",
+	"orange":      "#FFA500",
+	"gold":        "#FFD700",
+	"pink":        "#FFC0CB",
+	"brown":       "#A52A2A",
+	"darkgray":    "#A9A9A9",
+	"darkgrey":    "#A9A9A9",
+	"lightgray":   "#D3D3D3",
+	"lightgrey":   "#D3D3D3",
+	"dimgray":     "#696969",
+	"dimgrey":     "#696969",
+	"whitesmoke":  "#F5F5F5",
+	"gainsboro":   "#DCDCDC",
+	"darkblue":    "#00008B",
+	"darkgreen":   "#006400",
+	"darkred":     "#8B0000",
+	"steelblue":   "#4682B4",
+	"royalblue":   "#4169E1",
+	"skyblue":     "#87CEEB",
+	"lightblue":   "#ADD8E6",
+	"crimson":     "#DC143C",
+	"tomato":      "#FF6347",
+	"coral":       "#FF7F50",
+	"salmon":      "#FA8072",
+	"indigo":      "#4B0082",
+	"violet":      "#EE82EE",
+	"turquoise":   "#40E0D0",
+	"beige":       "#F5F5DC",
+	"ivory":       "#FFFFF0",
+	"khaki":       "#F0E68C",
+	"tan":         "#D2B48C",
+	"chocolate":   "#D2691E",
+	"forestgreen": "#228B22",
+	"seagreen":    "#2E8B57",
+	"slategray":   "#708090",
+	"slategrey":   "#708090",
+}
@@ -0,0 +1,644 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	asset "github.com/vpedrosa/pen2pdf/internal/asset/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+const (
+	// svgDefaultWidth and svgDefaultHeight size SVG documents that declare
+	// neither dimensions nor a viewBox, like browsers do.
+	svgDefaultWidth  = 300
+	svgDefaultHeight = 150
+
+	// maxSVGDepth bounds the nesting of elements and <use> references, which
+	// may form cycles.
+	maxSVGDepth = 64
+
+	// svgKappa places the control points of a cubic quarter ellipse.
+	svgKappa = 0.5522847498307936
+)
+
+// svgElement is an element of an SVG document. Style declarations override
+// the presentation attributes of the same name.
+type svgElement struct {
+	name     string
+	attrs    map[string]string
+	children []*svgElement
+}
+
+// svgStyle holds the inherited painting properties of an element.
+type svgStyle struct {
+	fill          string
+	fillOpacity   float64
+	fillRule      shared.FillRule
+	stroke        string
+	strokeWidth   float64
+	strokeOpacity float64
+	dash          []float64
+	color         string
+	opacity       float64 // product of the group opacities
+	hidden        bool
+}
+
+// svgParser flattens an SVG document into the shapes of a VectorImage.
+type svgParser struct {
+	ids    map[string]*svgElement
+	shapes []asset.VectorShape
+}
+
+// parseSVG parses a subset of SVG 1.1: paths, basic shapes, groups, <use>
+// references, transforms, and solid or gradient fills. Text, images, clip
+// paths, masks and filters are ignored.
+func parseSVG(data []byte) (*asset.VectorImage, error) {
+	root, err := decodeSVG(data)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height, viewBox := svgSize(root)
+	p := &svgParser{ids: make(map[string]*svgElement)}
+	p.index(root)
+
+	ctm := viewBoxMatrix(viewBox, root.attrs["preserveAspectRatio"], width, height)
+	style := svgStyle{
+		fill:          "black",
+		fillOpacity:   1,
+		fillRule:      shared.FillRuleNonZero,
+		stroke:        "none",
+		strokeWidth:   1,
+		strokeOpacity: 1,
+		color:         "black",
+		opacity:       1,
+	}
+	style = style.inherit(root)
+	if err := p.walkChildren(root, style, ctm, 0); err != nil {
+		return nil, err
+	}
+	return &asset.VectorImage{Width: width, Height: height, Shapes: p.shapes}, nil
+}
+
+// decodeSVG reads the element tree of an SVG document.
+func decodeSVG(data []byte) (*svgElement, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	var root *svgElement
+	var stack []*svgElement
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if root != nil && len(stack) == 0 {
+				break
+			}
+			return nil, fmt.Errorf("parse svg: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			el := &svgElement{name: t.Name.Local, attrs: make(map[string]string)}
+			for _, attr := range t.Attr {
+				el.attrs[attr.Name.Local] = strings.TrimSpace(attr.Value)
+			}
+			for name, value := range parseStyleDeclarations(el.attrs["style"]) {
+				el.attrs[name] = value
+			}
+			if len(stack) == 0 {
+				if root != nil || el.name != "svg" {
+					return nil, fmt.Errorf("parse svg: root element is <%s>, not <svg>", el.name)
+				}
+				root = el
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, el)
+			}
+			stack = append(stack, el)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	return root, nil
+}
+
+// parseStyleDeclarations parses the declarations of a style attribute.
+func parseStyleDeclarations(style string) map[string]string {
+	decls := make(map[string]string)
+	for _, decl := range strings.Split(style, ";") {
+		name, value, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+		decls[strings.ToLower(strings.TrimSpace(name))] = value
+	}
+	return decls
+}
+
+// svgSize returns the intrinsic size of a document and its viewBox. A
+// missing dimension follows the aspect ratio of the viewBox.
+func svgSize(root *svgElement) (width, height float64, viewBox []float64) {
+	viewBox = svgNumbers(root.attrs["viewBox"])
+	if len(viewBox) != 4 || viewBox[2] <= 0 || viewBox[3] <= 0 {
+		viewBox = nil
+	}
+
+	width, hasWidth := svgLength(root.attrs["width"])
+	height, hasHeight := svgLength(root.attrs["height"])
+	hasWidth = hasWidth && width > 0
+	hasHeight = hasHeight && height > 0
+
+	switch {
+	case hasWidth && hasHeight:
+	case viewBox != nil && hasWidth:
+		height = width * viewBox[3] / viewBox[2]
+	case viewBox != nil && hasHeight:
+		width = height * viewBox[2] / viewBox[3]
+	case viewBox != nil:
+		width, height = viewBox[2], viewBox[3]
+	default:
+		if !hasWidth {
+			width = svgDefaultWidth
+		}
+		if !hasHeight {
+			height = svgDefaultHeight
+		}
+	}
+	return width, height, viewBox
+}
+
+// index records the elements of the tree by ID, for gradients and <use>.
+func (p *svgParser) index(el *svgElement) {
+	if id := el.attrs["id"]; id != "" {
+		if _, ok := p.ids[id]; !ok {
+			p.ids[id] = el
+		}
+	}
+	for _, child := range el.children {
+		p.index(child)
+	}
+}
+
+func (p *svgParser) walkChildren(el *svgElement, style svgStyle, ctm svgMatrix, depth int) error {
+	for _, child := range el.children {
+		if err := p.walk(child, style, ctm, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walk adds the shapes painted by an element and its descendants.
+func (p *svgParser) walk(el *svgElement, parent svgStyle, ctm svgMatrix, depth int) error {
+	if depth > maxSVGDepth || el.attrs["display"] == "none" {
+		return nil
+	}
+	style := parent.inherit(el)
+	ctm = ctm.multiply(parseTransform(el.attrs["transform"]))
+
+	switch el.name {
+	case "g", "a", "switch":
+		return p.walkChildren(el, style, ctm, depth)
+	case "svg":
+		x, _ := svgLength(el.attrs["x"])
+		y, _ := svgLength(el.attrs["y"])
+		return p.walkViewport(el, style, ctm.multiply(translateMatrix(x, y)), el.attrs["width"], el.attrs["height"], depth)
+	case "use":
+		target := p.ids[strings.TrimPrefix(el.attrs["href"], "#")]
+		if target == nil {
+			return nil
+		}
+		x, _ := svgLength(el.attrs["x"])
+		y, _ := svgLength(el.attrs["y"])
+		ctm = ctm.multiply(translateMatrix(x, y))
+		if target.name == "symbol" {
+			return p.walkViewport(target, style, ctm, el.attrs["width"], el.attrs["height"], depth)
+		}
+		return p.walk(target, style, ctm, depth+1)
+	case "path", "rect", "circle", "ellipse", "line", "polyline", "polygon":
+		return p.addShape(el, style, ctm)
+	default:
+		// Definitions, text, images and unknown elements paint nothing
+		return nil
+	}
+}
+
+// walkViewport walks the children of a nested <svg> or a <symbol>, mapping
+// its viewBox onto the given viewport size.
+func (p *svgParser) walkViewport(el *svgElement, style svgStyle, ctm svgMatrix, width, height string, depth int) error {
+	viewBox := svgNumbers(el.attrs["viewBox"])
+	w, hasWidth := svgLength(width)
+	h, hasHeight := svgLength(height)
+	if len(viewBox) == 4 && viewBox[2] > 0 && viewBox[3] > 0 {
+		if !hasWidth {
+			w = viewBox[2]
+		}
+		if !hasHeight {
+			h = viewBox[3]
+		}
+		ctm = ctm.multiply(viewBoxMatrix(viewBox, el.attrs["preserveAspectRatio"], w, h))
+	}
+	return p.walkChildren(el, style, ctm, depth)
+}
+
+// addShape adds a basic shape or path with its fill and stroke.
+func (p *svgParser) addShape(el *svgElement, style svgStyle, ctm svgMatrix) error {
+	if style.hidden {
+		return nil
+	}
+	local, err := shapeGeometry(el)
+	if err != nil {
+		return fmt.Errorf("parse svg: %s: %w", el.name, err)
+	}
+	if local == nil || len(local.Segments) == 0 {
+		return nil
+	}
+
+	geometry := ctm.transformGeometry(local)
+	shape := asset.VectorShape{Geometry: geometry, FillRule: style.fillRule}
+	shape.Fill = p.fill(style, local.Bounds(), ctm, geometry.Bounds())
+	shape.Stroke = p.stroke(style, ctm)
+	if shape.Fill == nil && shape.Stroke == nil {
+		return nil
+	}
+	p.shapes = append(p.shapes, shape)
+	return nil
+}
+
+// shapeGeometry returns the outline of a shape element in its own user
+// space, or nil when the shape is empty.
+func shapeGeometry(el *svgElement) (*shared.PathGeometry, error) {
+	attr := func(name string) float64 {
+		v, _ := svgLength(el.attrs[name])
+		return v
+	}
+
+	switch el.name {
+	case "path":
+		return shared.ParsePathData(el.attrs["d"])
+	case "rect":
+		return rectGeometry(attr("x"), attr("y"), attr("width"), attr("height"), el.attrs["rx"], el.attrs["ry"]), nil
+	case "circle":
+		r := attr("r")
+		return ellipseGeometry(attr("cx"), attr("cy"), r, r), nil
+	case "ellipse":
+		return ellipseGeometry(attr("cx"), attr("cy"), attr("rx"), attr("ry")), nil
+	case "line":
+		return &shared.PathGeometry{Segments: []shared.PathSegment{
+			{Op: shared.PathMoveTo, Points: []shared.PathPoint{{X: attr("x1"), Y: attr("y1")}}},
+			{Op: shared.PathLineTo, Points: []shared.PathPoint{{X: attr("x2"), Y: attr("y2")}}},
+		}}, nil
+	case "polyline", "polygon":
+		coords := svgNumbers(el.attrs["points"])
+		if len(coords) < 4 {
+			return nil, nil
+		}
+		g := &shared.PathGeometry{}
+		for i := 0; i+1 < len(coords); i += 2 {
+			op := shared.PathLineTo
+			if i == 0 {
+				op = shared.PathMoveTo
+			}
+			g.Segments = append(g.Segments, shared.PathSegment{Op: op, Points: []shared.PathPoint{{X: coords[i], Y: coords[i+1]}}})
+		}
+		if el.name == "polygon" {
+			g.Segments = append(g.Segments, shared.PathSegment{Op: shared.PathClose})
+		}
+		return g, nil
+	}
+	return nil, nil
+}
+
+// rectGeometry returns a rectangle with corners rounded by rx and ry, where
+// a missing radius takes the value of the other one.
+func rectGeometry(x, y, w, h float64, rxAttr, ryAttr string) *shared.PathGeometry {
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+	rx, hasRX := svgLength(rxAttr)
+	ry, hasRY := svgLength(ryAttr)
+	if !hasRX {
+		rx = ry
+	}
+	if !hasRY {
+		ry = rx
+	}
+	rx = math.Min(math.Max(rx, 0), w/2)
+	ry = math.Min(math.Max(ry, 0), h/2)
+
+	pt := func(px, py float64) shared.PathPoint { return shared.PathPoint{X: px, Y: py} }
+	line := func(px, py float64) shared.PathSegment {
+		return shared.PathSegment{Op: shared.PathLineTo, Points: []shared.PathPoint{pt(px, py)}}
+	}
+	if rx == 0 || ry == 0 {
+		return &shared.PathGeometry{Segments: []shared.PathSegment{
+			{Op: shared.PathMoveTo, Points: []shared.PathPoint{pt(x, y)}},
+			line(x+w, y), line(x+w, y+h), line(x, y+h),
+			{Op: shared.PathClose},
+		}}
+	}
+
+	kx, ky := rx*svgKappa, ry*svgKappa
+	corner := func(c1x, c1y, c2x, c2y, ex, ey float64) shared.PathSegment {
+		return shared.PathSegment{Op: shared.PathCubicTo, Points: []shared.PathPoint{pt(c1x, c1y), pt(c2x, c2y), pt(ex, ey)}}
+	}
+	return &shared.PathGeometry{Segments: []shared.PathSegment{
+		{Op: shared.PathMoveTo, Points: []shared.PathPoint{pt(x+rx, y)}},
+		line(x+w-rx, y),
+		corner(x+w-rx+kx, y, x+w, y+ry-ky, x+w, y+ry),
+		line(x+w, y+h-ry),
+		corner(x+w, y+h-ry+ky, x+w-rx+kx, y+h, x+w-rx, y+h),
+		line(x+rx, y+h),
+		corner(x+rx-kx, y+h, x, y+h-ry+ky, x, y+h-ry),
+		line(x, y+ry),
+		corner(x, y+ry-ky, x+rx-kx, y, x+rx, y),
+		{Op: shared.PathClose},
+	}}
+}
+
+// ellipseGeometry returns an ellipse as four cubic quarter arcs, clockwise
+// from the rightmost point.
+func ellipseGeometry(cx, cy, rx, ry float64) *shared.PathGeometry {
+	if rx <= 0 || ry <= 0 {
+		return nil
+	}
+	kx, ky := rx*svgKappa, ry*svgKappa
+	quarter := func(c1x, c1y, c2x, c2y, ex, ey float64) shared.PathSegment {
+		return shared.PathSegment{Op: shared.PathCubicTo, Points: []shared.PathPoint{
+			{X: c1x, Y: c1y}, {X: c2x, Y: c2y}, {X: ex, Y: ey},
+		}}
+	}
+	return &shared.PathGeometry{Segments: []shared.PathSegment{
+		{Op: shared.PathMoveTo, Points: []shared.PathPoint{{X: cx + rx, Y: cy}}},
+		quarter(cx+rx, cy+ky, cx+kx, cy+ry, cx, cy+ry),
+		quarter(cx-kx, cy+ry, cx-rx, cy+ky, cx-rx, cy),
+		quarter(cx-rx, cy-ky, cx-kx, cy-ry, cx, cy-ry),
+		quarter(cx+kx, cy-ry, cx+rx, cy-ky, cx+rx, cy),
+		{Op: shared.PathClose},
+	}}
+}
+
+// inherit returns the style of an element whose parent has style s.
+func (s svgStyle) inherit(el *svgElement) svgStyle {
+	a := el.attrs
+	if v, ok := a["fill"]; ok && v != "inherit" {
+		s.fill = v
+	}
+	if v, ok := svgOpacity(a["fill-opacity"]); ok {
+		s.fillOpacity = v
+	}
+	switch a["fill-rule"] {
+	case "evenodd":
+		s.fillRule = shared.FillRuleEvenOdd
+	case "nonzero":
+		s.fillRule = shared.FillRuleNonZero
+	}
+	if v, ok := a["stroke"]; ok && v != "inherit" {
+		s.stroke = v
+	}
+	if v, ok := svgLength(a["stroke-width"]); ok {
+		s.strokeWidth = v
+	}
+	if v, ok := svgOpacity(a["stroke-opacity"]); ok {
+		s.strokeOpacity = v
+	}
+	if v, ok := a["stroke-dasharray"]; ok && v != "inherit" {
+		s.dash = svgDashArray(v)
+	}
+	if v, ok := a["color"]; ok && v != "inherit" {
+		s.color = v
+	}
+	if v, ok := svgOpacity(a["opacity"]); ok {
+		s.opacity *= v
+	}
+	switch a["visibility"] {
+	case "hidden", "collapse":
+		s.hidden = true
+	case "visible":
+		s.hidden = false
+	}
+	return s
+}
+
+// svgDashArray parses a dash array; an odd number of values is repeated to
+// make it even, and invalid arrays disable dashing.
+func svgDashArray(value string) []float64 {
+	dash := svgNumbers(value)
+	total := 0.0
+	for _, d := range dash {
+		if d < 0 {
+			return nil
+		}
+		total += d
+	}
+	if total <= 0 {
+		return nil
+	}
+	if len(dash)%2 == 1 {
+		dash = append(dash, dash...)
+	}
+	return dash
+}
+
+// svgOpacity parses an opacity as a number or percentage, clamped to [0, 1].
+func svgOpacity(value string) (float64, bool) {
+	if value == "" {
+		return 0, false
+	}
+	scale := 1.0
+	if strings.HasSuffix(value, "%") {
+		value, scale = strings.TrimSuffix(value, "%"), 0.01
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, false
+	}
+	return math.Min(math.Max(v*scale, 0), 1), true
+}
+
+// svgLengthUnits converts length units to pixels, which are drawn as points.
+var svgLengthUnits = map[string]float64{
+	"":   1,
+	"px": 1,
+	"pt": 96.0 / 72,
+	"pc": 16,
+	"in": 96,
+	"cm": 96 / 2.54,
+	"mm": 96 / 25.4,
+	"em": 16,
+	"ex": 8,
+}
+
+var svgLengthPattern = regexp.MustCompile(`^([-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?)\s*([a-z]*)$`)
+
+// svgLength parses a length in pixels. Percentages and unknown units are
+// not supported and report false.
+func svgLength(value string) (float64, bool) {
+	m := svgLengthPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return 0, false
+	}
+	unit, ok := svgLengthUnits[m[2]]
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return v * unit, true
+}
+
+var svgNumberPattern = regexp.MustCompile(`[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?`)
+
+// svgNumbers returns the numbers of a comma or space separated list.
+func svgNumbers(value string) []float64 {
+	matches := svgNumberPattern.FindAllString(value, -1)
+	numbers := make([]float64, 0, len(matches))
+	for _, m := range matches {
+		v, err := strconv.ParseFloat(m, 64)
+		if err != nil {
+			continue
+		}
+		numbers = append(numbers, v)
+	}
+	return numbers
+}
+
+// svgMatrix is an affine transform [a b c d e f], mapping (x, y) to
+// (a*x + c*y + e, b*x + d*y + f).
+type svgMatrix [6]float64
+
+var identityMatrix = svgMatrix{1, 0, 0, 1, 0, 0}
+
+func translateMatrix(x, y float64) svgMatrix {
+	return svgMatrix{1, 0, 0, 1, x, y}
+}
+
+// multiply returns the transform applying n, then m.
+func (m svgMatrix) multiply(n svgMatrix) svgMatrix {
+	return svgMatrix{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+func (m svgMatrix) apply(p shared.PathPoint) shared.PathPoint {
+	return shared.PathPoint{X: m[0]*p.X + m[2]*p.Y + m[4], Y: m[1]*p.X + m[3]*p.Y + m[5]}
+}
+
+// scale returns the factor by which the transform scales areas, as a
+// length: the stroke width of transformed outlines.
+func (m svgMatrix) scale() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+// transformGeometry returns a transformed copy of a geometry. Affine
+// transforms map cubic curves to cubic curves, so the result is exact.
+func (m svgMatrix) transformGeometry(g *shared.PathGeometry) *shared.PathGeometry {
+	out := &shared.PathGeometry{Segments: make([]shared.PathSegment, len(g.Segments))}
+	for i, seg := range g.Segments {
+		points := make([]shared.PathPoint, len(seg.Points))
+		for j, pt := range seg.Points {
+			points[j] = m.apply(pt)
+		}
+		out.Segments[i] = shared.PathSegment{Op: seg.Op, Points: points}
+	}
+	return out
+}
+
+var svgTransformPattern = regexp.MustCompile(`([a-zA-Z]+)\s*\(([^)]*)\)`)
+
+// parseTransform parses a transform list. Malformed transforms are ignored.
+func parseTransform(value string) svgMatrix {
+	m := identityMatrix
+	for _, match := range svgTransformPattern.FindAllStringSubmatch(value, -1) {
+		args := svgNumbers(match[2])
+		arg := func(i int, fallback float64) float64 {
+			if i < len(args) {
+				return args[i]
+			}
+			return fallback
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		var t svgMatrix
+		switch match[1] {
+		case "matrix":
+			if len(args) != 6 {
+				continue
+			}
+			copy(t[:], args)
+		case "translate":
+			t = translateMatrix(args[0], arg(1, 0))
+		case "scale":
+			t = svgMatrix{args[0], 0, 0, arg(1, args[0]), 0, 0}
+		case "rotate":
+			theta := args[0] * math.Pi / 180
+			cos, sin := math.Cos(theta), math.Sin(theta)
+			cx, cy := arg(1, 0), arg(2, 0)
+			t = translateMatrix(cx, cy).multiply(svgMatrix{cos, sin, -sin, cos, 0, 0}).multiply(translateMatrix(-cx, -cy))
+		case "skewX":
+			t = svgMatrix{1, 0, math.Tan(args[0] * math.Pi / 180), 1, 0, 0}
+		case "skewY":
+			t = svgMatrix{1, math.Tan(args[0] * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			continue
+		}
+		m = m.multiply(t)
+	}
+	return m
+}
+
+// viewBoxMatrix maps a viewBox onto a viewport of the given size, honoring
+// preserveAspectRatio. A nil viewBox maps user units to pixels unchanged.
+func viewBoxMatrix(viewBox []float64, preserve string, width, height float64) svgMatrix {
+	if viewBox == nil {
+		return identityMatrix
+	}
+	sx, sy := width/viewBox[2], height/viewBox[3]
+
+	fields := strings.Fields(preserve)
+	align := "xMidYMid"
+	if len(fields) > 0 {
+		align = fields[0]
+	}
+	if align == "none" {
+		return svgMatrix{sx, 0, 0, sy, -viewBox[0] * sx, -viewBox[1] * sy}
+	}
+
+	scale := math.Min(sx, sy)
+	if len(fields) > 1 && fields[1] == "slice" {
+		scale = math.Max(sx, sy)
+	}
+	tx, ty := -viewBox[0]*scale, -viewBox[1]*scale
+	extraW, extraH := width-viewBox[2]*scale, height-viewBox[3]*scale
+	switch {
+	case strings.Contains(align, "xMid"):
+		tx += extraW / 2
+	case strings.Contains(align, "xMax"):
+		tx += extraW
+	}
+	switch {
+	case strings.Contains(align, "YMid"):
+		ty += extraH / 2
+	case strings.Contains(align, "YMax"):
+		ty += extraH
+	}
+	return svgMatrix{scale, 0, 0, scale, tx, ty}
+}
@@ -0,0 +1,242 @@
+package infrastructure
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	asset "github.com/vpedrosa/pen2pdf/internal/asset/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestSVGSize(t *testing.T) {
+	tests := map[string][2]float64{
+		`<svg width="120" height="40"/>`:           {120, 40},
+		`<svg width="2in" height="72pt"/>`:         {192, 96},
+		`<svg viewBox="0 0 24 12"/>`:               {24, 12},
+		`<svg width="48" viewBox="0 0 24 12"/>`:    {48, 24},
+		`<svg height="100%" viewBox="0,0,24,12"/>`: {24, 12},
+		`<svg/>`: {300, 150},
+		`<?xml version="1.0"?><svg width="10" height="5"/>`: {10, 5},
+	}
+	for doc, want := range tests {
+		img := mustParseSVG(t, doc)
+		if img.Width != want[0] || img.Height != want[1] {
+			t.Errorf("%s: expected %vx%v, got %vx%v", doc, want[0], want[1], img.Width, img.Height)
+		}
+	}
+}
+
+func TestParseSVGRejectsOtherDocuments(t *testing.T) {
+	for _, doc := range []string{`<html><svg/></html>`, `not xml`, `<svg><g>`} {
+		if _, err := parseSVG([]byte(doc)); err == nil {
+			t.Errorf("%s: expected error", doc)
+		}
+	}
+}
+
+func TestParseSVGShapes(t *testing.T) {
+	img := mustParseSVG(t, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 100 100">
+		<rect x="10" y="10" width="30" height="20" rx="4" fill="#f00"/>
+		<circle cx="50" cy="50" r="10" fill="rgb(0, 128, 255)"/>
+		<ellipse cx="50" cy="50" rx="20" ry="10" fill="none" stroke="navy" stroke-width="2"/>
+		<line x1="0" y1="0" x2="100" y2="100" stroke="black"/>
+		<polygon points="0,0 10,0 5,10" fill-rule="evenodd"/>
+		<path d="M0 0 H10 V10 Z" style="fill: teal; fill-opacity: 50%"/>
+		<rect width="0" height="10"/>
+		<text x="0" y="0">ignored</text>
+	</svg>`)
+
+	if len(img.Shapes) != 6 {
+		t.Fatalf("expected 6 shapes, got %d", len(img.Shapes))
+	}
+	if got := img.Shapes[0].Fill.Color; got != "#FF0000FF" {
+		t.Errorf("expected short hex to expand, got %s", got)
+	}
+	if b := img.Shapes[0].Geometry.Bounds(); b != (shared.PathBounds{MinX: 10, MinY: 10, MaxX: 40, MaxY: 30}) {
+		t.Errorf("unexpected rect bounds %+v", b)
+	}
+	if got := img.Shapes[1].Fill.Color; got != "#0080FFFF" {
+		t.Errorf("expected rgb() color, got %s", got)
+	}
+	ellipse := img.Shapes[2]
+	if ellipse.Fill != nil || ellipse.Stroke == nil || ellipse.Stroke.Color != "#000080FF" || ellipse.Stroke.Thickness.Top != 2 {
+		t.Errorf("expected a navy stroke without fill, got fill %+v stroke %+v", ellipse.Fill, ellipse.Stroke)
+	}
+	if img.Shapes[3].Stroke == nil || img.Shapes[3].Stroke.Align != shared.StrokeCenter {
+		t.Errorf("expected centered line stroke, got %+v", img.Shapes[3].Stroke)
+	}
+	if img.Shapes[4].FillRule != shared.FillRuleEvenOdd || img.Shapes[4].Fill.Color != "#000000FF" {
+		t.Errorf("expected evenodd polygon with default black fill, got %+v", img.Shapes[4])
+	}
+	if got := img.Shapes[5].Fill.Color; got != "#00808080" {
+		t.Errorf("expected style declarations with opacity, got %s", got)
+	}
+}
+
+func TestParseSVGGroupsAndTransforms(t *testing.T) {
+	img := mustParseSVG(t, `<svg width="200" height="100" viewBox="0 0 100 50">
+		<g fill="red" opacity="0.5" transform="translate(10 5)">
+			<rect width="10" height="10" transform="scale(2)"/>
+			<rect width="10" height="10" fill="blue" transform="rotate(90 5 5)"/>
+			<g display="none"><rect width="10" height="10"/></g>
+			<rect width="10" height="10" visibility="hidden"/>
+		</g>
+	</svg>`)
+
+	if len(img.Shapes) != 2 {
+		t.Fatalf("expected 2 shapes, got %d", len(img.Shapes))
+	}
+	// viewBox scale 2, translate (10,5), then scale 2
+	if b := img.Shapes[0].Geometry.Bounds(); b != (shared.PathBounds{MinX: 20, MinY: 10, MaxX: 60, MaxY: 50}) {
+		t.Errorf("unexpected bounds %+v", b)
+	}
+	if got := img.Shapes[0].Fill.Color; got != "#FF000080" {
+		t.Errorf("expected inherited red at half opacity, got %s", got)
+	}
+	b := img.Shapes[1].Geometry.Bounds()
+	if !nearly(b.MinX, 20) || !nearly(b.MinY, 10) || !nearly(b.MaxX, 40) || !nearly(b.MaxY, 30) {
+		t.Errorf("expected the rotation around the center to keep the bounds, got %+v", b)
+	}
+}
+
+func TestParseSVGPreserveAspectRatio(t *testing.T) {
+	tests := map[string]shared.PathBounds{
+		"":               {MinX: 25, MinY: 0, MaxX: 75, MaxY: 50},
+		"xMinYMin meet":  {MinX: 0, MinY: 0, MaxX: 50, MaxY: 50},
+		"xMaxYMax meet":  {MinX: 50, MinY: 0, MaxX: 100, MaxY: 50},
+		"none":           {MinX: 0, MinY: 0, MaxX: 100, MaxY: 50},
+		"xMidYMin slice": {MinX: 0, MinY: 0, MaxX: 100, MaxY: 100},
+	}
+	for preserve, want := range tests {
+		img := mustParseSVG(t, `<svg width="100" height="50" viewBox="0 0 10 10" preserveAspectRatio="`+preserve+`">
+			<rect width="10" height="10"/>
+		</svg>`)
+		if b := img.Shapes[0].Geometry.Bounds(); b != want {
+			t.Errorf("%q: expected %+v, got %+v", preserve, want, b)
+		}
+	}
+}
+
+func TestParseSVGUse(t *testing.T) {
+	img := mustParseSVG(t, `<svg xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 100 100">
+		<defs><path id="dot" d="M0 0 H4 V4 H0 Z"/></defs>
+		<use xlink:href="#dot" x="10" y="20" fill="green"/>
+		<use href="#missing"/>
+	</svg>`)
+	if len(img.Shapes) != 1 {
+		t.Fatalf("expected the referenced path once, got %d shapes", len(img.Shapes))
+	}
+	if b := img.Shapes[0].Geometry.Bounds(); b.MinX != 10 || b.MinY != 20 {
+		t.Errorf("expected the use offset, got %+v", b)
+	}
+	if got := img.Shapes[0].Fill.Color; got != "#008000FF" {
+		t.Errorf("expected the use fill to be inherited, got %s", got)
+	}
+}
+
+func TestParseSVGLinearGradient(t *testing.T) {
+	img := mustParseSVG(t, `<svg viewBox="0 0 100 100">
+		<defs>
+			<linearGradient id="base"><stop offset="0" stop-color="#fff"/><stop offset="100%" stop-color="#000" stop-opacity="0.5"/></linearGradient>
+			<linearGradient id="down" href="#base" x1="0" y1="0" x2="0" y2="1"/>
+		</defs>
+		<rect width="100" height="50" fill="url(#base)"/>
+		<rect width="100" height="50" fill="url(#down)"/>
+		<rect width="100" height="50" fill="url(#missing) red"/>
+	</svg>`)
+
+	across := img.Shapes[0].Fill
+	if across.Type != shared.FillLinearGradient || len(across.Stops) != 2 {
+		t.Fatalf("expected a two-stop linear gradient, got %+v", across)
+	}
+	if !nearly(across.Rotation, -90) || across.Center != (shared.GradientPoint{X: 0.5, Y: 0}) || !nearly(across.Size.Height, 1) {
+		t.Errorf("expected a left-to-right gradient, got rotation %v center %+v size %+v", across.Rotation, across.Center, across.Size)
+	}
+	if across.Stops[1].Color != "#00000080" || across.Stops[1].Position != 1 {
+		t.Errorf("unexpected last stop %+v", across.Stops[1])
+	}
+
+	down := img.Shapes[1].Fill
+	if !nearly(down.Rotation, 0) || len(down.Stops) != 2 {
+		t.Errorf("expected the template stops running downwards, got %+v", down)
+	}
+	if got := img.Shapes[2].Fill; got.Type != shared.FillSolid || got.Color != "#FF0000FF" {
+		t.Errorf("expected the fallback color, got %+v", got)
+	}
+}
+
+func TestParseSVGRadialGradientUserSpace(t *testing.T) {
+	img := mustParseSVG(t, `<svg viewBox="0 0 100 100">
+		<radialGradient id="glow" gradientUnits="userSpaceOnUse" cx="50" cy="25" r="25">
+			<stop offset="0" stop-color="yellow"/><stop offset="1" stop-color="orange"/>
+		</radialGradient>
+		<rect width="100" height="50" fill="url(#glow)"/>
+	</svg>`)
+
+	fill := img.Shapes[0].Fill
+	if fill.Type != shared.FillRadialGradient {
+		t.Fatalf("expected radial gradient, got %s", fill.Type)
+	}
+	if fill.Center != (shared.GradientPoint{X: 0.5, Y: 0.5}) {
+		t.Errorf("expected centered gradient, got %+v", fill.Center)
+	}
+	if !nearly(fill.Size.Width, 0.5) || !nearly(fill.Size.Height, 1) {
+		t.Errorf("expected a circle relative to the bounds, got %+v", fill.Size)
+	}
+}
+
+func TestParseSVGStrokeScalesWithTransform(t *testing.T) {
+	img := mustParseSVG(t, `<svg width="40" height="40" viewBox="0 0 10 10">
+		<path d="M0 0 L10 10" stroke="currentColor" color="#336699" stroke-width="0.5" stroke-dasharray="1"/>
+	</svg>`)
+
+	stroke := img.Shapes[0].Stroke
+	if stroke.Color != "#336699FF" || stroke.Thickness.Top != 2 {
+		t.Errorf("expected a 2pt currentColor stroke, got %+v", stroke)
+	}
+	if len(stroke.Dash) != 2 || stroke.Dash[0] != 4 || stroke.Dash[1] != 4 {
+		t.Errorf("expected the odd dash array repeated and scaled, got %v", stroke.Dash)
+	}
+}
+
+func TestParseSVGInvalidPath(t *testing.T) {
+	_, err := parseSVG([]byte(`<svg><path d="L 10 10"/></svg>`))
+	if err == nil {
+		t.Fatal("expected error for invalid path data")
+	}
+	if !strings.Contains(err.Error(), "path") {
+		t.Errorf("expected element name in error, got: %s", err)
+	}
+}
+
+func TestIsSVG(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+		want bool
+	}{
+		{"logo.SVG", "", true},
+		{"logo", "\xef\xbb\xbf<?xml version=\"1.0\"?>\n<svg/>", true},
+		{"logo.png", "\x89PNG\r\n", false},
+		{"notes.xml", "<notes/>", false},
+	}
+	for _, tt := range tests {
+		if got := isSVG(tt.path, []byte(tt.data)); got != tt.want {
+			t.Errorf("isSVG(%q): expected %v, got %v", tt.path, tt.want, got)
+		}
+	}
+}
+
+func mustParseSVG(t *testing.T, doc string) *asset.VectorImage {
+	t.Helper()
+	img, err := parseSVG([]byte(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return img
+}
+
+func nearly(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -2,15 +2,20 @@ package domain
 
 import (
 	"fmt"
+	"math"
 
 	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
 )
 
 // FlexboxEngine implements a subset of CSS Flexbox layout for .pen files.
-type FlexboxEngine struct{}
+// Images are measured with the given ImageMeasurer to size image nodes that
+// lack explicit dimensions; it may be nil.
+type FlexboxEngine struct {
+	images ImageMeasurer
+}
 
-func NewFlexboxEngine() *FlexboxEngine {
-	return &FlexboxEngine{}
+func NewFlexboxEngine(images ImageMeasurer) *FlexboxEngine {
+	return &FlexboxEngine{images: images}
 }
 
 func (e *FlexboxEngine) Layout(doc *shared.Document, measurer TextMeasurer) ([]Page, error) {
@@ -20,8 +25,11 @@ func (e *FlexboxEngine) Layout(doc *shared.Document, measurer TextMeasurer) ([]P
 		if !ok {
 			return nil, fmt.Errorf("top-level node %q must be a frame", child.GetID())
 		}
+		if frame.Visibility.Hidden {
+			continue
+		}
 
-		root := layoutFrame(frame, 0, 0, frame.Width.Value, frame.Height.Value, measurer)
+		root := layoutFrame(frame, 0, 0, frame.Width.Value, frame.Height.Value, measurer, e.images)
 		pages = append(pages, Page{
 			Width:  frame.Width.Value,
 			Height: frame.Height.Value,
@@ -31,7 +39,7 @@ func (e *FlexboxEngine) Layout(doc *shared.Document, measurer TextMeasurer) ([]P
 	return pages, nil
 }
 
-func layoutFrame(frame *shared.Frame, x, y, w, h float64, measurer TextMeasurer) *LayoutBox {
+func layoutFrame(frame *shared.Frame, x, y, w, h float64, measurer TextMeasurer, images ImageMeasurer) *LayoutBox {
 	box := &LayoutBox{
 		X:      x,
 		Y:      y,
@@ -40,217 +48,551 @@ func layoutFrame(frame *shared.Frame, x, y, w, h float64, measurer TextMeasurer)
 		Node:   frame,
 	}
 
-	if len(frame.Children) == 0 {
+	nodes := visibleChildren(frame)
+	if len(nodes) == 0 {
 		return box
 	}
 
-	contentX := x + frame.Padding.Left
-	contentY := y + frame.Padding.Top
-	contentW := w - frame.Padding.Left - frame.Padding.Right
-	contentH := h - frame.Padding.Top - frame.Padding.Bottom
+	insets := contentInsets(frame)
+	contentX := x + insets.Left
+	contentY := y + insets.Top
+	contentW := w - insets.Left - insets.Right
+	contentH := h - insets.Top - insets.Bottom
+
+	// Children in flow are laid out by the frame's layout, and positioned
+	// ones at their x and y, keeping their order for drawing
+	var flow []shared.Node
+	for _, child := range nodes {
+		if !shared.IsPositioned(frame, child) {
+			flow = append(flow, child)
+		}
+	}
+	var flowBoxes []*LayoutBox
+	switch {
+	case len(flow) == 0:
+	case frame.Layout == "grid":
+		flowBoxes = layoutGrid(frame, flow, contentX, contentY, contentW, contentH, measurer, images)
+	default:
+		flowBoxes = layoutStack(frame, flow, contentX, contentY, contentW, contentH, measurer, images)
+	}
+	for _, child := range nodes {
+		if shared.IsPositioned(frame, child) {
+			box.Children = append(box.Children, layoutPositioned(child, x, y, w, h, measurer, images))
+		} else {
+			box.Children = append(box.Children, flowBoxes[0])
+			flowBoxes = flowBoxes[1:]
+		}
+	}
+
+	return box
+}
 
+// layoutStack lays out the children of a vertical or horizontal frame
+// within its content box.
+func layoutStack(frame *shared.Frame, nodes []shared.Node, contentX, contentY, contentW, contentH float64, measurer TextMeasurer, images ImageMeasurer) []*LayoutBox {
 	isVertical := frame.Layout == "vertical"
 
+	boxes := make([]*LayoutBox, 0, len(nodes))
+
 	// Phase 1: Measure all children to determine fixed vs fill_container sizes
-	type childInfo struct {
-		node       shared.Node
-		width      float64
-		height     float64
-		fillWidth  bool
-		fillHeight bool
+	items := make([]flexItem, len(nodes))
+	for i, child := range nodes {
+		items[i] = measureItem(child, contentW, measurer, images)
 	}
 
-	children := make([]childInfo, len(frame.Children))
-	totalFixedMain := 0.0
-	fillCount := 0
-	gaps := 0.0
-	if len(frame.Children) > 1 {
-		gaps = float64(len(frame.Children)-1) * frame.Gap
+	// Phase 2: Break the children into lines. Frames that do not wrap have
+	// a single line as large as their content box
+	mainSize, crossSize := contentW, contentH
+	if isVertical {
+		mainSize, crossSize = contentH, contentW
+	}
+	mainGap, crossGap := axisGaps(frame)
+	lines := [][]flexItem{items}
+	if frame.Wrap {
+		lines = nil
+		for _, n := range lineLengths(itemMains(items, isVertical), mainSize, mainGap) {
+			lines = append(lines, items[:n])
+			items = items[n:]
+		}
 	}
 
-	for i, child := range frame.Children {
-		info := childInfo{node: child}
-
-		switch n := child.(type) {
-		case *shared.Frame:
-			info.fillWidth = n.Width.FillContainer
-			info.fillHeight = n.Height.FillContainer
-			if !info.fillWidth {
-				info.width = n.Width.Value
-			}
-			if !info.fillHeight {
-				info.height = n.Height.Value
+	// Phase 3: Shrink, size and position the children of each line.
+	// Wrapped lines are as thick as their largest child and packed at the
+	// cross start
+	crossStart := 0.0
+	for _, line := range lines {
+		shrinkLine(line, isVertical, mainSize, mainGap, measurer, images)
+		lineCross := crossSize
+		if frame.Wrap {
+			lineCross = lineCrossSize(line, isVertical)
+		}
+		for _, item := range placeLine(frame, line, isVertical, mainSize, lineCross, mainGap) {
+			childX, childY := contentX+item.main, contentY+crossStart+item.cross
+			if isVertical {
+				childX, childY = contentX+crossStart+item.cross, contentY+item.main
 			}
-			// Auto-size: compute intrinsic size when dimension is missing
-			if (info.width == 0 && !info.fillWidth) || (info.height == 0 && !info.fillHeight) {
-				iw, ih := intrinsicSize(n, measurer, contentW)
-				if info.width == 0 && !info.fillWidth {
-					info.width = iw
-				}
-				if info.height == 0 && !info.fillHeight {
-					info.height = ih
+
+			var childBox *LayoutBox
+			switch n := item.node.(type) {
+			case *shared.Frame:
+				childBox = layoutFrame(n, childX, childY, item.width, item.height, measurer, images)
+			case *shared.Text, *shared.Shape, *shared.Path, *shared.Image:
+				childBox = &LayoutBox{
+					X:      childX,
+					Y:      childY,
+					Width:  item.width,
+					Height: item.height,
+					Node:   n,
 				}
 			}
-		case *shared.Text:
-			info.fillWidth = n.Width.FillContainer
-			if !info.fillWidth && n.Width.Value > 0 {
-				info.width = n.Width.Value
+			boxes = append(boxes, childBox)
+		}
+		crossStart += lineCross + crossGap
+	}
+
+	return boxes
+}
+
+// flexItem is a child of a frame measured for layout. Children that fill
+// their container have no size until their line is placed, and keep the
+// dimensions they were measured from for the limits of that size.
+type flexItem struct {
+	node       shared.Node
+	width      float64
+	height     float64
+	fillWidth  bool
+	fillHeight bool
+	widthDim   shared.Dimension
+	heightDim  shared.Dimension
+}
+
+// mainSize returns the size of the item along the main axis, or its
+// minimum when it fills the container along it.
+func (it flexItem) mainSize(isVertical bool) float64 {
+	if isVertical {
+		if it.fillHeight {
+			return it.heightDim.Min
+		}
+		return it.height
+	}
+	if it.fillWidth {
+		return it.widthDim.Min
+	}
+	return it.width
+}
+
+// crossSize returns the size of the item along the cross axis, or its
+// minimum when it fills the container along it.
+func (it flexItem) crossSize(isVertical bool) float64 {
+	return it.mainSize(!isVertical)
+}
+
+// fillsMain reports whether the item fills its container along the main
+// axis.
+func (it flexItem) fillsMain(isVertical bool) bool {
+	if isVertical {
+		return it.fillHeight
+	}
+	return it.fillWidth
+}
+
+// measureHeight measures the height of a text, or of a frame without a
+// height, again at the item's width. Items with no width keep their
+// height, since a zero width means no wrapping to the measurer.
+func (it *flexItem) measureHeight(measurer TextMeasurer, images ImageMeasurer) {
+	if it.width <= 0 {
+		return
+	}
+	switch n := it.node.(type) {
+	case *shared.Text:
+		if measurer != nil {
+			_, it.height = measurer.MeasureText(n.Content, TextStyleOf(n), it.width)
+		}
+	case *shared.Frame:
+		if !it.fillHeight && n.Height.Value == 0 {
+			_, h := intrinsicSize(n, measurer, images, it.width)
+			it.height = n.Height.Clamp(h)
+		}
+	}
+}
+
+// mainDim returns the dimension of the item along the main axis.
+func (it flexItem) mainDim(isVertical bool) shared.Dimension {
+	if isVertical {
+		return it.heightDim
+	}
+	return it.widthDim
+}
+
+// measureItem measures a child of a frame whose content box is contentW
+// wide. Sizes are kept within the limits of the child's dimensions.
+func measureItem(child shared.Node, contentW float64, measurer TextMeasurer, images ImageMeasurer) flexItem {
+	info := flexItem{node: child}
+
+	switch n := child.(type) {
+	case *shared.Frame:
+		info.widthDim, info.heightDim = n.Width, n.Height
+		info.fillWidth = n.Width.FillContainer
+		info.fillHeight = n.Height.FillContainer
+		if !info.fillWidth {
+			info.width = n.Width.Value
+		}
+		if !info.fillHeight {
+			info.height = n.Height.Value
+		}
+		// Auto-size: compute intrinsic size when dimension is missing
+		if (info.width == 0 && !info.fillWidth) || (info.height == 0 && !info.fillHeight) {
+			iw, ih := intrinsicSize(n, measurer, images, contentW)
+			if info.width == 0 && !info.fillWidth {
+				info.width = iw
+			}
+			if info.height == 0 && !info.fillHeight {
+				info.height = ih
 			}
-			// Measure text intrinsic size
-			if measurer != nil {
-				maxW := info.width
-				if maxW == 0 {
-					maxW = contentW
-				}
-				tw, th := measurer.MeasureText(n.Content, n.FontFamily, n.FontSize, n.FontWeight, maxW)
-				if info.width == 0 && !info.fillWidth {
-					info.width = tw
-				}
-				if info.height == 0 {
-					info.height = th
+		}
+	case *shared.Text:
+		info.widthDim = n.Width
+		info.fillWidth = n.Width.FillContainer
+		if !info.fillWidth && n.Width.Value > 0 {
+			info.width = n.Width.Value
+		}
+		// Measure text intrinsic size, wrapping at its maximum width
+		if measurer != nil {
+			maxW := info.width
+			if maxW == 0 {
+				maxW = contentW
+				if n.Width.Max > 0 {
+					maxW = math.Min(maxW, n.Width.Max)
 				}
 			}
-		}
-
-		if isVertical {
-			if info.fillHeight {
-				fillCount++
-			} else {
-				totalFixedMain += info.height
+			// Texts that fill their container keep their natural width
+			// for frames sized to their content
+			tw, th := measurer.MeasureText(n.Content, TextStyleOf(n), maxW)
+			if info.width == 0 {
+				info.width = tw
 			}
-		} else {
-			if info.fillWidth {
-				fillCount++
-			} else {
-				totalFixedMain += info.width
+			if info.height == 0 {
+				info.height = th
 			}
 		}
-
-		children[i] = info
+	case *shared.Shape:
+		info.widthDim, info.heightDim = n.Width, n.Height
+		info.fillWidth = n.Width.FillContainer
+		info.fillHeight = n.Height.FillContainer
+		if !info.fillWidth {
+			info.width = n.Width.Value
+		}
+		if !info.fillHeight {
+			info.height = n.Height.Value
+		}
+	case *shared.Path:
+		info.widthDim, info.heightDim = n.Width, n.Height
+		info.fillWidth = n.Width.FillContainer
+		info.fillHeight = n.Height.FillContainer
+		info.width, info.height = pathSize(n)
+	case *shared.Image:
+		info.widthDim, info.heightDim = n.Width, n.Height
+		info.fillWidth = n.Width.FillContainer
+		info.fillHeight = n.Height.FillContainer
+		info.width, info.height = imageSize(n, images)
 	}
 
-	// Phase 2: Calculate fill_container sizes
-	remainingMain := 0.0
-	if isVertical {
-		remainingMain = contentH - totalFixedMain - gaps
-	} else {
-		remainingMain = contentW - totalFixedMain - gaps
+	if !info.fillWidth {
+		info.width = info.widthDim.Clamp(info.width)
 	}
-	if remainingMain < 0 {
-		remainingMain = 0
+	if !info.fillHeight {
+		info.height = info.heightDim.Clamp(info.height)
 	}
+	return info
+}
+
+// placedItem is a child sized and positioned within its line, with offsets
+// along the main axis of the frame and the cross axis of the line.
+type placedItem struct {
+	flexItem
+	main  float64
+	cross float64
+}
 
-	fillSize := 0.0
-	if fillCount > 0 {
-		fillSize = remainingMain / float64(fillCount)
+// placeLine sizes the fill_container children of a line and positions its
+// children following justifyContent and alignItems.
+func placeLine(frame *shared.Frame, line []flexItem, isVertical bool, mainSize, crossSize, gap float64) []placedItem {
+	totalFixedMain := 0.0
+	var fills []int
+	for i, item := range line {
+		if item.fillsMain(isVertical) {
+			fills = append(fills, i)
+		} else {
+			totalFixedMain += item.mainSize(isVertical)
+		}
+	}
+	gaps := 0.0
+	if len(line) > 1 {
+		gaps = float64(len(line)-1) * gap
 	}
+	fillSizes := fillMainSizes(line, fills, isVertical, mainSize-totalFixedMain-gaps)
 
-	for i := range children {
+	placed := make([]placedItem, len(line))
+	totalFillMain := 0.0
+	for i, item := range line {
 		if isVertical {
-			if children[i].fillHeight {
-				children[i].height = fillSize
+			if item.fillHeight {
+				item.height = fillSizes[i]
+				totalFillMain += item.height
 			}
-			if children[i].fillWidth {
-				children[i].width = contentW
+			if item.fillWidth {
+				item.width = item.widthDim.Clamp(crossSize)
 			}
 		} else {
-			if children[i].fillWidth {
-				children[i].width = fillSize
+			if item.fillWidth {
+				item.width = fillSizes[i]
+				totalFillMain += item.width
 			}
-			if children[i].fillHeight {
-				children[i].height = contentH
+			if item.fillHeight {
+				item.height = item.heightDim.Clamp(crossSize)
 			}
 		}
+		placed[i] = placedItem{flexItem: item}
 	}
 
-	// Phase 3: Position children based on justifyContent and alignItems
-	totalUsedMain := totalFixedMain + float64(fillCount)*fillSize + gaps
+	// Position children based on justifyContent and alignItems
+	totalUsedMain := totalFixedMain + totalFillMain + gaps
 
 	var mainOffset float64
 	var mainSpacing float64
 
 	switch frame.JustifyContent {
 	case "center":
-		if isVertical {
-			mainOffset = (contentH - totalUsedMain) / 2
-		} else {
-			mainOffset = (contentW - totalUsedMain) / 2
-		}
+		mainOffset = (mainSize - totalUsedMain) / 2
 	case "end":
-		if isVertical {
-			mainOffset = contentH - totalUsedMain
-		} else {
-			mainOffset = contentW - totalUsedMain
-		}
+		mainOffset = mainSize - totalUsedMain
 	case "space-between":
-		if len(children) > 1 {
-			totalWithoutGaps := totalFixedMain + float64(fillCount)*fillSize
-			if isVertical {
-				mainSpacing = (contentH - totalWithoutGaps) / float64(len(children)-1)
-			} else {
-				mainSpacing = (contentW - totalWithoutGaps) / float64(len(children)-1)
-			}
+		if len(line) > 1 {
+			totalWithoutGaps := totalFixedMain + totalFillMain
+			mainSpacing = (mainSize - totalWithoutGaps) / float64(len(line)-1)
 		}
 	default: // "start" or empty
 		mainOffset = 0
 	}
 
 	currentMain := mainOffset
-
-	for i, info := range children {
-		var childX, childY float64
-		var childW, childH float64
-
-		childW = info.width
-		childH = info.height
-
+	for i := range placed {
+		item := &placed[i]
+		item.main = currentMain
 		if isVertical {
-			childY = contentY + currentMain
-			childX = contentX + crossOffset(frame.AlignItems, contentW, childW)
-			currentMain += childH
+			item.cross = crossOffset(frame.AlignItems, crossSize, item.width)
+			currentMain += item.height
 		} else {
-			childX = contentX + currentMain
-			childY = contentY + crossOffset(frame.AlignItems, contentH, childH)
-			currentMain += childW
+			item.cross = crossOffset(frame.AlignItems, crossSize, item.height)
+			currentMain += item.width
 		}
 
 		// Add gap or space-between spacing
-		if i < len(children)-1 {
-			if frame.JustifyContent == "space-between" {
-				currentMain += mainSpacing
+		if frame.JustifyContent == "space-between" {
+			currentMain += mainSpacing
+		} else {
+			currentMain += gap
+		}
+	}
+	return placed
+}
+
+// fillMainSizes shares free space along the main axis among the
+// fill_container children of a line, at indexes fills, in proportion to
+// their grow weights and returns their sizes by index. As in the
+// resolve-flexible-lengths step of CSS flexbox, children clamped to a limit
+// keep it and the others share what is left, until no share breaks a
+// limit.
+func fillMainSizes(line []flexItem, fills []int, isVertical bool, free float64) map[int]float64 {
+	sizes := make(map[int]float64, len(fills))
+	frozen := make(map[int]bool, len(fills))
+	for len(frozen) < len(fills) {
+		remaining, weights := free, 0.0
+		for _, i := range fills {
+			if frozen[i] {
+				remaining -= sizes[i]
 			} else {
-				currentMain += frame.Gap
+				weights += line[i].mainDim(isVertical).GrowWeight()
+			}
+		}
+		share := math.Max(remaining, 0) / weights
+
+		violation := 0.0
+		targets := make(map[int]float64, len(fills))
+		for _, i := range fills {
+			if !frozen[i] {
+				dim := line[i].mainDim(isVertical)
+				targets[i] = share * dim.GrowWeight()
+				sizes[i] = dim.Clamp(targets[i])
+				violation += sizes[i] - targets[i]
 			}
 		}
+		if math.Abs(violation) < lineTolerance {
+			break
+		}
+		// Freeze the children clamped up to their minimum when the line
+		// overflows, or down to their maximum when space is left
+		for i, target := range targets {
+			if violation > 0 && sizes[i] > target || violation < 0 && sizes[i] < target {
+				frozen[i] = true
+			}
+		}
+	}
+	return sizes
+}
 
-		var childBox *LayoutBox
-		switch n := info.node.(type) {
-		case *shared.Frame:
-			childBox = layoutFrame(n, childX, childY, childW, childH, measurer)
-		case *shared.Text:
-			childBox = &LayoutBox{
-				X:      childX,
-				Y:      childY,
-				Width:  childW,
-				Height: childH,
-				Node:   n,
+// shrinkLine shrinks the children of a line that overflows mainSize, as
+// flex-shrink does: each gives up space in proportion to its shrink weight
+// times its size, down to its minimum or, without one, the size of its
+// content (see shrinkMinimum). Children shrunk across their width are
+// measured again, so their text wraps instead of overflowing.
+func shrinkLine(line []flexItem, isVertical bool, mainSize, gap float64, measurer TextMeasurer, images ImageMeasurer) {
+	bases := itemMains(line, isVertical)
+	used := float64(len(line)-1) * gap
+	for _, size := range bases {
+		used += size
+	}
+	if used <= mainSize+lineTolerance {
+		return
+	}
+
+	sizes := append([]float64(nil), bases...)
+	frozen := make([]bool, len(line))
+	minimums := make([]float64, len(line))
+	for i, item := range line {
+		frozen[i] = item.fillsMain(isVertical) || item.mainDim(isVertical).ShrinkWeight() <= 0
+		if !frozen[i] {
+			minimums[i] = shrinkMinimum(item, isVertical, measurer, images)
+		}
+	}
+
+	for {
+		free, scaled := mainSize-float64(len(line)-1)*gap, 0.0
+		for i, item := range line {
+			if frozen[i] {
+				free -= sizes[i]
+			} else {
+				free -= bases[i]
+				scaled += item.mainDim(isVertical).ShrinkWeight() * bases[i]
 			}
 		}
+		if free > -lineTolerance || scaled == 0 {
+			break
+		}
+		// Children shrunk below their minimum keep it, and the others
+		// share the overflow again
+		clamped := false
+		for i, item := range line {
+			if frozen[i] {
+				continue
+			}
+			dim := item.mainDim(isVertical)
+			target := math.Max(bases[i]+free*dim.ShrinkWeight()*bases[i]/scaled, 0)
+			if sizes[i] = math.Max(dim.Clamp(target), minimums[i]); sizes[i] > target {
+				frozen[i] = true
+				clamped = true
+			}
+		}
+		if !clamped {
+			break
+		}
+	}
 
-		box.Children = append(box.Children, childBox)
+	for i := range line {
+		if sizes[i] == bases[i] {
+			continue
+		}
+		if isVertical {
+			line[i].height = sizes[i]
+		} else {
+			line[i].width = sizes[i]
+			line[i].measureHeight(measurer, images)
+		}
 	}
+}
 
-	return box
+// axisGaps returns the gaps between the children of a frame along its main
+// axis and between its wrapped lines.
+func axisGaps(frame *shared.Frame) (float64, float64) {
+	row, column := frame.Gaps()
+	if frame.Layout == "vertical" {
+		return row, column
+	}
+	return column, row
+}
+
+// itemMains returns the main sizes of items.
+func itemMains(items []flexItem, isVertical bool) []float64 {
+	mains := make([]float64, len(items))
+	for i, item := range items {
+		mains[i] = item.mainSize(isVertical)
+	}
+	return mains
+}
+
+// lineLengths breaks children of the given main sizes into lines no longer
+// than limit and returns how many children each line holds. A child larger
+// than limit gets a line of its own, and children that fill their container
+// count as empty.
+func lineLengths(mains []float64, limit, gap float64) []int {
+	var lengths []int
+	n, used := 0, 0.0
+	for _, size := range mains {
+		if n > 0 && used+gap+size > limit+lineTolerance {
+			lengths = append(lengths, n)
+			n, used = 0, 0
+		}
+		if n > 0 {
+			used += gap
+		}
+		used += size
+		n++
+	}
+	return append(lengths, n)
+}
+
+// lineTolerance absorbs rounding errors when children fit a line exactly.
+const lineTolerance = 1e-6
+
+// lineCrossSize returns the thickness of a wrapped line: that of its
+// thickest child.
+func lineCrossSize(line []flexItem, isVertical bool) float64 {
+	size := 0.0
+	for _, item := range line {
+		size = math.Max(size, item.crossSize(isVertical))
+	}
+	return size
 }
 
 // intrinsicSize computes the natural size of a frame based on its children.
 // Used when a frame has no explicit width/height and is not fill_container.
-func intrinsicSize(frame *shared.Frame, measurer TextMeasurer, availableW float64) (float64, float64) {
-	padH := frame.Padding.Left + frame.Padding.Right
-	padV := frame.Padding.Top + frame.Padding.Bottom
+// Wrapping frames break their children into lines as wide as their width,
+// or as availableW when it is not set; vertical ones only wrap with a set
+// height. Grids are as wide as their columns.
+func intrinsicSize(frame *shared.Frame, measurer TextMeasurer, images ImageMeasurer, availableW float64) (float64, float64) {
+	insets := contentInsets(frame)
+	padH := insets.Left + insets.Right
+	padV := insets.Top + insets.Bottom
+	if frame.Width.Max > 0 {
+		availableW = math.Min(availableW, frame.Width.Max)
+	}
 
-	if len(frame.Children) == 0 {
-		return padH, padV
+	// Positioned children take no space in the flow. Frames without a
+	// layout reach to the right and bottom edges of their children
+	var nodes []shared.Node
+	var extentW, extentH float64
+	for _, child := range visibleChildren(frame) {
+		if !shared.IsPositioned(frame, child) {
+			nodes = append(nodes, child)
+			continue
+		}
+		if frame.Layout == "none" {
+			right, bottom := positionedExtent(child, availableW, measurer, images)
+			extentW, extentH = math.Max(extentW, right), math.Max(extentH, bottom)
+		}
+	}
+	if len(nodes) == 0 {
+		return math.Max(extentW, padH), math.Max(extentH, padV)
 	}
 
 	contentW := availableW - padH
@@ -258,63 +600,214 @@ func intrinsicSize(frame *shared.Frame, measurer TextMeasurer, availableW float6
 		contentW = 0
 	}
 
+	// Grids share their width, or availableW, among their columns, and
+	// size their rows to their content unless their height is set
+	if frame.Layout == "grid" {
+		gridW, gridH := contentW, -1.0
+		if frame.Width.Value > 0 {
+			gridW = math.Max(frame.Width.Value-padH, 0)
+		}
+		if frame.Height.Value > 0 {
+			gridH = math.Max(frame.Height.Value-padV, 0)
+		}
+		w, h := gridContentSize(frame, nodes, gridW, gridH, measurer, images)
+		return w + padH, h + padV
+	}
+
 	isVertical := frame.Layout == "vertical"
 
-	var totalMain, maxCross float64
-	gaps := 0.0
-	if len(frame.Children) > 1 {
-		gaps = float64(len(frame.Children)-1) * frame.Gap
+	items := make([]flexItem, len(nodes))
+	for i, child := range nodes {
+		items[i] = measureItem(child, contentW, measurer, images)
+		// Texts that fill their container count with their natural width
+		if _, ok := child.(*shared.Text); ok && items[i].fillWidth {
+			items[i].fillWidth = false
+			items[i].width = items[i].widthDim.Clamp(items[i].width)
+		}
 	}
 
-	for _, child := range frame.Children {
-		var cw, ch float64
-
-		switch n := child.(type) {
-		case *shared.Frame:
-			if n.Width.Value > 0 {
-				cw = n.Width.Value
-			} else if !n.Width.FillContainer {
-				cw, _ = intrinsicSize(n, measurer, contentW)
-			}
-			if n.Height.Value > 0 {
-				ch = n.Height.Value
-			} else if !n.Height.FillContainer {
-				_, ch = intrinsicSize(n, measurer, contentW)
-			}
-		case *shared.Text:
-			if n.Width.Value > 0 {
-				cw = n.Width.Value
-			}
-			if measurer != nil {
-				maxW := cw
-				if maxW == 0 {
-					maxW = contentW
-				}
-				tw, th := measurer.MeasureText(n.Content, n.FontFamily, n.FontSize, n.FontWeight, maxW)
-				if cw == 0 {
-					cw = tw
-				}
-				ch = th
-			}
+	// Lines wrap, and shrink, at the frame's size or the width available
+	// to it; vertical frames only with a height
+	mainGap, crossGap := axisGaps(frame)
+	limit := math.Inf(1)
+	switch {
+	case !isVertical && frame.Width.Value > 0:
+		limit = frame.Width.Value - padH
+	case !isVertical:
+		limit = contentW
+	case frame.Height.Value > 0:
+		limit = frame.Height.Value - padV
+	case frame.Height.Max > 0:
+		limit = frame.Height.Max - padV
+	}
+	lines := [][]flexItem{items}
+	if frame.Wrap {
+		lines = nil
+		for _, n := range lineLengths(itemMains(items, isVertical), limit, mainGap) {
+			lines = append(lines, items[:n])
+			items = items[n:]
 		}
+	}
 
-		if isVertical {
-			totalMain += ch
-			if cw > maxCross {
-				maxCross = cw
-			}
-		} else {
-			totalMain += cw
-			if ch > maxCross {
-				maxCross = ch
-			}
+	// The longest line and the lines stacked with their gaps
+	var totalMain, totalCross float64
+	for i, line := range lines {
+		if !math.IsInf(limit, 1) {
+			shrinkLine(line, isVertical, limit, mainGap, measurer, images)
+		}
+		lineMain := float64(len(line)-1) * mainGap
+		for _, size := range itemMains(line, isVertical) {
+			lineMain += size
+		}
+
+		totalMain = math.Max(totalMain, lineMain)
+		totalCross += lineCrossSize(line, isVertical)
+		if i > 0 {
+			totalCross += crossGap
 		}
 	}
 
 	if isVertical {
-		return maxCross + padH, totalMain + gaps + padV
+		return totalCross + padH, totalMain + padV
+	}
+	return totalMain + padH, totalCross + padV
+}
+
+// layoutPositioned lays out a child at its x and y from the top-left corner
+// of a parent at (x, y) of size w×h. Children pinned to the right or bottom
+// edge are placed from that edge, or stretch to it when they fill their
+// container.
+func layoutPositioned(child shared.Node, x, y, w, h float64, measurer TextMeasurer, images ImageMeasurer) *LayoutBox {
+	left, top := shared.NodeOffset(child)
+	pos := shared.NodePosition(child)
+	availableW := w - left
+	if pos.Right != nil {
+		availableW -= *pos.Right
+	}
+
+	item := measureItem(child, math.Max(availableW, 0), measurer, images)
+	childX, childW := positionAxis(left, pos.Right, w, item.width, item.fillWidth)
+	childY, childH := positionAxis(top, pos.Bottom, h, item.height, item.fillHeight)
+	if item.fillWidth {
+		childW = item.widthDim.Clamp(childW)
+	}
+	if item.fillHeight {
+		childH = item.heightDim.Clamp(childH)
+	}
+
+	switch n := child.(type) {
+	case *shared.Frame:
+		return layoutFrame(n, x+childX, y+childY, childW, childH, measurer, images)
+	default:
+		return &LayoutBox{X: x + childX, Y: y + childY, Width: childW, Height: childH, Node: n}
+	}
+}
+
+// positionAxis returns the offset and size along one axis of a positioned
+// child starting at start, or pinned at end from the far edge of a parent
+// of the given length.
+func positionAxis(start float64, end *float64, length, size float64, fill bool) (float64, float64) {
+	switch {
+	case fill && end != nil:
+		return start, math.Max(length-start-*end, 0)
+	case fill:
+		return start, math.Max(length-start, 0)
+	case end != nil:
+		return length - *end - size, size
+	default:
+		return start, size
+	}
+}
+
+// positionedExtent returns how far right and down a positioned child
+// reaches in a frame without a layout. Children pinned to an edge or that
+// fill their container follow the frame's size, so they do not extend it.
+func positionedExtent(child shared.Node, availableW float64, measurer TextMeasurer, images ImageMeasurer) (float64, float64) {
+	left, top := shared.NodeOffset(child)
+	pos := shared.NodePosition(child)
+	item := measureItem(child, math.Max(availableW-left, 0), measurer, images)
+
+	var right, bottom float64
+	if pos.Right == nil && !item.fillWidth {
+		right = left + item.width
+	}
+	if pos.Bottom == nil && !item.fillHeight {
+		bottom = top + item.height
+	}
+	return right, bottom
+}
+
+// pathSize returns the size of a path: its explicit dimensions, or those of
+// its geometry bounds. When only one dimension is set, the other follows the
+// aspect ratio of the geometry.
+func pathSize(path *shared.Path) (float64, float64) {
+	var bw, bh float64
+	if path.Geometry != nil {
+		bounds := path.Geometry.Bounds()
+		bw, bh = bounds.Width(), bounds.Height()
+	}
+
+	return aspectSize(path.Width.Value, path.Height.Value, bw, bh)
+}
+
+// imageSize returns the size of an image node: its explicit dimensions, or
+// the natural size of the image. When only one dimension is set, the other
+// follows the aspect ratio of the image. Images that cannot be measured
+// count as empty.
+func imageSize(image *shared.Image, images ImageMeasurer) (float64, float64) {
+	var nw, nh float64
+	if images != nil {
+		if w, h, err := images.MeasureImage(image.URL); err == nil {
+			nw, nh = w, h
+		}
+	}
+	return aspectSize(image.Width.Value, image.Height.Value, nw, nh)
+}
+
+// aspectSize completes the explicit dimensions w and h of a node with
+// natural size nw×nh, keeping the natural aspect ratio when only one of
+// them is set.
+func aspectSize(w, h, nw, nh float64) (float64, float64) {
+	switch {
+	case w > 0 && h > 0:
+	case w > 0:
+		h = nh
+		if nw > 0 {
+			h = w * nh / nw
+		}
+	case h > 0:
+		w = nw
+		if nh > 0 {
+			w = h * nw / nh
+		}
+	default:
+		w, h = nw, nh
+	}
+	return w, h
+}
+
+// visibleChildren returns the children of a frame that take part in
+// layout; hidden nodes take no space and add no gap.
+func visibleChildren(frame *shared.Frame) []shared.Node {
+	nodes := make([]shared.Node, 0, len(frame.Children))
+	for _, child := range frame.Children {
+		if !shared.IsHidden(child) {
+			nodes = append(nodes, child)
+		}
+	}
+	return nodes
+}
+
+// contentInsets returns the space between a frame's edges and its content
+// box: the padding plus the width of an inside stroke.
+func contentInsets(frame *shared.Frame) shared.Padding {
+	stroke := frame.Stroke.InsideInsets()
+	return shared.Padding{
+		Top:    frame.Padding.Top + stroke.Top,
+		Right:  frame.Padding.Right + stroke.Right,
+		Bottom: frame.Padding.Bottom + stroke.Bottom,
+		Left:   frame.Padding.Left + stroke.Left,
 	}
-	return totalMain + gaps + padH, maxCross + padV
 }
 
 func crossOffset(alignItems string, available, size float64) float64 {
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"testing"
 
 	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
@@ -8,18 +9,18 @@ import (
 
 type stubMeasurer struct{}
 
-func (m *stubMeasurer) MeasureText(text, family string, size float64, weight string, maxWidth float64) (float64, float64) {
+func (m *stubMeasurer) MeasureText(text string, style TextStyle, maxWidth float64) (float64, float64) {
 	// Simple estimation: 8px per char width, size for height
 	w := float64(len(text)) * 8
 	if maxWidth > 0 && w > maxWidth {
 		w = maxWidth
 	}
-	return w, size
+	return w, style.FontSize
 }
 
 func TestIntrinsicSizeEmptyFrame(t *testing.T) {
 	frame := &shared.Frame{ID: "f1"}
-	w, h := intrinsicSize(frame, nil, 800)
+	w, h := intrinsicSize(frame, nil, nil, 800)
 	if w != 0 || h != 0 {
 		t.Errorf("expected (0,0) for empty frame, got (%f,%f)", w, h)
 	}
@@ -30,7 +31,7 @@ func TestIntrinsicSizeEmptyFrameWithPadding(t *testing.T) {
 		ID:      "f1",
 		Padding: shared.UniformPadding(20),
 	}
-	w, h := intrinsicSize(frame, nil, 800)
+	w, h := intrinsicSize(frame, nil, nil, 800)
 	if w != 40 || h != 40 {
 		t.Errorf("expected (40,40) for padded empty frame, got (%f,%f)", w, h)
 	}
@@ -45,7 +46,7 @@ func TestIntrinsicSizeHorizontalFixedChildren(t *testing.T) {
 			&shared.Frame{ID: "b", Width: shared.FixedDimension(200), Height: shared.FixedDimension(80)},
 		},
 	}
-	w, h := intrinsicSize(frame, nil, 800)
+	w, h := intrinsicSize(frame, nil, nil, 800)
 	// horizontal: totalMain = 100+200 = 300, maxCross = 80
 	if w != 300 {
 		t.Errorf("expected width 300, got %f", w)
@@ -64,7 +65,7 @@ func TestIntrinsicSizeVerticalFixedChildren(t *testing.T) {
 			&shared.Frame{ID: "b", Width: shared.FixedDimension(200), Height: shared.FixedDimension(80)},
 		},
 	}
-	w, h := intrinsicSize(frame, nil, 800)
+	w, h := intrinsicSize(frame, nil, nil, 800)
 	// vertical: totalMain = 50+80 = 130, maxCross = 200
 	if w != 200 {
 		t.Errorf("expected width 200, got %f", w)
@@ -85,7 +86,7 @@ func TestIntrinsicSizeWithGap(t *testing.T) {
 			&shared.Frame{ID: "c", Width: shared.FixedDimension(100), Height: shared.FixedDimension(50)},
 		},
 	}
-	w, h := intrinsicSize(frame, nil, 800)
+	w, h := intrinsicSize(frame, nil, nil, 800)
 	// vertical: totalMain = 150, gaps = 2*10 = 20
 	if w != 100 {
 		t.Errorf("expected width 100, got %f", w)
@@ -104,7 +105,7 @@ func TestIntrinsicSizeWithPaddingAndChildren(t *testing.T) {
 			&shared.Frame{ID: "a", Width: shared.FixedDimension(100), Height: shared.FixedDimension(50)},
 		},
 	}
-	w, h := intrinsicSize(frame, nil, 800)
+	w, h := intrinsicSize(frame, nil, nil, 800)
 	// horizontal: totalMain=100 + padH=40, maxCross=50 + padV=20
 	if w != 140 {
 		t.Errorf("expected width 140, got %f", w)
@@ -129,7 +130,7 @@ func TestIntrinsicSizeNestedFrames(t *testing.T) {
 			},
 		},
 	}
-	w, h := intrinsicSize(frame, nil, 800)
+	w, h := intrinsicSize(frame, nil, nil, 800)
 	// inner horizontal: w=100, h=30; outer vertical: w=100, h=30
 	if w != 100 {
 		t.Errorf("expected width 100, got %f", w)
@@ -148,7 +149,7 @@ func TestIntrinsicSizeWithTextNode(t *testing.T) {
 		},
 	}
 	measurer := &stubMeasurer{}
-	w, h := intrinsicSize(frame, measurer, 800)
+	w, h := intrinsicSize(frame, measurer, nil, 800)
 	// "Hello" = 5 chars * 8 = 40 width, 16 height
 	if w != 40 {
 		t.Errorf("expected width 40, got %f", w)
@@ -167,7 +168,7 @@ func TestIntrinsicSizeTextWithFixedWidth(t *testing.T) {
 		},
 	}
 	measurer := &stubMeasurer{}
-	w, h := intrinsicSize(frame, measurer, 800)
+	w, h := intrinsicSize(frame, measurer, nil, 800)
 	// Text has fixed width 200, measurer uses that as maxWidth
 	if w != 200 {
 		t.Errorf("expected width 200, got %f", w)
@@ -177,6 +178,88 @@ func TestIntrinsicSizeTextWithFixedWidth(t *testing.T) {
 	}
 }
 
+func TestIntrinsicSizeIncludesInsideStroke(t *testing.T) {
+	frame := &shared.Frame{
+		ID:      "f1",
+		Padding: shared.UniformPadding(10),
+		Stroke:  &shared.Stroke{Thickness: shared.StrokeThickness{Top: 1, Right: 2, Bottom: 3, Left: 4}, Align: shared.StrokeInside},
+	}
+	w, h := intrinsicSize(frame, nil, nil, 800)
+	if w != 26 || h != 24 {
+		t.Errorf("expected (26,24) for padding plus inside stroke, got (%f,%f)", w, h)
+	}
+}
+
+func TestIntrinsicSizeWrapsToAvailableWidth(t *testing.T) {
+	frame := &shared.Frame{
+		ID:     "f1",
+		Layout: "horizontal",
+		Wrap:   true,
+		Gap:    10,
+		Children: []shared.Node{
+			&shared.Frame{ID: "a", Width: shared.FixedDimension(100), Height: shared.FixedDimension(50)},
+			&shared.Frame{ID: "b", Width: shared.FixedDimension(120), Height: shared.FixedDimension(30)},
+			&shared.Frame{ID: "c", Width: shared.FixedDimension(150), Height: shared.FixedDimension(40)},
+		},
+	}
+	w, h := intrinsicSize(frame, nil, nil, 250)
+	// lines: a+b (230 wide, 50 high) and c (150 wide, 40 high)
+	if w != 230 || h != 100 {
+		t.Errorf("expected (230,100), got (%f,%f)", w, h)
+	}
+
+	// A vertical stack wraps only within a set height
+	frame.Layout = "vertical"
+	if w, h := intrinsicSize(frame, nil, nil, 250); w != 150 || h != 140 {
+		t.Errorf("expected a single column (150,140), got (%f,%f)", w, h)
+	}
+	frame.Height = shared.FixedDimension(90)
+	if w, _ := intrinsicSize(frame, nil, nil, 250); w != 280 { // 120 + 10 + 150
+		t.Errorf("expected two columns 280 wide, got %f", w)
+	}
+}
+
+func TestLineLengths(t *testing.T) {
+	tests := []struct {
+		mains []float64
+		limit float64
+		want  []int
+	}{
+		{[]float64{100, 100, 100}, 320, []int{3}},
+		{[]float64{100, 100, 100}, 319, []int{2, 1}},
+		{[]float64{400, 50, 50}, 300, []int{1, 2}},
+		{[]float64{50, 0, 0}, 50, []int{1, 2}},
+	}
+	for _, tt := range tests {
+		got := lineLengths(tt.mains, tt.limit, 10)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%v in %v: expected %v, got %v", tt.mains, tt.limit, tt.want, got)
+		}
+	}
+}
+
+func TestPositionAxis(t *testing.T) {
+	end := 10.0
+	tests := []struct {
+		name      string
+		end       *float64
+		fill      bool
+		wantStart float64
+		wantSize  float64
+	}{
+		{"from the start", nil, false, 20, 50},
+		{"pinned to the end", &end, false, 140, 50},
+		{"filling to the edge", nil, true, 20, 180},
+		{"stretched to the end", &end, true, 20, 170},
+	}
+	for _, tt := range tests {
+		start, size := positionAxis(20, tt.end, 200, 50, tt.fill)
+		if start != tt.wantStart || size != tt.wantSize {
+			t.Errorf("%s: expected (%v,%v), got (%v,%v)", tt.name, tt.wantStart, tt.wantSize, start, size)
+		}
+	}
+}
+
 func TestCrossOffsetCenter(t *testing.T) {
 	offset := crossOffset("center", 800, 200)
 	if offset != 300 { // (800-200)/2
@@ -204,3 +287,128 @@ func TestCrossOffsetDefault(t *testing.T) {
 		t.Errorf("expected 0 for empty alignItems, got %f", offset)
 	}
 }
+
+func TestFillMainSizes(t *testing.T) {
+	fill := func(min, max float64) flexItem {
+		d := shared.FillContainerDimension()
+		d.Min, d.Max = min, max
+		return flexItem{fillWidth: true, widthDim: d}
+	}
+	tests := []struct {
+		name string
+		line []flexItem
+		free float64
+		want []float64
+	}{
+		{"even shares", []flexItem{fill(0, 0), fill(0, 0)}, 300, []float64{150, 150}},
+		{"maximum frees space", []flexItem{fill(0, 50), fill(0, 0), fill(0, 0)}, 300, []float64{50, 125, 125}},
+		{"minimum takes space", []flexItem{fill(200, 0), fill(0, 0)}, 300, []float64{200, 100}},
+		{"minimums overflow", []flexItem{fill(200, 0), fill(150, 0)}, 300, []float64{200, 150}},
+		{"a share freed by a maximum breaks another", []flexItem{fill(0, 10), fill(0, 100), fill(0, 0)}, 300, []float64{10, 100, 190}},
+	}
+	for _, tt := range tests {
+		fills := make([]int, len(tt.line))
+		for i := range fills {
+			fills[i] = i
+		}
+		sizes := fillMainSizes(tt.line, fills, false, tt.free)
+		got := make([]float64, len(tt.line))
+		for i := range got {
+			got[i] = sizes[i]
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestShrinkLine(t *testing.T) {
+	text := func(width, min float64) flexItem {
+		return flexItem{node: &shared.Text{}, width: width, widthDim: shared.Dimension{Min: min}}
+	}
+	rigidWeight := 0.0
+	fixed := flexItem{node: &shared.Shape{}, width: 100, widthDim: shared.FixedDimension(100)}
+	rigid := fixed
+	rigid.widthDim.Shrink = &rigidWeight
+	fill := flexItem{node: &shared.Shape{}, fillWidth: true, widthDim: shared.FillContainerDimension()}
+
+	tests := []struct {
+		name string
+		line []flexItem
+		gap  float64
+		want []float64
+	}{
+		{"fits", []flexItem{text(100, 0), fixed}, 0, []float64{100, 100}},
+		{"in proportion to size", []flexItem{text(200, 0), text(100, 0), fixed}, 0, []float64{150, 75, 75}},
+		{"down to the minimum", []flexItem{text(200, 180), text(100, 0), fixed}, 0, []float64{180, 60, 60}},
+		{"fills and children without a weight keep their size", []flexItem{text(300, 0), rigid, fill}, 10, []float64{180, 100, 0}},
+	}
+	for _, tt := range tests {
+		shrinkLine(tt.line, false, 300, tt.gap, nil, nil)
+		if got := itemMains(tt.line, false); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestShrinkLineKeepsContent(t *testing.T) {
+	measurer := &stubMeasurer{}
+	text := &shared.Text{Content: "lengthy words", FontSize: 30}
+
+	// A text keeps its longest word, 56 wide, and the box takes the rest
+	row := []flexItem{
+		measureItem(text, 500, measurer, nil),
+		measureItem(&shared.Shape{Width: shared.FixedDimension(200)}, 500, measurer, nil),
+	}
+	shrinkLine(row, false, 150, 0, measurer, nil)
+	if got := itemMains(row, false); fmt.Sprint(got) != "[56 94]" {
+		t.Errorf("expected widths [56 94], got %v", got)
+	}
+
+	// Texts keep their height, while an empty frame gives up the overflow
+	column := []flexItem{
+		measureItem(text, 100, measurer, nil),
+		measureItem(text, 100, measurer, nil),
+		measureItem(&shared.Frame{Width: shared.FixedDimension(100), Height: shared.FixedDimension(60)}, 100, measurer, nil),
+	}
+	shrinkLine(column, true, 90, 0, measurer, nil)
+	if got := itemMains(column, true); fmt.Sprint(got) != "[30 30 30]" {
+		t.Errorf("expected heights [30 30 30], got %v", got)
+	}
+}
+
+func TestMinContentWidth(t *testing.T) {
+	measurer := &stubMeasurer{}
+	rigid := 0.0
+	word := func(content string) *shared.Text { return &shared.Text{Content: content, FontSize: 10} }
+	tests := []struct {
+		name string
+		node shared.Node
+		want float64
+	}{
+		{"longest word", word("a lengthy\nparagraph"), 72},
+		{"no-break spaces join words", word("pay 12\u00a0€"), 56},
+		{"a minimum wins", &shared.Text{Content: "lengthy", Width: shared.Dimension{Min: 20}}, 20},
+		{"a fixed width caps the content", &shared.Text{Content: "lengthy", Width: shared.FixedDimension(30)}, 30},
+		{"empty shapes shrink away", &shared.Shape{Width: shared.FixedDimension(80)}, 0},
+		{"fixed sizes without a weight stay", &shared.Shape{Width: shared.Dimension{Value: 80, Shrink: &rigid}}, 80},
+		{"horizontal stacks add up", &shared.Frame{
+			Gap: 5, Padding: shared.UniformPadding(10),
+			Children: []shared.Node{word("lengthy"), word("abc")},
+		}, 105},
+		{"vertical stacks take the widest", &shared.Frame{
+			Layout:   "vertical",
+			Children: []shared.Node{word("lengthy"), word("abc")},
+		}, 56},
+		{"grids add up their columns", &shared.Frame{
+			Layout: "grid", Gap: 10,
+			Columns:  []shared.GridTrack{shared.FixedTrack(20), shared.FractionTrack(1)},
+			Children: []shared.Node{word("a"), word("lengthy"), word("abc")},
+		}, 90},
+	}
+	for _, tt := range tests {
+		if got := minContentWidth(tt.node, measurer, nil); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
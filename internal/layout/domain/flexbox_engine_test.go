@@ -1,6 +1,7 @@
 package domain_test
 
 import (
+	"fmt"
 	"testing"
 
 	layout "github.com/vpedrosa/pen2pdf/internal/layout/domain"
@@ -12,12 +13,22 @@ type fixedMeasurer struct {
 	height float64
 }
 
-func (m *fixedMeasurer) MeasureText(_, _ string, _ float64, _ string, _ float64) (float64, float64) {
+func (m *fixedMeasurer) MeasureText(_ string, _ layout.TextStyle, _ float64) (float64, float64) {
 	return m.width, m.height
 }
 
+type fixedImages map[string][2]float64
+
+func (m fixedImages) MeasureImage(url string) (float64, float64, error) {
+	size, ok := m[url]
+	if !ok {
+		return 0, 0, fmt.Errorf("image %q not found", url)
+	}
+	return size[0], size[1], nil
+}
+
 func TestFlexboxEngineImplementsPort(t *testing.T) {
-	var _ layout.LayoutEngine = layout.NewFlexboxEngine()
+	var _ layout.LayoutEngine = layout.NewFlexboxEngine(nil)
 }
 
 // --- Issue #19: Fixed dimensions and padding ---
@@ -521,7 +532,7 @@ func TestLayoutTextNodeWithMeasurer(t *testing.T) {
 	}
 
 	measurer := &fixedMeasurer{width: 200, height: 60}
-	engine := layout.NewFlexboxEngine()
+	engine := layout.NewFlexboxEngine(nil)
 	pages, err := engine.Layout(doc, measurer)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -560,12 +571,693 @@ func TestLayoutFillContainerWithGap(t *testing.T) {
 	}
 }
 
+func TestLayoutInsideStrokeInsetsContent(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000),
+				Padding: shared.UniformPadding(10),
+				Stroke:  shared.SolidStroke("#000000", 4, shared.StrokeInside),
+				Layout:  "vertical",
+				Children: []shared.Node{
+					&shared.Frame{
+						ID: "child", Name: "child",
+						Width: shared.FillContainerDimension(), Height: shared.FillContainerDimension(),
+					},
+				},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	child := pages[0].Root.Children[0]
+
+	if child.X != 14 || child.Y != 14 {
+		t.Errorf("expected child at (14,14), got (%f,%f)", child.X, child.Y)
+	}
+	if child.Width != 772 || child.Height != 972 {
+		t.Errorf("expected child 772x972, got %fx%f", child.Width, child.Height)
+	}
+}
+
+func TestLayoutCenterAndOutsideStrokeDoNotAffectLayout(t *testing.T) {
+	for _, align := range []shared.StrokeAlign{shared.StrokeCenter, shared.StrokeOutside} {
+		doc := &shared.Document{
+			Children: []shared.Node{
+				&shared.Frame{
+					ID: "page", Name: "page",
+					Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000),
+					Stroke: shared.SolidStroke("#000000", 4, align),
+					Children: []shared.Node{
+						&shared.Frame{ID: "child", Name: "child", Width: shared.FixedDimension(10), Height: shared.FixedDimension(10)},
+					},
+				},
+			},
+		}
+
+		pages := mustLayout(t, doc)
+		child := pages[0].Root.Children[0]
+		if child.X != 0 || child.Y != 0 {
+			t.Errorf("%s: expected child at (0,0), got (%f,%f)", align, child.X, child.Y)
+		}
+	}
+}
+
+func TestLayoutShapeLeaves(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000),
+				Layout: "vertical", Gap: 10,
+				Children: []shared.Node{
+					&shared.Shape{ID: "rect", Type: shared.NodeTypeRectangle, Width: shared.FixedDimension(200), Height: shared.FixedDimension(100)},
+					&shared.Shape{ID: "divider", Type: shared.NodeTypeLine, Width: shared.FillContainerDimension(), Height: shared.FixedDimension(0)},
+					&shared.Shape{ID: "blob", Type: shared.NodeTypeEllipse, Width: shared.FixedDimension(50), Height: shared.FillContainerDimension()},
+				},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	root := pages[0].Root
+	if len(root.Children) != 3 {
+		t.Fatalf("expected 3 children, got %d", len(root.Children))
+	}
+
+	rect, divider, blob := root.Children[0], root.Children[1], root.Children[2]
+	if rect.Width != 200 || rect.Height != 100 {
+		t.Errorf("expected rectangle 200x100, got %fx%f", rect.Width, rect.Height)
+	}
+	if divider.Y != 110 || divider.Width != 800 || divider.Height != 0 {
+		t.Errorf("expected line at y=110 800x0, got y=%f %fx%f", divider.Y, divider.Width, divider.Height)
+	}
+	// remaining = 1000 - 100 - 0 - 2*10 (gaps) = 880
+	if blob.Y != 120 || blob.Height != 880 {
+		t.Errorf("expected ellipse at y=120 with height 880, got y=%f height %f", blob.Y, blob.Height)
+	}
+	if blob.Node.GetID() != "blob" {
+		t.Errorf("expected ellipse node, got %q", blob.Node.GetID())
+	}
+}
+
+func TestLayoutAutoSizeIncludesShapes(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000),
+				Children: []shared.Node{
+					&shared.Frame{
+						ID: "row", Name: "row", Gap: 5,
+						Children: []shared.Node{
+							&shared.Shape{ID: "a", Type: shared.NodeTypeRectangle, Width: shared.FixedDimension(20), Height: shared.FixedDimension(30)},
+							&shared.Shape{ID: "b", Type: shared.NodeTypePolygon, Sides: 6, Width: shared.FixedDimension(40), Height: shared.FixedDimension(10)},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	row := pages[0].Root.Children[0]
+	if row.Width != 65 || row.Height != 30 {
+		t.Errorf("expected auto-sized row 65x30, got %fx%f", row.Width, row.Height)
+	}
+}
+
+func TestLayoutPathSizing(t *testing.T) {
+	geometry, err := shared.ParsePathData("M2 2 L22 2 L22 12 Z")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000),
+				Layout: "vertical",
+				Children: []shared.Node{
+					&shared.Path{ID: "natural", Geometry: geometry},
+					&shared.Path{ID: "wide", Geometry: geometry, Width: shared.FixedDimension(40)},
+					&shared.Path{ID: "tall", Geometry: geometry, Height: shared.FixedDimension(30)},
+					&shared.Path{ID: "stretched", Geometry: geometry, Width: shared.FixedDimension(10), Height: shared.FixedDimension(10)},
+				},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	want := [][2]float64{{20, 10}, {40, 20}, {60, 30}, {10, 10}}
+	for i, box := range pages[0].Root.Children {
+		if box.Width != want[i][0] || box.Height != want[i][1] {
+			t.Errorf("%s: expected %vx%v, got %vx%v", box.Node.GetID(), want[i][0], want[i][1], box.Width, box.Height)
+		}
+	}
+	if y := pages[0].Root.Children[1].Y; y != 10 {
+		t.Errorf("expected second path at y=10, got %v", y)
+	}
+}
+
+func TestLayoutImageSizing(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000),
+				Layout: "vertical",
+				Children: []shared.Node{
+					&shared.Image{ID: "natural", URL: "photo.png"},
+					&shared.Image{ID: "wide", URL: "photo.png", Width: shared.FixedDimension(100)},
+					&shared.Image{ID: "tall", URL: "photo.png", Height: shared.FixedDimension(50)},
+					&shared.Image{ID: "fixed", URL: "photo.png", Width: shared.FixedDimension(30), Height: shared.FixedDimension(30)},
+					&shared.Image{ID: "missing", URL: "missing.png", Width: shared.FixedDimension(30)},
+				},
+			},
+		},
+	}
+
+	engine := layout.NewFlexboxEngine(fixedImages{"photo.png": {200, 100}})
+	pages, err := engine.Layout(doc, nil)
+	if err != nil {
+		t.Fatalf("unexpected layout error: %v", err)
+	}
+	want := [][2]float64{{200, 100}, {100, 50}, {100, 50}, {30, 30}, {30, 0}}
+	for i, box := range pages[0].Root.Children {
+		if box.Width != want[i][0] || box.Height != want[i][1] {
+			t.Errorf("%s: expected %vx%v, got %vx%v", box.Node.GetID(), want[i][0], want[i][1], box.Width, box.Height)
+		}
+	}
+}
+
+func TestLayoutAutoSizeIncludesImages(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000),
+				Children: []shared.Node{
+					&shared.Frame{
+						ID: "card", Layout: "vertical", Padding: shared.Padding{Top: 10, Right: 10, Bottom: 10, Left: 10},
+						Children: []shared.Node{
+							&shared.Image{ID: "logo", URL: "logo.png", Height: shared.FixedDimension(40)},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	engine := layout.NewFlexboxEngine(fixedImages{"logo.png": {300, 100}})
+	pages, err := engine.Layout(doc, nil)
+	if err != nil {
+		t.Fatalf("unexpected layout error: %v", err)
+	}
+	card := pages[0].Root.Children[0]
+	if card.Width != 140 || card.Height != 60 {
+		t.Errorf("expected card 140x60, got %vx%v", card.Width, card.Height)
+	}
+}
+
+func TestLayoutSkipsHiddenNodes(t *testing.T) {
+	hidden := shared.Visibility{Hidden: true}
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000),
+				Layout: "vertical", Gap: 20,
+				Children: []shared.Node{
+					&shared.Frame{ID: "a", Width: shared.FixedDimension(200), Height: shared.FixedDimension(100)},
+					&shared.Frame{ID: "badge", Width: shared.FixedDimension(200), Height: shared.FixedDimension(100), Visibility: hidden},
+					&shared.Frame{
+						ID: "card", Layout: "horizontal", Gap: 10,
+						Children: []shared.Node{
+							&shared.Shape{ID: "dot", Type: shared.NodeTypeEllipse, Width: shared.FixedDimension(30), Height: shared.FixedDimension(30)},
+							&shared.Image{ID: "logo", Width: shared.FixedDimension(50), Height: shared.FixedDimension(50), Visibility: hidden},
+						},
+					},
+				},
+			},
+			&shared.Frame{ID: "draft", Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000), Visibility: hidden},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	if len(pages) != 1 {
+		t.Fatalf("expected hidden pages to be skipped, got %d pages", len(pages))
+	}
+	root := pages[0].Root
+	if len(root.Children) != 2 {
+		t.Fatalf("expected 2 visible children, got %d", len(root.Children))
+	}
+	card := root.Children[1]
+	if card.Y != 120 { // 100 + one gap, none for the hidden badge
+		t.Errorf("expected card.Y 120, got %v", card.Y)
+	}
+	if card.Width != 30 || card.Height != 30 {
+		t.Errorf("expected the hidden logo to add no size or gap, got %vx%v", card.Width, card.Height)
+	}
+}
+
+func fixedBox(id string, w, h float64) *shared.Frame {
+	return &shared.Frame{ID: id, Width: shared.FixedDimension(w), Height: shared.FixedDimension(h)}
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestLayoutWrapHorizontal(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(300), Height: shared.FixedDimension(400),
+				Layout: "horizontal", Wrap: true, Gap: 10, RowGap: floatPtr(20), AlignItems: "center",
+				Padding: shared.UniformPadding(10),
+				Children: []shared.Node{
+					fixedBox("a", 100, 40),
+					fixedBox("b", 100, 60),
+					fixedBox("c", 100, 40), // 100+10+100+10+100 = 320 > 280
+					fixedBox("d", 280, 20),
+					fixedBox("e", 50, 30),
+				},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	want := map[string][4]float64{
+		"a": {10, 20, 100, 40}, // centered in a 60-high line
+		"b": {120, 10, 100, 60},
+		"c": {10, 90, 100, 40}, // 10 + 60 + 20 row gap
+		"d": {10, 150, 280, 20},
+		"e": {10, 190, 50, 30},
+	}
+	for _, box := range pages[0].Root.Children {
+		w := want[box.Node.GetID()]
+		if box.X != w[0] || box.Y != w[1] || box.Width != w[2] || box.Height != w[3] {
+			t.Errorf("%s: expected %v, got [%v %v %v %v]", box.Node.GetID(), w, box.X, box.Y, box.Width, box.Height)
+		}
+	}
+}
+
+func TestLayoutWrapVertical(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(400), Height: shared.FixedDimension(100),
+				Layout: "vertical", Wrap: true, RowGap: floatPtr(10), ColumnGap: floatPtr(30), JustifyContent: "end",
+				Children: []shared.Node{
+					fixedBox("a", 50, 40),
+					fixedBox("b", 80, 40),
+					fixedBox("c", 60, 70),
+				},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	children := pages[0].Root.Children
+	// Column 1 holds a and b (90 high, justified to the end), column 2 c
+	if a := children[0]; a.X != 0 || a.Y != 10 {
+		t.Errorf("expected a at (0,10), got (%v,%v)", a.X, a.Y)
+	}
+	if b := children[1]; b.X != 0 || b.Y != 60 {
+		t.Errorf("expected b at (0,60), got (%v,%v)", b.X, b.Y)
+	}
+	if c := children[2]; c.X != 110 || c.Y != 30 { // 80 wide column + 30 column gap
+		t.Errorf("expected c at (110,30), got (%v,%v)", c.X, c.Y)
+	}
+}
+
+func TestLayoutWrapFillContainer(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(300), Height: shared.FixedDimension(400),
+				Layout: "horizontal", Wrap: true, Gap: 10,
+				Children: []shared.Node{
+					fixedBox("a", 200, 50),
+					fixedBox("b", 200, 30),
+					&shared.Frame{ID: "fill", Width: shared.FillContainerDimension(), Height: shared.FillContainerDimension()},
+				},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	fill := pages[0].Root.Children[2]
+	// The fill child joins the second line and takes its free space and
+	// thickness
+	if fill.X != 210 || fill.Y != 60 || fill.Width != 90 || fill.Height != 30 {
+		t.Errorf("expected fill at (210,60) 90x30, got (%v,%v) %vx%v", fill.X, fill.Y, fill.Width, fill.Height)
+	}
+}
+
+func TestLayoutWrapAutoHeight(t *testing.T) {
+	tags := &shared.Frame{
+		ID: "tags", Width: shared.FixedDimension(204), Layout: "horizontal", Wrap: true,
+		Gap: 8, Padding: shared.UniformPadding(4),
+	}
+	for i := range 5 {
+		tags.Children = append(tags.Children, fixedBox(fmt.Sprintf("tag%d", i), 60, 20))
+	}
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000),
+				Layout:   "vertical",
+				Children: []shared.Node{tags},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	box := pages[0].Root.Children[0]
+	// Three 60-wide tags fit in 196: two lines of 20 with an 8 gap, plus padding
+	if box.Height != 56 {
+		t.Errorf("expected height 56, got %v", box.Height)
+	}
+	if last := box.Children[4]; last.X != 72 || last.Y != 32 {
+		t.Errorf("expected the last tag at (72,32), got (%v,%v)", last.X, last.Y)
+	}
+}
+
 func mustLayout(t *testing.T, doc *shared.Document) []layout.Page {
 	t.Helper()
-	engine := layout.NewFlexboxEngine()
+	engine := layout.NewFlexboxEngine(nil)
 	pages, err := engine.Layout(doc, nil)
 	if err != nil {
 		t.Fatalf("unexpected layout error: %v", err)
 	}
 	return pages
 }
+
+func TestLayoutFreeform(t *testing.T) {
+	badge := fixedBox("badge", 40, 20)
+	badge.Position = shared.Position{Right: floatPtr(10), Bottom: floatPtr(5)}
+	footer := &shared.Frame{ID: "footer", X: 20, Height: shared.FixedDimension(30), Width: shared.FillContainerDimension()}
+	footer.Position = shared.Position{Right: floatPtr(20), Bottom: floatPtr(0)}
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page", Layout: "none",
+				Width: shared.FixedDimension(300), Height: shared.FixedDimension(200),
+				Padding: shared.UniformPadding(50), // positions ignore padding
+				Children: []shared.Node{
+					&shared.Shape{ID: "logo", Type: shared.NodeTypeRectangle, X: 15, Y: 25, Width: shared.FixedDimension(60), Height: shared.FixedDimension(40)},
+					&shared.Text{ID: "title", X: 100, Y: 30, Width: shared.FixedDimension(120)},
+					badge,
+					footer,
+				},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	want := map[string][4]float64{
+		"logo":   {15, 25, 60, 40},
+		"title":  {100, 30, 120, 0},
+		"badge":  {250, 175, 40, 20},
+		"footer": {20, 170, 260, 30}, // stretched from x to 20 from the right edge
+	}
+	for _, box := range pages[0].Root.Children {
+		w := want[box.Node.GetID()]
+		if box.X != w[0] || box.Y != w[1] || box.Width != w[2] || box.Height != w[3] {
+			t.Errorf("%s: expected %v, got [%v %v %v %v]", box.Node.GetID(), w, box.X, box.Y, box.Width, box.Height)
+		}
+	}
+}
+
+func TestLayoutAbsoluteChildInStack(t *testing.T) {
+	ribbon := fixedBox("ribbon", 50, 16)
+	ribbon.Y = 8
+	ribbon.Position = shared.Position{Absolute: true, Right: floatPtr(0)}
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(400), Height: shared.FixedDimension(400),
+				Layout: "vertical",
+				Children: []shared.Node{
+					&shared.Frame{
+						ID: "card", Layout: "vertical", Gap: 10, Padding: shared.UniformPadding(10),
+						Width:    shared.FixedDimension(200),
+						Children: []shared.Node{fixedBox("a", 100, 40), ribbon, fixedBox("b", 100, 40)},
+					},
+				},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	card := pages[0].Root.Children[0]
+	// The ribbon takes no space in the stack, nor adds a gap
+	if card.Height != 110 {
+		t.Errorf("expected the card to fit a and b only (110), got %v", card.Height)
+	}
+	ids := []string{"a", "ribbon", "b"}
+	for i, child := range card.Children {
+		if child.Node.GetID() != ids[i] {
+			t.Errorf("expected children in document order %v, got %q at %d", ids, child.Node.GetID(), i)
+		}
+	}
+	if r := card.Children[1]; r.X != 150 || r.Y != 8 {
+		t.Errorf("expected the ribbon pinned to the top-right corner at (150,8), got (%v,%v)", r.X, r.Y)
+	}
+	if b := card.Children[2]; b.Y != 60 {
+		t.Errorf("expected b right after a at 60, got %v", b.Y)
+	}
+}
+
+func TestLayoutFreeformAutoSize(t *testing.T) {
+	pinned := fixedBox("pinned", 500, 500)
+	pinned.Position = shared.Position{Right: floatPtr(0)}
+	group := &shared.Frame{
+		ID: "group", Layout: "none",
+		Children: []shared.Node{
+			&shared.Shape{ID: "a", Type: shared.NodeTypeEllipse, X: 10, Y: 20, Width: shared.FixedDimension(30), Height: shared.FixedDimension(30)},
+			&shared.Shape{ID: "b", Type: shared.NodeTypeEllipse, X: 60, Y: 5, Width: shared.FixedDimension(20), Height: shared.FixedDimension(20)},
+			pinned,
+		},
+	}
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000),
+				Layout:   "vertical",
+				Children: []shared.Node{group},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	// The group reaches to b's right edge; the child pinned to the right
+	// follows the group's width, so only its height extends the group
+	box := pages[0].Root.Children[0]
+	if box.Width != 80 || box.Height != 500 {
+		t.Errorf("expected 80x500, got %vx%v", box.Width, box.Height)
+	}
+}
+
+func limited(d shared.Dimension, min, max float64) shared.Dimension {
+	d.Min, d.Max = min, max
+	return d
+}
+
+func TestLayoutFillLimits(t *testing.T) {
+	side := &shared.Frame{ID: "side", Width: limited(shared.FillContainerDimension(), 300, 0), Height: shared.FixedDimension(50)}
+	main := &shared.Frame{ID: "main", Width: shared.FillContainerDimension(), Height: shared.FillContainerDimension()}
+	narrow := &shared.Frame{ID: "narrow", Width: limited(shared.FillContainerDimension(), 0, 50), Height: limited(shared.FillContainerDimension(), 0, 40)}
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(500), Height: shared.FixedDimension(100),
+				Layout:   "horizontal",
+				Children: []shared.Node{side, main, narrow},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	// An even share is 166.67: side grows to its minimum of 300, then the
+	// 200 left is shared until narrow is held to its maximum of 50
+	checkBoxes(t, pages[0].Root.Children, map[string][4]float64{
+		"side":   {0, 0, 300, 50},
+		"main":   {300, 0, 150, 100},
+		"narrow": {450, 0, 50, 40},
+	})
+}
+
+func TestLayoutMaxWidthWrapsText(t *testing.T) {
+	label := &shared.Text{ID: "label", Content: "a long label", FontSize: 10, Width: limited(shared.FitContentDimension(), 0, 120)}
+	button := &shared.Frame{
+		ID: "button", Layout: "vertical", Padding: shared.UniformPadding(10),
+		Width:    limited(shared.FitContentDimension(), 0, 100),
+		Children: []shared.Node{label},
+	}
+	small := &shared.Frame{ID: "small", Width: limited(shared.FixedDimension(10), 30, 0), Height: limited(shared.FixedDimension(90), 0, 60)}
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000),
+				Layout:   "vertical",
+				Children: []shared.Node{button, small},
+			},
+		},
+	}
+
+	pages, err := layout.NewFlexboxEngine(nil).Layout(doc, &wrappingMeasurer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The label wraps within the button's 80 of content
+	checkBoxes(t, pages[0].Root.Children, map[string][4]float64{
+		"button": {0, 0, 100, 40},
+		"small":  {0, 40, 30, 60},
+	})
+	if l := pages[0].Root.Children[0].Children[0]; l.Width != 80 || l.Height != 20 {
+		t.Errorf("expected the label wrapped to 80x20, got %vx%v", l.Width, l.Height)
+	}
+}
+
+func TestLayoutWeightedFill(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(420), Height: shared.FixedDimension(100),
+				Layout: "horizontal", Gap: 10,
+				Children: []shared.Node{
+					&shared.Frame{ID: "a", Width: shared.FillContainerDimension(), Height: shared.FixedDimension(10)},
+					&shared.Frame{ID: "b", Width: shared.WeightedFillDimension(3), Height: shared.FixedDimension(10)},
+					fixedBox("c", 100, 10),
+				},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	// 300 left after c and the gaps, shared one to three
+	checkBoxes(t, pages[0].Root.Children, map[string][4]float64{
+		"a": {0, 0, 75, 10},
+		"b": {85, 0, 225, 10},
+		"c": {320, 0, 100, 10},
+	})
+}
+
+func TestLayoutShrinkWrapsText(t *testing.T) {
+	row := &shared.Frame{
+		ID: "row", Width: shared.FixedDimension(200), Layout: "horizontal",
+		Children: []shared.Node{
+			&shared.Text{ID: "label", Content: "fifteen letters", FontSize: 10},
+			fixedBox("icon", 100, 10),
+		},
+	}
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000),
+				Layout:   "vertical",
+				Children: []shared.Node{row},
+			},
+		},
+	}
+
+	pages, err := layout.NewFlexboxEngine(nil).Layout(doc, &wrappingMeasurer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The 150 wide label and the 100 wide icon share the 50 that do not fit
+	// in proportion to their widths; the row grows to the wrapped label
+	box := pages[0].Root.Children[0]
+	if box.Width != 200 || box.Height != 20 {
+		t.Errorf("expected the row at 200x20, got %vx%v", box.Width, box.Height)
+	}
+	checkBoxes(t, box.Children, map[string][4]float64{
+		"label": {0, 0, 120, 20},
+		"icon":  {120, 0, 80, 10},
+	})
+}
+
+func TestLayoutShrinkKeepsContentHeight(t *testing.T) {
+	column := &shared.Frame{
+		ID: "column", Layout: "vertical",
+		Width: shared.FixedDimension(100), Height: shared.FixedDimension(60),
+	}
+	for _, id := range []string{"t1", "t2", "t3", "t4"} {
+		column.Children = append(column.Children, &shared.Text{ID: id, Content: "line", FontSize: 30})
+	}
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000),
+				Layout:   "vertical",
+				Children: []shared.Node{column},
+			},
+		},
+	}
+
+	pages, err := layout.NewFlexboxEngine(nil).Layout(doc, &fixedMeasurer{width: 40, height: 30})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Texts cannot shrink below their lines, so they overflow the column
+	checkBoxes(t, pages[0].Root.Children[0].Children, map[string][4]float64{
+		"t1": {0, 0, 40, 30},
+		"t2": {0, 30, 40, 30},
+		"t3": {0, 60, 40, 30},
+		"t4": {0, 90, 40, 30},
+	})
+}
+
+func TestLayoutShrinkFixedChildren(t *testing.T) {
+	rigid := 0.0
+	logo := fixedBox("logo", 100, 20)
+	logo.Width.Shrink = &rigid
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(340), Height: shared.FixedDimension(100),
+				Layout: "horizontal",
+				Children: []shared.Node{
+					fixedBox("a", 200, 20),
+					fixedBox("b", 100, 20),
+					logo,
+				},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	// a and b give up the 60 that do not fit two to one; the logo, with
+	// no shrink weight, keeps its width
+	checkBoxes(t, pages[0].Root.Children, map[string][4]float64{
+		"a":    {0, 0, 160, 20},
+		"b":    {160, 0, 80, 20},
+		"logo": {240, 0, 100, 20},
+	})
+}
+
+// wrappingMeasurer measures 10 per character, in lines of 10 when text is
+// wider than maxWidth.
+type wrappingMeasurer struct{}
+
+func (m *wrappingMeasurer) MeasureText(text string, _ layout.TextStyle, maxWidth float64) (float64, float64) {
+	w := float64(len(text)) * 10
+	if maxWidth > 0 && w > maxWidth {
+		return maxWidth, 20
+	}
+	return w, 10
+}
@@ -0,0 +1,333 @@
+package domain
+
+import (
+	"math"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// gridItem is a child of a grid frame with the tracks it covers, 0-based,
+// and its measured size.
+type gridItem struct {
+	flexItem
+	row, column         int
+	rowSpan, columnSpan int
+}
+
+// grid is the result of sizing the tracks of a grid frame.
+type grid struct {
+	items   []gridItem
+	columns []float64
+	rows    []float64
+	rowGap  float64
+	colGap  float64
+}
+
+// layoutGrid sizes the tracks of a grid frame within its content box and
+// lays out each child in the area of the tracks it spans. Children keep
+// their size, unless they fill their container, and are aligned in their
+// area with justifyContent (horizontally) and alignItems (vertically).
+func layoutGrid(frame *shared.Frame, nodes []shared.Node, contentX, contentY, contentW, contentH float64, measurer TextMeasurer, images ImageMeasurer) []*LayoutBox {
+	g := sizeGrid(frame, nodes, contentW, contentH, measurer, images)
+	columnStarts := trackStarts(g.columns, g.colGap)
+	rowStarts := trackStarts(g.rows, g.rowGap)
+
+	boxes := make([]*LayoutBox, 0, len(g.items))
+	for _, item := range g.items {
+		areaW := spanSize(g.columns, item.column, item.columnSpan, g.colGap)
+		areaH := spanSize(g.rows, item.row, item.rowSpan, g.rowGap)
+		w, h := item.width, item.height
+		if item.fillWidth {
+			w = item.widthDim.Clamp(areaW)
+		}
+		if item.fillHeight {
+			h = item.heightDim.Clamp(areaH)
+		}
+		x := contentX + columnStarts[item.column] + crossOffset(frame.JustifyContent, areaW, w)
+		y := contentY + rowStarts[item.row] + crossOffset(frame.AlignItems, areaH, h)
+
+		var box *LayoutBox
+		switch n := item.node.(type) {
+		case *shared.Frame:
+			box = layoutFrame(n, x, y, w, h, measurer, images)
+		case *shared.Text, *shared.Shape, *shared.Path, *shared.Image:
+			box = &LayoutBox{X: x, Y: y, Width: w, Height: h, Node: n}
+		}
+		boxes = append(boxes, box)
+	}
+	return boxes
+}
+
+// gridContentSize returns the size of the tracks of a grid frame and their
+// gaps. Columns share width when it is positive; a negative height sizes
+// fraction rows to their content.
+func gridContentSize(frame *shared.Frame, nodes []shared.Node, width, height float64, measurer TextMeasurer, images ImageMeasurer) (float64, float64) {
+	g := sizeGrid(frame, nodes, width, height, measurer, images)
+	return spanSize(g.columns, 0, len(g.columns), g.colGap), spanSize(g.rows, 0, len(g.rows), g.rowGap)
+}
+
+// sizeGrid places the children of a grid frame and sizes its columns to
+// width, then its rows to height. A negative size is indefinite: fraction
+// tracks then take the size of their content, like auto ones.
+func sizeGrid(frame *shared.Frame, nodes []shared.Node, width, height float64, measurer TextMeasurer, images ImageMeasurer) grid {
+	g := grid{}
+	g.rowGap, g.colGap = frame.Gaps()
+
+	items, rowCount, columnCount := placeGridItems(frame, nodes)
+
+	// Columns fit the natural width of their children. Children in fraction
+	// columns are measured again at the width those columns share without
+	// them, so text wraps within its share instead of claiming the grid
+	columnContents := make([]trackContent, len(items))
+	var fractionItems []int
+	for i := range items {
+		items[i].flexItem = measureItem(items[i].node, width, measurer, images)
+		columnContents[i] = trackContent{items[i].column, items[i].columnSpan, items[i].mainSize(false)}
+		if width >= 0 && spansFraction(frame.Columns, items[i].column, items[i].columnSpan) {
+			fractionItems = append(fractionItems, i)
+		}
+	}
+	if len(fractionItems) > 0 {
+		var fixedContents []trackContent
+		for i, c := range columnContents {
+			if !spansFraction(frame.Columns, items[i].column, items[i].columnSpan) {
+				fixedContents = append(fixedContents, c)
+			}
+		}
+		shares := sizeTracks(frame.Columns, columnCount, width, g.colGap, fixedContents)
+		for _, i := range fractionItems {
+			areaW := spanSize(shares, items[i].column, items[i].columnSpan, g.colGap)
+			columnContents[i].size = measureItem(items[i].node, areaW, measurer, images).mainSize(false)
+		}
+	}
+	g.columns = sizeTracks(frame.Columns, columnCount, width, g.colGap, columnContents)
+
+	// Rows fit the height of their children measured at their area's width,
+	// so text wraps within its columns
+	rowContents := make([]trackContent, len(items))
+	for i := range items {
+		areaW := spanSize(g.columns, items[i].column, items[i].columnSpan, g.colGap)
+		items[i].flexItem = measureItem(items[i].node, areaW, measurer, images)
+		rowContents[i] = trackContent{items[i].row, items[i].rowSpan, items[i].mainSize(true)}
+	}
+	g.rows = sizeTracks(frame.Rows, rowCount, height, g.rowGap, rowContents)
+	g.items = items
+	return g
+}
+
+// placeGridItems assigns the tracks of each child of a grid frame and
+// returns them with the number of rows and columns. Children with a row
+// and column sit there; the others take the next free area along the
+// grid's flow (rows by default), after the previous automatically placed
+// child. Columns (or rows, when flowing by column) are those defined,
+// and rows are added as children need them.
+func placeGridItems(frame *shared.Frame, nodes []shared.Node) ([]gridItem, int, int) {
+	byColumn := frame.GridFlow == "column"
+	// Children are placed along the major axis, which grows, across a
+	// fixed number of minor tracks
+	minorCount := max(len(frame.Columns), 1)
+	majorCount := len(frame.Rows)
+	if byColumn {
+		minorCount, majorCount = max(len(frame.Rows), 1), len(frame.Columns)
+	}
+
+	occupied := make(map[[2]int]bool)
+	fits := func(major, minor, majorSpan, minorSpan int) bool {
+		for i := major; i < major+majorSpan; i++ {
+			for j := minor; j < minor+minorSpan; j++ {
+				if occupied[[2]int{i, j}] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	items := make([]gridItem, 0, len(nodes))
+	cursorMajor, cursorMinor := 0, 0
+	for _, node := range nodes {
+		var cell shared.GridCell
+		if c := shared.NodeGridCell(node); c != nil {
+			cell = *c
+		}
+		major, minor := cell.Row-1, cell.Column-1
+		majorSpan, minorSpan := max(cell.RowSpan, 1), max(cell.ColumnSpan, 1)
+		if byColumn {
+			major, minor = minor, major
+			majorSpan, minorSpan = minorSpan, majorSpan
+		}
+		// Minor tracks are not added, so placements beyond them are clamped
+		minorSpan = min(minorSpan, minorCount)
+		if minor >= 0 {
+			minor = min(minor, minorCount-minorSpan)
+		}
+
+		switch {
+		case major >= 0 && minor >= 0:
+		case major >= 0:
+			minor = 0
+			for m := 0; m+minorSpan <= minorCount; m++ {
+				if fits(major, m, majorSpan, minorSpan) {
+					minor = m
+					break
+				}
+			}
+		case minor >= 0:
+			major = 0
+			for !fits(major, minor, majorSpan, minorSpan) {
+				major++
+			}
+		default:
+			major, minor = cursorMajor, cursorMinor
+			for minor+minorSpan > minorCount || !fits(major, minor, majorSpan, minorSpan) {
+				minor++
+				if minor+minorSpan > minorCount {
+					major, minor = major+1, 0
+				}
+			}
+			cursorMajor, cursorMinor = major, minor+minorSpan
+		}
+
+		for i := major; i < major+majorSpan; i++ {
+			for j := minor; j < minor+minorSpan; j++ {
+				occupied[[2]int{i, j}] = true
+			}
+		}
+		majorCount = max(majorCount, major+majorSpan)
+
+		item := gridItem{flexItem: flexItem{node: node}, row: major, column: minor, rowSpan: majorSpan, columnSpan: minorSpan}
+		if byColumn {
+			item.row, item.column, item.rowSpan, item.columnSpan = minor, major, minorSpan, majorSpan
+		}
+		items = append(items, item)
+	}
+
+	if byColumn {
+		return items, minorCount, majorCount
+	}
+	return items, majorCount, minorCount
+}
+
+// trackContent is the size a child needs across the tracks it spans.
+type trackContent struct {
+	start, span int
+	size        float64
+}
+
+// sizeTracks returns the sizes of count tracks: those defined, then auto
+// ones. Fixed tracks keep their size and auto tracks grow to fit the
+// children in them; children spanning several tracks grow the auto tracks
+// they span when those are too small. Fraction tracks share the space left
+// in available, but are never smaller than their content; without
+// available space (a negative one) they fit their content.
+func sizeTracks(defs []shared.GridTrack, count int, available, gap float64, contents []trackContent) []float64 {
+	tracks := make([]shared.GridTrack, count)
+	for i := range tracks {
+		tracks[i] = shared.AutoTrack()
+		if i < len(defs) {
+			tracks[i] = defs[i]
+		}
+	}
+	fits := func(t shared.GridTrack) bool { return t.Kind != shared.TrackFixed }
+
+	sizes := make([]float64, count)
+	for i, t := range tracks {
+		if t.Kind == shared.TrackFixed {
+			sizes[i] = t.Value
+		}
+	}
+	for _, c := range contents {
+		if c.span == 1 && fits(tracks[c.start]) {
+			sizes[c.start] = math.Max(sizes[c.start], c.size)
+		}
+	}
+	for _, c := range contents {
+		if c.span == 1 {
+			continue
+		}
+		var growable []int
+		for i := c.start; i < c.start+c.span; i++ {
+			if fits(tracks[i]) {
+				growable = append(growable, i)
+			}
+		}
+		if missing := c.size - spanSize(sizes, c.start, c.span, gap); missing > 0 && len(growable) > 0 {
+			for _, i := range growable {
+				sizes[i] += missing / float64(len(growable))
+			}
+		}
+	}
+	if available < 0 {
+		return sizes
+	}
+
+	// Find the size of one fraction: tracks whose content is larger than
+	// their share keep it, and the others share what is left
+	flexible := make(map[int]bool)
+	for i, t := range tracks {
+		if t.Kind == shared.TrackFraction && t.Value > 0 {
+			flexible[i] = true
+		}
+	}
+	for len(flexible) > 0 {
+		free := available - float64(count-1)*gap
+		totalFr := 0.0
+		for i := range sizes {
+			if flexible[i] {
+				totalFr += tracks[i].Value
+			} else {
+				free -= sizes[i]
+			}
+		}
+		fraction := math.Max(free, 0) / totalFr
+		settled := true
+		for i := range flexible {
+			if sizes[i] > fraction*tracks[i].Value {
+				delete(flexible, i)
+				settled = false
+			}
+		}
+		if settled {
+			for i := range flexible {
+				sizes[i] = fraction * tracks[i].Value
+			}
+			break
+		}
+	}
+	return sizes
+}
+
+// spansFraction reports whether any of span tracks from start is a
+// fraction track.
+func spansFraction(defs []shared.GridTrack, start, span int) bool {
+	for i := start; i < start+span && i < len(defs); i++ {
+		if defs[i].Kind == shared.TrackFraction {
+			return true
+		}
+	}
+	return false
+}
+
+// trackStarts returns the offset of each track from the start of the grid.
+func trackStarts(sizes []float64, gap float64) []float64 {
+	starts := make([]float64, len(sizes))
+	offset := 0.0
+	for i, size := range sizes {
+		starts[i] = offset
+		offset += size + gap
+	}
+	return starts
+}
+
+// spanSize returns the size of span tracks from start, with the gaps
+// between them.
+func spanSize(sizes []float64, start, span int, gap float64) float64 {
+	if span <= 0 {
+		return 0
+	}
+	size := float64(span-1) * gap
+	for _, s := range sizes[start : start+span] {
+		size += s
+	}
+	return size
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestSizeTracks(t *testing.T) {
+	fixed, fr, auto := shared.FixedTrack, shared.FractionTrack, shared.AutoTrack()
+	tests := []struct {
+		name      string
+		defs      []shared.GridTrack
+		count     int
+		available float64
+		contents  []trackContent
+		want      []float64
+	}{
+		{"fractions share the free space", []shared.GridTrack{fixed(100), fr(1), fr(3)}, 3, 520, nil, []float64{100, 100, 300}},
+		{"fractions keep their content", []shared.GridTrack{fixed(100), fr(1), fr(1)}, 3, 320, []trackContent{{1, 1, 250}}, []float64{100, 250, 0}},
+		{"spans grow auto tracks", []shared.GridTrack{auto, auto, fixed(50)}, 3, -1, []trackContent{{0, 1, 20}, {0, 3, 200}}, []float64{75, 55, 50}},
+		{"implicit tracks are auto", []shared.GridTrack{fixed(10)}, 2, 100, []trackContent{{1, 1, 35}}, []float64{10, 35}},
+		{"indefinite fractions fit their content", []shared.GridTrack{fr(1), fr(2)}, 2, -1, []trackContent{{0, 1, 30}, {1, 1, 40}}, []float64{30, 40}},
+	}
+	for _, tt := range tests {
+		got := sizeTracks(tt.defs, tt.count, tt.available, 10, tt.contents)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestPlaceGridItems(t *testing.T) {
+	cells := []shared.GridCell{
+		{},                  // next free cell
+		{Row: 2},            // first free column of row 2
+		{Column: 2},         // first free row of column 2
+		{ColumnSpan: 5},     // clamped to the two columns
+		{Column: 9, Row: 1}, // clamped to the last column, over c
+	}
+	frame := &shared.Frame{Columns: []shared.GridTrack{shared.FractionTrack(1), shared.FractionTrack(1)}}
+	for i, cell := range cells {
+		frame.Children = append(frame.Children, &shared.Shape{ID: fmt.Sprint(i), Cell: cell})
+	}
+
+	items, rows, columns := placeGridItems(frame, frame.Children)
+	if rows != 3 || columns != 2 {
+		t.Fatalf("expected a 3x2 grid, got %dx%d", rows, columns)
+	}
+	want := [][4]int{{0, 0, 1, 1}, {1, 0, 1, 1}, {0, 1, 1, 1}, {2, 0, 1, 2}, {0, 1, 1, 1}}
+	for i, item := range items {
+		if got := [4]int{item.row, item.column, item.rowSpan, item.columnSpan}; got != want[i] {
+			t.Errorf("child %d: expected row, column and spans %v, got %v", i, want[i], got)
+		}
+	}
+
+	// Flowing by column swaps the axes: rows are fixed and columns added
+	frame.GridFlow = "column"
+	frame.Rows, frame.Columns = frame.Columns, nil
+	items, rows, columns = placeGridItems(frame, frame.Children[:1])
+	if rows != 2 || columns != 1 || items[0].row != 0 || items[0].column != 0 {
+		t.Errorf("expected the first child at the origin of a 2x1 grid, got %dx%d and %+v", rows, columns, items[0])
+	}
+}
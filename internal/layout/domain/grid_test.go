@@ -0,0 +1,138 @@
+package domain_test
+
+import (
+	"testing"
+
+	layout "github.com/vpedrosa/pen2pdf/internal/layout/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func gridPage(grid *shared.Frame) *shared.Document {
+	grid.ID, grid.Name, grid.Layout = "page", "page", "grid"
+	return &shared.Document{Children: []shared.Node{grid}}
+}
+
+func checkBoxes(t *testing.T, boxes []*layout.LayoutBox, want map[string][4]float64) {
+	t.Helper()
+	for _, box := range boxes {
+		w := want[box.Node.GetID()]
+		if box.X != w[0] || box.Y != w[1] || box.Width != w[2] || box.Height != w[3] {
+			t.Errorf("%s: expected %v, got [%v %v %v %v]", box.Node.GetID(), w, box.X, box.Y, box.Width, box.Height)
+		}
+	}
+}
+
+func TestLayoutGridTracks(t *testing.T) {
+	doc := gridPage(&shared.Frame{
+		Width: shared.FixedDimension(420), Height: shared.FixedDimension(300),
+		Columns:   []shared.GridTrack{shared.FixedTrack(100), shared.FractionTrack(1), shared.FractionTrack(2)},
+		ColumnGap: floatPtr(10), RowGap: floatPtr(20),
+		Children: []shared.Node{
+			fixedBox("a", 50, 40),
+			&shared.Frame{ID: "b", Width: shared.FillContainerDimension(), Height: shared.FillContainerDimension()},
+			fixedBox("c", 60, 30),
+			fixedBox("d", 30, 70),
+		},
+	})
+
+	pages := mustLayout(t, doc)
+	// 300 left after the fixed column and gaps: 100 and 200 for 1fr and 2fr.
+	// Rows are as high as their highest child
+	checkBoxes(t, pages[0].Root.Children, map[string][4]float64{
+		"a": {0, 0, 50, 40},
+		"b": {110, 0, 100, 40},
+		"c": {220, 0, 60, 30},
+		"d": {0, 60, 30, 70},
+	})
+}
+
+func TestLayoutGridSpansAndPlacement(t *testing.T) {
+	header := &shared.Frame{ID: "header", Width: shared.FillContainerDimension(), Height: shared.FixedDimension(20)}
+	header.Cell = shared.GridCell{ColumnSpan: 3}
+	side := &shared.Frame{ID: "side", Width: shared.FixedDimension(50), Height: shared.FillContainerDimension()}
+	side.Cell = shared.GridCell{Column: 1, Row: 2, RowSpan: 2}
+	doc := gridPage(&shared.Frame{
+		Width: shared.FixedDimension(300), Height: shared.FixedDimension(300),
+		Columns:  []shared.GridTrack{shared.FractionTrack(1), shared.FractionTrack(1), shared.FractionTrack(1)},
+		Children: []shared.Node{header, side, fixedBox("x", 40, 30), fixedBox("y", 40, 50)},
+	})
+
+	pages := mustLayout(t, doc)
+	// x and y flow around the side bar, which fills its two rows
+	checkBoxes(t, pages[0].Root.Children, map[string][4]float64{
+		"header": {0, 0, 300, 20},
+		"side":   {0, 20, 50, 50},
+		"x":      {100, 20, 40, 30},
+		"y":      {200, 20, 40, 50},
+	})
+}
+
+func TestLayoutGridFlowByColumn(t *testing.T) {
+	doc := gridPage(&shared.Frame{
+		Width: shared.FixedDimension(300), Height: shared.FixedDimension(300),
+		Rows:     []shared.GridTrack{shared.FixedTrack(30), shared.FixedTrack(30)},
+		GridFlow: "column", AlignItems: "center", JustifyContent: "end",
+		Children: []shared.Node{fixedBox("a", 40, 20), fixedBox("b", 30, 20), fixedBox("c", 40, 20)},
+	})
+
+	pages := mustLayout(t, doc)
+	// Columns are added as needed and sized to their widest child
+	checkBoxes(t, pages[0].Root.Children, map[string][4]float64{
+		"a": {0, 5, 40, 20},
+		"b": {10, 35, 30, 20},
+		"c": {40, 5, 40, 20},
+	})
+}
+
+func TestLayoutGridAutoHeight(t *testing.T) {
+	cards := &shared.Frame{
+		ID: "cards", Width: shared.FixedDimension(210), Layout: "grid", Gap: 10,
+		Columns: []shared.GridTrack{shared.FractionTrack(1), shared.FractionTrack(1)},
+		Padding: shared.UniformPadding(5),
+	}
+	for _, id := range []string{"c1", "c2", "c3", "c4"} {
+		cards.Children = append(cards.Children, fixedBox(id, 50, 30))
+	}
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "page", Name: "page",
+				Width: shared.FixedDimension(800), Height: shared.FixedDimension(1000),
+				Layout:   "vertical",
+				Children: []shared.Node{cards},
+			},
+		},
+	}
+
+	pages := mustLayout(t, doc)
+	box := pages[0].Root.Children[0]
+	// Two rows of 30 with a 10 gap, plus padding
+	if box.Width != 210 || box.Height != 80 {
+		t.Errorf("expected 210x80, got %vx%v", box.Width, box.Height)
+	}
+	if last := box.Children[3]; last.X != 110 || last.Y != 45 { // 5 + 95 + 10
+		t.Errorf("expected the last card at (110,45), got (%v,%v)", last.X, last.Y)
+	}
+}
+
+func TestLayoutGridWrapsTextInFractionColumns(t *testing.T) {
+	doc := gridPage(&shared.Frame{
+		Width: shared.FixedDimension(200), Height: shared.FixedDimension(300),
+		Columns: []shared.GridTrack{shared.FractionTrack(1), shared.FractionTrack(1)},
+		Children: []shared.Node{
+			&shared.Text{ID: "paragraph", Content: "a paragraph far wider than the grid", FontSize: 10},
+			&shared.Text{ID: "note", Content: "ok", FontSize: 10},
+		},
+	})
+
+	pages, err := layout.NewFlexboxEngine(nil).Layout(doc, &wrappingMeasurer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Both columns stay 100 wide: the paragraph wraps within its own instead
+	// of pushing the note out
+	checkBoxes(t, pages[0].Root.Children, map[string][4]float64{
+		"paragraph": {0, 0, 100, 20},
+		"note":      {100, 0, 20, 10},
+	})
+}
@@ -21,10 +21,47 @@ type Page struct {
 	Root   *LayoutBox
 }
 
+// TextStyle holds the text properties that affect measurement.
+type TextStyle struct {
+	FontFamily    string
+	FontSize      float64
+	FontWeight    string
+	FontStyle     string
+	LetterSpacing float64
+	LineHeight    float64
+}
+
+// TextStyleOf extracts the measurement-relevant properties of a text node.
+func TextStyleOf(t *shared.Text) TextStyle {
+	return TextStyle{
+		FontFamily:    t.FontFamily,
+		FontSize:      t.FontSize,
+		FontWeight:    t.FontWeight,
+		FontStyle:     t.FontStyle,
+		LetterSpacing: t.LetterSpacing,
+		LineHeight:    t.LineHeight,
+	}
+}
+
+// LineSpacing returns the distance between consecutive baselines.
+// LineHeight is a multiplier of FontSize; it defaults to 1.2.
+func (s TextStyle) LineSpacing() float64 {
+	if s.LineHeight > 0 {
+		return s.FontSize * s.LineHeight
+	}
+	return s.FontSize * 1.2
+}
+
 // TextMeasurer decouples text measurement from the layout engine,
 // allowing tests to run without real font files.
 type TextMeasurer interface {
-	MeasureText(text string, fontFamily string, fontSize float64, fontWeight string, maxWidth float64) (width, height float64)
+	MeasureText(text string, style TextStyle, maxWidth float64) (width, height float64)
+}
+
+// ImageMeasurer reports the natural size of an image in points, so image
+// nodes without explicit dimensions can be laid out.
+type ImageMeasurer interface {
+	MeasureImage(url string) (width, height float64, err error)
 }
 
 // LayoutEngine computes absolute positions for all nodes in a document.
@@ -9,7 +9,7 @@ import (
 
 type stubTextMeasurer struct{}
 
-func (s *stubTextMeasurer) MeasureText(_, _ string, _ float64, _ string, _ float64) (float64, float64) {
+func (s *stubTextMeasurer) MeasureText(_ string, _ layout.TextStyle, _ float64) (float64, float64) {
 	return 100, 20
 }
 
@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// widthEpsilon absorbs floating point noise when a line is re-broken at the
+// exact width it was measured with.
+const widthEpsilon = 1e-6
+
+// WidthFunc returns the rendered width of a string in the current font.
+type WidthFunc func(s string) float64
+
+// TextLine is a single line produced by the line breaker. X and Y are offsets
+// relative to the top-left corner of the text box; Width excludes trailing
+// whitespace.
+type TextLine struct {
+	Text  string
+	X     float64
+	Y     float64
+	Width float64
+}
+
+// BreakLines splits text into lines that fit within maxWidth. Explicit "\n"
+// always starts a new line; otherwise lines break after spaces and hyphens,
+// and words wider than maxWidth are split between characters. A maxWidth of
+// zero or less disables wrapping. The returned lines are not positioned; use
+// PositionLines for that.
+func BreakLines(text string, maxWidth float64, measure WidthFunc) []TextLine {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	paragraphs := strings.Split(text, "\n")
+
+	lines := make([]TextLine, 0, len(paragraphs))
+	for _, para := range paragraphs {
+		lines = append(lines, breakParagraph(para, maxWidth, measure)...)
+	}
+	return lines
+}
+
+// PositionLines assigns X and Y offsets to each line for the given box width,
+// line height and horizontal alignment ("left", "center" or "right").
+func PositionLines(lines []TextLine, boxWidth, lineHeight float64, align string) {
+	for i := range lines {
+		lines[i].Y = float64(i) * lineHeight
+		switch align {
+		case "center":
+			lines[i].X = (boxWidth - lines[i].Width) / 2
+		case "right":
+			lines[i].X = boxWidth - lines[i].Width
+		default:
+			lines[i].X = 0
+		}
+	}
+}
+
+// LinesSize returns the widest line width and the total height of the lines.
+func LinesSize(lines []TextLine, lineHeight float64) (width, height float64) {
+	for _, l := range lines {
+		if l.Width > width {
+			width = l.Width
+		}
+	}
+	return width, float64(len(lines)) * lineHeight
+}
+
+func breakParagraph(para string, maxWidth float64, measure WidthFunc) []TextLine {
+	if maxWidth <= 0 || measure(trimTrailingSpace(para)) <= maxWidth+widthEpsilon {
+		return []TextLine{newLine(para, measure)}
+	}
+
+	var lines []TextLine
+	current := ""
+	for _, seg := range breakSegments(para) {
+		candidate := current + seg
+		if measure(trimTrailingSpace(candidate)) <= maxWidth+widthEpsilon {
+			current = candidate
+			continue
+		}
+
+		if current != "" {
+			lines = append(lines, newLine(current, measure))
+			current = ""
+		}
+
+		// The segment alone does not fit: split the word between characters,
+		// keeping its trailing whitespace on the last piece.
+		for measure(trimTrailingSpace(seg)) > maxWidth+widthEpsilon {
+			head, tail := splitToFit(seg, maxWidth, measure)
+			lines = append(lines, newLine(head, measure))
+			seg = tail
+		}
+		current = seg
+	}
+
+	if current != "" || len(lines) == 0 {
+		lines = append(lines, newLine(current, measure))
+	}
+	return lines
+}
+
+// breakSegments splits a paragraph into unbreakable segments. Each segment
+// ends right after a break opportunity: a run of breaking spaces or a
+// hyphen.
+func breakSegments(para string) []string {
+	var segs []string
+	start := 0
+	inSpace := false
+
+	for i, r := range para {
+		if inSpace && !isBreakingSpace(r) {
+			segs = append(segs, para[start:i])
+			start = i
+		}
+		inSpace = isBreakingSpace(r)
+		if r == '-' && i > start {
+			end := i + utf8.RuneLen(r)
+			segs = append(segs, para[start:end])
+			start = end
+		}
+	}
+	if start < len(para) {
+		segs = append(segs, para[start:])
+	}
+	return segs
+}
+
+// splitToFit returns the longest prefix of seg that fits within maxWidth
+// (always at least one character) and the remainder.
+func splitToFit(seg string, maxWidth float64, measure WidthFunc) (string, string) {
+	end := 0
+	for i, r := range seg {
+		next := i + utf8.RuneLen(r)
+		if end > 0 && measure(seg[:next]) > maxWidth+widthEpsilon {
+			break
+		}
+		end = next
+	}
+	return seg[:end], seg[end:]
+}
+
+func newLine(s string, measure WidthFunc) TextLine {
+	s = trimTrailingSpace(s)
+	return TextLine{Text: s, Width: measure(s)}
+}
+
+func trimTrailingSpace(s string) string {
+	return strings.TrimRightFunc(s, isBreakingSpace)
+}
+
+// isBreakingSpace reports whether lines may break after r. No-break spaces,
+// as used between the digits and currency of formatted numbers, keep their
+// neighbours on the same line.
+func isBreakingSpace(r rune) bool {
+	switch r {
+	case '\u00a0', '\u2007', '\u202f':
+		return false
+	}
+	return unicode.IsSpace(r)
+}
@@ -0,0 +1,146 @@
+package domain_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	layout "github.com/vpedrosa/pen2pdf/internal/layout/domain"
+)
+
+// monoWidth measures every glyph as 10 units wide.
+func monoWidth(s string) float64 {
+	return float64(utf8.RuneCountInString(s)) * 10
+}
+
+func lineTexts(lines []layout.TextLine) []string {
+	texts := make([]string, len(lines))
+	for i, l := range lines {
+		texts[i] = l.Text
+	}
+	return texts
+}
+
+func assertLines(t *testing.T, got []layout.TextLine, want ...string) {
+	t.Helper()
+	texts := lineTexts(got)
+	if len(texts) != len(want) {
+		t.Fatalf("expected %d lines %q, got %d: %q", len(want), want, len(texts), texts)
+	}
+	for i := range want {
+		if texts[i] != want[i] {
+			t.Errorf("line %d: expected %q, got %q", i, want[i], texts[i])
+		}
+	}
+}
+
+func TestBreakLinesNoWrapWithoutMaxWidth(t *testing.T) {
+	lines := layout.BreakLines("Hello big world", 0, monoWidth)
+	assertLines(t, lines, "Hello big world")
+	if lines[0].Width != 150 {
+		t.Errorf("expected width 150, got %f", lines[0].Width)
+	}
+}
+
+func TestBreakLinesFitsExactly(t *testing.T) {
+	lines := layout.BreakLines("Hello", 50, monoWidth)
+	assertLines(t, lines, "Hello")
+}
+
+func TestBreakLinesAtSpaces(t *testing.T) {
+	// "Hello big " fits in 100 once the trailing space is ignored
+	lines := layout.BreakLines("Hello big world", 100, monoWidth)
+	assertLines(t, lines, "Hello big", "world")
+	if lines[0].Width != 90 {
+		t.Errorf("expected trailing space excluded from width (90), got %f", lines[0].Width)
+	}
+}
+
+func TestBreakLinesKeepsNoBreakSpaces(t *testing.T) {
+	for _, space := range []string{"\u00a0", "\u2007", "\u202f"} {
+		price := "1" + space + "234,56" + space + "€"
+		lines := layout.BreakLines("Pay "+price+" now", 100, monoWidth)
+		assertLines(t, lines, "Pay", price, "now")
+	}
+	// Trailing no-break spaces are part of the text
+	if lines := layout.BreakLines("50\u00a0", 100, monoWidth); lines[0].Width != 30 {
+		t.Errorf("expected the no-break space to be measured, got %v", lines[0].Width)
+	}
+}
+
+func TestBreakLinesExplicitNewlines(t *testing.T) {
+	lines := layout.BreakLines("one\ntwo\r\n\nthree", 0, monoWidth)
+	assertLines(t, lines, "one", "two", "", "three")
+}
+
+func TestBreakLinesAfterHyphen(t *testing.T) {
+	lines := layout.BreakLines("well-known fact", 60, monoWidth)
+	assertLines(t, lines, "well-", "known", "fact")
+}
+
+func TestBreakLinesSplitsOverlongWord(t *testing.T) {
+	lines := layout.BreakLines("abcdefghijkl xy", 50, monoWidth)
+	assertLines(t, lines, "abcde", "fghij", "kl xy")
+}
+
+func TestBreakLinesSplitsAtLeastOneCharacter(t *testing.T) {
+	lines := layout.BreakLines("abc", 5, monoWidth)
+	assertLines(t, lines, "a", "b", "c")
+}
+
+func TestBreakLinesCollapsesTrailingWhitespace(t *testing.T) {
+	lines := layout.BreakLines("aaa     bbb", 50, monoWidth)
+	assertLines(t, lines, "aaa", "bbb")
+}
+
+func TestBreakLinesKeepsLeadingWhitespace(t *testing.T) {
+	lines := layout.BreakLines("  indented", 0, monoWidth)
+	assertLines(t, lines, "  indented")
+}
+
+func TestBreakLinesEmptyText(t *testing.T) {
+	lines := layout.BreakLines("", 100, monoWidth)
+	assertLines(t, lines, "")
+}
+
+func TestBreakLinesIsStableAtMeasuredWidth(t *testing.T) {
+	// Re-breaking at the widest measured line must yield the same lines,
+	// which is what the renderer does with an auto-sized text box.
+	first := layout.BreakLines("The quick brown fox jumps over the lazy dog", 120, monoWidth)
+	width, _ := layout.LinesSize(first, 10)
+	second := layout.BreakLines("The quick brown fox jumps over the lazy dog", width, monoWidth)
+
+	assertLines(t, second, lineTexts(first)...)
+}
+
+func TestPositionLinesAlignment(t *testing.T) {
+	lines := []layout.TextLine{{Text: "ab", Width: 20}, {Text: "abcd", Width: 40}}
+
+	layout.PositionLines(lines, 100, 12, "center")
+	if lines[0].X != 40 || lines[1].X != 30 {
+		t.Errorf("center: expected X (40, 30), got (%f, %f)", lines[0].X, lines[1].X)
+	}
+	if lines[0].Y != 0 || lines[1].Y != 12 {
+		t.Errorf("expected Y (0, 12), got (%f, %f)", lines[0].Y, lines[1].Y)
+	}
+
+	layout.PositionLines(lines, 100, 12, "right")
+	if lines[0].X != 80 || lines[1].X != 60 {
+		t.Errorf("right: expected X (80, 60), got (%f, %f)", lines[0].X, lines[1].X)
+	}
+
+	layout.PositionLines(lines, 100, 12, "left")
+	if lines[0].X != 0 || lines[1].X != 0 {
+		t.Errorf("left: expected X 0, got (%f, %f)", lines[0].X, lines[1].X)
+	}
+}
+
+func TestLinesSize(t *testing.T) {
+	lines := layout.BreakLines("Hello big world", 100, monoWidth)
+	w, h := layout.LinesSize(lines, 20)
+	if w != 90 {
+		t.Errorf("expected width 90, got %f", w)
+	}
+	if h != 40 {
+		t.Errorf("expected height 40, got %f", h)
+	}
+}
@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"math"
+	"strings"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// shrinkMinimum returns how far a child of a line may shrink along the main
+// axis, like the automatic minimum size of CSS flex items: its minimum when
+// set, or else the smallest size its content takes, never more than its
+// current size or its maximum. Texts keep their longest word and their
+// height at their width; frames keep what their children need.
+func shrinkMinimum(item flexItem, isVertical bool, measurer TextMeasurer, images ImageMeasurer) float64 {
+	dim := item.mainDim(isVertical)
+	if dim.Min > 0 {
+		return dim.Min
+	}
+	var content float64
+	if isVertical {
+		content = minContentHeight(item, measurer, images)
+	} else {
+		content = minContentWidth(item.node, measurer, images)
+	}
+	if dim.Max > 0 {
+		content = math.Min(content, dim.Max)
+	}
+	return math.Min(content, item.mainSize(isVertical))
+}
+
+// minContentHeight returns the height the content of an item needs at its
+// width: the lines of a text or the intrinsic height of a frame. Other
+// nodes have no content and may shrink away.
+func minContentHeight(item flexItem, measurer TextMeasurer, images ImageMeasurer) float64 {
+	switch n := item.node.(type) {
+	case *shared.Text:
+		if measurer != nil && item.width > 0 {
+			_, h := measurer.MeasureText(n.Content, TextStyleOf(n), item.width)
+			return h
+		}
+		return item.height
+	case *shared.Frame:
+		_, h := intrinsicSize(n, measurer, images, item.width)
+		return h
+	}
+	return 0
+}
+
+// minContentWidth returns the narrowest width a node takes without its
+// content overflowing: the longest word of a text, or for a frame the
+// narrowest widths of its children side by side or stacked, following its
+// layout. Children that do not shrink count with their width.
+func minContentWidth(node shared.Node, measurer TextMeasurer, images ImageMeasurer) float64 {
+	var dim shared.Dimension
+	var content float64
+	switch n := node.(type) {
+	case *shared.Text:
+		dim, content = n.Width, longestWordWidth(n, measurer)
+	case *shared.Frame:
+		dim, content = n.Width, frameMinContentWidth(n, measurer, images)
+	case *shared.Shape:
+		dim = n.Width
+	case *shared.Path:
+		dim = n.Width
+	case *shared.Image:
+		dim = n.Width
+	}
+
+	if dim.Min > 0 {
+		return dim.Min
+	}
+	if !dim.FillContainer && dim.Value > 0 {
+		if dim.ShrinkWeight() <= 0 {
+			return dim.Value
+		}
+		content = math.Min(content, dim.Value)
+	}
+	if dim.Max > 0 {
+		content = math.Min(content, dim.Max)
+	}
+	return content
+}
+
+// frameMinContentWidth returns the narrowest width of the children of a
+// frame with its padding. Horizontal stacks add up their children and
+// gaps, grids their columns, and other layouts take their widest child.
+// Positioned children take no space.
+func frameMinContentWidth(frame *shared.Frame, measurer TextMeasurer, images ImageMeasurer) float64 {
+	insets := contentInsets(frame)
+	padH := insets.Left + insets.Right
+
+	var nodes []shared.Node
+	for _, child := range visibleChildren(frame) {
+		if !shared.IsPositioned(frame, child) {
+			nodes = append(nodes, child)
+		}
+	}
+	if len(nodes) == 0 {
+		return padH
+	}
+
+	if frame.Layout == "grid" {
+		items, _, columnCount := placeGridItems(frame, nodes)
+		columns := make([]float64, columnCount)
+		for i, t := range frame.Columns {
+			if t.Kind == shared.TrackFixed {
+				columns[i] = t.Value
+			}
+		}
+		for _, item := range items {
+			if item.columnSpan == 1 {
+				columns[item.column] = math.Max(columns[item.column], minContentWidth(item.node, measurer, images))
+			}
+		}
+		_, colGap := frame.Gaps()
+		return spanSize(columns, 0, columnCount, colGap) + padH
+	}
+
+	content := 0.0
+	sideBySide := frame.Layout != "vertical" && !frame.Wrap
+	for _, child := range nodes {
+		w := minContentWidth(child, measurer, images)
+		if sideBySide {
+			content += w
+		} else {
+			content = math.Max(content, w)
+		}
+	}
+	if sideBySide {
+		mainGap, _ := axisGaps(frame)
+		content += float64(len(nodes)-1) * mainGap
+	}
+	return content + padH
+}
+
+// longestWordWidth returns the width of the widest run of a text that
+// cannot be broken across lines.
+func longestWordWidth(text *shared.Text, measurer TextMeasurer) float64 {
+	if measurer == nil {
+		return 0
+	}
+	style := TextStyleOf(text)
+	widest := 0.0
+	for _, para := range strings.Split(text.Content, "\n") {
+		for _, seg := range breakSegments(para) {
+			if seg = trimTrailingSpace(seg); seg != "" {
+				w, _ := measurer.MeasureText(seg, style, 0)
+				widest = math.Max(widest, w)
+			}
+		}
+	}
+	return widest
+}
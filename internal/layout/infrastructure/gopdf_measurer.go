@@ -1,13 +1,17 @@
 package infrastructure
 
 import (
-	"strings"
+	"bytes"
 
 	"github.com/signintech/gopdf"
 	asset "github.com/vpedrosa/pen2pdf/internal/asset/domain"
+	assetInfra "github.com/vpedrosa/pen2pdf/internal/asset/infrastructure"
+	layout "github.com/vpedrosa/pen2pdf/internal/layout/domain"
 )
 
 // GopdfTextMeasurer uses gopdf to measure text dimensions based on loaded fonts.
+// Fonts are resolved exactly like PDFRenderer does (including the embedded Go
+// font fallback), so measured lines match the drawn ones.
 type GopdfTextMeasurer struct {
 	pdf        *gopdf.GoPdf
 	fontLoader asset.FontLoader
@@ -24,69 +28,59 @@ func NewGopdfTextMeasurer(fontLoader asset.FontLoader) *GopdfTextMeasurer {
 	}
 }
 
-func (m *GopdfTextMeasurer) MeasureText(text, fontFamily string, fontSize float64, fontWeight string, maxWidth float64) (width, height float64) {
-	fontKey := fontFamily + "-" + fontWeight
+func (m *GopdfTextMeasurer) MeasureText(text string, style layout.TextStyle, maxWidth float64) (width, height float64) {
+	fontStyle := style.FontStyle
+	if fontStyle == "" {
+		fontStyle = "normal"
+	}
+	fontKey := style.FontFamily + "-" + style.FontWeight + "-" + fontStyle
 	if !m.loaded[fontKey] {
-		fontData, err := m.fontLoader.LoadFont(fontFamily, fontWeight, "normal")
-		if err != nil {
-			// Fallback: estimate based on fontSize
-			return estimateText(text, fontSize, maxWidth)
-		}
-		if err := m.pdf.AddTTFFontByReader(fontKey, strings.NewReader(string(fontData.Data))); err != nil {
-			return estimateText(text, fontSize, maxWidth)
+		if err := m.loadFont(fontKey, style.FontFamily, style.FontWeight, fontStyle); err != nil {
+			return estimateText(text, style, maxWidth)
 		}
-		m.loaded[fontKey] = true
 	}
 
-	if err := m.pdf.SetFont(fontKey, "", fontSize); err != nil {
-		return estimateText(text, fontSize, maxWidth)
+	if err := m.pdf.SetFont(fontKey, "", style.FontSize); err != nil {
+		return estimateText(text, style, maxWidth)
+	}
+	if err := m.pdf.SetCharSpacing(style.LetterSpacing); err != nil {
+		return estimateText(text, style, maxWidth)
 	}
 
-	lines := strings.Split(text, "\n")
-	maxLineWidth := 0.0
-
-	for _, line := range lines {
-		lineWidth, _ := m.pdf.MeasureTextWidth(line)
+	lines := layout.BreakLines(text, maxWidth, func(s string) float64 {
+		w, _ := m.pdf.MeasureTextWidth(s)
+		return w
+	})
+	return layout.LinesSize(lines, style.LineSpacing())
+}
 
-		if maxWidth > 0 && lineWidth > maxWidth {
-			// Wrap text: estimate wrapped lines
-			wrappedLines := int(lineWidth/maxWidth) + 1
-			height += float64(wrappedLines) * fontSize * 1.2
-			if maxWidth > maxLineWidth {
-				maxLineWidth = maxWidth
-			}
-		} else {
-			height += fontSize * 1.2
-			if lineWidth > maxLineWidth {
-				maxLineWidth = lineWidth
+// loadFont registers the requested font, falling back to the embedded Go
+// font variant when the font loader cannot provide it.
+func (m *GopdfTextMeasurer) loadFont(fontKey, family, weight, fontStyle string) error {
+	if m.fontLoader != nil {
+		fontData, err := m.fontLoader.LoadFont(family, weight, fontStyle)
+		if err == nil {
+			if err := m.pdf.AddTTFFontByReader(fontKey, bytes.NewReader(fontData.Data)); err != nil {
+				return err
 			}
+			m.loaded[fontKey] = true
+			return nil
 		}
 	}
 
-	return maxLineWidth, height
+	_, data := assetInfra.FallbackFont(weight, fontStyle)
+	if err := m.pdf.AddTTFFontData(fontKey, data); err != nil {
+		return err
+	}
+	m.loaded[fontKey] = true
+	return nil
 }
 
-// estimateText provides a rough fallback when fonts aren't available.
-func estimateText(text string, fontSize, maxWidth float64) (float64, float64) {
-	charWidth := fontSize * 0.6
-	lines := strings.Split(text, "\n")
-	totalHeight := 0.0
-	maxLineWidth := 0.0
-
-	for _, line := range lines {
-		lineWidth := float64(len(line)) * charWidth
-		if maxWidth > 0 && lineWidth > maxWidth {
-			wrappedLines := int(lineWidth/maxWidth) + 1
-			totalHeight += float64(wrappedLines) * fontSize * 1.2
-			if maxWidth > maxLineWidth {
-				maxLineWidth = maxWidth
-			}
-		} else {
-			totalHeight += fontSize * 1.2
-			if lineWidth > maxLineWidth {
-				maxLineWidth = lineWidth
-			}
-		}
-	}
-	return maxLineWidth, totalHeight
+// estimateText provides a rough fallback when no font can be registered.
+func estimateText(text string, style layout.TextStyle, maxWidth float64) (float64, float64) {
+	charWidth := style.FontSize*0.6 + style.LetterSpacing
+	lines := layout.BreakLines(text, maxWidth, func(s string) float64 {
+		return float64(len([]rune(s))) * charWidth
+	})
+	return layout.LinesSize(lines, style.LineSpacing())
 }
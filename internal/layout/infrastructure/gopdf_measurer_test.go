@@ -22,7 +22,7 @@ func TestGopdfTextMeasurerImplementsPort(t *testing.T) {
 func TestMeasureTextFallbackSingleLine(t *testing.T) {
 	measurer := infrastructure.NewGopdfTextMeasurer(&errorFontLoader{})
 
-	w, h := measurer.MeasureText("Hello", "Inter", 16, "400", 0)
+	w, h := measurer.MeasureText("Hello", layout.TextStyle{FontFamily: "Inter", FontSize: 16, FontWeight: "400"}, 0)
 	if w <= 0 {
 		t.Errorf("expected positive width, got %f", w)
 	}
@@ -34,8 +34,8 @@ func TestMeasureTextFallbackSingleLine(t *testing.T) {
 func TestMeasureTextFallbackMultiLine(t *testing.T) {
 	measurer := infrastructure.NewGopdfTextMeasurer(&errorFontLoader{})
 
-	_, h1 := measurer.MeasureText("Line 1", "Inter", 16, "400", 0)
-	_, h2 := measurer.MeasureText("Line 1\nLine 2", "Inter", 16, "400", 0)
+	_, h1 := measurer.MeasureText("Line 1", layout.TextStyle{FontFamily: "Inter", FontSize: 16, FontWeight: "400"}, 0)
+	_, h2 := measurer.MeasureText("Line 1\nLine 2", layout.TextStyle{FontFamily: "Inter", FontSize: 16, FontWeight: "400"}, 0)
 
 	if h2 <= h1 {
 		t.Errorf("expected multi-line height (%f) > single-line height (%f)", h2, h1)
@@ -45,8 +45,8 @@ func TestMeasureTextFallbackMultiLine(t *testing.T) {
 func TestMeasureTextFallbackWrapping(t *testing.T) {
 	measurer := infrastructure.NewGopdfTextMeasurer(&errorFontLoader{})
 
-	wNoLimit, hNoLimit := measurer.MeasureText("This is a really long text that should wrap", "Inter", 16, "400", 0)
-	wLimited, hLimited := measurer.MeasureText("This is a really long text that should wrap", "Inter", 16, "400", 100)
+	wNoLimit, hNoLimit := measurer.MeasureText("This is a really long text that should wrap", layout.TextStyle{FontFamily: "Inter", FontSize: 16, FontWeight: "400"}, 0)
+	wLimited, hLimited := measurer.MeasureText("This is a really long text that should wrap", layout.TextStyle{FontFamily: "Inter", FontSize: 16, FontWeight: "400"}, 100)
 
 	if wLimited > 100 {
 		t.Errorf("expected width <= 100 with maxWidth, got %f", wLimited)
@@ -61,10 +61,37 @@ func TestMeasureTextFallbackWrapping(t *testing.T) {
 func TestMeasureTextFallbackDifferentSizes(t *testing.T) {
 	measurer := infrastructure.NewGopdfTextMeasurer(&errorFontLoader{})
 
-	_, h12 := measurer.MeasureText("Hello", "Inter", 12, "400", 0)
-	_, h24 := measurer.MeasureText("Hello", "Inter", 24, "400", 0)
+	_, h12 := measurer.MeasureText("Hello", layout.TextStyle{FontFamily: "Inter", FontSize: 12, FontWeight: "400"}, 0)
+	_, h24 := measurer.MeasureText("Hello", layout.TextStyle{FontFamily: "Inter", FontSize: 24, FontWeight: "400"}, 0)
 
 	if h24 <= h12 {
 		t.Errorf("expected fontSize 24 height (%f) > fontSize 12 height (%f)", h24, h12)
 	}
 }
+
+// recordingFontLoader records the styles it is asked for and fails, so the
+// measurer falls back to the embedded fonts.
+type recordingFontLoader struct {
+	styles []string
+}
+
+func (l *recordingFontLoader) LoadFont(_, _, style string) (*asset.FontData, error) {
+	l.styles = append(l.styles, style)
+	return nil, fmt.Errorf("font not found")
+}
+
+func TestMeasureTextLoadsEachFontStyle(t *testing.T) {
+	loader := &recordingFontLoader{}
+	measurer := infrastructure.NewGopdfTextMeasurer(loader)
+
+	regular := layout.TextStyle{FontFamily: "Inter", FontSize: 16, FontWeight: "400", FontStyle: "normal"}
+	italic := layout.TextStyle{FontFamily: "Inter", FontSize: 16, FontWeight: "400", FontStyle: "italic"}
+	measurer.MeasureText("Hello", regular, 0)
+	measurer.MeasureText("Hello", italic, 0)
+	measurer.MeasureText("Hello", italic, 0)
+
+	// The italic text gets its own font instead of reusing the regular one
+	if fmt.Sprint(loader.styles) != "[normal italic]" {
+		t.Errorf("expected the normal and italic fonts to be loaded once each, got %v", loader.styles)
+	}
+}
@@ -0,0 +1,23 @@
+package infrastructure
+
+import (
+	asset "github.com/vpedrosa/pen2pdf/internal/asset/domain"
+)
+
+// AssetImageMeasurer measures images through an ImageLoader, taking one
+// pixel as one point, the way PDFRenderer draws images at natural size.
+type AssetImageMeasurer struct {
+	loader asset.ImageLoader
+}
+
+func NewAssetImageMeasurer(loader asset.ImageLoader) *AssetImageMeasurer {
+	return &AssetImageMeasurer{loader: loader}
+}
+
+func (m *AssetImageMeasurer) MeasureImage(url string) (width, height float64, err error) {
+	img, err := m.loader.LoadImage(url)
+	if err != nil {
+		return 0, 0, err
+	}
+	return float64(img.Width), float64(img.Height), nil
+}
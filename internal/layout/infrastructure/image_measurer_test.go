@@ -0,0 +1,43 @@
+package infrastructure_test
+
+import (
+	"fmt"
+	"testing"
+
+	asset "github.com/vpedrosa/pen2pdf/internal/asset/domain"
+	layout "github.com/vpedrosa/pen2pdf/internal/layout/domain"
+	"github.com/vpedrosa/pen2pdf/internal/layout/infrastructure"
+)
+
+type stubImageLoader struct {
+	img *asset.ImageData
+	err error
+}
+
+func (l *stubImageLoader) LoadImage(_ string) (*asset.ImageData, error) {
+	return l.img, l.err
+}
+
+func TestAssetImageMeasurerImplementsPort(t *testing.T) {
+	var _ layout.ImageMeasurer = infrastructure.NewAssetImageMeasurer(nil)
+}
+
+func TestMeasureImageNaturalSize(t *testing.T) {
+	measurer := infrastructure.NewAssetImageMeasurer(&stubImageLoader{img: &asset.ImageData{Width: 640, Height: 480}})
+
+	w, h, err := measurer.MeasureImage("photo.jpg")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w != 640 || h != 480 {
+		t.Errorf("expected 640x480, got %vx%v", w, h)
+	}
+}
+
+func TestMeasureImageLoadError(t *testing.T) {
+	measurer := infrastructure.NewAssetImageMeasurer(&stubImageLoader{err: fmt.Errorf("not found")})
+
+	if _, _, err := measurer.MeasureImage("missing.png"); err == nil {
+		t.Fatal("expected error for missing image")
+	}
+}
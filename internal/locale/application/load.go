@@ -0,0 +1,40 @@
+package application
+
+import (
+	"fmt"
+
+	locale "github.com/vpedrosa/pen2pdf/internal/locale/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// LocaleService orchestrates loading the string tables of a document.
+type LocaleService struct {
+	store locale.LocaleStore
+}
+
+// NewLocaleService creates a LocaleService with the given LocaleStore port.
+func NewLocaleService(s locale.LocaleStore) *LocaleService {
+	return &LocaleService{store: s}
+}
+
+// Locales returns the locales the store has string tables for.
+func (s *LocaleService) Locales() ([]string, error) {
+	return s.store.Locales()
+}
+
+// LoadAll reads the string table of every locale, keyed by locale.
+func (s *LocaleService) LoadAll() (map[string]shared.Translations, error) {
+	locales, err := s.store.Locales()
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string]shared.Translations, len(locales))
+	for _, l := range locales {
+		table, err := s.store.Load(l)
+		if err != nil {
+			return nil, fmt.Errorf("locale %s: %w", l, err)
+		}
+		tables[l] = table
+	}
+	return tables, nil
+}
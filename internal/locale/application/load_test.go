@@ -0,0 +1,51 @@
+package application_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vpedrosa/pen2pdf/internal/locale/application"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+type stubStore struct {
+	tables map[string]shared.Translations
+	err    error
+}
+
+func (s *stubStore) Locales() ([]string, error) {
+	var locales []string
+	for l := range s.tables {
+		locales = append(locales, l)
+	}
+	return locales, nil
+}
+
+func (s *stubStore) Load(l string) (shared.Translations, error) {
+	return s.tables[l], s.err
+}
+
+func TestLocaleServiceLoadAll(t *testing.T) {
+	svc := application.NewLocaleService(&stubStore{tables: map[string]shared.Translations{
+		"en": {"title": "Welcome"},
+		"es": {"title": "Bienvenida"},
+	}})
+	tables, err := svc.LoadAll()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tables) != 2 || tables["es"]["title"] != "Bienvenida" {
+		t.Errorf("unexpected tables %v", tables)
+	}
+}
+
+func TestLocaleServiceLoadError(t *testing.T) {
+	svc := application.NewLocaleService(&stubStore{
+		tables: map[string]shared.Translations{"es": nil},
+		err:    fmt.Errorf("bad table"),
+	})
+	if _, err := svc.LoadAll(); err == nil || !strings.Contains(err.Error(), "locale es: bad table") {
+		t.Errorf("expected locale error, got %v", err)
+	}
+}
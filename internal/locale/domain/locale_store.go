@@ -0,0 +1,13 @@
+package domain
+
+import (
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// LocaleStore defines the contract for finding the string tables a document
+// is translated with. Adapters implement this interface for specific
+// storage (e.g., a directory of JSON or PO files).
+type LocaleStore interface {
+	Locales() ([]string, error)
+	Load(locale string) (shared.Translations, error)
+}
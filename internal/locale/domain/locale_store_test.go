@@ -0,0 +1,22 @@
+package domain_test
+
+import (
+	"testing"
+
+	locale "github.com/vpedrosa/pen2pdf/internal/locale/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+type stubStore struct{}
+
+func (s *stubStore) Locales() ([]string, error) {
+	return nil, nil
+}
+
+func (s *stubStore) Load(_ string) (shared.Translations, error) {
+	return nil, nil
+}
+
+func TestLocaleStoreInterfaceCompliance(t *testing.T) {
+	var _ locale.LocaleStore = &stubStore{}
+}
@@ -0,0 +1,84 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// translationDecoders read the string table of a locale file, by extension.
+// A locale with files in several formats is read from the first one.
+var translationDecoders = []struct {
+	ext    string
+	decode func(r io.Reader) (shared.Translations, error)
+}{
+	{".json", decodeJSONTranslations},
+	{".po", decodePOTranslations},
+}
+
+// FSLocaleStore reads string tables from a directory holding one file per
+// locale, named after it (e.g., es.json or pt-BR.po).
+type FSLocaleStore struct {
+	dir string
+}
+
+func NewFSLocaleStore(dir string) *FSLocaleStore {
+	return &FSLocaleStore{dir: dir}
+}
+
+// Locales returns the sorted locales with a file in the directory. A missing
+// directory has none.
+func (s *FSLocaleStore) Locales() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read locales: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var locales []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		locale := strings.TrimSuffix(entry.Name(), ext)
+		for _, d := range translationDecoders {
+			if ext == d.ext && !seen[locale] {
+				seen[locale] = true
+				locales = append(locales, locale)
+			}
+		}
+	}
+	sort.Strings(locales)
+	return locales, nil
+}
+
+func (s *FSLocaleStore) Load(locale string) (shared.Translations, error) {
+	for _, d := range translationDecoders {
+		path := filepath.Join(s.dir, locale+d.ext)
+		f, err := os.Open(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		table, err := d.decode(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		return table, nil
+	}
+	return nil, fmt.Errorf("no string table for %s in %s", locale, s.dir)
+}
@@ -0,0 +1,157 @@
+package infrastructure_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	locale "github.com/vpedrosa/pen2pdf/internal/locale/domain"
+	"github.com/vpedrosa/pen2pdf/internal/locale/infrastructure"
+)
+
+func TestFSLocaleStoreImplementsPort(t *testing.T) {
+	var _ locale.LocaleStore = infrastructure.NewFSLocaleStore("")
+}
+
+func writeLocales(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestFSLocaleStoreLocales(t *testing.T) {
+	dir := writeLocales(t, map[string]string{
+		"es.json":  `{}`,
+		"fr.po":    ``,
+		"fr.json":  `{}`,
+		"notes.md": ``,
+	})
+	locales, err := infrastructure.NewFSLocaleStore(dir).Locales()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(locales, ",") != "es,fr" {
+		t.Errorf("expected es,fr, got %v", locales)
+	}
+
+	locales, err = infrastructure.NewFSLocaleStore(filepath.Join(dir, "missing")).Locales()
+	if err != nil || len(locales) != 0 {
+		t.Errorf("expected no locales without a directory, got %v, %v", locales, err)
+	}
+}
+
+func TestFSLocaleStoreLoadJSON(t *testing.T) {
+	dir := writeLocales(t, map[string]string{
+		"es.json": `{"title": "Bienvenida", "menu": {"drinks": "Bebidas", "food": {"title": "Comida"}}}`,
+	})
+	table, err := infrastructure.NewFSLocaleStore(dir).Load("es")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"title": "Bienvenida", "menu.drinks": "Bebidas", "menu.food.title": "Comida"}
+	if len(table) != len(want) {
+		t.Errorf("expected %v, got %v", want, table)
+	}
+	for key, value := range want {
+		if table[key] != value {
+			t.Errorf("%s: expected %q, got %q", key, value, table[key])
+		}
+	}
+}
+
+func TestFSLocaleStoreLoadPO(t *testing.T) {
+	dir := writeLocales(t, map[string]string{"fr.po": `# Brochure
+msgid ""
+msgstr ""
+"Language: fr\n"
+
+#: cover
+msgid "title"
+msgstr "Bienvenue"
+
+msgid "intro"
+msgstr ""
+"Découvrez "
+"\"nos\" offres"
+
+msgid "pending"
+msgstr ""
+
+msgid "item"
+msgid_plural "items"
+msgstr[0] "article"
+msgstr[1] "articles"
+`})
+	table, err := infrastructure.NewFSLocaleStore(dir).Load("fr")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"title": "Bienvenue", "intro": `Découvrez "nos" offres`, "item": "article"}
+	if len(table) != len(want) {
+		t.Errorf("expected %v, got %v", want, table)
+	}
+	for key, value := range want {
+		if table[key] != value {
+			t.Errorf("%s: expected %q, got %q", key, value, table[key])
+		}
+	}
+}
+
+func TestFSLocaleStoreLoadPOContexts(t *testing.T) {
+	dir := writeLocales(t, map[string]string{"fr.po": `msgctxt "menu"
+msgid "open"
+msgstr "Ouvrir"
+
+msgid "open"
+msgstr "Ouvert"
+
+msgctxt "door"
+msgid "open"
+msgstr "Ouverte"
+
+msgctxt "button"
+msgid "close"
+msgstr "Fermer"
+
+msgctxt "window"
+msgid "close"
+msgstr "Fermée"
+`})
+	table, err := infrastructure.NewFSLocaleStore(dir).Load("fr")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The entry without a context wins, or else the first one
+	if table["open"] != "Ouvert" || table["close"] != "Fermer" || len(table) != 2 {
+		t.Errorf("unexpected table %v", table)
+	}
+}
+
+func TestFSLocaleStoreLoadErrors(t *testing.T) {
+	dir := writeLocales(t, map[string]string{
+		"es.json": `{"count": 3}`,
+		"de.json": `["Hallo"]`,
+		"fr.po":   "msgid \"title\"\nmsgstr Bienvenue\n",
+		"it.po":   "msgstring \"menu\"\n",
+	})
+	tests := map[string]string{
+		"es": `es.json: key "count": expected a string or an object`,
+		"de": "de.json: invalid JSON: expected an object",
+		"fr": "fr.po: line 2: invalid string Bienvenue",
+		"it": `it.po: line 1: unexpected "msgstring"`,
+		"pt": "no string table for pt in",
+	}
+	store := infrastructure.NewFSLocaleStore(dir)
+	for l, want := range tests {
+		_, err := store.Load(l)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected %q, got %v", l, want, err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// decodeJSONTranslations reads a JSON object of strings. Nested objects
+// group keys, so {"menu": {"title": "Menú"}} defines menu.title.
+func decodeJSONTranslations(r io.Reader) (shared.Translations, error) {
+	var root map[string]any
+	if err := json.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("invalid JSON: expected an object: %w", err)
+	}
+	table := make(shared.Translations)
+	if err := flattenTranslations(table, "", root); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+func flattenTranslations(table shared.Translations, prefix string, object map[string]any) error {
+	for key, value := range object {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case string:
+			table[key] = v
+		case map[string]any:
+			if err := flattenTranslations(table, key, v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("key %q: expected a string or an object", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package infrastructure
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// decodePOTranslations reads a gettext PO file, keyed by msgid. Plural
+// entries use their first form, and untranslated entries (an empty msgstr)
+// are left out so the fallback locale applies. Contexts (msgctxt) are
+// dropped, since translation keys have none, so entries that share a msgid
+// collapse into one even when their contexts differ: the one without a
+// context is used, or else the first, and the others are lost.
+func decodePOTranslations(r io.Reader) (shared.Translations, error) {
+	table := make(shared.Translations)
+	withContext := make(map[string]bool)
+	var id, str, context string
+	var target *string
+	// hasContext marks an entry with a context, and awaitingID one whose
+	// msgctxt has not been followed by its msgid yet
+	hasContext, awaitingID := false, false
+	flush := func() {
+		if id != "" && str != "" {
+			if _, ok := table[id]; !ok || withContext[id] && !hasContext {
+				table[id] = str
+				withContext[id] = hasContext
+			}
+		}
+		id, str, context, target, hasContext = "", "", "", nil, false
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		keyword, rest, _ := strings.Cut(text, " ")
+		switch {
+		case text == "" || strings.HasPrefix(text, "#"):
+			continue
+		case keyword == "msgctxt":
+			flush()
+			target, hasContext, awaitingID = &context, true, true
+		case keyword == "msgid":
+			if !awaitingID {
+				flush()
+			}
+			target, awaitingID = &id, false
+		case keyword == "msgstr" || keyword == "msgstr[0]":
+			target = &str
+		case keyword == "msgid_plural" || strings.HasPrefix(keyword, "msgstr["):
+			// Only the singular form is used
+			target = nil
+			continue
+		case strings.HasPrefix(text, `"`):
+			if target == nil {
+				continue
+			}
+			rest = text
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q", line, keyword)
+		}
+		s, err := strconv.Unquote(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid string %s", line, strings.TrimSpace(rest))
+		}
+		*target += s
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return table, nil
+}
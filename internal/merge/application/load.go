@@ -0,0 +1,31 @@
+package application
+
+import (
+	"fmt"
+	"io"
+
+	merge "github.com/vpedrosa/pen2pdf/internal/merge/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// RecordService orchestrates loading the data records of a merge.
+type RecordService struct {
+	loader merge.RecordLoader
+}
+
+// NewRecordService creates a RecordService with the given RecordLoader port.
+func NewRecordService(l merge.RecordLoader) *RecordService {
+	return &RecordService{loader: l}
+}
+
+// Load reads the data records, failing when there are none.
+func (s *RecordService) Load(input io.Reader) ([]shared.Record, error) {
+	records, err := s.loader.Load(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no records")
+	}
+	return records, nil
+}
@@ -0,0 +1,40 @@
+package application_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/vpedrosa/pen2pdf/internal/merge/application"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+type stubLoader struct {
+	records []shared.Record
+	err     error
+}
+
+func (s *stubLoader) Load(_ io.Reader) ([]shared.Record, error) {
+	return s.records, s.err
+}
+
+func TestRecordServiceLoad(t *testing.T) {
+	svc := application.NewRecordService(&stubLoader{records: []shared.Record{{"name": "Ada"}}})
+	records, err := svc.Load(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 1 || records[0]["name"] != "Ada" {
+		t.Errorf("unexpected records %v", records)
+	}
+}
+
+func TestRecordServiceErrors(t *testing.T) {
+	if _, err := application.NewRecordService(&stubLoader{}).Load(strings.NewReader("")); err == nil {
+		t.Error("expected error without records")
+	}
+	if _, err := application.NewRecordService(&stubLoader{err: fmt.Errorf("bad data")}).Load(strings.NewReader("")); err == nil {
+		t.Error("expected loader error")
+	}
+}
@@ -0,0 +1,14 @@
+package domain
+
+import (
+	"io"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// RecordLoader defines the contract for reading the data records that fill a
+// template. Adapters implement this interface for specific formats (e.g.,
+// CSV or JSON).
+type RecordLoader interface {
+	Load(r io.Reader) ([]shared.Record, error)
+}
@@ -0,0 +1,21 @@
+package domain_test
+
+import (
+	"io"
+	"testing"
+
+	merge "github.com/vpedrosa/pen2pdf/internal/merge/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+type stubLoader struct {
+	records []shared.Record
+}
+
+func (s *stubLoader) Load(_ io.Reader) ([]shared.Record, error) {
+	return s.records, nil
+}
+
+func TestRecordLoaderInterfaceCompliance(t *testing.T) {
+	var _ merge.RecordLoader = &stubLoader{}
+}
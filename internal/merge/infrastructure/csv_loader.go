@@ -0,0 +1,50 @@
+package infrastructure
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// CSVRecordLoader reads records from CSV data whose first row names the
+// fields.
+type CSVRecordLoader struct{}
+
+func NewCSVRecordLoader() *CSVRecordLoader {
+	return &CSVRecordLoader{}
+}
+
+func (l *CSVRecordLoader) Load(r io.Reader) ([]shared.Record, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	for i, field := range header {
+		field = strings.TrimSpace(field)
+		if i == 0 {
+			field = strings.TrimPrefix(field, "\ufeff")
+		}
+		if field == "" {
+			return nil, fmt.Errorf("invalid CSV: column %d has no name", i+1)
+		}
+		header[i] = field
+	}
+
+	records := make([]shared.Record, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(shared.Record, len(header))
+		for i, field := range header {
+			record[field] = row[i]
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,46 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// JSONRecordLoader reads records from a JSON array of objects. Numbers and
+// booleans become their text, and null an empty field.
+type JSONRecordLoader struct{}
+
+func NewJSONRecordLoader() *JSONRecordLoader {
+	return &JSONRecordLoader{}
+}
+
+func (l *JSONRecordLoader) Load(r io.Reader) ([]shared.Record, error) {
+	var items []map[string]any
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("invalid JSON: expected an array of objects: %w", err)
+	}
+
+	records := make([]shared.Record, 0, len(items))
+	for i, item := range items {
+		record := make(shared.Record, len(item))
+		for field, value := range item {
+			switch v := value.(type) {
+			case string:
+				record[field] = v
+			case float64:
+				record[field] = strconv.FormatFloat(v, 'f', -1, 64)
+			case bool:
+				record[field] = strconv.FormatBool(v)
+			case nil:
+				record[field] = ""
+			default:
+				return nil, fmt.Errorf("record %d field %q: expected a string, number or boolean", i, field)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,72 @@
+package infrastructure_test
+
+import (
+	"strings"
+	"testing"
+
+	merge "github.com/vpedrosa/pen2pdf/internal/merge/domain"
+	"github.com/vpedrosa/pen2pdf/internal/merge/infrastructure"
+)
+
+func TestLoadersImplementPort(t *testing.T) {
+	var _ merge.RecordLoader = infrastructure.NewCSVRecordLoader()
+	var _ merge.RecordLoader = infrastructure.NewJSONRecordLoader()
+}
+
+func TestCSVRecordLoader(t *testing.T) {
+	input := "\ufeffname, course\nAda Lovelace,\"Engines, Analytical\"\nAlan Turing,Computability\n"
+	records, err := infrastructure.NewCSVRecordLoader().Load(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0]["name"] != "Ada Lovelace" || records[0]["course"] != "Engines, Analytical" || records[1]["name"] != "Alan Turing" {
+		t.Errorf("unexpected records %v", records)
+	}
+}
+
+func TestCSVRecordLoaderErrors(t *testing.T) {
+	tests := map[string]string{
+		"name,course\nAda\n": "invalid CSV",
+		"name,\nAda,x\n":     "column 2 has no name",
+	}
+	for input, want := range tests {
+		_, err := infrastructure.NewCSVRecordLoader().Load(strings.NewReader(input))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected %q, got %v", input, want, err)
+		}
+	}
+}
+
+func TestJSONRecordLoader(t *testing.T) {
+	input := `[{"name": "Ada", "score": 97.5, "honors": true, "note": null}, {"name": "Alan", "score": 100}]`
+	records, err := infrastructure.NewJSONRecordLoader().Load(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	first := records[0]
+	if first["name"] != "Ada" || first["score"] != "97.5" || first["honors"] != "true" || first["note"] != "" {
+		t.Errorf("unexpected record %v", first)
+	}
+	if records[1]["score"] != "100" {
+		t.Errorf("expected integral numbers without decimals, got %q", records[1]["score"])
+	}
+}
+
+func TestJSONRecordLoaderErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"name": "Ada"}`:              "expected an array of objects",
+		`[{"name": {"first": "Ada"}}]`: `record 0 field "name"`,
+	}
+	for input, want := range tests {
+		_, err := infrastructure.NewJSONRecordLoader().Load(strings.NewReader(input))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected %q, got %v", input, want, err)
+		}
+	}
+}
@@ -4,6 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
 )
@@ -35,6 +40,10 @@ func (p *JSONParser) Parse(r io.Reader) (*shared.Document, error) {
 		Version:   raw.Version,
 		Children:  children,
 		Variables: variables,
+		Themes:    raw.Themes,
+		Theme:     raw.Theme,
+		Data:      raw.Data,
+		Locale:    raw.Locale,
 	}, nil
 }
 
@@ -43,6 +52,10 @@ type rawDocument struct {
 	Version   string                     `json:"version"`
 	Children  []json.RawMessage          `json:"children"`
 	Variables map[string]json.RawMessage `json:"variables"`
+	Themes    map[string][]string        `json:"themes"`
+	Theme     shared.Theme               `json:"theme"`
+	Data      map[string]any             `json:"data"`
+	Locale    string                     `json:"locale"`
 }
 
 // rawNode is a partially-decoded node used to determine type.
@@ -59,22 +72,72 @@ type rawFrame struct {
 	Width          json.RawMessage   `json:"width"`
 	Height         json.RawMessage   `json:"height"`
 	Fill           json.RawMessage   `json:"fill"`
+	Stroke         json.RawMessage   `json:"stroke"`
+	Effect         json.RawMessage   `json:"effect"`
 	CornerRadius   float64           `json:"cornerRadius"`
 	Clip           bool              `json:"clip"`
 	Layout         string            `json:"layout"`
 	Gap            float64           `json:"gap"`
+	RowGap         *float64          `json:"rowGap"`
+	ColumnGap      *float64          `json:"columnGap"`
+	Wrap           bool              `json:"wrap"`
+	Columns        json.RawMessage   `json:"columns"`
+	Rows           json.RawMessage   `json:"rows"`
+	GridFlow       string            `json:"gridFlow"`
 	Padding        json.RawMessage   `json:"padding"`
 	JustifyContent string            `json:"justifyContent"`
 	AlignItems     string            `json:"alignItems"`
+	Reusable       bool              `json:"reusable"`
+	Theme          shared.Theme      `json:"theme"`
+	Repeat         *rawRepeat        `json:"repeat"`
 	Children       []json.RawMessage `json:"children"`
+	rawSizeLimits
+	rawGridCell
+	rawPosition
+	Enabled *bool           `json:"enabled"`
+	Visible json.RawMessage `json:"visible"`
+}
+
+// rawSizeLimits holds the limits of a node's width and height, and how
+// much it shrinks when its siblings do not fit.
+type rawSizeLimits struct {
+	MinWidth  *float64 `json:"minWidth"`
+	MaxWidth  *float64 `json:"maxWidth"`
+	MinHeight *float64 `json:"minHeight"`
+	MaxHeight *float64 `json:"maxHeight"`
+	Shrink    *float64 `json:"shrink"`
+}
+
+// rawGridCell holds the placement of a node in the grid of its parent.
+type rawGridCell struct {
+	GridColumn int `json:"gridColumn"`
+	GridRow    int `json:"gridRow"`
+	ColumnSpan int `json:"columnSpan"`
+	RowSpan    int `json:"rowSpan"`
+}
+
+// rawPosition holds how a node is placed at its x and y in its parent.
+type rawPosition struct {
+	Position string   `json:"position"`
+	Right    *float64 `json:"right"`
+	Bottom   *float64 `json:"bottom"`
+}
+
+type rawRepeat struct {
+	Data  string            `json:"data"`
+	As    string            `json:"as"`
+	Empty []json.RawMessage `json:"empty"`
 }
 
 // rawText holds all text fields with polymorphic types as RawMessage.
 type rawText struct {
 	ID            string          `json:"id"`
 	Name          string          `json:"name"`
+	X             float64         `json:"x"`
+	Y             float64         `json:"y"`
 	Content       string          `json:"content"`
-	Fill          string          `json:"fill"`
+	Fill          json.RawMessage `json:"fill"`
+	Stroke        json.RawMessage `json:"stroke"`
 	FontFamily    string          `json:"fontFamily"`
 	FontSize      float64         `json:"fontSize"`
 	FontWeight    string          `json:"fontWeight"`
@@ -84,6 +147,71 @@ type rawText struct {
 	TextAlign     string          `json:"textAlign"`
 	Width         json.RawMessage `json:"width"`
 	TextGrowth    string          `json:"textGrowth"`
+	rawSizeLimits
+	rawGridCell
+	rawPosition
+	Enabled *bool           `json:"enabled"`
+	Visible json.RawMessage `json:"visible"`
+}
+
+// rawShape holds the fields shared by rectangle, ellipse, line and polygon nodes.
+type rawShape struct {
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	X            float64         `json:"x"`
+	Y            float64         `json:"y"`
+	Width        json.RawMessage `json:"width"`
+	Height       json.RawMessage `json:"height"`
+	Fill         json.RawMessage `json:"fill"`
+	Stroke       json.RawMessage `json:"stroke"`
+	Opacity      *float64        `json:"opacity"`
+	CornerRadius float64         `json:"cornerRadius"`
+	PolygonCount *int            `json:"polygonCount"`
+	rawSizeLimits
+	rawGridCell
+	rawPosition
+	Enabled *bool           `json:"enabled"`
+	Visible json.RawMessage `json:"visible"`
+}
+
+type rawPath struct {
+	ID       string          `json:"id"`
+	Name     string          `json:"name"`
+	X        float64         `json:"x"`
+	Y        float64         `json:"y"`
+	Width    json.RawMessage `json:"width"`
+	Height   json.RawMessage `json:"height"`
+	Geometry string          `json:"geometry"`
+	FillRule string          `json:"fillRule"`
+	Fill     json.RawMessage `json:"fill"`
+	Stroke   json.RawMessage `json:"stroke"`
+	Opacity  *float64        `json:"opacity"`
+	rawSizeLimits
+	rawGridCell
+	rawPosition
+	Enabled *bool           `json:"enabled"`
+	Visible json.RawMessage `json:"visible"`
+}
+
+type rawImage struct {
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	X            float64         `json:"x"`
+	Y            float64         `json:"y"`
+	Width        json.RawMessage `json:"width"`
+	Height       json.RawMessage `json:"height"`
+	URL          string          `json:"url"`
+	Mode         string          `json:"mode"`
+	Scale        float64         `json:"scale"`
+	Offset       rawPoint        `json:"offset"`
+	Opacity      *float64        `json:"opacity"`
+	CornerRadius float64         `json:"cornerRadius"`
+	Stroke       json.RawMessage `json:"stroke"`
+	rawSizeLimits
+	rawGridCell
+	rawPosition
+	Enabled *bool           `json:"enabled"`
+	Visible json.RawMessage `json:"visible"`
 }
 
 func parseNodes(rawNodes []json.RawMessage) ([]shared.Node, error) {
@@ -109,42 +237,95 @@ func parseNode(data json.RawMessage) (shared.Node, error) {
 		return parseFrame(data)
 	case "text":
 		return parseText(data)
+	case shared.NodeTypeRectangle, shared.NodeTypeEllipse, shared.NodeTypeLine, shared.NodeTypePolygon:
+		return parseShape(probe.Type, data)
+	case shared.NodeTypePath:
+		return parsePath(data)
+	case shared.NodeTypeImage:
+		return parseImage(data)
+	case shared.NodeTypeRef:
+		return parseRef(data)
 	default:
 		return nil, fmt.Errorf("unknown node type: %q", probe.Type)
 	}
 }
 
 func parseFrame(data json.RawMessage) (*shared.Frame, error) {
+	data, bindings := extractBindings(data)
 	var raw rawFrame
 	if err := json.Unmarshal(data, &raw); err != nil {
 		return nil, fmt.Errorf("invalid frame: %w", err)
 	}
 
-	width, err := parseDimension(raw.Width)
+	width, err := parseSize(raw.Width, raw.MinWidth, raw.MaxWidth, raw.Shrink)
 	if err != nil {
 		return nil, fmt.Errorf("frame %q width: %w", raw.ID, err)
 	}
 
-	height, err := parseDimension(raw.Height)
+	height, err := parseSize(raw.Height, raw.MinHeight, raw.MaxHeight, raw.Shrink)
 	if err != nil {
 		return nil, fmt.Errorf("frame %q height: %w", raw.ID, err)
 	}
 
-	fill, err := parseFill(raw.Fill)
+	fills, err := parseFills(raw.Fill)
 	if err != nil {
 		return nil, fmt.Errorf("frame %q fill: %w", raw.ID, err)
 	}
 
+	stroke, err := parseStroke(raw.Stroke)
+	if err != nil {
+		return nil, fmt.Errorf("frame %q stroke: %w", raw.ID, err)
+	}
+
+	effects, err := parseEffects(raw.Effect)
+	if err != nil {
+		return nil, fmt.Errorf("frame %q effect: %w", raw.ID, err)
+	}
+
 	padding, err := parsePadding(raw.Padding)
 	if err != nil {
 		return nil, fmt.Errorf("frame %q padding: %w", raw.ID, err)
 	}
 
+	repeat, err := parseRepeat(raw.Repeat)
+	if err != nil {
+		return nil, fmt.Errorf("frame %q repeat: %w", raw.ID, err)
+	}
+
+	columns, err := parseTracks(raw.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("frame %q columns: %w", raw.ID, err)
+	}
+
+	rows, err := parseTracks(raw.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("frame %q rows: %w", raw.ID, err)
+	}
+
+	if err := checkGridFlow(raw.GridFlow); err != nil {
+		return nil, fmt.Errorf("frame %q gridFlow: %w", raw.ID, err)
+	}
+
 	children, err := parseNodes(raw.Children)
 	if err != nil {
 		return nil, fmt.Errorf("frame %q: %w", raw.ID, err)
 	}
 
+	visibility, err := parseVisibility(raw.Enabled, raw.Visible)
+	if err != nil {
+		return nil, fmt.Errorf("frame %q visible: %w", raw.ID, err)
+	}
+
+	cell, err := parseGridCell(raw.rawGridCell)
+	if err != nil {
+		return nil, fmt.Errorf("frame %q grid: %w", raw.ID, err)
+	}
+
+	position, err := parsePosition(raw.rawPosition)
+	if err != nil {
+		return nil, fmt.Errorf("frame %q position: %w", raw.ID, err)
+	}
+
 	return &shared.Frame{
 		ID:             raw.ID,
 		Name:           raw.Name,
@@ -152,34 +333,92 @@ func parseFrame(data json.RawMessage) (*shared.Frame, error) {
 		Y:              raw.Y,
 		Width:          width,
 		Height:         height,
-		Fill:           fill,
+		Fills:          fills,
+		Stroke:         stroke,
+		Effects:        effects,
 		CornerRadius:   raw.CornerRadius,
 		Clip:           raw.Clip,
 		Layout:         raw.Layout,
 		Gap:            raw.Gap,
+		RowGap:         raw.RowGap,
+		ColumnGap:      raw.ColumnGap,
+		Wrap:           raw.Wrap,
+		Columns:        columns,
+		Rows:           rows,
+		GridFlow:       raw.GridFlow,
 		Padding:        padding,
 		JustifyContent: raw.JustifyContent,
 		AlignItems:     raw.AlignItems,
+		Reusable:       raw.Reusable,
+		Theme:          raw.Theme,
+		Repeat:         repeat,
+		Cell:           cell,
+		Position:       position,
+		Visibility:     visibility,
+		Bindings:       bindings,
 		Children:       children,
 	}, nil
 }
 
+func parseRepeat(raw *rawRepeat) (*shared.Repeat, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	if raw.Data == "" {
+		return nil, fmt.Errorf("missing data")
+	}
+	empty, err := parseNodes(raw.Empty)
+	if err != nil {
+		return nil, fmt.Errorf("empty: %w", err)
+	}
+	return &shared.Repeat{Data: raw.Data, As: raw.As, Empty: empty}, nil
+}
+
 func parseText(data json.RawMessage) (*shared.Text, error) {
+	data, bindings := extractBindings(data)
 	var raw rawText
 	if err := json.Unmarshal(data, &raw); err != nil {
 		return nil, fmt.Errorf("invalid text: %w", err)
 	}
 
-	width, err := parseDimension(raw.Width)
+	width, err := parseSize(raw.Width, raw.MinWidth, raw.MaxWidth, raw.Shrink)
 	if err != nil {
 		return nil, fmt.Errorf("text %q width: %w", raw.ID, err)
 	}
 
+	fills, err := parseFills(raw.Fill)
+	if err != nil {
+		return nil, fmt.Errorf("text %q fill: %w", raw.ID, err)
+	}
+
+	stroke, err := parseStroke(raw.Stroke)
+	if err != nil {
+		return nil, fmt.Errorf("text %q stroke: %w", raw.ID, err)
+	}
+
+	visibility, err := parseVisibility(raw.Enabled, raw.Visible)
+	if err != nil {
+		return nil, fmt.Errorf("text %q visible: %w", raw.ID, err)
+	}
+
+	cell, err := parseGridCell(raw.rawGridCell)
+	if err != nil {
+		return nil, fmt.Errorf("text %q grid: %w", raw.ID, err)
+	}
+
+	position, err := parsePosition(raw.rawPosition)
+	if err != nil {
+		return nil, fmt.Errorf("text %q position: %w", raw.ID, err)
+	}
+
 	return &shared.Text{
 		ID:            raw.ID,
 		Name:          raw.Name,
+		X:             raw.X,
+		Y:             raw.Y,
 		Content:       raw.Content,
-		Fill:          raw.Fill,
+		Fills:         fills,
+		Stroke:        stroke,
 		FontFamily:    raw.FontFamily,
 		FontSize:      raw.FontSize,
 		FontWeight:    raw.FontWeight,
@@ -189,10 +428,603 @@ func parseText(data json.RawMessage) (*shared.Text, error) {
 		TextAlign:     raw.TextAlign,
 		Width:         width,
 		TextGrowth:    raw.TextGrowth,
+		Cell:          cell,
+		Position:      position,
+		Visibility:    visibility,
+		Bindings:      bindings,
+	}, nil
+}
+
+func parseShape(nodeType string, data json.RawMessage) (*shared.Shape, error) {
+	data, bindings := extractBindings(data)
+	var raw rawShape
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", nodeType, err)
+	}
+
+	width, err := parseSize(raw.Width, raw.MinWidth, raw.MaxWidth, raw.Shrink)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q width: %w", nodeType, raw.ID, err)
+	}
+
+	height, err := parseSize(raw.Height, raw.MinHeight, raw.MaxHeight, raw.Shrink)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q height: %w", nodeType, raw.ID, err)
+	}
+
+	fills, err := parseFills(raw.Fill)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q fill: %w", nodeType, raw.ID, err)
+	}
+
+	stroke, err := parseStroke(raw.Stroke)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q stroke: %w", nodeType, raw.ID, err)
+	}
+
+	opacity, err := parseOpacity(raw.Opacity)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q opacity: %w", nodeType, raw.ID, err)
+	}
+
+	sides := 0
+	if nodeType == shared.NodeTypePolygon {
+		sides = 3
+		if raw.PolygonCount != nil {
+			sides = *raw.PolygonCount
+		}
+		if sides < 3 {
+			return nil, fmt.Errorf("%s %q polygonCount: must be at least 3, got %d", nodeType, raw.ID, sides)
+		}
+	}
+
+	visibility, err := parseVisibility(raw.Enabled, raw.Visible)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q visible: %w", nodeType, raw.ID, err)
+	}
+
+	cell, err := parseGridCell(raw.rawGridCell)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q grid: %w", nodeType, raw.ID, err)
+	}
+
+	position, err := parsePosition(raw.rawPosition)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q position: %w", nodeType, raw.ID, err)
+	}
+
+	return &shared.Shape{
+		ID:           raw.ID,
+		Name:         raw.Name,
+		Type:         nodeType,
+		X:            raw.X,
+		Y:            raw.Y,
+		Width:        width,
+		Height:       height,
+		Fills:        fills,
+		Stroke:       stroke,
+		Opacity:      opacity,
+		CornerRadius: raw.CornerRadius,
+		Sides:        sides,
+		Cell:         cell,
+		Position:     position,
+		Visibility:   visibility,
+		Bindings:     bindings,
+	}, nil
+}
+
+func parsePath(data json.RawMessage) (*shared.Path, error) {
+	data, bindings := extractBindings(data)
+	var raw rawPath
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	width, err := parseSize(raw.Width, raw.MinWidth, raw.MaxWidth, raw.Shrink)
+	if err != nil {
+		return nil, fmt.Errorf("path %q width: %w", raw.ID, err)
+	}
+
+	height, err := parseSize(raw.Height, raw.MinHeight, raw.MaxHeight, raw.Shrink)
+	if err != nil {
+		return nil, fmt.Errorf("path %q height: %w", raw.ID, err)
+	}
+
+	geometry, err := shared.ParsePathData(raw.Geometry)
+	if err != nil {
+		return nil, fmt.Errorf("path %q geometry: %w", raw.ID, err)
+	}
+
+	fillRule := shared.FillRuleNonZero
+	switch shared.FillRule(raw.FillRule) {
+	case "", shared.FillRuleNonZero:
+	case shared.FillRuleEvenOdd:
+		fillRule = shared.FillRuleEvenOdd
+	default:
+		return nil, fmt.Errorf("path %q fillRule: unknown fill rule: %q", raw.ID, raw.FillRule)
+	}
+
+	fills, err := parseFills(raw.Fill)
+	if err != nil {
+		return nil, fmt.Errorf("path %q fill: %w", raw.ID, err)
+	}
+
+	stroke, err := parseStroke(raw.Stroke)
+	if err != nil {
+		return nil, fmt.Errorf("path %q stroke: %w", raw.ID, err)
+	}
+
+	opacity, err := parseOpacity(raw.Opacity)
+	if err != nil {
+		return nil, fmt.Errorf("path %q opacity: %w", raw.ID, err)
+	}
+
+	visibility, err := parseVisibility(raw.Enabled, raw.Visible)
+	if err != nil {
+		return nil, fmt.Errorf("path %q visible: %w", raw.ID, err)
+	}
+
+	cell, err := parseGridCell(raw.rawGridCell)
+	if err != nil {
+		return nil, fmt.Errorf("path %q grid: %w", raw.ID, err)
+	}
+
+	position, err := parsePosition(raw.rawPosition)
+	if err != nil {
+		return nil, fmt.Errorf("path %q position: %w", raw.ID, err)
+	}
+
+	return &shared.Path{
+		ID:         raw.ID,
+		Name:       raw.Name,
+		X:          raw.X,
+		Y:          raw.Y,
+		Width:      width,
+		Height:     height,
+		Geometry:   geometry,
+		FillRule:   fillRule,
+		Fills:      fills,
+		Stroke:     stroke,
+		Opacity:    opacity,
+		Cell:       cell,
+		Position:   position,
+		Visibility: visibility,
+		Bindings:   bindings,
+	}, nil
+}
+
+func parseImage(data json.RawMessage) (*shared.Image, error) {
+	data, bindings := extractBindings(data)
+	var raw rawImage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+
+	width, err := parseSize(raw.Width, raw.MinWidth, raw.MaxWidth, raw.Shrink)
+	if err != nil {
+		return nil, fmt.Errorf("image %q width: %w", raw.ID, err)
+	}
+
+	height, err := parseSize(raw.Height, raw.MinHeight, raw.MaxHeight, raw.Shrink)
+	if err != nil {
+		return nil, fmt.Errorf("image %q height: %w", raw.ID, err)
+	}
+
+	mode, err := parseImageMode(raw.Mode)
+	if err != nil {
+		return nil, fmt.Errorf("image %q mode: %w", raw.ID, err)
+	}
+
+	stroke, err := parseStroke(raw.Stroke)
+	if err != nil {
+		return nil, fmt.Errorf("image %q stroke: %w", raw.ID, err)
+	}
+
+	opacity, err := parseOpacity(raw.Opacity)
+	if err != nil {
+		return nil, fmt.Errorf("image %q opacity: %w", raw.ID, err)
+	}
+
+	visibility, err := parseVisibility(raw.Enabled, raw.Visible)
+	if err != nil {
+		return nil, fmt.Errorf("image %q visible: %w", raw.ID, err)
+	}
+
+	cell, err := parseGridCell(raw.rawGridCell)
+	if err != nil {
+		return nil, fmt.Errorf("image %q grid: %w", raw.ID, err)
+	}
+
+	position, err := parsePosition(raw.rawPosition)
+	if err != nil {
+		return nil, fmt.Errorf("image %q position: %w", raw.ID, err)
+	}
+
+	return &shared.Image{
+		ID:           raw.ID,
+		Name:         raw.Name,
+		X:            raw.X,
+		Y:            raw.Y,
+		Width:        width,
+		Height:       height,
+		URL:          raw.URL,
+		Mode:         mode,
+		Scale:        raw.Scale,
+		Offset:       shared.ImageOffset{X: raw.Offset.X, Y: raw.Offset.Y},
+		Opacity:      opacity,
+		CornerRadius: raw.CornerRadius,
+		Stroke:       stroke,
+		Cell:         cell,
+		Position:     position,
+		Visibility:   visibility,
+		Bindings:     bindings,
+	}, nil
+}
+
+// bindableProperties are the node properties that accept a "$variable"
+// reference in place of their value. Colors keep their references inline,
+// and text content is always literal, so "$AAPL" is drawn as written.
+var bindableProperties = map[string]bool{
+	"x": true, "y": true, "width": true, "height": true, "opacity": true,
+	"cornerRadius": true, "gap": true, "rowGap": true, "columnGap": true, "padding": true, "scale": true,
+	"fontSize": true, "letterSpacing": true, "lineHeight": true,
+	"fontFamily": true, "fontWeight": true, "fontStyle": true, "textAlign": true,
+	"url": true, "layout": true, "justifyContent": true, "alignItems": true,
+}
+
+// paddingSides are the sides set by each element of a padding array, by
+// array length.
+var paddingSides = map[int][][]string{
+	2: {{"top", "bottom"}, {"right", "left"}},
+	4: {{"top"}, {"right"}, {"bottom"}, {"left"}},
+}
+
+// variableRefPattern matches a value that is a whole variable reference.
+var variableRefPattern = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_.-]*)$`)
+
+// variableRef returns the variable a JSON value references, if it is a
+// "$name" string.
+func variableRef(data json.RawMessage) (string, bool) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return "", false
+	}
+	m := variableRefPattern.FindStringSubmatch(s)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// extractBindings removes the variable references from the properties of a
+// node object, so the rest decodes as usual, and returns the bindings they
+// make. Referencing padding elements are replaced by 0 and a referencing
+// stroke thickness is dropped.
+func extractBindings(data json.RawMessage) (json.RawMessage, shared.Bindings) {
+	var props map[string]json.RawMessage
+	if err := json.Unmarshal(data, &props); err != nil {
+		return data, nil // reported when decoding the node
+	}
+
+	var bindings shared.Bindings
+	for key, value := range props {
+		if !bindableProperties[key] {
+			continue
+		}
+		if name, ok := variableRef(value); ok {
+			bindings.Bind(key, name)
+			delete(props, key)
+		}
+	}
+
+	var padding []json.RawMessage
+	if json.Unmarshal(props["padding"], &padding) == nil && paddingSides[len(padding)] != nil {
+		changed := false
+		for i, item := range padding {
+			if name, ok := variableRef(item); ok {
+				for _, side := range paddingSides[len(padding)][i] {
+					bindings.Bind("padding."+side, name)
+				}
+				padding[i] = json.RawMessage("0")
+				changed = true
+			}
+		}
+		if changed {
+			props["padding"], _ = json.Marshal(padding)
+		}
+	}
+
+	var stroke map[string]json.RawMessage
+	if json.Unmarshal(props["stroke"], &stroke) == nil {
+		if name, ok := variableRef(stroke["thickness"]); ok {
+			bindings.Bind("stroke.thickness", name)
+			delete(stroke, "thickness")
+			props["stroke"], _ = json.Marshal(stroke)
+		}
+	}
+
+	if len(bindings) == 0 {
+		return data, nil
+	}
+	out, err := json.Marshal(props)
+	if err != nil {
+		return data, nil
+	}
+	return out, bindings
+}
+
+// refKeys are the properties of a ref node that are not root overrides.
+var refKeys = map[string]bool{"type": true, "id": true, "name": true, "ref": true, "descendants": true}
+
+func parseRef(data json.RawMessage) (*shared.Ref, error) {
+	var raw struct {
+		ID          string                     `json:"id"`
+		Name        string                     `json:"name"`
+		Ref         string                     `json:"ref"`
+		Descendants map[string]json.RawMessage `json:"descendants"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid ref: %w", err)
+	}
+	if raw.Ref == "" {
+		return nil, fmt.Errorf("ref %q: missing component reference", raw.ID)
+	}
+
+	override, err := parseOverride(data, refKeys)
+	if err != nil {
+		return nil, fmt.Errorf("ref %q: %w", raw.ID, err)
+	}
+
+	var descendants map[string]*shared.NodeOverride
+	if len(raw.Descendants) > 0 {
+		descendants = make(map[string]*shared.NodeOverride, len(raw.Descendants))
+	}
+	for id, item := range raw.Descendants {
+		o, err := parseOverride(item, nil)
+		if err != nil {
+			return nil, fmt.Errorf("ref %q descendant %q: %w", raw.ID, id, err)
+		}
+		descendants[id] = o
+	}
+
+	return &shared.Ref{
+		ID:          raw.ID,
+		Name:        raw.Name,
+		Component:   raw.Ref,
+		Override:    override,
+		Descendants: descendants,
 	}, nil
 }
 
-// parseDimension handles: number (800), string ("fill_container"), or absent (null/empty).
+// parseOverride parses the properties of an object, except skipped keys, as
+// overrides of a component node.
+func parseOverride(data json.RawMessage, skip map[string]bool) (*shared.NodeOverride, error) {
+	data, bindings := extractBindings(data)
+	var props map[string]json.RawMessage
+	if err := json.Unmarshal(data, &props); err != nil {
+		return nil, fmt.Errorf("invalid overrides: %w", err)
+	}
+
+	o := &shared.NodeOverride{Bindings: bindings}
+	for key, value := range props {
+		if skip[key] {
+			continue
+		}
+		var err error
+		switch key {
+		case "name":
+			o.Name, err = parseOverrideValue[string](value)
+		case "x":
+			o.X, err = parseOverrideValue[float64](value)
+		case "y":
+			o.Y, err = parseOverrideValue[float64](value)
+		case "width", "height":
+			var dim shared.Dimension
+			if dim, err = parseDimension(value); err == nil {
+				if key == "width" {
+					o.Width = &dim
+				} else {
+					o.Height = &dim
+				}
+			}
+		case "fill":
+			if o.Fills, err = parseFills(value); err == nil && o.Fills == nil {
+				o.Fills = []*shared.Fill{}
+			}
+		case "stroke":
+			o.Stroke, err = parseStroke(value)
+		case "opacity":
+			var opacity *float64
+			if opacity, err = parseOverrideValue[float64](value); err == nil {
+				var v float64
+				v, err = parseOpacity(opacity)
+				o.Opacity = &v
+			}
+		case "cornerRadius":
+			o.CornerRadius, err = parseOverrideValue[float64](value)
+		case "content":
+			o.Content, err = parseOverrideValue[string](value)
+		case "fontFamily":
+			o.FontFamily, err = parseOverrideValue[string](value)
+		case "fontSize":
+			o.FontSize, err = parseOverrideValue[float64](value)
+		case "fontWeight":
+			o.FontWeight, err = parseOverrideValue[string](value)
+		case "fontStyle":
+			o.FontStyle, err = parseOverrideValue[string](value)
+		case "letterSpacing":
+			o.LetterSpacing, err = parseOverrideValue[float64](value)
+		case "lineHeight":
+			o.LineHeight, err = parseOverrideValue[float64](value)
+		case "textAlign":
+			o.TextAlign, err = parseOverrideValue[string](value)
+		case "url":
+			o.URL, err = parseOverrideValue[string](value)
+		case "layout":
+			o.Layout, err = parseOverrideValue[string](value)
+		case "gap":
+			o.Gap, err = parseOverrideValue[float64](value)
+		case "rowGap":
+			o.RowGap, err = parseOverrideValue[float64](value)
+		case "columnGap":
+			o.ColumnGap, err = parseOverrideValue[float64](value)
+		case "wrap":
+			o.Wrap, err = parseOverrideValue[bool](value)
+		case "columns":
+			o.Columns, err = parseTracks(value)
+		case "rows":
+			o.Rows, err = parseTracks(value)
+		case "gridFlow":
+			if o.GridFlow, err = parseOverrideValue[string](value); err == nil {
+				err = checkGridFlow(*o.GridFlow)
+			}
+		case "gridColumn":
+			o.GridColumn, err = parseGridIndex(value)
+		case "gridRow":
+			o.GridRow, err = parseGridIndex(value)
+		case "columnSpan":
+			o.ColumnSpan, err = parseGridIndex(value)
+		case "rowSpan":
+			o.RowSpan, err = parseGridIndex(value)
+		case "minWidth":
+			o.MinWidth, err = parseOverrideValue[float64](value)
+		case "maxWidth":
+			o.MaxWidth, err = parseOverrideValue[float64](value)
+		case "minHeight":
+			o.MinHeight, err = parseOverrideValue[float64](value)
+		case "maxHeight":
+			o.MaxHeight, err = parseOverrideValue[float64](value)
+		case "shrink":
+			if o.Shrink, err = parseOverrideValue[float64](value); err == nil && o.Shrink != nil {
+				err = checkShrink(*o.Shrink)
+			}
+		case "position":
+			var name *string
+			if name, err = parseOverrideValue[string](value); err == nil {
+				var position shared.Position
+				if position, err = parsePosition(rawPosition{Position: *name}); err == nil {
+					o.Absolute = &position.Absolute
+				}
+			}
+		case "right":
+			o.Right, err = parseOverrideValue[float64](value)
+		case "bottom":
+			o.Bottom, err = parseOverrideValue[float64](value)
+		case "padding":
+			var padding shared.Padding
+			if padding, err = parsePadding(value); err == nil {
+				o.Padding = &padding
+			}
+		case "justifyContent":
+			o.JustifyContent, err = parseOverrideValue[string](value)
+		case "alignItems":
+			o.AlignItems, err = parseOverrideValue[string](value)
+		case "enabled":
+			var enabled *bool
+			if enabled, err = parseOverrideValue[bool](value); err == nil {
+				hidden := !*enabled
+				o.Hidden = &hidden
+			}
+		case "visible":
+			var v shared.Visibility
+			if v, err = parseVisibility(nil, value); err == nil {
+				if v.Condition != "" {
+					o.Condition = &v.Condition
+				} else {
+					o.Hidden = &v.Hidden
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unsupported override property %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return o, nil
+}
+
+// parseVisibility combines the Pencil enabled flag with visible, which is a
+// boolean or a condition evaluated by the resolver.
+func parseVisibility(enabled *bool, visible json.RawMessage) (shared.Visibility, error) {
+	v := shared.Visibility{Hidden: enabled != nil && !*enabled}
+	if len(visible) == 0 || string(visible) == "null" {
+		return v, nil
+	}
+
+	var flag bool
+	if err := json.Unmarshal(visible, &flag); err == nil {
+		v.Hidden = v.Hidden || !flag
+		return v, nil
+	}
+	var condition string
+	if err := json.Unmarshal(visible, &condition); err != nil || condition == "" {
+		return v, fmt.Errorf("expected a boolean or a condition, got %s", string(visible))
+	}
+	v.Condition = condition
+	return v, nil
+}
+
+// parseOverrideValue decodes a scalar override value.
+func parseOverrideValue[T any](data json.RawMessage) (*T, error) {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("invalid value %s", string(data))
+	}
+	return &v, nil
+}
+
+// parseOpacity returns a node opacity between 0 and 1, defaulting to opaque.
+func parseOpacity(value *float64) (float64, error) {
+	if value == nil {
+		return 1, nil
+	}
+	if *value < 0 || *value > 1 {
+		return 0, fmt.Errorf("must be between 0 and 1, got %v", *value)
+	}
+	return *value, nil
+}
+
+// parseSize parses a width or height with its minimum, maximum and shrink
+// weight.
+func parseSize(data json.RawMessage, minSize, maxSize, shrink *float64) (shared.Dimension, error) {
+	d, err := parseDimension(data)
+	if err != nil {
+		return d, err
+	}
+	if minSize != nil {
+		if *minSize < 0 {
+			return d, fmt.Errorf("minimum must not be negative, got %v", *minSize)
+		}
+		d.Min = *minSize
+	}
+	if maxSize != nil {
+		if *maxSize <= 0 {
+			return d, fmt.Errorf("maximum must be positive, got %v", *maxSize)
+		}
+		d.Max = *maxSize
+	}
+	if d.Max > 0 && d.Min > d.Max {
+		return d, fmt.Errorf("minimum %v is larger than maximum %v", d.Min, d.Max)
+	}
+	if shrink != nil {
+		if err := checkShrink(*shrink); err != nil {
+			return d, err
+		}
+		d.Shrink = shrink
+	}
+	return d, nil
+}
+
+func checkShrink(shrink float64) error {
+	if shrink < 0 {
+		return fmt.Errorf("shrink must not be negative, got %v", shrink)
+	}
+	return nil
+}
+
+// parseDimension handles: number (800), string ("fill_container",
+// "fill_container(2)" or "fit_content"), or absent (null/empty).
 func parseDimension(data json.RawMessage) (shared.Dimension, error) {
 	if len(data) == 0 || string(data) == "null" {
 		return shared.Dimension{}, nil
@@ -205,8 +1037,20 @@ func parseDimension(data json.RawMessage) (shared.Dimension, error) {
 
 	var str string
 	if err := json.Unmarshal(data, &str); err == nil {
-		if str == "fill_container" {
+		switch str {
+		case "fill_container":
 			return shared.FillContainerDimension(), nil
+		case "fit_content":
+			return shared.FitContentDimension(), nil
+		}
+		if weight, ok := strings.CutPrefix(str, "fill_container("); ok {
+			if weight, ok := strings.CutSuffix(weight, ")"); ok {
+				grow, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+				if err != nil || grow <= 0 {
+					return shared.Dimension{}, fmt.Errorf("fill_container weight must be a positive number, got %q", weight)
+				}
+				return shared.WeightedFillDimension(grow), nil
+			}
 		}
 		return shared.Dimension{}, fmt.Errorf("unknown dimension value: %q", str)
 	}
@@ -214,36 +1058,440 @@ func parseDimension(data json.RawMessage) (shared.Dimension, error) {
 	return shared.Dimension{}, fmt.Errorf("invalid dimension: %s", string(data))
 }
 
-// parseFill handles: string ("#RRGGBB"), object ({type, url, ...}), or absent.
-func parseFill(data json.RawMessage) (*shared.Fill, error) {
+// parseTracks handles the tracks of a grid: a count of equal fraction
+// tracks (3), a string of space-separated tracks ("120 1fr auto"), an array
+// of numbers and track strings, or absent.
+func parseTracks(data json.RawMessage) ([]shared.GridTrack, error) {
 	if len(data) == 0 || string(data) == "null" {
 		return nil, nil
 	}
 
+	var count int
+	if err := json.Unmarshal(data, &count); err == nil {
+		if count < 1 {
+			return nil, fmt.Errorf("track count must be at least 1, got %d", count)
+		}
+		tracks := make([]shared.GridTrack, count)
+		for i := range tracks {
+			tracks[i] = shared.FractionTrack(1)
+		}
+		return tracks, nil
+	}
+
+	var values []json.RawMessage
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		for _, field := range strings.Fields(str) {
+			values = append(values, json.RawMessage(strconv.Quote(field)))
+		}
+	} else if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("invalid tracks: %s", string(data))
+	}
+
+	tracks := make([]shared.GridTrack, 0, len(values))
+	for _, value := range values {
+		track, err := parseTrack(value)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+// parseTrack handles a grid track: a size in points (120 or "120"), a
+// fraction of the free space ("1fr") or "auto".
+func parseTrack(data json.RawMessage) (shared.GridTrack, error) {
+	var num float64
+	if err := json.Unmarshal(data, &num); err == nil {
+		if num < 0 {
+			return shared.GridTrack{}, fmt.Errorf("invalid track %s", string(data))
+		}
+		return shared.FixedTrack(num), nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return shared.GridTrack{}, fmt.Errorf("invalid track %s", string(data))
+	}
+	if str == "auto" {
+		return shared.AutoTrack(), nil
+	}
+	value, isFraction := strings.CutSuffix(str, "fr")
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v < 0 {
+		return shared.GridTrack{}, fmt.Errorf("invalid track %q", str)
+	}
+	if isFraction {
+		return shared.FractionTrack(v), nil
+	}
+	return shared.FixedTrack(v), nil
+}
+
+// parseGridCell returns the grid placement of a node. Rows and columns
+// start at 1; absent ones are placed automatically.
+func parseGridCell(raw rawGridCell) (shared.GridCell, error) {
+	for _, field := range []struct {
+		name  string
+		value int
+	}{
+		{"gridColumn", raw.GridColumn},
+		{"gridRow", raw.GridRow},
+		{"columnSpan", raw.ColumnSpan},
+		{"rowSpan", raw.RowSpan},
+	} {
+		if field.value < 0 {
+			return shared.GridCell{}, fmt.Errorf("%s must not be negative, got %d", field.name, field.value)
+		}
+	}
+	return shared.GridCell{
+		Column:     raw.GridColumn,
+		Row:        raw.GridRow,
+		ColumnSpan: raw.ColumnSpan,
+		RowSpan:    raw.RowSpan,
+	}, nil
+}
+
+// parsePosition returns how a node is placed in its parent: in its flow,
+// or at its x and y with "position": "absolute".
+func parsePosition(raw rawPosition) (shared.Position, error) {
+	p := shared.Position{Right: raw.Right, Bottom: raw.Bottom}
+	switch raw.Position {
+	case "":
+	case "absolute":
+		p.Absolute = true
+	default:
+		return p, fmt.Errorf("unknown position: %q", raw.Position)
+	}
+	return p, nil
+}
+
+// parseGridIndex decodes a grid row, column or span override.
+func parseGridIndex(data json.RawMessage) (*int, error) {
+	v, err := parseOverrideValue[int](data)
+	if err != nil {
+		return nil, err
+	}
+	if *v < 0 {
+		return nil, fmt.Errorf("must not be negative, got %d", *v)
+	}
+	return v, nil
+}
+
+// checkGridFlow returns an error unless flow is an axis grid children are
+// placed along.
+func checkGridFlow(flow string) error {
+	switch flow {
+	case "", "row", "column":
+		return nil
+	default:
+		return fmt.Errorf("unknown grid flow: %q", flow)
+	}
+}
+
+// parseFills handles: a single fill, an array of fills (bottom to top), or absent.
+func parseFills(data json.RawMessage) ([]*shared.Fill, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		fill, err := parseFill(data)
+		if err != nil {
+			return nil, err
+		}
+		return []*shared.Fill{fill}, nil
+	}
+
+	fills := make([]*shared.Fill, 0, len(items))
+	for i, item := range items {
+		fill, err := parseFill(item)
+		if err != nil {
+			return nil, fmt.Errorf("fill %d: %w", i, err)
+		}
+		fills = append(fills, fill)
+	}
+	return fills, nil
+}
+
+// parseFill handles: string ("#RRGGBB") or object ({type, enabled, ...}).
+// Object fills are enabled unless "enabled" is false.
+func parseFill(data json.RawMessage) (*shared.Fill, error) {
 	var str string
 	if err := json.Unmarshal(data, &str); err == nil {
 		return shared.SolidFill(str), nil
 	}
 
 	var obj struct {
-		Type    string  `json:"type"`
-		URL     string  `json:"url"`
-		Mode    string  `json:"mode"`
-		Opacity float64 `json:"opacity"`
-		Enabled bool    `json:"enabled"`
+		Type         string         `json:"type"`
+		Color        string         `json:"color"`
+		URL          string         `json:"url"`
+		Mode         string         `json:"mode"`
+		Scale        float64        `json:"scale"`
+		Offset       rawPoint       `json:"offset"`
+		Opacity      float64        `json:"opacity"`
+		Enabled      *bool          `json:"enabled"`
+		GradientType string         `json:"gradientType"`
+		Colors       []rawColorStop `json:"colors"`
+		Rotation     float64        `json:"rotation"`
+		Center       *rawPoint      `json:"center"`
+		Size         *rawGradientWH `json:"size"`
 	}
 	if err := json.Unmarshal(data, &obj); err != nil {
 		return nil, fmt.Errorf("invalid fill: %w", err)
 	}
 
+	enabled := obj.Enabled == nil || *obj.Enabled
+
 	switch obj.Type {
+	case "color":
+		fill := shared.SolidFill(obj.Color)
+		fill.Enabled = enabled
+		return fill, nil
 	case "image":
-		return shared.ImageFill(obj.URL, obj.Mode, obj.Opacity, obj.Enabled), nil
+		mode, err := parseImageMode(obj.Mode)
+		if err != nil {
+			return nil, err
+		}
+		fill := shared.ImageFill(obj.URL, mode, obj.Opacity, enabled)
+		fill.Scale = obj.Scale
+		fill.Offset = shared.ImageOffset{X: obj.Offset.X, Y: obj.Offset.Y}
+		return fill, nil
+	case "gradient":
+		fill, err := parseGradient(obj.GradientType, obj.Colors)
+		if err != nil {
+			return nil, err
+		}
+		fill.Opacity = obj.Opacity
+		fill.Enabled = enabled
+		fill.Rotation = obj.Rotation
+		if obj.Center != nil {
+			fill.Center = shared.GradientPoint{X: obj.Center.X, Y: obj.Center.Y}
+		}
+		if obj.Size != nil {
+			if obj.Size.Width != nil {
+				fill.Size.Width = *obj.Size.Width
+			}
+			if obj.Size.Height != nil {
+				fill.Size.Height = *obj.Size.Height
+			}
+		}
+		return fill, nil
 	default:
 		return nil, fmt.Errorf("unknown fill type: %q", obj.Type)
 	}
 }
 
+// parseImageMode validates an image fill mode, accepting the CSS names cover
+// and contain for fill and fit.
+func parseImageMode(mode string) (string, error) {
+	switch mode {
+	case "", shared.ImageModeFill, shared.ImageModeFit, shared.ImageModeStretch, shared.ImageModeTile, shared.ImageModeCrop:
+		return mode, nil
+	case "cover":
+		return shared.ImageModeFill, nil
+	case "contain":
+		return shared.ImageModeFit, nil
+	default:
+		return "", fmt.Errorf("unknown image mode: %q", mode)
+	}
+}
+
+type rawColorStop struct {
+	Color    string   `json:"color"`
+	Position *float64 `json:"position"`
+}
+
+// rawPoint is an {x, y} pair, used by gradient centers and by shadow and
+// image offsets.
+type rawPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type rawGradientWH struct {
+	Width  *float64 `json:"width"`
+	Height *float64 `json:"height"`
+}
+
+// parseGradient builds a gradient fill from its type ("linear", "radial" or
+// "angular"; defaults to linear) and color stops. Stops without a position
+// are spread evenly; positions are clamped to [0, 1] and sorted.
+func parseGradient(gradientType string, colors []rawColorStop) (*shared.Fill, error) {
+	var fillType shared.FillType
+	switch gradientType {
+	case "", "linear":
+		fillType = shared.FillLinearGradient
+	case "radial":
+		fillType = shared.FillRadialGradient
+	case "angular":
+		fillType = shared.FillAngularGradient
+	default:
+		return nil, fmt.Errorf("unknown gradient type: %q", gradientType)
+	}
+
+	if len(colors) == 0 {
+		return nil, fmt.Errorf("gradient has no color stops")
+	}
+
+	stops := make([]shared.ColorStop, len(colors))
+	for i, c := range colors {
+		pos := 0.0
+		switch {
+		case c.Position != nil:
+			pos = math.Min(math.Max(*c.Position, 0), 1)
+		case len(colors) > 1:
+			pos = float64(i) / float64(len(colors)-1)
+		}
+		stops[i] = shared.ColorStop{Color: c.Color, Position: pos}
+	}
+	sort.SliceStable(stops, func(i, j int) bool { return stops[i].Position < stops[j].Position })
+
+	return shared.GradientFill(fillType, stops), nil
+}
+
+// parseStroke handles: object ({fill, thickness, align, dashPattern}) or absent.
+// Thickness is a number or a per-side object ({top, right, bottom, left});
+// it defaults to 1. Align defaults to "inside".
+func parseStroke(data json.RawMessage) (*shared.Stroke, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+
+	var obj struct {
+		Fill        string          `json:"fill"`
+		Thickness   json.RawMessage `json:"thickness"`
+		Align       string          `json:"align"`
+		DashPattern []float64       `json:"dashPattern"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("invalid stroke: %w", err)
+	}
+
+	thickness, err := parseStrokeThickness(obj.Thickness)
+	if err != nil {
+		return nil, err
+	}
+
+	align := shared.StrokeAlign(obj.Align)
+	switch align {
+	case "":
+		align = shared.StrokeInside
+	case shared.StrokeInside, shared.StrokeCenter, shared.StrokeOutside:
+	default:
+		return nil, fmt.Errorf("unknown stroke align: %q", obj.Align)
+	}
+
+	for _, d := range obj.DashPattern {
+		if d < 0 {
+			return nil, fmt.Errorf("dash pattern values must be non-negative, got %v", obj.DashPattern)
+		}
+	}
+
+	return &shared.Stroke{
+		Color:     obj.Fill,
+		Thickness: thickness,
+		Align:     align,
+		Dash:      obj.DashPattern,
+	}, nil
+}
+
+// parseEffects handles: a single effect object, an array of effects, or absent.
+func parseEffects(data json.RawMessage) ([]*shared.Effect, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		effect, err := parseEffect(data)
+		if err != nil {
+			return nil, err
+		}
+		return []*shared.Effect{effect}, nil
+	}
+
+	effects := make([]*shared.Effect, 0, len(items))
+	for i, item := range items {
+		effect, err := parseEffect(item)
+		if err != nil {
+			return nil, fmt.Errorf("effect %d: %w", i, err)
+		}
+		effects = append(effects, effect)
+	}
+	return effects, nil
+}
+
+// parseEffect handles: shadow ({type: "shadow", shadowType, offset, blur,
+// spread, color}), layer blur ({type: "blur", radius}) and background blur
+// ({type: "background_blur", radius}). Effects are enabled unless "enabled"
+// is false.
+func parseEffect(data json.RawMessage) (*shared.Effect, error) {
+	var obj struct {
+		Type       string   `json:"type"`
+		ShadowType string   `json:"shadowType"`
+		Offset     rawPoint `json:"offset"`
+		Blur       float64  `json:"blur"`
+		Spread     float64  `json:"spread"`
+		Color      string   `json:"color"`
+		Radius     float64  `json:"radius"`
+		Enabled    *bool    `json:"enabled"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("invalid effect: %w", err)
+	}
+
+	if obj.Blur < 0 || obj.Radius < 0 {
+		return nil, fmt.Errorf("blur must be non-negative")
+	}
+
+	var effect *shared.Effect
+	switch obj.Type {
+	case "shadow":
+		switch obj.ShadowType {
+		case "", "outer":
+			effect = shared.DropShadow(obj.Color, obj.Offset.X, obj.Offset.Y, obj.Blur, obj.Spread)
+		case "inner":
+			effect = shared.InnerShadow(obj.Color, obj.Offset.X, obj.Offset.Y, obj.Blur, obj.Spread)
+		default:
+			return nil, fmt.Errorf("unknown shadow type: %q", obj.ShadowType)
+		}
+	case "blur":
+		effect = shared.LayerBlur(obj.Radius)
+	case "background_blur":
+		effect = shared.BackgroundBlur(obj.Radius)
+	default:
+		return nil, fmt.Errorf("unknown effect type: %q", obj.Type)
+	}
+
+	effect.Enabled = obj.Enabled == nil || *obj.Enabled
+	return effect, nil
+}
+
+// parseStrokeThickness handles: number (2), object ({top, right, bottom, left}), or absent.
+func parseStrokeThickness(data json.RawMessage) (shared.StrokeThickness, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return shared.UniformThickness(1), nil
+	}
+
+	var num float64
+	if err := json.Unmarshal(data, &num); err == nil {
+		return shared.UniformThickness(num), nil
+	}
+
+	var sides struct {
+		Top    float64 `json:"top"`
+		Right  float64 `json:"right"`
+		Bottom float64 `json:"bottom"`
+		Left   float64 `json:"left"`
+	}
+	if err := json.Unmarshal(data, &sides); err != nil {
+		return shared.StrokeThickness{}, fmt.Errorf("invalid stroke thickness: %s", string(data))
+	}
+	return shared.StrokeThickness{Top: sides.Top, Right: sides.Right, Bottom: sides.Bottom, Left: sides.Left}, nil
+}
+
 // parsePadding handles: number (40), 2-element array [v, h], 4-element array [t, r, b, l], or absent.
 func parsePadding(data json.RawMessage) (shared.Padding, error) {
 	if len(data) == 0 || string(data) == "null" {
@@ -297,19 +1545,73 @@ func parseVariable(data json.RawMessage) (shared.Variable, error) {
 
 	varType := shared.VariableType(raw.Type)
 	switch varType {
-	case shared.VariableColor, shared.VariableString:
-		var s string
-		if err := json.Unmarshal(raw.Value, &s); err != nil {
-			return shared.Variable{}, fmt.Errorf("expected string value: %w", err)
+	case shared.VariableColor, shared.VariableString, shared.VariableNumber, shared.VariableBoolean:
+	default:
+		return shared.Variable{}, fmt.Errorf("unknown variable type: %q", raw.Type)
+	}
+
+	// A list holds values for different themes
+	var entries []struct {
+		Value json.RawMessage `json:"value"`
+		Theme shared.Theme    `json:"theme"`
+	}
+	if err := json.Unmarshal(raw.Value, &entries); err != nil {
+		value, err := parseVariableValue(varType, raw.Value)
+		if err != nil {
+			return shared.Variable{}, err
 		}
-		return shared.Variable{Type: varType, Value: s}, nil
-	case shared.VariableNumber:
+		return shared.Variable{Type: varType, Value: value}, nil
+	}
+	if len(entries) == 0 {
+		return shared.Variable{}, fmt.Errorf("expected at least one value")
+	}
+
+	v := shared.Variable{Type: varType}
+	for i, entry := range entries {
+		value, err := parseVariableValue(varType, entry.Value)
+		if err != nil {
+			return shared.Variable{}, fmt.Errorf("value %d: %w", i, err)
+		}
+		if len(entry.Theme) == 0 {
+			v.Value = value
+			continue
+		}
+		v.Themed = append(v.Themed, shared.ThemedValue{Value: value, Theme: entry.Theme})
+	}
+	// Without an unthemed value, the first one is the fallback
+	if v.Value == nil {
+		v.Value = v.Themed[0].Value
+	}
+	return v, nil
+}
+
+func parseVariableValue(varType shared.VariableType, data json.RawMessage) (any, error) {
+	if varType == shared.VariableNumber {
 		var n float64
-		if err := json.Unmarshal(raw.Value, &n); err != nil {
-			return shared.Variable{}, fmt.Errorf("expected number value: %w", err)
+		if err := json.Unmarshal(data, &n); err != nil {
+			// Strings are expressions, evaluated by the resolver
+			var expr string
+			if json.Unmarshal(data, &expr) == nil {
+				return expr, nil
+			}
+			return nil, fmt.Errorf("expected number value: %w", err)
 		}
-		return shared.Variable{Type: varType, Value: n}, nil
-	default:
-		return shared.Variable{}, fmt.Errorf("unknown variable type: %q", raw.Type)
+		return n, nil
+	}
+	if varType == shared.VariableBoolean {
+		var b bool
+		if err := json.Unmarshal(data, &b); err != nil {
+			var expr string
+			if json.Unmarshal(data, &expr) == nil {
+				return expr, nil
+			}
+			return nil, fmt.Errorf("expected boolean value: %w", err)
+		}
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("expected string value: %w", err)
 	}
+	return s, nil
 }
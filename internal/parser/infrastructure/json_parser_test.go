@@ -1,6 +1,7 @@
 package infrastructure_test
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -124,8 +125,8 @@ func TestParseTextNode(t *testing.T) {
 	if text.Content != "Hello World" {
 		t.Errorf("expected Content 'Hello World', got '%s'", text.Content)
 	}
-	if text.Fill != "#FF0000" {
-		t.Errorf("expected Fill '#FF0000', got '%s'", text.Fill)
+	if text.Fills[0].Color != "#FF0000" {
+		t.Errorf("expected Fill '#FF0000', got '%s'", text.Fills[0].Color)
 	}
 	if text.FontFamily != "Inter" {
 		t.Errorf("expected FontFamily 'Inter', got '%s'", text.FontFamily)
@@ -186,14 +187,14 @@ func TestParseFillSolidString(t *testing.T) {
 	doc := mustParse(t, input)
 
 	frame := doc.Children[0].(*shared.Frame)
-	if frame.Fill == nil {
-		t.Fatal("expected Fill to be set")
+	if len(frame.Fills) != 1 {
+		t.Fatalf("expected one fill, got %d", len(frame.Fills))
 	}
-	if frame.Fill.Type != shared.FillSolid {
-		t.Errorf("expected FillSolid, got '%s'", frame.Fill.Type)
+	if frame.Fills[0].Type != shared.FillSolid {
+		t.Errorf("expected FillSolid, got '%s'", frame.Fills[0].Type)
 	}
-	if frame.Fill.Color != "#FF6B35" {
-		t.Errorf("expected color '#FF6B35', got '%s'", frame.Fill.Color)
+	if frame.Fills[0].Color != "#FF6B35" {
+		t.Errorf("expected color '#FF6B35', got '%s'", frame.Fills[0].Color)
 	}
 }
 
@@ -210,8 +211,8 @@ func TestParseFillSolidWithAlpha(t *testing.T) {
 	doc := mustParse(t, input)
 
 	frame := doc.Children[0].(*shared.Frame)
-	if frame.Fill.Color != "#000000BB" {
-		t.Errorf("expected color '#000000BB', got '%s'", frame.Fill.Color)
+	if frame.Fills[0].Color != "#000000BB" {
+		t.Errorf("expected color '#000000BB', got '%s'", frame.Fills[0].Color)
 	}
 }
 
@@ -228,8 +229,8 @@ func TestParseFillWithVariable(t *testing.T) {
 	doc := mustParse(t, input)
 
 	frame := doc.Children[0].(*shared.Frame)
-	if frame.Fill.Color != "$primary-color" {
-		t.Errorf("expected color '$primary-color', got '%s'", frame.Fill.Color)
+	if frame.Fills[0].Color != "$primary-color" {
+		t.Errorf("expected color '$primary-color', got '%s'", frame.Fills[0].Color)
 	}
 }
 
@@ -252,26 +253,196 @@ func TestParseFillImage(t *testing.T) {
 	doc := mustParse(t, input)
 
 	frame := doc.Children[0].(*shared.Frame)
-	if frame.Fill == nil {
-		t.Fatal("expected Fill to be set")
+	if len(frame.Fills) != 1 {
+		t.Fatalf("expected one fill, got %d", len(frame.Fills))
 	}
-	if frame.Fill.Type != shared.FillImage {
-		t.Errorf("expected FillImage, got '%s'", frame.Fill.Type)
+	if frame.Fills[0].Type != shared.FillImage {
+		t.Errorf("expected FillImage, got '%s'", frame.Fills[0].Type)
 	}
-	if frame.Fill.URL != "./images/bg.jpg" {
-		t.Errorf("expected URL './images/bg.jpg', got '%s'", frame.Fill.URL)
+	if frame.Fills[0].URL != "./images/bg.jpg" {
+		t.Errorf("expected URL './images/bg.jpg', got '%s'", frame.Fills[0].URL)
 	}
-	if frame.Fill.Mode != "fill" {
-		t.Errorf("expected mode 'fill', got '%s'", frame.Fill.Mode)
+	if frame.Fills[0].Mode != "fill" {
+		t.Errorf("expected mode 'fill', got '%s'", frame.Fills[0].Mode)
 	}
-	if frame.Fill.Opacity != 0.3 {
-		t.Errorf("expected opacity 0.3, got %f", frame.Fill.Opacity)
+	if frame.Fills[0].Opacity != 0.3 {
+		t.Errorf("expected opacity 0.3, got %f", frame.Fills[0].Opacity)
 	}
-	if !frame.Fill.Enabled {
+	if !frame.Fills[0].Enabled {
 		t.Error("expected Enabled true")
 	}
 }
 
+func TestParseFillLinearGradient(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{"type": "frame", "id": "f1", "name": "x", "fill": {
+			"type": "gradient", "gradientType": "linear", "enabled": true, "rotation": 45, "opacity": 0.5,
+			"colors": [{"color": "#0000FF", "position": 1}, {"color": "$primary", "position": 0}]
+		}}]
+	}`
+	p := infrastructure.NewJSONParser()
+	doc, err := p.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fill := doc.Children[0].(*shared.Frame).Fills[0]
+	if fill.Type != shared.FillLinearGradient {
+		t.Errorf("expected FillLinearGradient, got '%s'", fill.Type)
+	}
+	if fill.Rotation != 45 || fill.Opacity != 0.5 || !fill.Enabled {
+		t.Errorf("unexpected gradient attributes: %+v", fill)
+	}
+	if len(fill.Stops) != 2 {
+		t.Fatalf("expected 2 stops, got %d", len(fill.Stops))
+	}
+	if fill.Stops[0].Color != "$primary" || fill.Stops[0].Position != 0 {
+		t.Errorf("expected stops sorted by position, got %+v", fill.Stops)
+	}
+	if fill.Center != (shared.GradientPoint{X: 0.5, Y: 0.5}) || fill.Size != (shared.GradientSize{Width: 1, Height: 1}) {
+		t.Errorf("expected default geometry, got center %+v size %+v", fill.Center, fill.Size)
+	}
+}
+
+func TestParseFillRadialGradientGeometry(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{"type": "frame", "id": "f1", "name": "x", "fill": {
+			"type": "gradient", "gradientType": "radial",
+			"center": {"x": 0.25, "y": 0.75}, "size": {"width": 0.5},
+			"colors": [{"color": "#FFFFFF"}, {"color": "#FFFFFF80"}, {"color": "#00000000"}]
+		}}]
+	}`
+	p := infrastructure.NewJSONParser()
+	doc, err := p.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fill := doc.Children[0].(*shared.Frame).Fills[0]
+	if fill.Type != shared.FillRadialGradient {
+		t.Errorf("expected FillRadialGradient, got '%s'", fill.Type)
+	}
+	if fill.Center != (shared.GradientPoint{X: 0.25, Y: 0.75}) {
+		t.Errorf("expected center (0.25,0.75), got %+v", fill.Center)
+	}
+	if fill.Size != (shared.GradientSize{Width: 0.5, Height: 1}) {
+		t.Errorf("expected size (0.5,1), got %+v", fill.Size)
+	}
+	for i, want := range []float64{0, 0.5, 1} {
+		if fill.Stops[i].Position != want {
+			t.Errorf("stop %d: expected evenly spread position %v, got %v", i, want, fill.Stops[i].Position)
+		}
+	}
+}
+
+func TestParseFillAngularGradient(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{"type": "frame", "id": "f1", "name": "x", "fill": {
+			"type": "gradient", "gradientType": "angular", "colors": [{"color": "#FF0000", "position": 1.5}]
+		}}]
+	}`
+	p := infrastructure.NewJSONParser()
+	doc, err := p.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fill := doc.Children[0].(*shared.Frame).Fills[0]
+	if fill.Type != shared.FillAngularGradient {
+		t.Errorf("expected FillAngularGradient, got '%s'", fill.Type)
+	}
+	if fill.Stops[0].Position != 1 {
+		t.Errorf("expected position clamped to 1, got %v", fill.Stops[0].Position)
+	}
+}
+
+func TestParseFillGradientErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "gradient", "gradientType": "diamond", "colors": [{"color": "#000000"}]}`: "unknown gradient type",
+		`{"type": "gradient", "gradientType": "linear", "colors": []}`:                      "no color stops",
+	}
+	for fill, want := range tests {
+		input := `{"version": "1.0", "children": [{"type": "frame", "id": "f1", "name": "x", "fill": ` + fill + `}]}`
+		p := infrastructure.NewJSONParser()
+		_, err := p.Parse(strings.NewReader(input))
+		if err == nil {
+			t.Fatalf("expected error for %s", fill)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestParseFillArray(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{"type": "frame", "id": "f1", "name": "x", "fill": [
+			{"type": "image", "url": "./bg.jpg", "mode": "fill"},
+			{"type": "color", "color": "#00000080"},
+			{"type": "color", "color": "#FF0000", "enabled": false},
+			"$overlay"
+		]}]
+	}`
+	p := infrastructure.NewJSONParser()
+	doc, err := p.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fills := doc.Children[0].(*shared.Frame).Fills
+	if len(fills) != 4 {
+		t.Fatalf("expected 4 fills, got %d", len(fills))
+	}
+	if fills[0].Type != shared.FillImage || !fills[0].Enabled {
+		t.Errorf("expected enabled image fill first, got %+v", fills[0])
+	}
+	if fills[1].Type != shared.FillSolid || fills[1].Color != "#00000080" || !fills[1].Enabled {
+		t.Errorf("expected enabled color fill second, got %+v", fills[1])
+	}
+	if fills[2].Enabled {
+		t.Error("expected third fill to be disabled")
+	}
+	if fills[3].Color != "$overlay" || !fills[3].Enabled {
+		t.Errorf("expected enabled string fill last, got %+v", fills[3])
+	}
+}
+
+func TestParseTextFillArray(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{"type": "text", "id": "t1", "name": "x", "content": "Hi", "fill": ["#000000", {"type": "color", "color": "#FFFFFF40"}]}]
+	}`
+	p := infrastructure.NewJSONParser()
+	doc, err := p.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fills := doc.Children[0].(*shared.Text).Fills
+	if len(fills) != 2 || fills[0].Color != "#000000" || fills[1].Color != "#FFFFFF40" {
+		t.Errorf("unexpected text fills: %+v", fills)
+	}
+}
+
+func TestParseFillArrayInvalidItem(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{"type": "frame", "id": "f1", "name": "x", "fill": ["#FFFFFF", {"type": "pattern"}]}]
+	}`
+	p := infrastructure.NewJSONParser()
+	_, err := p.Parse(strings.NewReader(input))
+	if err == nil {
+		t.Fatal("expected error for invalid fill item")
+	}
+	if !strings.Contains(err.Error(), "fill 1: unknown fill type") {
+		t.Errorf("expected fill index in error, got: %s", err)
+	}
+}
+
 func TestParseFillAbsent(t *testing.T) {
 	input := `{
 		"version": "1.0",
@@ -284,8 +455,8 @@ func TestParseFillAbsent(t *testing.T) {
 	doc := mustParse(t, input)
 
 	frame := doc.Children[0].(*shared.Frame)
-	if frame.Fill != nil {
-		t.Errorf("expected nil Fill, got %+v", frame.Fill)
+	if frame.Fills != nil {
+		t.Errorf("expected no fills, got %+v", frame.Fills)
 	}
 }
 
@@ -509,6 +680,350 @@ func TestParseNoVariables(t *testing.T) {
 	}
 }
 
+func TestParseThemedVariables(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"themes": {"mode": ["light", "dark"]},
+		"theme": {"mode": "dark"},
+		"children": [{"type": "frame", "id": "cover", "theme": {"mode": "light"}}],
+		"variables": {
+			"bg": {"type": "color", "value": [
+				{"value": "#FFFFFF"},
+				{"value": "#111111", "theme": {"mode": "dark"}}
+			]},
+			"gap": {"type": "number", "value": [{"value": 8, "theme": {"mode": "dark"}}]}
+		}
+	}`
+	doc := mustParse(t, input)
+
+	if len(doc.Themes["mode"]) != 2 || doc.Theme["mode"] != "dark" {
+		t.Errorf("expected document themes, got %v and %v", doc.Themes, doc.Theme)
+	}
+	if frame := doc.Children[0].(*shared.Frame); frame.Theme["mode"] != "light" {
+		t.Errorf("expected frame theme, got %v", frame.Theme)
+	}
+
+	bg := doc.Variables["bg"]
+	if bg.Value != "#FFFFFF" || len(bg.Themed) != 1 || bg.Themed[0].Value != "#111111" || bg.Themed[0].Theme["mode"] != "dark" {
+		t.Errorf("unexpected themed variable: %+v", bg)
+	}
+	if gap := doc.Variables["gap"]; gap.Value != 8.0 || len(gap.Themed) != 1 {
+		t.Errorf("expected the only themed value as fallback, got %+v", gap)
+	}
+}
+
+func TestParseThemedVariableErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "color", "value": []}`:                                         "expected at least one value",
+		`{"type": "number", "value": [{"value": true, "theme": {"mode": "x"}}]}`: "value 0: expected number value",
+	}
+	for variable, want := range tests {
+		input := `{"version": "1.0", "children": [], "variables": {"v": ` + variable + `}}`
+		_, err := infrastructure.NewJSONParser().Parse(strings.NewReader(input))
+		if err == nil {
+			t.Fatalf("expected error for %s", variable)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestParseVariableBindings(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{
+			"type": "frame", "id": "card", "width": "$card-width", "gap": "$space-sm",
+			"padding": ["$space-lg", 8], "stroke": {"fill": "$border", "thickness": "$hairline"},
+			"children": [
+				{"type": "text", "id": "title", "content": "$5 off", "fontFamily": "$font-body", "fontSize": "$size-lg"},
+				{"type": "rectangle", "id": "r1", "opacity": "$fade"},
+				{"type": "text", "id": "ticker", "content": "$AAPL"}
+			]
+		}]
+	}`
+	doc := mustParse(t, input)
+
+	card := doc.Children[0].(*shared.Frame)
+	want := shared.Bindings{
+		"width": "card-width", "gap": "space-sm", "padding.top": "space-lg", "padding.bottom": "space-lg",
+		"stroke.thickness": "hairline",
+	}
+	if len(card.Bindings) != len(want) {
+		t.Fatalf("expected %v, got %v", want, card.Bindings)
+	}
+	for property, variable := range want {
+		if card.Bindings[property] != variable {
+			t.Errorf("%s: expected %q, got %q", property, variable, card.Bindings[property])
+		}
+	}
+	if card.Padding.Right != 8 || card.Stroke == nil || card.Stroke.Color != "$border" {
+		t.Errorf("expected literal values and inline colors to be kept, got padding %+v stroke %+v", card.Padding, card.Stroke)
+	}
+
+	title := card.Children[0].(*shared.Text)
+	if title.Content != "$5 off" || title.Bindings["fontFamily"] != "font-body" || title.Bindings["fontSize"] != "size-lg" {
+		t.Errorf("unexpected text: content %q bindings %v", title.Content, title.Bindings)
+	}
+	if rect := card.Children[1].(*shared.Shape); rect.Bindings["opacity"] != "fade" || rect.Opacity != 1 {
+		t.Errorf("expected opacity binding, got %+v", rect)
+	}
+	if ticker := card.Children[2].(*shared.Text); ticker.Content != "$AAPL" || ticker.Bindings != nil {
+		t.Errorf("expected content to be literal, got %q with bindings %v", ticker.Content, ticker.Bindings)
+	}
+}
+
+func TestParseRefOverrideBindings(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{"type": "ref", "id": "c1", "ref": "card", "gap": "$space-lg",
+			"descendants": {"title": {"fontSize": "$size-sm", "content": "Hi"}}}]
+	}`
+	doc := mustParse(t, input)
+	ref := doc.Children[0].(*shared.Ref)
+	if ref.Override.Bindings["gap"] != "space-lg" || ref.Override.Gap != nil {
+		t.Errorf("expected a gap binding, got %+v", ref.Override)
+	}
+	title := ref.Descendants["title"]
+	if title.Bindings["fontSize"] != "size-sm" || title.Content == nil || *title.Content != "Hi" {
+		t.Errorf("unexpected descendant override %+v", title)
+	}
+}
+
+func TestParseWrap(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [
+			{"type": "frame", "id": "tags", "layout": "horizontal", "wrap": true, "gap": 8, "rowGap": 12},
+			{"type": "ref", "id": "t2", "ref": "tags", "wrap": false, "columnGap": 0, "rowGap": "$space-lg"}
+		]
+	}`
+	doc := mustParse(t, input)
+	tags := doc.Children[0].(*shared.Frame)
+	if row, column := tags.Gaps(); !tags.Wrap || row != 12 || column != 8 {
+		t.Errorf("expected a wrapping frame with gaps 12 and 8, got %v, %v and %v", tags.Wrap, row, column)
+	}
+
+	o := doc.Children[1].(*shared.Ref).Override
+	if o.Wrap == nil || *o.Wrap || o.ColumnGap == nil || *o.ColumnGap != 0 || o.Bindings["rowGap"] != "space-lg" {
+		t.Errorf("unexpected override %+v", o)
+	}
+}
+
+func TestParseGrid(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [
+			{"type": "frame", "id": "table", "layout": "grid", "columns": "120 1fr auto", "rows": [40, "2fr"], "gridFlow": "column",
+				"children": [{"type": "text", "id": "cell", "content": "Tea", "gridColumn": 2, "rowSpan": 2}]},
+			{"type": "frame", "id": "cards", "layout": "grid", "columns": 3},
+			{"type": "ref", "id": "r1", "ref": "cards", "columns": "1fr 1fr", "gridRow": 1, "columnSpan": 2}
+		]
+	}`
+	doc := mustParse(t, input)
+	table := doc.Children[0].(*shared.Frame)
+	wantColumns := []shared.GridTrack{shared.FixedTrack(120), shared.FractionTrack(1), shared.AutoTrack()}
+	if fmt.Sprint(table.Columns) != fmt.Sprint(wantColumns) {
+		t.Errorf("expected columns %v, got %v", wantColumns, table.Columns)
+	}
+	wantRows := []shared.GridTrack{shared.FixedTrack(40), shared.FractionTrack(2)}
+	if fmt.Sprint(table.Rows) != fmt.Sprint(wantRows) || table.GridFlow != "column" {
+		t.Errorf("expected rows %v flowing by column, got %v and %q", wantRows, table.Rows, table.GridFlow)
+	}
+	if cell := table.Children[0].(*shared.Text).Cell; cell != (shared.GridCell{Column: 2, RowSpan: 2}) {
+		t.Errorf("unexpected cell %+v", cell)
+	}
+
+	if cards := doc.Children[1].(*shared.Frame); len(cards.Columns) != 3 || cards.Columns[2] != shared.FractionTrack(1) {
+		t.Errorf("expected three 1fr columns, got %v", cards.Columns)
+	}
+
+	o := doc.Children[2].(*shared.Ref).Override
+	if len(o.Columns) != 2 || o.GridRow == nil || *o.GridRow != 1 || o.ColumnSpan == nil || *o.ColumnSpan != 2 {
+		t.Errorf("unexpected override %+v", o)
+	}
+}
+
+func TestParseGridErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "frame", "id": "f1", "columns": "1fr 2xx"}`:              `frame "f1" columns: invalid track "2xx"`,
+		`{"type": "frame", "id": "f1", "rows": 0}`:                         `frame "f1" rows: track count must be at least 1`,
+		`{"type": "frame", "id": "f1", "columns": [true]}`:                 `frame "f1" columns: invalid track true`,
+		`{"type": "frame", "id": "f1", "gridFlow": "diagonal"}`:            `frame "f1" gridFlow: unknown grid flow`,
+		`{"type": "text", "id": "t1", "gridRow": -1}`:                      `text "t1" grid: gridRow must not be negative`,
+		`{"type": "ref", "id": "r1", "ref": "c", "columnSpan": -2}`:        `ref "r1": columnSpan: must not be negative`,
+		`{"type": "ref", "id": "r1", "ref": "c", "gridFlow": "backwards"}`: `ref "r1": gridFlow: unknown grid flow`,
+	}
+	for node, want := range tests {
+		input := `{"version": "1.0", "children": [` + node + `]}`
+		p := infrastructure.NewJSONParser()
+		_, err := p.Parse(strings.NewReader(input))
+		if err == nil {
+			t.Fatalf("expected error for %s", node)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [
+			{"type": "frame", "id": "card", "layout": "vertical", "children": [
+				{"type": "rectangle", "id": "ribbon", "position": "absolute", "y": 8, "right": 0},
+				{"type": "text", "id": "note", "x": 12, "y": 30, "bottom": 4, "content": "New"}
+			]},
+			{"type": "ref", "id": "r1", "ref": "card", "position": "absolute", "x": 40, "bottom": 16}
+		]
+	}`
+	doc := mustParse(t, input)
+	card := doc.Children[0].(*shared.Frame)
+	ribbon := card.Children[0].(*shared.Shape)
+	if !ribbon.Position.Absolute || ribbon.Position.Right == nil || *ribbon.Position.Right != 0 || ribbon.Y != 8 {
+		t.Errorf("expected an absolute ribbon pinned right, got %+v at y %v", ribbon.Position, ribbon.Y)
+	}
+	note := card.Children[1].(*shared.Text)
+	if note.X != 12 || note.Y != 30 || note.Position.Absolute || note.Position.Bottom == nil || *note.Position.Bottom != 4 {
+		t.Errorf("unexpected note position %+v at (%v,%v)", note.Position, note.X, note.Y)
+	}
+
+	o := doc.Children[1].(*shared.Ref).Override
+	if o.Absolute == nil || !*o.Absolute || o.X == nil || *o.X != 40 || o.Bottom == nil || *o.Bottom != 16 {
+		t.Errorf("unexpected override %+v", o)
+	}
+}
+
+func TestParsePositionErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "frame", "id": "f1", "position": "fixed"}`:         `frame "f1" position: unknown position: "fixed"`,
+		`{"type": "image", "id": "i1", "right": "edge"}`:             `invalid image`,
+		`{"type": "ref", "id": "r1", "ref": "c", "position": "top"}`: `ref "r1": position: unknown position`,
+		`{"type": "ref", "id": "r1", "ref": "c", "bottom": "low"}`:   `ref "r1": bottom: invalid value`,
+	}
+	for node, want := range tests {
+		input := `{"version": "1.0", "children": [` + node + `]}`
+		p := infrastructure.NewJSONParser()
+		_, err := p.Parse(strings.NewReader(input))
+		if err == nil {
+			t.Fatalf("expected error for %s", node)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestParseSizeLimits(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [
+			{"type": "frame", "id": "side", "width": "fill_container", "minWidth": 200, "height": "fit_content", "maxHeight": 600},
+			{"type": "text", "id": "label", "content": "Buy", "maxWidth": 120},
+			{"type": "ref", "id": "r1", "ref": "side", "minWidth": 100, "maxWidth": 300}
+		]
+	}`
+	doc := mustParse(t, input)
+	side := doc.Children[0].(*shared.Frame)
+	if side.Width != (shared.Dimension{FillContainer: true, Min: 200}) {
+		t.Errorf("unexpected width %+v", side.Width)
+	}
+	if side.Height != (shared.Dimension{FitContent: true, Max: 600}) {
+		t.Errorf("unexpected height %+v", side.Height)
+	}
+	if label := doc.Children[1].(*shared.Text); label.Width != (shared.Dimension{Max: 120}) {
+		t.Errorf("unexpected label width %+v", label.Width)
+	}
+
+	o := doc.Children[2].(*shared.Ref).Override
+	if o.MinWidth == nil || *o.MinWidth != 100 || o.MaxWidth == nil || *o.MaxWidth != 300 {
+		t.Errorf("unexpected override %+v", o)
+	}
+}
+
+func TestParseSizeLimitsErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "frame", "id": "f1", "minWidth": -1}`:                   `frame "f1" width: minimum must not be negative`,
+		`{"type": "rectangle", "id": "s1", "maxHeight": 0}`:               `rectangle "s1" height: maximum must be positive`,
+		`{"type": "image", "id": "i1", "minHeight": 90, "maxHeight": 40}`: `image "i1" height: minimum 90 is larger than maximum 40`,
+		`{"type": "text", "id": "t1", "width": "fit"}`:                    `text "t1" width: unknown dimension value: "fit"`,
+		`{"type": "ref", "id": "r1", "ref": "c", "maxWidth": "wide"}`:     `ref "r1": maxWidth: invalid value`,
+	}
+	for node, want := range tests {
+		input := `{"version": "1.0", "children": [` + node + `]}`
+		p := infrastructure.NewJSONParser()
+		_, err := p.Parse(strings.NewReader(input))
+		if err == nil {
+			t.Fatalf("expected error for %s", node)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestParseFillWeightAndShrink(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [
+			{"type": "frame", "id": "main", "width": "fill_container(2)", "height": "fill_container( 0.5 )"},
+			{"type": "rectangle", "id": "badge", "width": 80, "height": 20, "shrink": 1},
+			{"type": "text", "id": "label", "content": "Total", "shrink": 0},
+			{"type": "ref", "id": "r1", "ref": "main", "shrink": 2, "width": "fill_container(3)"}
+		]
+	}`
+	doc := mustParse(t, input)
+	main := doc.Children[0].(*shared.Frame)
+	if main.Width != shared.WeightedFillDimension(2) || main.Height != shared.WeightedFillDimension(0.5) {
+		t.Errorf("unexpected size %+v x %+v", main.Width, main.Height)
+	}
+	badge := doc.Children[1].(*shared.Shape)
+	if badge.Width.ShrinkWeight() != 1 || badge.Height.ShrinkWeight() != 1 {
+		t.Errorf("expected the badge to shrink, got %+v x %+v", badge.Width, badge.Height)
+	}
+	if label := doc.Children[2].(*shared.Text); label.Width.ShrinkWeight() != 0 {
+		t.Errorf("expected the label not to shrink, got %+v", label.Width)
+	}
+
+	o := doc.Children[3].(*shared.Ref).Override
+	if o.Shrink == nil || *o.Shrink != 2 || o.Width == nil || *o.Width != shared.WeightedFillDimension(3) {
+		t.Errorf("unexpected override %+v", o)
+	}
+}
+
+func TestParseFillWeightAndShrinkErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "frame", "id": "f1", "width": "fill_container(0)"}`:             `frame "f1" width: fill_container weight must be a positive number, got "0"`,
+		`{"type": "frame", "id": "f1", "height": "fill_container(x)"}`:            `frame "f1" height: fill_container weight must be a positive number, got "x"`,
+		`{"type": "ellipse", "id": "e1", "width": "fill_container(2"}`:            `ellipse "e1" width: unknown dimension value`,
+		`{"type": "path", "id": "p1", "shrink": -1}`:                              `path "p1" width: shrink must not be negative, got -1`,
+		`{"type": "ref", "id": "r1", "ref": "c", "shrink": -0.5}`:                 `ref "r1": shrink: shrink must not be negative`,
+		`{"type": "ref", "id": "r1", "ref": "c", "height": "fill_container(-1)"}`: `ref "r1": height`,
+	}
+	for node, want := range tests {
+		input := `{"version": "1.0", "children": [` + node + `]}`
+		p := infrastructure.NewJSONParser()
+		_, err := p.Parse(strings.NewReader(input))
+		if err == nil {
+			t.Fatalf("expected error for %s", node)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestParseNumberVariableExpression(t *testing.T) {
+	input := `{"version": "1.0", "children": [], "variables": {
+		"spacing-lg": {"type": "number", "value": "$spacing-base * 2"}
+	}}`
+	doc := mustParse(t, input)
+	if got := doc.Variables["spacing-lg"].Value; got != "$spacing-base * 2" {
+		t.Errorf("expected the expression to be kept, got %v", got)
+	}
+}
+
 func TestParseInvalidJSON(t *testing.T) {
 	p := infrastructure.NewJSONParser()
 	_, err := p.Parse(strings.NewReader("{invalid"))
@@ -523,7 +1038,7 @@ func TestParseInvalidJSON(t *testing.T) {
 func TestParseUnknownNodeType(t *testing.T) {
 	input := `{
 		"version": "1.0",
-		"children": [{"type": "rectangle", "id": "r1"}]
+		"children": [{"type": "chart", "id": "c1"}]
 	}`
 	p := infrastructure.NewJSONParser()
 	_, err := p.Parse(strings.NewReader(input))
@@ -569,7 +1084,7 @@ func TestParseUnknownVariableType(t *testing.T) {
 	input := `{
 		"version": "1.0",
 		"children": [],
-		"variables": {"x": {"type": "boolean", "value": true}}
+		"variables": {"x": {"type": "gradient", "value": "#FF0000"}}
 	}`
 	p := infrastructure.NewJSONParser()
 	_, err := p.Parse(strings.NewReader(input))
@@ -584,7 +1099,7 @@ func TestParseUnknownVariableType(t *testing.T) {
 func TestParseUnknownFillType(t *testing.T) {
 	input := `{
 		"version": "1.0",
-		"children": [{"type": "frame", "id": "f1", "name": "x", "fill": {"type": "gradient"}}]
+		"children": [{"type": "frame", "id": "f1", "name": "x", "fill": {"type": "pattern"}}]
 	}`
 	p := infrastructure.NewJSONParser()
 	_, err := p.Parse(strings.NewReader(input))
@@ -596,6 +1111,564 @@ func TestParseUnknownFillType(t *testing.T) {
 	}
 }
 
+func TestParseStrokeUniform(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{
+			"type": "frame",
+			"id": "f1",
+			"name": "button",
+			"stroke": {"fill": "$primary-color", "thickness": 2, "align": "center", "dashPattern": [4, 2]}
+		}]
+	}`
+	doc := mustParse(t, input)
+
+	frame := doc.Children[0].(*shared.Frame)
+	if frame.Stroke == nil {
+		t.Fatal("expected Stroke to be set")
+	}
+	if frame.Stroke.Color != "$primary-color" {
+		t.Errorf("expected color '$primary-color', got '%s'", frame.Stroke.Color)
+	}
+	if frame.Stroke.Thickness != shared.UniformThickness(2) {
+		t.Errorf("expected uniform thickness 2, got %+v", frame.Stroke.Thickness)
+	}
+	if frame.Stroke.Align != shared.StrokeCenter {
+		t.Errorf("expected align center, got '%s'", frame.Stroke.Align)
+	}
+	if len(frame.Stroke.Dash) != 2 || frame.Stroke.Dash[0] != 4 || frame.Stroke.Dash[1] != 2 {
+		t.Errorf("expected dash [4 2], got %v", frame.Stroke.Dash)
+	}
+}
+
+func TestParseStrokePerSide(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{
+			"type": "frame",
+			"id": "f1",
+			"name": "divider",
+			"stroke": {"fill": "#E0E0E0", "thickness": {"bottom": 1}}
+		}]
+	}`
+	doc := mustParse(t, input)
+
+	frame := doc.Children[0].(*shared.Frame)
+	want := shared.StrokeThickness{Bottom: 1}
+	if frame.Stroke.Thickness != want {
+		t.Errorf("expected thickness %+v, got %+v", want, frame.Stroke.Thickness)
+	}
+}
+
+func TestParseStrokeDefaults(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{
+			"type": "text",
+			"id": "t1",
+			"name": "outlined",
+			"content": "Hi",
+			"stroke": {"fill": "#000000"}
+		}]
+	}`
+	doc := mustParse(t, input)
+
+	text := doc.Children[0].(*shared.Text)
+	if text.Stroke == nil {
+		t.Fatal("expected Stroke to be set")
+	}
+	if text.Stroke.Thickness != shared.UniformThickness(1) {
+		t.Errorf("expected default thickness 1, got %+v", text.Stroke.Thickness)
+	}
+	if text.Stroke.Align != shared.StrokeInside {
+		t.Errorf("expected default align inside, got '%s'", text.Stroke.Align)
+	}
+}
+
+func TestParseStrokeAbsent(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{"type": "frame", "id": "f1", "name": "plain"}]
+	}`
+	doc := mustParse(t, input)
+
+	frame := doc.Children[0].(*shared.Frame)
+	if frame.Stroke != nil {
+		t.Errorf("expected nil Stroke, got %+v", frame.Stroke)
+	}
+}
+
+func TestParseStrokeUnknownAlign(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{"type": "frame", "id": "f1", "name": "x", "stroke": {"fill": "#000000", "align": "middle"}}]
+	}`
+	p := infrastructure.NewJSONParser()
+	_, err := p.Parse(strings.NewReader(input))
+	if err == nil {
+		t.Fatal("expected error for unknown stroke align")
+	}
+	if !strings.Contains(err.Error(), "unknown stroke align") {
+		t.Errorf("expected 'unknown stroke align' in error, got: %s", err)
+	}
+}
+
+func TestParseEffectShadows(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{"type": "frame", "id": "f1", "name": "card", "effect": [
+			{"type": "shadow", "shadowType": "outer", "offset": {"x": 2, "y": 4}, "blur": 12, "spread": 1, "color": "$shadow"},
+			{"type": "shadow", "shadowType": "inner", "offset": {"y": -1}, "color": "#FFFFFF40", "enabled": false}
+		]}]
+	}`
+	p := infrastructure.NewJSONParser()
+	doc, err := p.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	effects := doc.Children[0].(*shared.Frame).Effects
+	if len(effects) != 2 {
+		t.Fatalf("expected 2 effects, got %d", len(effects))
+	}
+	drop := effects[0]
+	if drop.Type != shared.EffectDropShadow || drop.OffsetX != 2 || drop.OffsetY != 4 || drop.Blur != 12 || drop.Spread != 1 || drop.Color != "$shadow" || !drop.Enabled {
+		t.Errorf("unexpected drop shadow: %+v", drop)
+	}
+	inner := effects[1]
+	if inner.Type != shared.EffectInnerShadow || inner.OffsetY != -1 || inner.Enabled {
+		t.Errorf("unexpected inner shadow: %+v", inner)
+	}
+}
+
+func TestParseEffectBlurs(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [
+			{"type": "frame", "id": "f1", "name": "a", "effect": {"type": "blur", "radius": 8}},
+			{"type": "frame", "id": "f2", "name": "b", "effect": {"type": "background_blur", "radius": 20}}
+		]
+	}`
+	p := infrastructure.NewJSONParser()
+	doc, err := p.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	layer := doc.Children[0].(*shared.Frame).Effects[0]
+	if layer.Type != shared.EffectLayerBlur || layer.Blur != 8 {
+		t.Errorf("unexpected layer blur: %+v", layer)
+	}
+	background := doc.Children[1].(*shared.Frame).Effects[0]
+	if background.Type != shared.EffectBackgroundBlur || background.Blur != 20 {
+		t.Errorf("unexpected background blur: %+v", background)
+	}
+}
+
+func TestParseEffectErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "glow"}`:                         "unknown effect type",
+		`{"type": "shadow", "shadowType": "cast"}`: "unknown shadow type",
+		`[{"type": "blur", "radius": -1}]`:         "effect 0: blur must be non-negative",
+	}
+	for effect, want := range tests {
+		input := `{"version": "1.0", "children": [{"type": "frame", "id": "f1", "name": "x", "effect": ` + effect + `}]}`
+		p := infrastructure.NewJSONParser()
+		_, err := p.Parse(strings.NewReader(input))
+		if err == nil {
+			t.Fatalf("expected error for %s", effect)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestParseShapes(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{"type": "frame", "id": "f1", "name": "page", "children": [
+			{"type": "rectangle", "id": "r1", "name": "card", "width": 200, "height": "fill_container", "fill": "$surface", "cornerRadius": 8, "stroke": {"fill": "#E0E0E0", "thickness": 1}},
+			{"type": "ellipse", "id": "e1", "width": 40, "height": 40, "fill": "#FF0000", "opacity": 0.5},
+			{"type": "line", "id": "l1", "width": "fill_container", "height": 0, "stroke": {"fill": "#000000", "thickness": 2}},
+			{"type": "polygon", "id": "p1", "width": 30, "height": 30, "polygonCount": 6}
+		]}]
+	}`
+	doc := mustParse(t, input)
+	children := doc.Children[0].(*shared.Frame).Children
+	if len(children) != 4 {
+		t.Fatalf("expected 4 children, got %d", len(children))
+	}
+
+	rect, ok := children[0].(*shared.Shape)
+	if !ok {
+		t.Fatalf("expected *Shape, got %T", children[0])
+	}
+	if rect.GetType() != shared.NodeTypeRectangle || rect.Name != "card" || rect.CornerRadius != 8 {
+		t.Errorf("unexpected rectangle: %+v", rect)
+	}
+	if rect.Width.Value != 200 || !rect.Height.FillContainer {
+		t.Errorf("unexpected rectangle size: %+v x %+v", rect.Width, rect.Height)
+	}
+	if len(rect.Fills) != 1 || rect.Fills[0].Color != "$surface" {
+		t.Errorf("expected fill '$surface', got %+v", rect.Fills)
+	}
+	if rect.Stroke == nil || rect.Stroke.Color != "#E0E0E0" {
+		t.Errorf("expected stroke '#E0E0E0', got %+v", rect.Stroke)
+	}
+	if rect.Opacity != 1 {
+		t.Errorf("expected default opacity 1, got %f", rect.Opacity)
+	}
+
+	ellipse := children[1].(*shared.Shape)
+	if ellipse.GetType() != shared.NodeTypeEllipse || ellipse.Opacity != 0.5 {
+		t.Errorf("unexpected ellipse: %+v", ellipse)
+	}
+
+	line := children[2].(*shared.Shape)
+	if line.GetType() != shared.NodeTypeLine || !line.Width.FillContainer || line.Stroke.Thickness.Top != 2 {
+		t.Errorf("unexpected line: %+v", line)
+	}
+
+	polygon := children[3].(*shared.Shape)
+	if polygon.GetType() != shared.NodeTypePolygon || polygon.Sides != 6 {
+		t.Errorf("unexpected polygon: %+v", polygon)
+	}
+}
+
+func TestParsePolygonDefaultsToTriangle(t *testing.T) {
+	doc := mustParse(t, `{"version": "1.0", "children": [{"type": "polygon", "id": "p1"}]}`)
+	if sides := doc.Children[0].(*shared.Shape).Sides; sides != 3 {
+		t.Errorf("expected 3 sides, got %d", sides)
+	}
+}
+
+func TestParseShapeErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "ellipse", "id": "e1", "opacity": 1.5}`:     `ellipse "e1" opacity: must be between 0 and 1`,
+		`{"type": "polygon", "id": "p1", "polygonCount": 2}`:  `polygon "p1" polygonCount: must be at least 3`,
+		`{"type": "rectangle", "id": "r1", "width": "auto"}`:  `rectangle "r1" width`,
+		`{"type": "line", "id": "l1", "fill": {"type": "?"}}`: `line "l1" fill`,
+	}
+	for node, want := range tests {
+		input := `{"version": "1.0", "children": [` + node + `]}`
+		p := infrastructure.NewJSONParser()
+		_, err := p.Parse(strings.NewReader(input))
+		if err == nil {
+			t.Fatalf("expected error for %s", node)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{"type": "path", "id": "p1", "name": "icon", "width": 24, "height": 24,
+			"geometry": "M0 0 L10 0 L10 10 Z", "fillRule": "evenodd", "fill": "$icon", "opacity": 0.8,
+			"stroke": {"fill": "#000000", "thickness": 1}}]
+	}`
+	doc := mustParse(t, input)
+	path, ok := doc.Children[0].(*shared.Path)
+	if !ok {
+		t.Fatalf("expected *Path, got %T", doc.Children[0])
+	}
+	if path.Name != "icon" || path.Width.Value != 24 || path.Height.Value != 24 {
+		t.Errorf("unexpected path: %+v", path)
+	}
+	if len(path.Geometry.Segments) != 4 {
+		t.Errorf("expected 4 segments, got %d", len(path.Geometry.Segments))
+	}
+	if path.FillRule != shared.FillRuleEvenOdd {
+		t.Errorf("expected evenodd fill rule, got %q", path.FillRule)
+	}
+	if len(path.Fills) != 1 || path.Fills[0].Color != "$icon" {
+		t.Errorf("expected fill '$icon', got %+v", path.Fills)
+	}
+	if path.Stroke == nil || path.Opacity != 0.8 {
+		t.Errorf("expected stroke and opacity 0.8, got %+v", path)
+	}
+}
+
+func TestParsePathDefaults(t *testing.T) {
+	doc := mustParse(t, `{"version": "1.0", "children": [{"type": "path", "id": "p1", "geometry": "M0 0 H10"}]}`)
+	path := doc.Children[0].(*shared.Path)
+	if path.FillRule != shared.FillRuleNonZero {
+		t.Errorf("expected nonzero fill rule, got %q", path.FillRule)
+	}
+	if path.Opacity != 1 {
+		t.Errorf("expected default opacity 1, got %f", path.Opacity)
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "path", "id": "p1", "geometry": "M0 0 L10"}`:                `path "p1" geometry: command 'L': expected number`,
+		`{"type": "path", "id": "p1", "geometry": "M0 0", "fillRule": "odd"}`: `path "p1" fillRule: unknown fill rule`,
+		`{"type": "path", "id": "p1", "opacity": -1}`:                         `path "p1" opacity`,
+	}
+	for node, want := range tests {
+		input := `{"version": "1.0", "children": [` + node + `]}`
+		p := infrastructure.NewJSONParser()
+		_, err := p.Parse(strings.NewReader(input))
+		if err == nil {
+			t.Fatalf("expected error for %s", node)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestParseImageFillModes(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{"type": "frame", "id": "f1", "fill": [
+			{"type": "image", "url": "./a.png", "mode": "contain"},
+			{"type": "image", "url": "./b.png", "mode": "cover"},
+			{"type": "image", "url": "./c.png", "mode": "tile", "scale": 0.5},
+			{"type": "image", "url": "./d.png", "mode": "crop", "offset": {"x": -20, "y": 10}}
+		]}]
+	}`
+	doc := mustParse(t, input)
+	fills := doc.Children[0].(*shared.Frame).Fills
+
+	if fills[0].Mode != shared.ImageModeFit || fills[1].Mode != shared.ImageModeFill {
+		t.Errorf("expected contain and cover to map to fit and fill, got %q and %q", fills[0].Mode, fills[1].Mode)
+	}
+	if fills[2].Mode != shared.ImageModeTile || fills[2].Scale != 0.5 {
+		t.Errorf("unexpected tile fill: %+v", fills[2])
+	}
+	if fills[3].Mode != shared.ImageModeCrop || fills[3].Offset != (shared.ImageOffset{X: -20, Y: 10}) {
+		t.Errorf("unexpected crop fill: %+v", fills[3])
+	}
+}
+
+func TestParseImageFillUnknownMode(t *testing.T) {
+	input := `{"version": "1.0", "children": [{"type": "frame", "id": "f1", "fill": {"type": "image", "url": "./a.png", "mode": "zoom"}}]}`
+	p := infrastructure.NewJSONParser()
+	_, err := p.Parse(strings.NewReader(input))
+	if err == nil {
+		t.Fatal("expected error for unknown image mode")
+	}
+	if !strings.Contains(err.Error(), "unknown image mode") {
+		t.Errorf("expected 'unknown image mode' in error, got: %s", err)
+	}
+}
+
+func TestParseImageNode(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [{"type": "image", "id": "i1", "name": "logo", "url": "./logo.png", "width": 120,
+			"mode": "fit", "opacity": 0.9, "cornerRadius": 4, "stroke": {"fill": "#E0E0E0", "thickness": 1}}]
+	}`
+	doc := mustParse(t, input)
+	img, ok := doc.Children[0].(*shared.Image)
+	if !ok {
+		t.Fatalf("expected *Image, got %T", doc.Children[0])
+	}
+	if img.Name != "logo" || img.URL != "./logo.png" || img.Mode != shared.ImageModeFit {
+		t.Errorf("unexpected image: %+v", img)
+	}
+	if img.Width.Value != 120 || img.Height != (shared.Dimension{}) {
+		t.Errorf("expected width 120 and no height, got %+v x %+v", img.Width, img.Height)
+	}
+	if img.Opacity != 0.9 || img.CornerRadius != 4 || img.Stroke == nil {
+		t.Errorf("expected opacity, corner radius and stroke, got %+v", img)
+	}
+}
+
+func TestParseImageNodeErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "image", "id": "i1", "mode": "zoom"}`:   `image "i1" mode: unknown image mode`,
+		`{"type": "image", "id": "i1", "opacity": 2}`:     `image "i1" opacity`,
+		`{"type": "image", "id": "i1", "height": "wide"}`: `image "i1" height`,
+	}
+	for node, want := range tests {
+		input := `{"version": "1.0", "children": [` + node + `]}`
+		p := infrastructure.NewJSONParser()
+		_, err := p.Parse(strings.NewReader(input))
+		if err == nil {
+			t.Fatalf("expected error for %s", node)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestParseReusableFrameAndRef(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"children": [
+			{"type": "frame", "id": "card", "reusable": true, "children": [{"type": "text", "id": "title", "content": "Title"}]},
+			{"type": "ref", "id": "c1", "name": "first card", "ref": "card", "x": 10, "width": "fill_container",
+				"fill": "#FF0000", "padding": [4, 8],
+				"descendants": {"title": {"content": "Hello", "fontSize": 18, "fill": null}}}
+		]
+	}`
+	doc := mustParse(t, input)
+	if frame := doc.Children[0].(*shared.Frame); !frame.Reusable {
+		t.Error("expected reusable frame")
+	}
+	ref, ok := doc.Children[1].(*shared.Ref)
+	if !ok {
+		t.Fatalf("expected *Ref, got %T", doc.Children[1])
+	}
+	if ref.ID != "c1" || ref.Name != "first card" || ref.Component != "card" {
+		t.Errorf("unexpected ref: %+v", ref)
+	}
+
+	o := ref.Override
+	if o == nil || o.X == nil || *o.X != 10 || o.Width == nil || !o.Width.FillContainer {
+		t.Fatalf("expected x and width overrides, got %+v", o)
+	}
+	if o.Name != nil || o.Y != nil {
+		t.Errorf("expected unset overrides to stay nil, got %+v", o)
+	}
+	if len(o.Fills) != 1 || o.Fills[0].Color != "#FF0000" {
+		t.Errorf("expected fill override, got %+v", o.Fills)
+	}
+	if o.Padding == nil || o.Padding.Top != 4 || o.Padding.Right != 8 {
+		t.Errorf("expected padding override, got %+v", o.Padding)
+	}
+
+	title := ref.Descendants["title"]
+	if title == nil || title.Content == nil || *title.Content != "Hello" || title.FontSize == nil || *title.FontSize != 18 {
+		t.Fatalf("expected descendant override, got %+v", title)
+	}
+	if title.Fills == nil || len(title.Fills) != 0 {
+		t.Errorf("expected null fill to clear the fills, got %#v", title.Fills)
+	}
+}
+
+func TestParseRefErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "ref", "id": "r1"}`:                                                  `ref "r1": missing component`,
+		`{"type": "ref", "id": "r1", "ref": "c", "children": []}`:                      `unsupported override property "children"`,
+		`{"type": "ref", "id": "r1", "ref": "c", "opacity": 3}`:                        `ref "r1": opacity`,
+		`{"type": "ref", "id": "r1", "ref": "c", "x": "left"}`:                         `ref "r1": x: invalid value "left"`,
+		`{"type": "ref", "id": "r1", "ref": "c", "descendants": {"t": {"gap": true}}}`: `ref "r1" descendant "t": gap`,
+	}
+	for node, want := range tests {
+		input := `{"version": "1.0", "children": [` + node + `]}`
+		p := infrastructure.NewJSONParser()
+		_, err := p.Parse(strings.NewReader(input))
+		if err == nil {
+			t.Fatalf("expected error for %s", node)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestParseRepeaterFrame(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"data": {"prices": [{"name": "Tea", "price": 2.5}]},
+		"children": [
+			{"type": "frame", "id": "list", "layout": "vertical",
+				"repeat": {"data": "prices", "as": "row", "empty": [{"type": "text", "id": "none", "content": "Sold out"}]},
+				"children": [{"type": "text", "id": "label", "content": "{{row.name}}"}]}
+		]
+	}`
+	doc := mustParse(t, input)
+	prices, ok := doc.Data["prices"].([]any)
+	if !ok || len(prices) != 1 {
+		t.Fatalf("expected document data, got %v", doc.Data)
+	}
+
+	r := doc.Children[0].(*shared.Frame).Repeat
+	if r == nil || r.Data != "prices" || r.Alias() != "row" {
+		t.Fatalf("unexpected repeat %+v", r)
+	}
+	if len(r.Empty) != 1 || r.Empty[0].GetID() != "none" {
+		t.Errorf("expected empty state node, got %v", r.Empty)
+	}
+}
+
+func TestParseRepeaterErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "frame", "id": "f", "repeat": {"as": "row"}}`:                                   `frame "f" repeat: missing data`,
+		`{"type": "frame", "id": "f", "repeat": {"data": "rows", "empty": [{"type": "sprite"}]}}`: `frame "f" repeat: empty: child[0]`,
+	}
+	for node, want := range tests {
+		input := `{"version": "1.0", "children": [` + node + `]}`
+		_, err := infrastructure.NewJSONParser().Parse(strings.NewReader(input))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %v", want, err)
+		}
+	}
+}
+
+func TestParseVisibility(t *testing.T) {
+	input := `{
+		"version": "1.0",
+		"variables": {
+			"show-badge": {"type": "boolean", "value": true},
+			"has-items": {"type": "boolean", "value": "$count > 0"}
+		},
+		"children": [
+			{"type": "frame", "id": "f1", "enabled": false, "children": [
+				{"type": "text", "id": "t1", "content": "Sale", "visible": "$show-badge"},
+				{"type": "rectangle", "id": "r1", "visible": false},
+				{"type": "image", "id": "i1", "url": "a.png", "enabled": true, "visible": true},
+				{"type": "ref", "id": "c1", "ref": "card", "enabled": false,
+					"descendants": {"badge": {"visible": "{{item.discount}} > 0"}}}
+			]}
+		]
+	}`
+	doc := mustParse(t, input)
+	frame := doc.Children[0].(*shared.Frame)
+	if !frame.Visibility.Hidden {
+		t.Error("expected enabled: false to hide the frame")
+	}
+	if v := frame.Children[0].(*shared.Text).Visibility; v.Hidden || v.Condition != "$show-badge" {
+		t.Errorf("expected a condition, got %+v", v)
+	}
+	if !shared.IsHidden(frame.Children[1]) || shared.IsHidden(frame.Children[2]) {
+		t.Error("unexpected visibility of shapes and images")
+	}
+
+	ref := frame.Children[3].(*shared.Ref)
+	if ref.Override.Hidden == nil || !*ref.Override.Hidden {
+		t.Errorf("expected a hidden override, got %+v", ref.Override)
+	}
+	if badge := ref.Descendants["badge"]; badge.Condition == nil || *badge.Condition != "{{item.discount}} > 0" {
+		t.Errorf("expected a condition override, got %+v", badge)
+	}
+
+	if v := doc.Variables["show-badge"]; v.Type != shared.VariableBoolean || v.Value != true {
+		t.Errorf("unexpected boolean variable %+v", v)
+	}
+	if v := doc.Variables["has-items"]; v.Value != "$count > 0" {
+		t.Errorf("expected a boolean expression, got %+v", v)
+	}
+}
+
+func TestParseVisibilityErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"type": "text", "id": "t1", "visible": 1}`:       `text "t1" visible: expected a boolean or a condition`,
+		`{"type": "frame", "id": "f1", "visible": ""}`:     `frame "f1" visible`,
+		`{"type": "ellipse", "id": "e1", "enabled": "no"}`: `invalid ellipse`,
+	}
+	for node, want := range tests {
+		input := `{"version": "1.0", "children": [` + node + `]}`
+		_, err := infrastructure.NewJSONParser().Parse(strings.NewReader(input))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %v", want, err)
+		}
+	}
+
+	input := `{"version": "1.0", "variables": {"flag": {"type": "boolean", "value": 1}}, "children": []}`
+	if _, err := infrastructure.NewJSONParser().Parse(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), "expected boolean value") {
+		t.Errorf("expected boolean value error, got %v", err)
+	}
+}
+
 func TestParseExampleFile(t *testing.T) {
 	// Integration-style test using a realistic multi-page document
 	input := `{
@@ -678,12 +1751,12 @@ func TestParseExampleFile(t *testing.T) {
 	if front.Name != "Front" {
 		t.Errorf("expected 'Front', got '%s'", front.Name)
 	}
-	if front.Fill.Type != shared.FillImage {
-		t.Errorf("expected image fill, got '%s'", front.Fill.Type)
+	if front.Fills[0].Type != shared.FillImage {
+		t.Errorf("expected image fill, got '%s'", front.Fills[0].Type)
 	}
 	overlay := front.Children[0].(*shared.Frame)
-	if overlay.Fill.Color != "#000000BB" {
-		t.Errorf("expected overlay color '#000000BB', got '%s'", overlay.Fill.Color)
+	if overlay.Fills[0].Color != "#000000BB" {
+		t.Errorf("expected overlay color '#000000BB', got '%s'", overlay.Fills[0].Color)
 	}
 	headline := overlay.Children[0].(*shared.Text)
 	if headline.Content != "Hello World" {
@@ -699,8 +1772,8 @@ func TestParseExampleFile(t *testing.T) {
 		t.Errorf("expected padding [50,40,50,40], got %+v", back.Padding)
 	}
 	terms := back.Children[0].(*shared.Text)
-	if terms.Fill != "$text-primary" {
-		t.Errorf("expected fill '$text-primary', got '%s'", terms.Fill)
+	if terms.Fills[0].Color != "$text-primary" {
+		t.Errorf("expected fill '$text-primary', got '%s'", terms.Fills[0].Color)
 	}
 
 	// Variables
@@ -0,0 +1,332 @@
+package infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+
+	"github.com/signintech/gopdf"
+	layout "github.com/vpedrosa/pen2pdf/internal/layout/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+const (
+	// effectRasterScale is the resolution of rasterized shadows and blurs, in
+	// pixels per point. PDF has no blur filter, so soft effects are painted
+	// as images with an alpha channel.
+	effectRasterScale = 2.0
+
+	// maxEffectPixels caps the size of a rasterized effect; larger effects
+	// are rasterized at a lower resolution.
+	maxEffectPixels = 4_000_000
+)
+
+// drawDropShadows paints the drop shadows of a frame below its fills. The
+// frame outline is clipped out, so shadows never show through translucent
+// fills.
+func (r *PDFRenderer) drawDropShadows(pdf *gopdf.GoPdf, box *layout.LayoutBox, frame *shared.Frame) error {
+	if box.Width <= 0 || box.Height <= 0 {
+		return nil
+	}
+	outline := outlineRect{x: box.X, y: box.Y, w: box.Width, h: box.Height, radius: frame.CornerRadius}
+
+	for _, effect := range frame.Effects {
+		if !effect.Enabled || effect.Type != shared.EffectDropShadow {
+			continue
+		}
+		rgba, err := shared.ParseHexColor(effect.Color)
+		if err != nil {
+			return fmt.Errorf("drop shadow: %w", err)
+		}
+
+		s := effect.Spread
+		shape := outline.expand(s, s, s, s)
+		shape.x += effect.OffsetX
+		shape.y += effect.OffsetY
+		if shape.w <= 0 || shape.h <= 0 {
+			continue
+		}
+
+		margin := blurMargin(effect.Blur)
+		bounds := shape.expand(margin, margin, margin, margin)
+		hole := outline.polygon()
+
+		pdf.SaveGraphicsState()
+		pdf.ClipPolygon(ringPolygon(boundingRect(bounds, outline).polygon(), hole))
+		err = r.drawSoftShape(pdf, shape, bounds, effect.Blur, rgba)
+		pdf.RestoreGraphicsState()
+		if err != nil {
+			return fmt.Errorf("drop shadow: %w", err)
+		}
+	}
+	return nil
+}
+
+// drawInnerShadows paints the inner shadows of a frame over its fills,
+// clipped to the frame outline.
+func (r *PDFRenderer) drawInnerShadows(pdf *gopdf.GoPdf, box *layout.LayoutBox, frame *shared.Frame) error {
+	if box.Width <= 0 || box.Height <= 0 {
+		return nil
+	}
+	outline := outlineRect{x: box.X, y: box.Y, w: box.Width, h: box.Height, radius: frame.CornerRadius}
+
+	for _, effect := range frame.Effects {
+		if !effect.Enabled || effect.Type != shared.EffectInnerShadow {
+			continue
+		}
+		rgba, err := shared.ParseHexColor(effect.Color)
+		if err != nil {
+			return fmt.Errorf("inner shadow: %w", err)
+		}
+
+		// The shadow is cast by everything outside the offset, shrunk outline
+		s := -effect.Spread
+		light := outline.expand(s, s, s, s)
+		light.x += effect.OffsetX
+		light.y += effect.OffsetY
+
+		margin := blurMargin(effect.Blur)
+		bounds := boundingRect(outline.expand(margin, margin, margin, margin), light)
+
+		pdf.SaveGraphicsState()
+		pdf.ClipPolygon(outline.polygon())
+		if effect.Blur <= 0 {
+			err = fillPolygon(pdf, ringPolygon(bounds.polygon(), light.polygon()), rgba)
+		} else {
+			mask := newAlphaMask(bounds)
+			mask.paint(light, true)
+			mask.blur(effect.Blur / 2)
+			err = drawAlphaMask(pdf, mask, rgba)
+		}
+		pdf.RestoreGraphicsState()
+		if err != nil {
+			return fmt.Errorf("inner shadow: %w", err)
+		}
+	}
+	return nil
+}
+
+// layerBlur returns the layer blur radius of a frame, or zero.
+func layerBlur(frame *shared.Frame) float64 {
+	radius := 0.0
+	for _, effect := range frame.Effects {
+		if effect.Enabled && effect.Type == shared.EffectLayerBlur {
+			radius = math.Max(radius, effect.Blur)
+		}
+	}
+	return radius
+}
+
+// warnUnsupportedEffects reports effects that cannot be expressed in PDF:
+// background blur needs the rendered backdrop, and layer blur is only
+// applied to the frame's own solid fills.
+func (r *PDFRenderer) warnUnsupportedEffects(frame *shared.Frame) {
+	for _, effect := range frame.Effects {
+		if effect.Enabled && effect.Type == shared.EffectBackgroundBlur && !r.warned["background-blur"] {
+			fmt.Fprintf(os.Stderr, "warning: background blur is not supported in PDF, ignoring\n")
+			r.warned["background-blur"] = true
+		}
+	}
+	if layerBlur(frame) <= 0 || r.warned["layer-blur"] {
+		return
+	}
+	for _, fill := range frame.Fills {
+		if fill.Enabled && fill.Type != shared.FillSolid {
+			fmt.Fprintf(os.Stderr, "warning: layer blur only applies to solid fills, drawing %s fills unblurred\n", fill.Type)
+			r.warned["layer-blur"] = true
+			return
+		}
+	}
+}
+
+// drawSoftShape fills a rounded rectangle blurred by the given radius. Sharp
+// shapes are drawn as vectors; blurred ones are rasterized over bounds.
+func (r *PDFRenderer) drawSoftShape(pdf *gopdf.GoPdf, shape, bounds outlineRect, blur float64, rgba shared.RGBA) error {
+	if blur <= 0 {
+		return fillPolygon(pdf, shape.polygon(), rgba)
+	}
+	mask := newAlphaMask(bounds)
+	mask.paint(shape, false)
+	mask.blur(blur / 2)
+	return drawAlphaMask(pdf, mask, rgba)
+}
+
+// fillPolygon fills a polygon with a color, honoring its alpha.
+func fillPolygon(pdf *gopdf.GoPdf, points []gopdf.Point, rgba shared.RGBA) error {
+	if err := setAlpha(pdf, rgba.A); err != nil {
+		return err
+	}
+	pdf.SetFillColor(rgba.R, rgba.G, rgba.B)
+	pdf.Polygon(points, "F")
+	return setAlpha(pdf, 1.0)
+}
+
+// blurMargin is how far a Gaussian blur of the given radius (twice the
+// standard deviation, as in CSS) visibly spreads.
+func blurMargin(blur float64) float64 {
+	return math.Ceil(1.5 * blur)
+}
+
+// boundingRect returns the smallest sharp rectangle containing both.
+func boundingRect(a, b outlineRect) outlineRect {
+	x := math.Min(a.x, b.x)
+	y := math.Min(a.y, b.y)
+	return outlineRect{
+		x: x,
+		y: y,
+		w: math.Max(a.x+a.w, b.x+b.w) - x,
+		h: math.Max(a.y+a.h, b.y+b.h) - y,
+	}
+}
+
+// alphaMask is a grayscale coverage raster placed over a region of the page.
+type alphaMask struct {
+	x, y  float64 // top-left corner in points
+	scale float64 // pixels per point
+	w, h  int
+	alpha []float64
+}
+
+func newAlphaMask(bounds outlineRect) *alphaMask {
+	scale := effectRasterScale
+	if area := bounds.w * bounds.h * scale * scale; area > maxEffectPixels {
+		scale *= math.Sqrt(maxEffectPixels / area)
+	}
+	w := max(int(math.Ceil(bounds.w*scale)), 1)
+	h := max(int(math.Ceil(bounds.h*scale)), 1)
+	return &alphaMask{x: bounds.x, y: bounds.y, scale: scale, w: w, h: h, alpha: make([]float64, w*h)}
+}
+
+// paint sets every pixel to its anti-aliased coverage by the shape, or by
+// everything outside the shape when invert is set.
+func (m *alphaMask) paint(shape outlineRect, invert bool) {
+	for py := 0; py < m.h; py++ {
+		y := m.y + (float64(py)+0.5)/m.scale
+		for px := 0; px < m.w; px++ {
+			x := m.x + (float64(px)+0.5)/m.scale
+			coverage := math.Min(math.Max(0.5-roundedRectDistance(shape, x, y)*m.scale, 0), 1)
+			if invert {
+				coverage = 1 - coverage
+			}
+			m.alpha[py*m.w+px] = coverage
+		}
+	}
+}
+
+// blur applies a Gaussian blur with the given standard deviation in points,
+// approximated by three successive box blurs.
+func (m *alphaMask) blur(sigma float64) {
+	for _, size := range gaussianBoxes(sigma*m.scale, 3) {
+		radius := (size - 1) / 2
+		if radius < 1 {
+			continue
+		}
+		for row := 0; row < m.h; row++ {
+			boxBlur(m.alpha, row*m.w, 1, m.w, radius)
+		}
+		for col := 0; col < m.w; col++ {
+			boxBlur(m.alpha, col, m.w, m.h, radius)
+		}
+	}
+}
+
+// png encodes the mask as an image of a single color whose alpha channel is
+// the mask coverage scaled by the color alpha.
+func (m *alphaMask) png(rgba shared.RGBA) ([]byte, error) {
+	img := image.NewNRGBA(image.Rect(0, 0, m.w, m.h))
+	for i, a := range m.alpha {
+		img.SetNRGBA(i%m.w, i/m.w, color.NRGBA{
+			R: rgba.R,
+			G: rgba.G,
+			B: rgba.B,
+			A: uint8(math.Round(math.Min(math.Max(a*rgba.A, 0), 1) * 255)),
+		})
+	}
+
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestSpeed}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawAlphaMask paints the mask in the given color at its page position.
+func drawAlphaMask(pdf *gopdf.GoPdf, m *alphaMask, rgba shared.RGBA) error {
+	data, err := m.png(rgba)
+	if err != nil {
+		return fmt.Errorf("encode effect: %w", err)
+	}
+	holder, err := gopdf.ImageHolderByBytes(data)
+	if err != nil {
+		return fmt.Errorf("create image holder: %w", err)
+	}
+	return pdf.ImageByHolderWithOptions(holder, gopdf.ImageOptions{
+		X:    m.x,
+		Y:    m.y,
+		Rect: &gopdf.Rect{W: float64(m.w) / m.scale, H: float64(m.h) / m.scale},
+	})
+}
+
+// roundedRectDistance returns the signed distance from a point to the edge of
+// a rounded rectangle: negative inside, positive outside.
+func roundedRectDistance(o outlineRect, x, y float64) float64 {
+	radius := clampRadius(o.w, o.h, o.radius)
+	hw, hh := o.w/2, o.h/2
+	qx := math.Abs(x-(o.x+hw)) - (hw - radius)
+	qy := math.Abs(y-(o.y+hh)) - (hh - radius)
+	outside := math.Hypot(math.Max(qx, 0), math.Max(qy, 0))
+	inside := math.Min(math.Max(qx, qy), 0)
+	return outside + inside - radius
+}
+
+// gaussianBoxes returns the widths of n box blurs that together approximate a
+// Gaussian blur with standard deviation sigma (in pixels).
+func gaussianBoxes(sigma float64, n int) []int {
+	ideal := math.Sqrt(12*sigma*sigma/float64(n) + 1)
+	lower := int(math.Floor(ideal))
+	if lower%2 == 0 {
+		lower--
+	}
+	upper := lower + 2
+
+	fl := float64(lower)
+	m := int(math.Round((12*sigma*sigma - float64(n)*fl*fl - 4*float64(n)*fl - 3*float64(n)) / (-4*fl - 4)))
+
+	sizes := make([]int, n)
+	for i := range sizes {
+		if i < m {
+			sizes[i] = lower
+		} else {
+			sizes[i] = upper
+		}
+	}
+	return sizes
+}
+
+// boxBlur blurs n values of data, starting at offset and stride apart, with a
+// moving average of the given radius. Values beyond the ends repeat the edge
+// value.
+func boxBlur(data []float64, offset, stride, n, radius int) {
+	src := make([]float64, n)
+	for i := range src {
+		src[i] = data[offset+i*stride]
+	}
+	at := func(i int) float64 {
+		return src[min(max(i, 0), n-1)]
+	}
+
+	window := float64(2*radius + 1)
+	sum := 0.0
+	for i := -radius; i <= radius; i++ {
+		sum += at(i)
+	}
+	for i := 0; i < n; i++ {
+		data[offset+i*stride] = sum / window
+		sum += at(i+radius+1) - at(i-radius)
+	}
+}
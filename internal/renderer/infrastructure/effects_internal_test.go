@@ -0,0 +1,114 @@
+package infrastructure
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"testing"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestRoundedRectDistance(t *testing.T) {
+	o := outlineRect{x: 0, y: 0, w: 100, h: 50, radius: 10}
+
+	if d := roundedRectDistance(o, 50, 25); d != -25 {
+		t.Errorf("expected -25 at the center, got %f", d)
+	}
+	if d := roundedRectDistance(o, 50, 60); d != 10 {
+		t.Errorf("expected 10 below the bottom edge, got %f", d)
+	}
+	// The corner is cut by the radius: the box corner lies outside the shape
+	want := math.Sqrt2*10 - 10
+	if d := roundedRectDistance(o, 0, 0); math.Abs(d-want) > 1e-9 {
+		t.Errorf("expected %f at the rounded corner, got %f", want, d)
+	}
+}
+
+func TestGaussianBoxesMatchVariance(t *testing.T) {
+	sigma := 6.0
+	variance := 0.0
+	for _, size := range gaussianBoxes(sigma, 3) {
+		if size%2 == 0 {
+			t.Fatalf("expected odd box sizes, got %d", size)
+		}
+		variance += float64(size*size-1) / 12
+	}
+	if math.Abs(math.Sqrt(variance)-sigma) > 0.5 {
+		t.Errorf("expected combined sigma ~%f, got %f", sigma, math.Sqrt(variance))
+	}
+}
+
+func TestBoxBlurPreservesConstantAndClampsEdges(t *testing.T) {
+	data := []float64{1, 1, 1, 1, 1}
+	boxBlur(data, 0, 1, len(data), 2)
+	for i, v := range data {
+		if math.Abs(v-1) > 1e-9 {
+			t.Errorf("index %d: expected 1, got %f", i, v)
+		}
+	}
+
+	step := []float64{0, 0, 1, 1}
+	boxBlur(step, 0, 1, len(step), 1)
+	if step[1] >= step[2] || step[0] != 0 || step[3] != 1 {
+		t.Errorf("expected a smoothed step, got %v", step)
+	}
+}
+
+func TestAlphaMaskPaintAndInvert(t *testing.T) {
+	shape := outlineRect{x: 10, y: 10, w: 20, h: 20}
+	mask := newAlphaMask(outlineRect{x: 0, y: 0, w: 40, h: 40})
+	mask.paint(shape, false)
+	if a := mask.alpha[40*mask.w+40]; a != 1 {
+		t.Errorf("expected full coverage inside, got %f", a)
+	}
+	if a := mask.alpha[0]; a != 0 {
+		t.Errorf("expected no coverage outside, got %f", a)
+	}
+
+	mask.paint(shape, true)
+	if a := mask.alpha[0]; a != 1 {
+		t.Errorf("expected full coverage outside when inverted, got %f", a)
+	}
+}
+
+func TestAlphaMaskBlurSoftensEdges(t *testing.T) {
+	mask := newAlphaMask(outlineRect{x: 0, y: 0, w: 60, h: 60})
+	mask.paint(outlineRect{x: 20, y: 20, w: 20, h: 20}, false)
+	mask.blur(4)
+
+	edge := mask.alpha[60*mask.w+40] // left edge of the shape, mid height
+	if edge < 0.3 || edge > 0.7 {
+		t.Errorf("expected ~0.5 coverage on the blurred edge, got %f", edge)
+	}
+	if a := mask.alpha[60*mask.w+20]; a <= 0 {
+		t.Errorf("expected the blur to spread outside the shape, got %f", a)
+	}
+}
+
+func TestNewAlphaMaskCapsResolution(t *testing.T) {
+	mask := newAlphaMask(outlineRect{w: 4000, h: 4000})
+	if mask.w*mask.h > maxEffectPixels*11/10 {
+		t.Errorf("expected at most ~%d pixels, got %d", maxEffectPixels, mask.w*mask.h)
+	}
+}
+
+func TestAlphaMaskPNGScalesAlpha(t *testing.T) {
+	mask := newAlphaMask(outlineRect{w: 1, h: 1})
+	for i := range mask.alpha {
+		mask.alpha[i] = 1
+	}
+	data, err := mask.png(shared.RGBA{R: 10, G: 20, B: 30, A: 0.5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	_, _, _, a := img.At(0, 0).RGBA()
+	if a>>8 != 128 {
+		t.Errorf("expected alpha 128, got %d", a>>8)
+	}
+}
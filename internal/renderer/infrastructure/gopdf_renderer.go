@@ -6,29 +6,15 @@ import (
 	"io"
 	"math"
 	"os"
-	"strings"
 
 	"github.com/signintech/gopdf"
-	"golang.org/x/image/font/gofont/gobold"
-	"golang.org/x/image/font/gofont/gobolditalic"
-	"golang.org/x/image/font/gofont/goitalic"
-	"golang.org/x/image/font/gofont/goregular"
 
 	asset "github.com/vpedrosa/pen2pdf/internal/asset/domain"
+	assetInfra "github.com/vpedrosa/pen2pdf/internal/asset/infrastructure"
 	layout "github.com/vpedrosa/pen2pdf/internal/layout/domain"
 	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
 )
 
-const fallbackFontFamily = "GoFont"
-
-// fallbackFonts maps style keys to embedded Go font TTF data.
-var fallbackFonts = map[string][]byte{
-	"regular":    goregular.TTF,
-	"bold":       gobold.TTF,
-	"italic":     goitalic.TTF,
-	"bolditalic": gobolditalic.TTF,
-}
-
 // PDFRenderer renders layout pages to PDF using gopdf.
 type PDFRenderer struct {
 	imageLoader   asset.ImageLoader
@@ -36,6 +22,10 @@ type PDFRenderer struct {
 	loadedFonts   map[string]bool
 	fallbackReady map[string]bool
 	warned        map[string]bool
+
+	// Imported form XObjects (gradients) of the render in progress
+	templates       map[string]int
+	templateSources []*io.ReadSeeker
 }
 
 func NewPDFRenderer(imageLoader asset.ImageLoader, fontLoader asset.FontLoader) *PDFRenderer {
@@ -51,6 +41,11 @@ func NewPDFRenderer(imageLoader asset.ImageLoader, fontLoader asset.FontLoader)
 func (r *PDFRenderer) Render(pages []layout.Page, output io.Writer) error {
 	pdf := &gopdf.GoPdf{}
 	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+	// Fonts are added to each PDF, so a new render loads them again
+	r.loadedFonts = make(map[string]bool)
+	r.fallbackReady = make(map[string]bool)
+	r.templates = make(map[string]int)
+	r.templateSources = nil
 
 	for i, page := range pages {
 		pdf.AddPageWithOption(gopdf.PageOption{
@@ -66,8 +61,23 @@ func (r *PDFRenderer) Render(pages []layout.Page, output io.Writer) error {
 }
 
 func (r *PDFRenderer) renderBox(pdf *gopdf.GoPdf, box *layout.LayoutBox) error {
+	if shared.IsHidden(box.Node) {
+		return nil
+	}
+	clipped := false
 	switch node := box.Node.(type) {
 	case *shared.Frame:
+		r.warnUnsupportedEffects(node)
+		if err := r.drawDropShadows(pdf, box, node); err != nil {
+			return err
+		}
+		// A clipping frame scopes its own fill and every descendant to its
+		// (possibly rounded) outline; the graphics state is restored below.
+		if node.Clip && box.Width > 0 && box.Height > 0 {
+			pdf.SaveGraphicsState()
+			pdf.ClipPolygon(roundedRectPolygon(box.X, box.Y, box.Width, box.Height, node.CornerRadius))
+			clipped = true
+		}
 		if err := r.renderFrame(pdf, box, node); err != nil {
 			return err
 		}
@@ -75,6 +85,18 @@ func (r *PDFRenderer) renderBox(pdf *gopdf.GoPdf, box *layout.LayoutBox) error {
 		if err := r.renderText(pdf, box, node); err != nil {
 			return err
 		}
+	case *shared.Shape:
+		if err := r.renderShape(pdf, box, node); err != nil {
+			return fmt.Errorf("%s %q: %w", node.Type, node.ID, err)
+		}
+	case *shared.Path:
+		if err := r.renderPath(pdf, box, node); err != nil {
+			return fmt.Errorf("path %q: %w", node.ID, err)
+		}
+	case *shared.Image:
+		if err := r.renderImage(pdf, box, node); err != nil {
+			return fmt.Errorf("image %q: %w", node.ID, err)
+		}
 	}
 
 	for _, child := range box.Children {
@@ -82,134 +104,245 @@ func (r *PDFRenderer) renderBox(pdf *gopdf.GoPdf, box *layout.LayoutBox) error {
 			return err
 		}
 	}
+
+	if clipped {
+		pdf.RestoreGraphicsState()
+	}
+
+	// Frame strokes sit on top of the content and are never clipped by it
+	if frame, ok := box.Node.(*shared.Frame); ok {
+		return r.drawStroke(pdf, box.X, box.Y, box.Width, box.Height, frame.CornerRadius, frame.Stroke)
+	}
 	return nil
 }
 
+// renderFrame paints the enabled fills of a frame from bottom to top, then
+// its inner shadows. A layer blur softens the solid fills.
 func (r *PDFRenderer) renderFrame(pdf *gopdf.GoPdf, box *layout.LayoutBox, frame *shared.Frame) error {
-	if frame.Fill == nil {
+	blur := layerBlur(frame)
+	for _, fill := range frame.Fills {
+		if !fill.Enabled {
+			continue
+		}
+		if blur > 0 && fill.Type == shared.FillSolid && box.Width > 0 && box.Height > 0 {
+			if err := r.drawBlurredRect(pdf, box, fill.Color, frame.CornerRadius, blur); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := r.drawFill(pdf, box.X, box.Y, box.Width, box.Height, fill, frame.CornerRadius); err != nil {
+			return err
+		}
+	}
+	return r.drawInnerShadows(pdf, box, frame)
+}
+
+// renderShape paints the enabled fills of a shape from bottom to top inside
+// its outline, then its stroke. Lines have no area and only draw the stroke.
+// The shape opacity scales the alpha of each fill and of the stroke.
+func (r *PDFRenderer) renderShape(pdf *gopdf.GoPdf, box *layout.LayoutBox, shape *shared.Shape) error {
+	if shape.Opacity <= 0 {
 		return nil
 	}
+	stroke, err := fadeStroke(shape.Stroke, shape.Opacity)
+	if err != nil {
+		return fmt.Errorf("stroke: %w", err)
+	}
 
-	switch frame.Fill.Type {
-	case shared.FillSolid:
-		return r.drawSolidRect(pdf, box.X, box.Y, box.Width, box.Height, frame.Fill.Color, frame.CornerRadius, frame.Clip)
-	case shared.FillImage:
-		return r.drawImage(pdf, box.X, box.Y, box.Width, box.Height, frame.Fill, frame.Clip)
+	if shape.Type == shared.NodeTypeLine {
+		width := strokeWidth(stroke)
+		if width <= 0 || stroke.Color == "" {
+			return nil
+		}
+		rgba, err := shared.ParseHexColor(stroke.Color)
+		if err != nil {
+			return fmt.Errorf("stroke: %w", err)
+		}
+		line := []gopdf.Point{{X: box.X, Y: box.Y}, {X: box.X + box.Width, Y: box.Y + box.Height}}
+		return strokePath(pdf, line, false, width, stroke.Dash, rgba)
 	}
-	return nil
-}
 
-func (r *PDFRenderer) drawSolidRect(pdf *gopdf.GoPdf, x, y, w, h float64, color string, radius float64, _ bool) error {
-	if w <= 0 || h <= 0 {
+	if box.Width <= 0 || box.Height <= 0 {
 		return nil
 	}
 
-	rgba, err := shared.ParseHexColor(color)
-	if err != nil {
-		return err
+	// Rectangles keep the frame drawing path, with per-side stroke widths
+	var outline []gopdf.Point
+	switch shape.Type {
+	case shared.NodeTypeEllipse:
+		outline = ellipsePolygon(box.X, box.Y, box.Width, box.Height)
+	case shared.NodeTypePolygon:
+		outline = regularPolygon(box.X, box.Y, box.Width, box.Height, max(shape.Sides, 3))
 	}
 
-	pdf.SetFillColor(rgba.R, rgba.G, rgba.B)
-	if rgba.A < 1.0 {
-		if err := pdf.SetTransparency(gopdf.Transparency{Alpha: rgba.A, BlendModeType: gopdf.NormalBlendMode}); err != nil {
+	for _, fill := range shape.Fills {
+		if !fill.Enabled {
+			continue
+		}
+		fill, err := fadeFill(fill, shape.Opacity)
+		if err != nil {
+			return fmt.Errorf("fill: %w", err)
+		}
+		if outline == nil {
+			err = r.drawFill(pdf, box.X, box.Y, box.Width, box.Height, fill, shape.CornerRadius)
+		} else {
+			err = r.drawOutlineFill(pdf, box, outline, fill)
+		}
+		if err != nil {
 			return err
 		}
 	}
 
-	if radius > 0 {
-		// Clamp radius to half the smallest dimension
-		maxRadius := math.Min(w, h) / 2
-		if radius > maxRadius {
-			radius = maxRadius
+	if outline == nil {
+		return r.drawStroke(pdf, box.X, box.Y, box.Width, box.Height, shape.CornerRadius, stroke)
+	}
+	bounds := outlineRect{x: box.X, y: box.Y, w: box.Width, h: box.Height}
+	return r.drawOutlineStroke(pdf, outline, bounds, stroke)
+}
+
+// drawOutlineFill paints a fill inside an arbitrary outline. Solid colors
+// fill the outline directly; other fills are drawn over the box and clipped.
+func (r *PDFRenderer) drawOutlineFill(pdf *gopdf.GoPdf, box *layout.LayoutBox, outline []gopdf.Point, fill *shared.Fill) error {
+	if fill.Type == shared.FillSolid {
+		if fill.Color == "" {
+			return nil
 		}
-		if err := pdf.Rectangle(x, y, x+w, y+h, "F", radius, 20); err != nil {
+		rgba, err := shared.ParseHexColor(fill.Color)
+		if err != nil {
 			return err
 		}
-	} else {
-		pdf.RectFromUpperLeftWithStyle(x, y, w, h, "F")
+		return fillPolygon(pdf, outline, rgba)
 	}
 
-	if rgba.A < 1.0 {
-		if err := pdf.SetTransparency(gopdf.Transparency{Alpha: 1.0, BlendModeType: gopdf.NormalBlendMode}); err != nil {
-			return err
+	pdf.SaveGraphicsState()
+	pdf.ClipPolygon(outline)
+	err := r.drawFill(pdf, box.X, box.Y, box.Width, box.Height, fill, 0)
+	pdf.RestoreGraphicsState()
+	return err
+}
+
+// fadeFill returns a copy of a fill with its opacity scaled. Solid fills
+// carry their opacity in the alpha of the color.
+func fadeFill(fill *shared.Fill, opacity float64) (*shared.Fill, error) {
+	if opacity >= 1 {
+		return fill, nil
+	}
+	faded := *fill
+	if fill.Type == shared.FillSolid {
+		if fill.Color == "" {
+			return fill, nil
 		}
+		color, err := fadeColor(fill.Color, opacity)
+		if err != nil {
+			return nil, err
+		}
+		faded.Color = color
+		return &faded, nil
 	}
-	return nil
-}
 
-func (r *PDFRenderer) drawImage(pdf *gopdf.GoPdf, x, y, w, h float64, fill *shared.Fill, clip bool) error {
-	if r.imageLoader == nil {
-		return nil
+	// Zero means the fill opacity is unset
+	current := fill.Opacity
+	if current <= 0 || current > 1 {
+		current = 1
 	}
+	faded.Opacity = current * opacity
+	return &faded, nil
+}
 
-	imgData, err := r.imageLoader.LoadImage(fill.URL)
+// fadeStroke returns a copy of a stroke with the alpha of its color scaled.
+func fadeStroke(stroke *shared.Stroke, opacity float64) (*shared.Stroke, error) {
+	if stroke == nil || stroke.Color == "" || opacity >= 1 {
+		return stroke, nil
+	}
+	color, err := fadeColor(stroke.Color, opacity)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "warning: image %q not found, skipping\n", fill.URL)
-		return nil
+		return nil, err
 	}
+	faded := *stroke
+	faded.Color = color
+	return &faded, nil
+}
 
-	if fill.Opacity > 0 && fill.Opacity < 1.0 {
-		if err := pdf.SetTransparency(gopdf.Transparency{Alpha: fill.Opacity, BlendModeType: gopdf.NormalBlendMode}); err != nil {
-			return err
-		}
+// fadeColor scales the alpha of a hex color, returning it as #RRGGBBAA.
+func fadeColor(color string, opacity float64) (string, error) {
+	rgba, err := shared.ParseHexColor(color)
+	if err != nil {
+		return "", err
 	}
+	alpha := math.Round(math.Min(math.Max(rgba.A*opacity, 0), 1) * 255)
+	return fmt.Sprintf("#%02X%02X%02X%02X", rgba.R, rgba.G, rgba.B, uint8(alpha)), nil
+}
 
-	// Calculate fill mode dimensions (cover)
-	imgW := float64(imgData.Width)
-	imgH := float64(imgData.Height)
-	scaleW := w / imgW
-	scaleH := h / imgH
-	scale := math.Max(scaleW, scaleH)
+func (r *PDFRenderer) drawBlurredRect(pdf *gopdf.GoPdf, box *layout.LayoutBox, color string, radius, blur float64) error {
+	if color == "" {
+		return nil
+	}
+	rgba, err := shared.ParseHexColor(color)
+	if err != nil {
+		return err
+	}
+	shape := outlineRect{x: box.X, y: box.Y, w: box.Width, h: box.Height, radius: radius}
+	margin := blurMargin(blur)
+	return r.drawSoftShape(pdf, shape, shape.expand(margin, margin, margin, margin), blur, rgba)
+}
 
-	drawW := imgW * scale
-	drawH := imgH * scale
-	drawX := x - (drawW-w)/2
-	drawY := y - (drawH-h)/2
+func (r *PDFRenderer) drawFill(pdf *gopdf.GoPdf, x, y, w, h float64, fill *shared.Fill, radius float64) error {
+	switch fill.Type {
+	case shared.FillSolid:
+		return r.drawSolidRect(pdf, x, y, w, h, fill.Color, radius)
+	case shared.FillImage:
+		return r.drawImage(pdf, x, y, w, h, fill, radius)
+	case shared.FillLinearGradient, shared.FillRadialGradient, shared.FillAngularGradient:
+		return r.drawGradient(pdf, x, y, w, h, fill, radius)
+	}
+	return nil
+}
 
-	if clip {
-		pdf.SaveGraphicsState()
-		pdf.ClipPolygon([]gopdf.Point{
-			{X: x, Y: y},
-			{X: x + w, Y: y},
-			{X: x + w, Y: y + h},
-			{X: x, Y: y + h},
-		})
+func (r *PDFRenderer) drawSolidRect(pdf *gopdf.GoPdf, x, y, w, h float64, color string, radius float64) error {
+	if w <= 0 || h <= 0 || color == "" {
+		return nil
 	}
 
-	imgHolder, err := gopdf.ImageHolderByBytes(imgData.Data)
+	rgba, err := shared.ParseHexColor(color)
 	if err != nil {
-		return fmt.Errorf("create image holder: %w", err)
+		return err
 	}
 
-	if err := pdf.ImageByHolderWithOptions(imgHolder, gopdf.ImageOptions{
-		X:    drawX,
-		Y:    drawY,
-		Rect: &gopdf.Rect{W: drawW, H: drawH},
-	}); err != nil {
-		return fmt.Errorf("draw image: %w", err)
+	pdf.SetFillColor(rgba.R, rgba.G, rgba.B)
+	if rgba.A < 1.0 {
+		if err := pdf.SetTransparency(gopdf.Transparency{Alpha: rgba.A, BlendModeType: gopdf.NormalBlendMode}); err != nil {
+			return err
+		}
 	}
 
-	if clip {
-		pdf.RestoreGraphicsState()
+	if radius > 0 {
+		pdf.Polygon(roundedRectPolygon(x, y, w, h, radius), "F")
+	} else {
+		pdf.RectFromUpperLeftWithStyle(x, y, w, h, "F")
 	}
 
-	if fill.Opacity > 0 && fill.Opacity < 1.0 {
+	if rgba.A < 1.0 {
 		if err := pdf.SetTransparency(gopdf.Transparency{Alpha: 1.0, BlendModeType: gopdf.NormalBlendMode}); err != nil {
 			return err
 		}
 	}
-
 	return nil
 }
 
+// renderText draws the lines of a text, broken as the layout measured them,
+// with its stroke first and then once per enabled solid fill.
 func (r *PDFRenderer) renderText(pdf *gopdf.GoPdf, box *layout.LayoutBox, text *shared.Text) error {
 	if text.Content == "" {
 		return nil
 	}
 
-	fontKey := text.FontFamily + "-" + text.FontWeight
+	fontStyle := text.FontStyle
+	if fontStyle == "" {
+		fontStyle = "normal"
+	}
+	fontKey := text.FontFamily + "-" + text.FontWeight + "-" + fontStyle
 	if !r.loadedFonts[fontKey] {
-		if err := r.loadFont(pdf, fontKey, text.FontFamily, text.FontWeight, text.FontStyle); err != nil {
+		if err := r.loadFont(pdf, fontKey, text.FontFamily, text.FontWeight, fontStyle); err != nil {
 			return err
 		}
 	}
@@ -218,20 +351,6 @@ func (r *PDFRenderer) renderText(pdf *gopdf.GoPdf, box *layout.LayoutBox, text *
 		return fmt.Errorf("set font %q: %w", fontKey, err)
 	}
 
-	// Set text color
-	if text.Fill != "" {
-		rgba, err := shared.ParseHexColor(text.Fill)
-		if err != nil {
-			return err
-		}
-		pdf.SetTextColor(rgba.R, rgba.G, rgba.B)
-		if rgba.A < 1.0 {
-			if err := pdf.SetTransparency(gopdf.Transparency{Alpha: rgba.A, BlendModeType: gopdf.NormalBlendMode}); err != nil {
-				return err
-			}
-		}
-	}
-
 	// Set letter spacing
 	if text.LetterSpacing != 0 {
 		if err := pdf.SetCharSpacing(text.LetterSpacing); err != nil {
@@ -239,33 +358,31 @@ func (r *PDFRenderer) renderText(pdf *gopdf.GoPdf, box *layout.LayoutBox, text *
 		}
 	}
 
-	// Render lines
-	lineHeight := text.FontSize * 1.2
-	if text.LineHeight > 0 {
-		lineHeight = text.FontSize * text.LineHeight
-	}
+	// Break and position lines exactly as the text measurer did
+	lineHeight := layout.TextStyleOf(text).LineSpacing()
+	lines := layout.BreakLines(text.Content, box.Width, func(s string) float64 {
+		w, _ := pdf.MeasureTextWidth(s)
+		return w
+	})
+	layout.PositionLines(lines, box.Width, lineHeight, text.TextAlign)
 
-	// Determine horizontal alignment for CellWithOption
-	hAlign := gopdf.Left
-	switch text.TextAlign {
-	case "center":
-		hAlign = gopdf.Center
-	case "right":
-		hAlign = gopdf.Right
+	if err := r.drawTextStroke(pdf, box, lines, lineHeight, text.Stroke); err != nil {
+		return err
 	}
 
-	lines := strings.Split(text.Content, "\n")
-	currentY := box.Y
-
-	for _, line := range lines {
-		pdf.SetX(box.X)
-		pdf.SetY(currentY)
-		if err := pdf.CellWithOption(&gopdf.Rect{W: box.Width, H: lineHeight}, line, gopdf.CellOption{
-			Align: hAlign | gopdf.Top,
-		}); err != nil {
-			return fmt.Errorf("render text: %w", err)
+	// Paint the text once per fill, bottom to top
+	for _, color := range r.textFillColors(text) {
+		rgba, err := shared.ParseHexColor(color)
+		if err != nil {
+			return err
+		}
+		pdf.SetTextColor(rgba.R, rgba.G, rgba.B)
+		if err := setAlpha(pdf, rgba.A); err != nil {
+			return err
+		}
+		if err := drawLines(pdf, box.X, box.Y, lines, lineHeight); err != nil {
+			return err
 		}
-		currentY += lineHeight
 	}
 
 	// Reset letter spacing
@@ -282,12 +399,54 @@ func (r *PDFRenderer) renderText(pdf *gopdf.GoPdf, box *layout.LayoutBox, text *
 	return nil
 }
 
-// loadFont tries the font loader first, then falls back to embedded Go fonts.
-func (r *PDFRenderer) loadFont(pdf *gopdf.GoPdf, fontKey, family, weight, style string) error {
-	if style == "" {
-		style = "normal"
+// textFillColors returns the colors of the enabled solid fills of a text,
+// or black when it has no fills. Glyphs cannot be used as a clipping path
+// with gopdf, so image and gradient fills are skipped with a warning.
+func (r *PDFRenderer) textFillColors(text *shared.Text) []string {
+	if len(text.Fills) == 0 {
+		return []string{"#000000"}
 	}
 
+	var colors []string
+	for _, fill := range text.Fills {
+		if !fill.Enabled {
+			continue
+		}
+		if fill.Type != shared.FillSolid {
+			key := "text-fill-" + string(fill.Type)
+			if !r.warned[key] {
+				fmt.Fprintf(os.Stderr, "warning: %s fills are not supported on text, skipping\n", fill.Type)
+				r.warned[key] = true
+			}
+			continue
+		}
+		if fill.Color != "" {
+			colors = append(colors, fill.Color)
+		}
+	}
+	return colors
+}
+
+// drawLines draws positioned text lines relative to (x, y) with the current
+// font and text color.
+func drawLines(pdf *gopdf.GoPdf, x, y float64, lines []layout.TextLine, lineHeight float64) error {
+	for _, line := range lines {
+		if line.Text == "" {
+			continue
+		}
+		pdf.SetX(x + line.X)
+		pdf.SetY(y + line.Y)
+		if err := pdf.CellWithOption(&gopdf.Rect{W: line.Width, H: lineHeight}, line.Text, gopdf.CellOption{
+			Align: gopdf.Left | gopdf.Top,
+		}); err != nil {
+			return fmt.Errorf("render text: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadFont tries the font loader first, then falls back to embedded Go fonts.
+func (r *PDFRenderer) loadFont(pdf *gopdf.GoPdf, fontKey, family, weight, style string) error {
 	// Try the real font loader first
 	if r.fontLoader != nil {
 		fontData, err := r.fontLoader.LoadFont(family, weight, style)
@@ -306,47 +465,20 @@ func (r *PDFRenderer) loadFont(pdf *gopdf.GoPdf, fontKey, family, weight, style
 	}
 
 	// Fallback to embedded Go fonts
-	fbKey := fallbackStyleKey(weight, style)
-	fbFontKey := fallbackFontFamily + "-" + fbKey
+	fbKey, data := assetInfra.FallbackFont(weight, style)
+	fbFontKey := assetInfra.FallbackFontFamily + "-" + fbKey
 
 	if !r.fallbackReady[fbFontKey] {
-		data, ok := fallbackFonts[fbKey]
-		if !ok {
-			data = fallbackFonts["regular"]
-			fbFontKey = fallbackFontFamily + "-regular"
-		}
-		if !r.fallbackReady[fbFontKey] {
-			if err := pdf.AddTTFFontData(fbFontKey, data); err != nil {
-				return fmt.Errorf("add fallback font: %w", err)
-			}
-			r.fallbackReady[fbFontKey] = true
+		if err := pdf.AddTTFFontData(fbFontKey, data); err != nil {
+			return fmt.Errorf("add fallback font: %w", err)
 		}
+		r.fallbackReady[fbFontKey] = true
 	}
 
 	// Map the requested fontKey to the fallback so SetFont works
-	if err := pdf.AddTTFFontData(fontKey, fallbackFonts[fbKey]); err != nil {
-		// Already loaded under another key, try regular
-		if err2 := pdf.AddTTFFontData(fontKey, fallbackFonts["regular"]); err2 != nil {
-			return fmt.Errorf("add fallback font for %q: %w", fontKey, err)
-		}
+	if err := pdf.AddTTFFontData(fontKey, data); err != nil {
+		return fmt.Errorf("add fallback font for %q: %w", fontKey, err)
 	}
 	r.loadedFonts[fontKey] = true
 	return nil
 }
-
-// fallbackStyleKey maps weight/style to a Go font variant key.
-func fallbackStyleKey(weight, style string) string {
-	isBold := weight == "700" || weight == "800" || weight == "900"
-	isItalic := style == "italic"
-
-	switch {
-	case isBold && isItalic:
-		return "bolditalic"
-	case isBold:
-		return "bold"
-	case isItalic:
-		return "italic"
-	default:
-		return "regular"
-	}
-}
@@ -2,8 +2,17 @@ package infrastructure_test
 
 import (
 	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"regexp"
+	"strings"
 	"testing"
 
+	asset "github.com/vpedrosa/pen2pdf/internal/asset/domain"
 	layout "github.com/vpedrosa/pen2pdf/internal/layout/domain"
 	renderer "github.com/vpedrosa/pen2pdf/internal/renderer/domain"
 	"github.com/vpedrosa/pen2pdf/internal/renderer/infrastructure"
@@ -89,7 +98,7 @@ func TestRenderSolidFillRect(t *testing.T) {
 				X: 0, Y: 0, Width: 800, Height: 1000,
 				Node: &shared.Frame{
 					ID: "page", Name: "page",
-					Fill: shared.SolidFill("#FF6B35"),
+					Fills: []*shared.Fill{shared.SolidFill("#FF6B35")},
 				},
 			},
 		},
@@ -114,7 +123,7 @@ func TestRenderSolidFillWithAlpha(t *testing.T) {
 				X: 0, Y: 0, Width: 800, Height: 1000,
 				Node: &shared.Frame{
 					ID: "page", Name: "page",
-					Fill: shared.SolidFill("#000000BB"),
+					Fills: []*shared.Fill{shared.SolidFill("#000000BB")},
 				},
 			},
 		},
@@ -136,7 +145,7 @@ func TestRenderRoundedRect(t *testing.T) {
 				X: 0, Y: 0, Width: 800, Height: 1000,
 				Node: &shared.Frame{
 					ID: "page", Name: "page",
-					Fill:         shared.SolidFill("#FF6B35"),
+					Fills:        []*shared.Fill{shared.SolidFill("#FF6B35")},
 					CornerRadius: 20,
 				},
 			},
@@ -157,11 +166,11 @@ func TestRenderNestedFrames(t *testing.T) {
 			Width: 800, Height: 1000,
 			Root: &layout.LayoutBox{
 				X: 0, Y: 0, Width: 800, Height: 1000,
-				Node: &shared.Frame{ID: "page", Name: "page", Fill: shared.SolidFill("#FFFFFF")},
+				Node: &shared.Frame{ID: "page", Name: "page", Fills: []*shared.Fill{shared.SolidFill("#FFFFFF")}},
 				Children: []*layout.LayoutBox{
 					{
 						X: 40, Y: 40, Width: 720, Height: 920,
-						Node: &shared.Frame{ID: "inner", Name: "inner", Fill: shared.SolidFill("#FF0000")},
+						Node: &shared.Frame{ID: "inner", Name: "inner", Fills: []*shared.Fill{shared.SolidFill("#FF0000")}},
 					},
 				},
 			},
@@ -234,7 +243,7 @@ func TestRenderTextWithFallbackFont(t *testing.T) {
 						Node: &shared.Text{
 							ID: "t1", Name: "label",
 							Content:    "Hello World",
-							Fill:       "#FF0000",
+							Fills:      []*shared.Fill{shared.SolidFill("#FF0000")},
 							FontFamily: "NonExistentFont",
 							FontSize:   16,
 							FontWeight: "400",
@@ -255,6 +264,43 @@ func TestRenderTextWithFallbackFont(t *testing.T) {
 	}
 }
 
+func TestRenderTwiceWithSameRenderer(t *testing.T) {
+	// Fonts belong to each PDF; a second render must embed them again
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	pages := []layout.Page{
+		{
+			Width: 400, Height: 200,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 400, Height: 200,
+				Node: &shared.Frame{ID: "page", Name: "page"},
+				Children: []*layout.LayoutBox{
+					{
+						X: 20, Y: 20, Width: 200, Height: 30,
+						Node: &shared.Text{
+							ID: "t1", Name: "label",
+							Content:    "Hello",
+							Fills:      []*shared.Fill{shared.SolidFill("#000000")},
+							FontFamily: "NonExistentFont",
+							FontSize:   16,
+							FontWeight: "400",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for i := range 2 {
+		var buf bytes.Buffer
+		if err := r.Render(pages, &buf); err != nil {
+			t.Fatalf("render %d: unexpected error: %v", i+1, err)
+		}
+		if !bytes.Contains(buf.Bytes(), []byte("/FontFile2")) {
+			t.Errorf("render %d: expected an embedded font", i+1)
+		}
+	}
+}
+
 func TestRenderTextBoldFallback(t *testing.T) {
 	r := infrastructure.NewPDFRenderer(nil, nil)
 	pages := []layout.Page{
@@ -352,3 +398,667 @@ func TestRenderMultipleTextNodesFallback(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+// contentOperators returns the operators and operands drawn on the pages of
+// a rendered PDF, inflating its content streams. Binary streams such as
+// fonts are left out.
+func contentOperators(t *testing.T, pdf []byte) []string {
+	t.Helper()
+	var ops []string
+	for _, m := range streamPattern.FindAllSubmatch(pdf, -1) {
+		zr, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(zr)
+		if err != nil || bytes.ContainsFunc(data, func(r rune) bool { return r >= 0x80 || r == 0 }) {
+			continue
+		}
+		ops = append(ops, strings.Fields(string(data))...)
+	}
+	return ops
+}
+
+var streamPattern = regexp.MustCompile(`(?s)stream\r?\n(.*?)\r?\nendstream`)
+
+func TestRenderWrappedParagraph(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node: &shared.Frame{ID: "page", Name: "page"},
+				Children: []*layout.LayoutBox{
+					{
+						X: 40, Y: 40, Width: 120, Height: 200,
+						Node: &shared.Text{
+							ID: "t1", Name: "paragraph",
+							Content:    "A long paragraph that cannot possibly fit on a single line of 120pt",
+							FontFamily: "MissingFont", FontSize: 14, FontWeight: "400",
+							TextAlign: "center",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The fallback font breaks the paragraph into four lines at 120pt
+	lines := 0
+	for _, op := range contentOperators(t, buf.Bytes()) {
+		if op == "BT" {
+			lines++
+		}
+	}
+	if lines != 4 {
+		t.Errorf("expected 4 lines of text, got %d", lines)
+	}
+}
+
+func TestRenderClippedRoundedFrameWithOverflowingChildren(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node: &shared.Frame{ID: "page", Name: "page"},
+				Children: []*layout.LayoutBox{
+					{
+						X: 40, Y: 40, Width: 300, Height: 200,
+						Node: &shared.Frame{
+							ID: "card", Name: "card",
+							Fills: []*shared.Fill{shared.SolidFill("#FFFFFF")}, CornerRadius: 24, Clip: true,
+						},
+						Children: []*layout.LayoutBox{
+							{
+								X: 20, Y: 20, Width: 400, Height: 400,
+								Node: &shared.Frame{ID: "overflow", Name: "overflow", Fills: []*shared.Fill{shared.SolidFill("#FF0000")}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The clip path is set within a saved graphics state that is restored
+	// after the children, so it does not leak to later siblings
+	ops := contentOperators(t, buf.Bytes())
+	depth, clipped := 0, false
+	for i, op := range ops {
+		switch op {
+		case "q":
+			depth++
+		case "Q":
+			depth--
+		case "W":
+			if i+1 < len(ops) && ops[i+1] == "n" && depth > 0 {
+				clipped = true
+			}
+		}
+		if depth < 0 {
+			t.Fatal("expected every Q to restore a saved state")
+		}
+	}
+	if !clipped {
+		t.Error("expected a W n clip within q and Q")
+	}
+	if depth != 0 {
+		t.Errorf("expected balanced q and Q, got %d unrestored", depth)
+	}
+}
+
+func TestRenderFrameStrokes(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	strokes := []*shared.Stroke{
+		shared.SolidStroke("#000000", 2, shared.StrokeInside),
+		shared.SolidStroke("#FF000080", 3, shared.StrokeCenter),
+		{Color: "#0000FF", Thickness: shared.UniformThickness(1), Align: shared.StrokeOutside, Dash: []float64{4, 2}},
+		{Color: "#E0E0E0", Thickness: shared.StrokeThickness{Bottom: 1}, Align: shared.StrokeInside},
+		{Color: "#E0E0E0", Thickness: shared.StrokeThickness{Top: 2, Left: 6}, Align: shared.StrokeOutside},
+	}
+
+	var children []*layout.LayoutBox
+	for i, s := range strokes {
+		children = append(children, &layout.LayoutBox{
+			X: 40, Y: 40 + float64(i)*120, Width: 300, Height: 100,
+			Node: &shared.Frame{ID: "card", Name: "card", Stroke: s, CornerRadius: 12, Clip: true},
+		})
+	}
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node:     &shared.Frame{ID: "page", Name: "page"},
+				Children: children,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRenderTextStroke(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node: &shared.Frame{ID: "page", Name: "page"},
+				Children: []*layout.LayoutBox{
+					{
+						X: 40, Y: 40, Width: 400, Height: 60,
+						Node: &shared.Text{
+							ID: "t1", Name: "outlined",
+							Content: "Outlined", FontFamily: "MissingFont", FontSize: 48, FontWeight: "700",
+							Fills:  []*shared.Fill{shared.SolidFill("#FFFFFF")},
+							Stroke: shared.SolidStroke("#000000", 2, shared.StrokeOutside),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRenderStrokeInvalidColor(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node: &shared.Frame{ID: "page", Name: "page", Stroke: shared.SolidStroke("$unresolved", 1, shared.StrokeInside)},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err == nil {
+		t.Fatal("expected error for unresolved stroke color")
+	}
+}
+
+func TestRenderGradientFills(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	stops := []shared.ColorStop{{Color: "#FF6B35", Position: 0}, {Color: "#FF6B3500", Position: 1}}
+	fills := []*shared.Fill{
+		shared.GradientFill(shared.FillLinearGradient, stops),
+		shared.GradientFill(shared.FillRadialGradient, stops),
+		shared.GradientFill(shared.FillAngularGradient, stops),
+		shared.GradientFill(shared.FillLinearGradient, stops),
+	}
+
+	var children []*layout.LayoutBox
+	for i, f := range fills {
+		children = append(children, &layout.LayoutBox{
+			X: 40, Y: 40 + float64(i)*120, Width: 300, Height: 100,
+			Node: &shared.Frame{ID: "card", Name: "card", Fills: []*shared.Fill{f}, CornerRadius: 16},
+		})
+	}
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node:     &shared.Frame{ID: "page", Name: "page"},
+				Children: children,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Identical gradients share one imported form XObject
+	if n := bytes.Count(buf.Bytes(), []byte("/Subtype /Form\n/FormType 1")); n != 3 {
+		t.Errorf("expected 3 gradient XObjects, got %d", n)
+	}
+}
+
+func TestRenderGradientInvalidStopColor(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	fill := shared.GradientFill(shared.FillLinearGradient, []shared.ColorStop{{Color: "$brand", Position: 0}})
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node: &shared.Frame{ID: "page", Name: "page", Fills: []*shared.Fill{fill}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err == nil {
+		t.Fatal("expected error for unresolved gradient stop")
+	}
+}
+
+func TestRenderStackedFills(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	disabled := shared.SolidFill("$unresolved")
+	disabled.Enabled = false
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node: &shared.Frame{ID: "page", Name: "page", CornerRadius: 12, Fills: []*shared.Fill{
+					shared.SolidFill("#FFFFFF"),
+					shared.GradientFill(shared.FillLinearGradient, []shared.ColorStop{{Color: "#FF6B35", Position: 0}}),
+					shared.SolidFill("#00000080"),
+					disabled,
+				}},
+				Children: []*layout.LayoutBox{
+					{
+						X: 40, Y: 40, Width: 400, Height: 60,
+						Node: &shared.Text{
+							ID: "t1", Name: "label", Content: "Layered", FontFamily: "MissingFont", FontSize: 24, FontWeight: "400",
+							Fills: []*shared.Fill{
+								shared.SolidFill("#000000"),
+								shared.GradientFill(shared.FillLinearGradient, []shared.ColorStop{{Color: "#FFFFFF", Position: 0}}),
+								shared.SolidFill("#FFFFFF40"),
+								disabled,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRenderEffects(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	effects := [][]*shared.Effect{
+		{shared.DropShadow("#00000040", 0, 4, 12, 2)},
+		{shared.DropShadow("#000000", 4, 4, 0, 0)},
+		{shared.InnerShadow("#00000080", 0, 2, 6, 1)},
+		{shared.InnerShadow("#FFFFFF", 0, 1, 0, 0)},
+		{shared.LayerBlur(8)},
+		{shared.BackgroundBlur(20)},
+	}
+
+	var children []*layout.LayoutBox
+	for i, e := range effects {
+		children = append(children, &layout.LayoutBox{
+			X: 40, Y: 40 + float64(i)*140, Width: 300, Height: 100,
+			Node: &shared.Frame{
+				ID: "card", Name: "card", CornerRadius: 16, Clip: true, Effects: e,
+				Fills: []*shared.Fill{shared.SolidFill("#FFFFFF")},
+			},
+		})
+	}
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node:     &shared.Frame{ID: "page", Name: "page"},
+				Children: children,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/SMask")) {
+		t.Error("expected soft effects to be drawn as alpha-masked images")
+	}
+}
+
+func TestRenderShadowInvalidColor(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node: &shared.Frame{ID: "page", Name: "page", Effects: []*shared.Effect{shared.DropShadow("$shadow", 0, 4, 12, 0)}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err == nil {
+		t.Fatal("expected error for unresolved shadow color")
+	}
+}
+
+func TestRenderShapes(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	gradient := shared.GradientFill(shared.FillRadialGradient, []shared.ColorStop{{Color: "#FF6B35", Position: 0}, {Color: "#004E89", Position: 1}})
+	shapes := []*shared.Shape{
+		{ID: "rect", Type: shared.NodeTypeRectangle, Opacity: 1, CornerRadius: 12, Fills: []*shared.Fill{shared.SolidFill("#FF0000")}, Stroke: shared.SolidStroke("#000000", 2, shared.StrokeInside)},
+		{ID: "ellipse", Type: shared.NodeTypeEllipse, Opacity: 0.5, Fills: []*shared.Fill{shared.SolidFill("#00FF00"), gradient}, Stroke: shared.SolidStroke("#000000", 2, shared.StrokeOutside)},
+		{ID: "line", Type: shared.NodeTypeLine, Opacity: 1, Stroke: &shared.Stroke{Color: "#E0E0E0", Thickness: shared.UniformThickness(1), Dash: []float64{4, 2}}},
+		{ID: "hexagon", Type: shared.NodeTypePolygon, Sides: 6, Opacity: 0.8, Fills: []*shared.Fill{gradient}, Stroke: shared.SolidStroke("#0000FF80", 3, shared.StrokeCenter)},
+		{ID: "hidden", Type: shared.NodeTypeEllipse, Opacity: 0, Fills: []*shared.Fill{shared.SolidFill("$unresolved")}},
+	}
+
+	var children []*layout.LayoutBox
+	for i, s := range shapes {
+		children = append(children, &layout.LayoutBox{
+			X: 40, Y: 40 + float64(i)*120, Width: 300, Height: 100,
+			Node: s,
+		})
+	}
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node:     &shared.Frame{ID: "page", Name: "page"},
+				Children: children,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Both gradients share an XObject but differ in opacity
+	if n := bytes.Count(buf.Bytes(), []byte("/Subtype /Form\n/FormType 1")); n != 2 {
+		t.Errorf("expected 2 gradient XObjects, got %d", n)
+	}
+}
+
+func TestRenderShapeInvalidColor(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node: &shared.Frame{ID: "page", Name: "page"},
+				Children: []*layout.LayoutBox{
+					{
+						X: 40, Y: 40, Width: 100, Height: 100,
+						Node: &shared.Shape{ID: "dot", Type: shared.NodeTypeEllipse, Opacity: 1, Fills: []*shared.Fill{shared.SolidFill("$brand")}},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := r.Render(pages, &buf)
+	if err == nil {
+		t.Fatal("expected error for unresolved shape color")
+	}
+	if !strings.Contains(err.Error(), `ellipse "dot"`) {
+		t.Errorf("expected shape in error, got: %s", err)
+	}
+}
+
+func TestRenderSkipsHiddenNodes(t *testing.T) {
+	// The hidden shape's unresolved color would fail if it were drawn
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node: &shared.Frame{ID: "page", Name: "page"},
+				Children: []*layout.LayoutBox{
+					{
+						X: 40, Y: 40, Width: 100, Height: 100,
+						Node: &shared.Shape{
+							ID: "dot", Type: shared.NodeTypeEllipse, Opacity: 1,
+							Fills:      []*shared.Fill{shared.SolidFill("$brand")},
+							Visibility: shared.Visibility{Hidden: true},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err != nil {
+		t.Fatalf("expected the hidden shape to be skipped, got: %v", err)
+	}
+}
+
+func TestRenderPaths(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	ring, err := shared.ParsePathData("M12 2a10 10 0 1 0 0.01 0ZM12 6a6 6 0 1 1-0.01 0Z")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check, err := shared.ParsePathData("M4 12l5 5L20 6")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gradient := shared.GradientFill(shared.FillLinearGradient, []shared.ColorStop{{Color: "#FF6B35", Position: 0}, {Color: "#FF6B3500", Position: 1}})
+
+	paths := []*shared.Path{
+		{ID: "ring", Geometry: ring, FillRule: shared.FillRuleEvenOdd, Opacity: 1, Fills: []*shared.Fill{shared.SolidFill("#333333"), gradient}},
+		{ID: "check", Geometry: check, Opacity: 0.5, Stroke: &shared.Stroke{Color: "#00AA00", Thickness: shared.UniformThickness(2), Align: shared.StrokeCenter, Dash: []float64{2, 1}}},
+		{ID: "outlined", Geometry: ring, Opacity: 1, Fills: []*shared.Fill{shared.SolidFill("#FFFFFF80")}, Stroke: shared.SolidStroke("#000000", 1, shared.StrokeOutside)},
+		{ID: "inset", Geometry: ring, Opacity: 1, Stroke: shared.SolidStroke("#000000", 1, shared.StrokeInside)},
+	}
+
+	var children []*layout.LayoutBox
+	for i, p := range paths {
+		children = append(children, &layout.LayoutBox{
+			X: 40, Y: 40 + float64(i)*60, Width: 48, Height: 48,
+			Node: p,
+		})
+	}
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node:     &shared.Frame{ID: "page", Name: "page"},
+				Children: children,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := bytes.Count(buf.Bytes(), []byte("/Subtype /Form\n/FormType 1")); n != len(paths) {
+		t.Errorf("expected %d path XObjects, got %d", len(paths), n)
+	}
+}
+
+func TestRenderPathInvalidColor(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(nil, nil)
+	geometry, err := shared.ParsePathData("M0 0 L10 10")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node: &shared.Frame{ID: "page", Name: "page"},
+				Children: []*layout.LayoutBox{
+					{
+						X: 40, Y: 40, Width: 24, Height: 24,
+						Node: &shared.Path{ID: "icon", Geometry: geometry, Opacity: 1, Stroke: shared.SolidStroke("$icon", 1, shared.StrokeCenter)},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	err = r.Render(pages, &buf)
+	if err == nil {
+		t.Fatal("expected error for unresolved path color")
+	}
+	if !strings.Contains(err.Error(), `path "icon"`) {
+		t.Errorf("expected path in error, got: %s", err)
+	}
+}
+
+// pngImageLoader serves generated PNG images of the given pixel sizes.
+type pngImageLoader map[string][2]int
+
+func (l pngImageLoader) LoadImage(path string) (*asset.ImageData, error) {
+	size, ok := l[path]
+	if !ok {
+		return nil, fmt.Errorf("image %q not found", path)
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, size[0], size[1]))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	img.SetNRGBA(0, 0, color.NRGBA{R: 0xFF, A: 0xFF})
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return &asset.ImageData{Path: path, Width: size[0], Height: size[1], Data: buf.Bytes()}, nil
+}
+
+func TestRenderImageNodes(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(pngImageLoader{"photo.png": {40, 20}, "pattern.png": {8, 8}}, nil)
+	images := []*shared.Image{
+		{ID: "cover", URL: "photo.png", Opacity: 1, CornerRadius: 8},
+		{ID: "fit", URL: "photo.png", Mode: shared.ImageModeFit, Opacity: 0.5, Stroke: shared.SolidStroke("#000000", 1, shared.StrokeInside)},
+		{ID: "tile", URL: "pattern.png", Mode: shared.ImageModeTile, Scale: 2, Opacity: 1},
+		{ID: "crop", URL: "photo.png", Mode: shared.ImageModeCrop, Offset: shared.ImageOffset{X: -10, Y: -5}, Opacity: 1},
+		{ID: "missing", URL: "missing.png", Opacity: 1},
+		{ID: "hidden", URL: "photo.png", Opacity: 0, Stroke: shared.SolidStroke("$unresolved", 1, shared.StrokeInside)},
+	}
+
+	var children []*layout.LayoutBox
+	for i, img := range images {
+		children = append(children, &layout.LayoutBox{
+			X: 40, Y: 40 + float64(i)*120, Width: 300, Height: 100,
+			Node: img,
+		})
+	}
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node:     &shared.Frame{ID: "page", Name: "page"},
+				Children: children,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Each image is embedded once, however often it is drawn
+	if n := bytes.Count(buf.Bytes(), []byte("/Subtype /Image")); n != 2 {
+		t.Errorf("expected 2 embedded images, got %d", n)
+	}
+}
+
+func TestRenderImageInvalidStrokeColor(t *testing.T) {
+	r := infrastructure.NewPDFRenderer(pngImageLoader{"photo.png": {40, 20}}, nil)
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node: &shared.Frame{ID: "page", Name: "page"},
+				Children: []*layout.LayoutBox{{
+					X: 10, Y: 10, Width: 100, Height: 100,
+					Node: &shared.Image{ID: "logo", URL: "photo.png", Opacity: 1, Stroke: shared.SolidStroke("$border", 1, shared.StrokeInside)},
+				}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := r.Render(pages, &buf)
+	if err == nil {
+		t.Fatal("expected error for unresolved stroke color")
+	}
+	if !strings.Contains(err.Error(), `image "logo"`) {
+		t.Errorf("expected image ID in error, got: %s", err)
+	}
+}
+
+// vectorImageLoader serves one vector image.
+type vectorImageLoader struct {
+	img *asset.VectorImage
+}
+
+func (l *vectorImageLoader) LoadImage(path string) (*asset.ImageData, error) {
+	return &asset.ImageData{Path: path, Width: int(l.img.Width), Height: int(l.img.Height), Vector: l.img}, nil
+}
+
+func TestRenderVectorImage(t *testing.T) {
+	geometry, err := shared.ParsePathData("M0 0 H16 V16 Z")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loader := &vectorImageLoader{img: &asset.VectorImage{
+		Width: 16, Height: 16,
+		Shapes: []asset.VectorShape{{Geometry: geometry, FillRule: shared.FillRuleNonZero, Fill: shared.SolidFill("#0A84FFFF")}},
+	}}
+	r := infrastructure.NewPDFRenderer(loader, nil)
+
+	pages := []layout.Page{
+		{
+			Width: 800, Height: 1000,
+			Root: &layout.LayoutBox{
+				X: 0, Y: 0, Width: 800, Height: 1000,
+				Node: &shared.Frame{ID: "page", Name: "page", Fills: []*shared.Fill{shared.ImageFill("icon.svg", shared.ImageModeTile, 0, true)}},
+				Children: []*layout.LayoutBox{{
+					X: 40, Y: 40, Width: 160, Height: 80,
+					Node: &shared.Image{ID: "logo", URL: "logo.svg", Mode: shared.ImageModeFit, Opacity: 0.5},
+				}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(pages, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("/Subtype /Image")) {
+		t.Error("expected vector images not to be rasterized")
+	}
+	// One XObject for the tiles and one for the faded logo
+	if n := bytes.Count(buf.Bytes(), []byte("/Subtype /Form\n/FormType 1")); n != 2 {
+		t.Errorf("expected 2 vector image XObjects, got %d", n)
+	}
+}
@@ -0,0 +1,350 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/signintech/gopdf"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+const (
+	// angularSegments is the number of triangles in the mesh approximating an
+	// angular gradient, since PDF has no native conic shading.
+	angularSegments = 180
+
+	// angularRadius is the mesh radius in unit-square coordinates; large
+	// enough to cover the box from any center inside it.
+	angularRadius = 2.0
+
+	// minGradientExtent keeps degenerate gradient geometry invertible.
+	minGradientExtent = 1e-3
+)
+
+// drawGradient paints a gradient fill as a native PDF shading clipped to the
+// box outline. gopdf has no API for shading patterns, so the shading is built
+// as a one-page PDF over the unit square and imported as a form XObject that
+// is scaled onto the box.
+func (r *PDFRenderer) drawGradient(pdf *gopdf.GoPdf, x, y, w, h float64, fill *shared.Fill, radius float64) error {
+	if w <= 0 || h <= 0 || len(fill.Stops) == 0 {
+		return nil
+	}
+
+	data, err := gradientPDF(fill)
+	if err != nil {
+		return err
+	}
+	tpl, err := r.importTemplate(pdf, data)
+	if err != nil {
+		return fmt.Errorf("import gradient: %w", err)
+	}
+
+	pdf.SaveGraphicsState()
+	pdf.ClipPolygon(roundedRectPolygon(x, y, w, h, radius))
+	pdf.UseImportedTemplate(tpl, x, y, w, h)
+	pdf.RestoreGraphicsState()
+	return nil
+}
+
+// importTemplate imports the first page of an in-memory PDF and returns its
+// template ID. Identical documents are imported once per render.
+func (r *PDFRenderer) importTemplate(pdf *gopdf.GoPdf, data []byte) (tpl int, err error) {
+	key := string(data)
+	if id, ok := r.templates[key]; ok {
+		return id, nil
+	}
+
+	// gofpdi panics on malformed input instead of returning errors
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%v", rec)
+		}
+	}()
+
+	// gofpdi identifies sources by the address of the reader, so every
+	// reader is kept alive until the render finishes.
+	rs := io.ReadSeeker(bytes.NewReader(data))
+	r.templateSources = append(r.templateSources, &rs)
+	tpl = pdf.ImportPageStream(&rs, 1, "/MediaBox")
+	r.templates[key] = tpl
+	return tpl, nil
+}
+
+// gradientStop is a parsed color stop.
+type gradientStop struct {
+	pos   float64
+	color shared.RGBA
+}
+
+// gradientPDF returns a one-page PDF whose 1×1 media box is painted with the
+// gradient. Transparent stops are honored with a luminosity soft mask built
+// from the same geometry.
+func gradientPDF(fill *shared.Fill) ([]byte, error) {
+	stops, err := parseGradientStops(fill)
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := gradientMatrix(fill)
+	opaque := true
+	for _, s := range stops {
+		if s.color.A < 1 {
+			opaque = false
+		}
+	}
+
+	var doc pdfBuilder
+	doc.add("<< /Type /Catalog /Pages 2 0 R >>")
+	doc.add("<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+
+	resources := "/Shading << /Sh0 5 0 R >>"
+	content := "q 1 0 0 -1 0 1 cm " + matrix + " cm /Sh0 sh Q"
+	if !opaque {
+		resources += " /ExtGState << /GS0 6 0 R >>"
+		content = "q /GS0 gs 1 0 0 -1 0 1 cm " + matrix + " cm /Sh0 sh Q"
+	}
+	doc.add("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 1 1] /Resources << " + resources + " >> /Contents 4 0 R >>")
+	doc.addStream("", []byte(content))
+
+	colorShading := gradientShading(fill.Type, stops, "/DeviceRGB", func(c shared.RGBA) []float64 {
+		return []float64{float64(c.R) / 255, float64(c.G) / 255, float64(c.B) / 255}
+	})
+	doc.addShading(colorShading)
+
+	if !opaque {
+		doc.add("<< /Type /ExtGState /SMask << /Type /Mask /S /Luminosity /G 7 0 R >> >>")
+		mask := "1 0 0 -1 0 1 cm " + matrix + " cm /Sh0 sh"
+		doc.addStream("/Type /XObject /Subtype /Form /BBox [0 0 1 1] /Group << /S /Transparency /CS /DeviceGray >> /Resources << /Shading << /Sh0 8 0 R >> >>", []byte(mask))
+		alphaShading := gradientShading(fill.Type, stops, "/DeviceGray", func(c shared.RGBA) []float64 {
+			return []float64{c.A}
+		})
+		doc.addShading(alphaShading)
+	}
+
+	return doc.bytes(), nil
+}
+
+// parseGradientStops parses the stop colors, applies the fill opacity and
+// pads the stops so they cover positions 0 to 1.
+func parseGradientStops(fill *shared.Fill) ([]gradientStop, error) {
+	opacity := 1.0
+	if fill.Opacity > 0 && fill.Opacity < 1 {
+		opacity = fill.Opacity
+	}
+
+	stops := make([]gradientStop, 0, len(fill.Stops)+2)
+	for i, s := range fill.Stops {
+		rgba, err := shared.ParseHexColor(s.Color)
+		if err != nil {
+			return nil, fmt.Errorf("color stop %d: %w", i, err)
+		}
+		rgba.A *= opacity
+		stops = append(stops, gradientStop{pos: math.Min(math.Max(s.Position, 0), 1), color: rgba})
+	}
+
+	if first := stops[0]; first.pos > 0 {
+		stops = append([]gradientStop{{pos: 0, color: first.color}}, stops...)
+	}
+	if last := stops[len(stops)-1]; last.pos < 1 {
+		stops = append(stops, gradientStop{pos: 1, color: last.color})
+	}
+	return stops, nil
+}
+
+// gradientMatrix maps the shading space onto the unit square (y pointing
+// down): a translation to the gradient center, the clockwise rotation and a
+// scale by the gradient size. Shadings are drawn around the origin, with the
+// linear axis running from (0,-0.5) to (0,0.5) and the radial gradient as a
+// unit circle.
+func gradientMatrix(fill *shared.Fill) string {
+	theta := fill.Rotation * math.Pi / 180
+	cos, sin := math.Cos(theta), math.Sin(theta)
+
+	sx, sy := 1.0, 1.0
+	switch fill.Type {
+	case shared.FillLinearGradient:
+		sy = math.Max(fill.Size.Height, minGradientExtent)
+	case shared.FillRadialGradient:
+		sx = math.Max(fill.Size.Width, minGradientExtent) / 2
+		sy = math.Max(fill.Size.Height, minGradientExtent) / 2
+	}
+	return pdfNumbers(cos*sx, sin*sx, -sin*sy, cos*sy, fill.Center.X, fill.Center.Y)
+}
+
+// shading is a shading object: its dictionary entries and, for mesh
+// shadings, the stream data.
+type shading struct {
+	dict string
+	data []byte
+}
+
+// gradientShading returns the shading for the fill type, with stop colors
+// converted to the color space by comps.
+func gradientShading(fillType shared.FillType, stops []gradientStop, colorSpace string, comps func(shared.RGBA) []float64) shading {
+	switch fillType {
+	case shared.FillRadialGradient:
+		return shading{dict: fmt.Sprintf("/ShadingType 3 /ColorSpace %s /Coords [0 0 0 0 0 1] /Function %s /Extend [true true]",
+			colorSpace, stopsFunction(stops, comps))}
+	case shared.FillAngularGradient:
+		return angularShading(stops, colorSpace, comps)
+	default:
+		return shading{dict: fmt.Sprintf("/ShadingType 2 /ColorSpace %s /Coords [0 -0.5 0 0.5] /Function %s /Extend [true true]",
+			colorSpace, stopsFunction(stops, comps))}
+	}
+}
+
+// stopsFunction returns a PDF function mapping [0, 1] to the stop colors:
+// an exponential interpolation for two stops, or a stitching function with
+// one interpolation per pair of adjacent stops.
+func stopsFunction(stops []gradientStop, comps func(shared.RGBA) []float64) string {
+	interpolate := func(a, b gradientStop) string {
+		return fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1 >>",
+			pdfNumbers(comps(a.color)...), pdfNumbers(comps(b.color)...))
+	}
+	if len(stops) == 2 {
+		return interpolate(stops[0], stops[1])
+	}
+
+	var functions, bounds, encode []string
+	for i := 0; i+1 < len(stops); i++ {
+		functions = append(functions, interpolate(stops[i], stops[i+1]))
+		encode = append(encode, "0 1")
+		if i > 0 {
+			bounds = append(bounds, pdfNumbers(stops[i].pos))
+		}
+	}
+	return fmt.Sprintf("<< /FunctionType 3 /Domain [0 1] /Functions [%s] /Bounds [%s] /Encode [%s] >>",
+		strings.Join(functions, " "), strings.Join(bounds, " "), strings.Join(encode, " "))
+}
+
+// angularShading approximates a conic gradient with a free-form triangle
+// mesh (shading type 4): a fan of thin triangles around the origin, starting
+// straight up and turning clockwise.
+func angularShading(stops []gradientStop, colorSpace string, comps func(shared.RGBA) []float64) shading {
+	n := len(comps(stops[0].color))
+	decode := []float64{-angularRadius, angularRadius, -angularRadius, angularRadius}
+	for range n {
+		decode = append(decode, 0, 1)
+	}
+
+	var data bytes.Buffer
+	vertex := func(x, y float64, t float64) {
+		data.WriteByte(0) // edge flag: every triangle is independent
+		_ = binary.Write(&data, binary.BigEndian, meshCoordinate(x))
+		_ = binary.Write(&data, binary.BigEndian, meshCoordinate(y))
+		for _, c := range comps(colorAt(stops, t)) {
+			data.WriteByte(uint8(math.Round(math.Min(math.Max(c, 0), 1) * 255)))
+		}
+	}
+	point := func(t float64) (float64, float64) {
+		a := t * 2 * math.Pi
+		return math.Sin(a) * angularRadius, -math.Cos(a) * angularRadius
+	}
+
+	for i := range angularSegments {
+		t0 := float64(i) / angularSegments
+		t1 := float64(i+1) / angularSegments
+		x0, y0 := point(t0)
+		x1, y1 := point(t1)
+		vertex(0, 0, (t0+t1)/2)
+		vertex(x0, y0, t0)
+		vertex(x1, y1, t1)
+	}
+
+	return shading{
+		dict: fmt.Sprintf("/ShadingType 4 /ColorSpace %s /BitsPerCoordinate 16 /BitsPerComponent 8 /BitsPerFlag 8 /Decode [%s]",
+			colorSpace, pdfNumbers(decode...)),
+		data: data.Bytes(),
+	}
+}
+
+// meshCoordinate encodes a mesh coordinate in [-angularRadius, angularRadius]
+// as a 16-bit value.
+func meshCoordinate(v float64) uint16 {
+	return uint16(math.Round((v + angularRadius) / (2 * angularRadius) * math.MaxUint16))
+}
+
+// colorAt interpolates the stop colors at position t.
+func colorAt(stops []gradientStop, t float64) shared.RGBA {
+	for i := 0; i+1 < len(stops); i++ {
+		a, b := stops[i], stops[i+1]
+		if t > b.pos {
+			continue
+		}
+		f := 0.0
+		if b.pos > a.pos {
+			f = (t - a.pos) / (b.pos - a.pos)
+		}
+		lerp := func(x, y uint8) uint8 {
+			return uint8(math.Round(float64(x) + (float64(y)-float64(x))*f))
+		}
+		return shared.RGBA{
+			R: lerp(a.color.R, b.color.R),
+			G: lerp(a.color.G, b.color.G),
+			B: lerp(a.color.B, b.color.B),
+			A: a.color.A + (b.color.A-a.color.A)*f,
+		}
+	}
+	return stops[len(stops)-1].color
+}
+
+// pdfNumbers formats numbers for a PDF content stream or dictionary.
+func pdfNumbers(values ...float64) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strings.Join(parts, " ")
+}
+
+// pdfBuilder writes a minimal PDF file: numbered objects followed by a
+// cross-reference table.
+type pdfBuilder struct {
+	objects [][]byte
+}
+
+func (b *pdfBuilder) add(obj string) {
+	b.objects = append(b.objects, []byte(obj))
+}
+
+func (b *pdfBuilder) addStream(dict string, data []byte) {
+	var obj bytes.Buffer
+	fmt.Fprintf(&obj, "<< %s /Length %d >>\nstream\n", dict, len(data))
+	obj.Write(data)
+	obj.WriteString("\nendstream")
+	b.objects = append(b.objects, obj.Bytes())
+}
+
+func (b *pdfBuilder) addShading(s shading) {
+	if s.data != nil {
+		b.addStream(s.dict, s.data)
+		return
+	}
+	b.add("<< " + s.dict + " >>")
+}
+
+func (b *pdfBuilder) bytes() []byte {
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(b.objects))
+	for i, obj := range b.objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n", i+1)
+		out.Write(obj)
+		out.WriteString("\nendobj\n")
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(b.objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(b.objects)+1, xref)
+	return out.Bytes()
+}
@@ -0,0 +1,135 @@
+package infrastructure
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func twoStops(from, to string) []shared.ColorStop {
+	return []shared.ColorStop{{Color: from, Position: 0}, {Color: to, Position: 1}}
+}
+
+func TestParseGradientStopsPadsEnds(t *testing.T) {
+	fill := shared.GradientFill(shared.FillLinearGradient, []shared.ColorStop{
+		{Color: "#FF0000", Position: 0.25},
+		{Color: "#0000FF", Position: 0.75},
+	})
+	stops, err := parseGradientStops(fill)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stops) != 4 {
+		t.Fatalf("expected 4 stops after padding, got %d", len(stops))
+	}
+	if stops[0].pos != 0 || stops[0].color.R != 255 {
+		t.Errorf("expected first color repeated at 0, got %+v", stops[0])
+	}
+	if stops[3].pos != 1 || stops[3].color.B != 255 {
+		t.Errorf("expected last color repeated at 1, got %+v", stops[3])
+	}
+}
+
+func TestParseGradientStopsAppliesOpacity(t *testing.T) {
+	fill := shared.GradientFill(shared.FillLinearGradient, twoStops("#000000", "#00000080"))
+	fill.Opacity = 0.5
+	stops, err := parseGradientStops(fill)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stops[0].color.A != 0.5 {
+		t.Errorf("expected alpha 0.5, got %f", stops[0].color.A)
+	}
+	if stops[1].color.A > 0.26 || stops[1].color.A < 0.24 {
+		t.Errorf("expected alpha ~0.25, got %f", stops[1].color.A)
+	}
+}
+
+func TestParseGradientStopsInvalidColor(t *testing.T) {
+	fill := shared.GradientFill(shared.FillLinearGradient, twoStops("#000000", "$unresolved"))
+	_, err := parseGradientStops(fill)
+	if err == nil || !strings.Contains(err.Error(), "color stop 1") {
+		t.Errorf("expected color stop error, got %v", err)
+	}
+}
+
+func TestColorAtInterpolates(t *testing.T) {
+	stops := []gradientStop{
+		{pos: 0, color: shared.RGBA{R: 0, A: 0}},
+		{pos: 0.5, color: shared.RGBA{R: 200, A: 1}},
+		{pos: 1, color: shared.RGBA{R: 100, A: 1}},
+	}
+	if c := colorAt(stops, 0.25); c.R != 100 || c.A != 0.5 {
+		t.Errorf("expected (100, 0.5) at 0.25, got (%d, %f)", c.R, c.A)
+	}
+	if c := colorAt(stops, 0.75); c.R != 150 {
+		t.Errorf("expected 150 at 0.75, got %d", c.R)
+	}
+	if c := colorAt(stops, 1); c.R != 100 {
+		t.Errorf("expected 100 at 1, got %d", c.R)
+	}
+}
+
+func TestGradientPDFShadingTypes(t *testing.T) {
+	tests := map[shared.FillType]string{
+		shared.FillLinearGradient:  "/ShadingType 2",
+		shared.FillRadialGradient:  "/ShadingType 3",
+		shared.FillAngularGradient: "/ShadingType 4",
+	}
+	for fillType, want := range tests {
+		data, err := gradientPDF(shared.GradientFill(fillType, twoStops("#FF0000", "#0000FF")))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", fillType, err)
+		}
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("%s: expected %q in shading", fillType, want)
+		}
+		if bytes.Contains(data, []byte("/SMask")) {
+			t.Errorf("%s: expected no soft mask for opaque stops", fillType)
+		}
+	}
+}
+
+func TestGradientPDFSoftMaskForAlphaStops(t *testing.T) {
+	data, err := gradientPDF(shared.GradientFill(shared.FillLinearGradient, twoStops("#FF0000", "#FF000000")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"/SMask", "/Luminosity", "/DeviceGray", "/GS0 gs"} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("expected %q in gradient PDF", want)
+		}
+	}
+}
+
+func TestGradientPDFStitchesMultipleStops(t *testing.T) {
+	fill := shared.GradientFill(shared.FillLinearGradient, []shared.ColorStop{
+		{Color: "#FF0000", Position: 0},
+		{Color: "#00FF00", Position: 0.3},
+		{Color: "#0000FF", Position: 1},
+	})
+	data, err := gradientPDF(fill)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(data, []byte("/FunctionType 3")) || !bytes.Contains(data, []byte("/Bounds [0.3]")) {
+		t.Errorf("expected stitching function with bound 0.3, got %s", data)
+	}
+}
+
+func TestGradientMatrixRadialScalesBySize(t *testing.T) {
+	fill := shared.GradientFill(shared.FillRadialGradient, nil)
+	fill.Center = shared.GradientPoint{X: 0.25, Y: 0.75}
+	fill.Size = shared.GradientSize{Width: 1, Height: 0.5}
+	if got := gradientMatrix(fill); got != "0.5 0 -0 0.25 0.25 0.75" {
+		t.Errorf("unexpected matrix %q", got)
+	}
+}
+
+func TestMeshCoordinateRange(t *testing.T) {
+	if meshCoordinate(-angularRadius) != 0 || meshCoordinate(angularRadius) != 65535 {
+		t.Error("expected radius to map to the full 16-bit range")
+	}
+}
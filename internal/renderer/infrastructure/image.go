@@ -0,0 +1,198 @@
+package infrastructure
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/signintech/gopdf"
+	asset "github.com/vpedrosa/pen2pdf/internal/asset/domain"
+	layout "github.com/vpedrosa/pen2pdf/internal/layout/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// maxImageTiles caps the number of copies drawn by a tiled image fill.
+const maxImageTiles = 10_000
+
+// renderImage paints an image node like a frame with a single image fill,
+// then its stroke. The node opacity applies to the image and the stroke.
+func (r *PDFRenderer) renderImage(pdf *gopdf.GoPdf, box *layout.LayoutBox, image *shared.Image) error {
+	if image.Opacity <= 0 {
+		return nil
+	}
+	if err := r.drawImage(pdf, box.X, box.Y, box.Width, box.Height, image.Fill(), image.CornerRadius); err != nil {
+		return err
+	}
+	stroke, err := fadeStroke(image.Stroke, image.Opacity)
+	if err != nil {
+		return fmt.Errorf("stroke: %w", err)
+	}
+	return r.drawStroke(pdf, box.X, box.Y, box.Width, box.Height, image.CornerRadius, stroke)
+}
+
+// drawImage paints an image fill over a box according to its mode, clipped
+// to the box's (possibly rounded) outline. Vector images are drawn as native
+// PDF content.
+func (r *PDFRenderer) drawImage(pdf *gopdf.GoPdf, x, y, w, h float64, fill *shared.Fill, radius float64) error {
+	if r.imageLoader == nil || w <= 0 || h <= 0 {
+		return nil
+	}
+
+	imgData, err := r.imageLoader.LoadImage(fill.URL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: image %q not found, skipping\n", fill.URL)
+		return nil
+	}
+	imgW, imgH := float64(imgData.Width), float64(imgData.Height)
+	if imgData.Vector != nil {
+		imgW, imgH = imgData.Vector.Width, imgData.Vector.Height
+	}
+	if imgW <= 0 || imgH <= 0 {
+		return nil
+	}
+
+	box := outlineRect{x: x, y: y, w: w, h: h}
+	placements := imagePlacements(box, imgW, imgH, fill)
+	if len(placements) == 0 {
+		return nil
+	}
+	if fill.Mode == shared.ImageModeTile && len(placements) == maxImageTiles && !r.warned["image-tiles"] {
+		fmt.Fprintf(os.Stderr, "warning: tiled image %q needs more than %d tiles, drawing the first ones\n", fill.URL, maxImageTiles)
+		r.warned["image-tiles"] = true
+	}
+
+	if imgData.Vector != nil {
+		return r.drawVectorImage(pdf, box, radius, imgData.Vector, fill, placements)
+	}
+
+	imgHolder, err := gopdf.ImageHolderByBytes(imgData.Data)
+	if err != nil {
+		return fmt.Errorf("create image holder: %w", err)
+	}
+
+	if fill.Opacity > 0 && fill.Opacity < 1.0 {
+		if err := pdf.SetTransparency(gopdf.Transparency{Alpha: fill.Opacity, BlendModeType: gopdf.NormalBlendMode}); err != nil {
+			return err
+		}
+	}
+
+	pdf.SaveGraphicsState()
+	pdf.ClipPolygon(roundedRectPolygon(x, y, w, h, radius))
+	for _, p := range placements {
+		if err := pdf.ImageByHolderWithOptions(imgHolder, gopdf.ImageOptions{
+			X:    p.x,
+			Y:    p.y,
+			Rect: &gopdf.Rect{W: p.w, H: p.h},
+		}); err != nil {
+			pdf.RestoreGraphicsState()
+			return fmt.Errorf("draw image: %w", err)
+		}
+	}
+	pdf.RestoreGraphicsState()
+
+	if fill.Opacity > 0 && fill.Opacity < 1.0 {
+		if err := pdf.SetTransparency(gopdf.Transparency{Alpha: 1.0, BlendModeType: gopdf.NormalBlendMode}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// drawVectorImage draws each placement of a vector image. Like paths, the
+// image is drawn into a one-page PDF imported as a form XObject; the fill
+// opacity is applied to its shapes.
+func (r *PDFRenderer) drawVectorImage(pdf *gopdf.GoPdf, box outlineRect, radius float64, img *asset.VectorImage, fill *shared.Fill, placements []outlineRect) error {
+	opacity := 1.0
+	if fill.Opacity > 0 && fill.Opacity < 1 {
+		opacity = fill.Opacity
+	}
+	data, err := vectorImagePDF(img, opacity)
+	if err != nil {
+		return fmt.Errorf("image %q: %w", fill.URL, err)
+	}
+	tpl, err := r.importTemplate(pdf, data)
+	if err != nil {
+		return fmt.Errorf("import image %q: %w", fill.URL, err)
+	}
+
+	pdf.SaveGraphicsState()
+	pdf.ClipPolygon(roundedRectPolygon(box.x, box.y, box.w, box.h, radius))
+	for _, p := range placements {
+		pdf.UseImportedTemplate(tpl, p.x, p.y, p.w, p.h)
+	}
+	pdf.RestoreGraphicsState()
+	return nil
+}
+
+// vectorImagePDF returns a one-page PDF the size of a vector image with its
+// shapes painted from bottom to top, faded by opacity.
+func vectorImagePDF(img *asset.VectorImage, opacity float64) ([]byte, error) {
+	res := newPathResources()
+	var content strings.Builder
+	content.WriteString(pdfNumbers(1, 0, 0, -1, 0, img.Height) + " cm\n")
+
+	page := outlineRect{w: img.Width, h: img.Height}
+	for i, shape := range img.Shapes {
+		if shape.Geometry == nil || len(shape.Geometry.Segments) == 0 {
+			continue
+		}
+		stroke, err := fadeStroke(shape.Stroke, opacity)
+		if err != nil {
+			return nil, fmt.Errorf("shape %d stroke: %w", i, err)
+		}
+		var fills []*shared.Fill
+		if shape.Fill != nil {
+			fill, err := fadeFill(shape.Fill, opacity)
+			if err != nil {
+				return nil, fmt.Errorf("shape %d fill: %w", i, err)
+			}
+			fills = append(fills, fill)
+		}
+
+		b := shape.Geometry.Bounds()
+		bounds := outlineRect{x: b.MinX, y: b.MinY, w: b.Width(), h: b.Height()}
+		if err := writePathPaint(&content, res, pathOperators(shape.Geometry), shape.FillRule, fills, stroke, bounds, page); err != nil {
+			return nil, fmt.Errorf("shape %d: %w", i, err)
+		}
+	}
+	return pagePDF(img.Width, img.Height, res, content.String()), nil
+}
+
+// imagePlacements returns where copies of an imgW×imgH image are drawn to
+// fill a box in the mode of the fill. Only tiles have more than one copy;
+// tiles entirely outside the box are left out.
+func imagePlacements(box outlineRect, imgW, imgH float64, fill *shared.Fill) []outlineRect {
+	switch fill.Mode {
+	case shared.ImageModeFit:
+		scale := math.Min(box.w/imgW, box.h/imgH)
+		return []outlineRect{centeredRect(box, imgW*scale, imgH*scale)}
+	case shared.ImageModeStretch:
+		return []outlineRect{box}
+	case shared.ImageModeCrop:
+		scale := fill.ImageScale()
+		return []outlineRect{{x: box.x + fill.Offset.X, y: box.y + fill.Offset.Y, w: imgW * scale, h: imgH * scale}}
+	case shared.ImageModeTile:
+		scale := fill.ImageScale()
+		tileW, tileH := imgW*scale, imgH*scale
+		var tiles []outlineRect
+		for ty := box.y; ty < box.y+box.h; ty += tileH {
+			for tx := box.x; tx < box.x+box.w; tx += tileW {
+				if len(tiles) == maxImageTiles {
+					return tiles
+				}
+				tiles = append(tiles, outlineRect{x: tx, y: ty, w: tileW, h: tileH})
+			}
+		}
+		return tiles
+	default: // fill: cover the box
+		scale := math.Max(box.w/imgW, box.h/imgH)
+		return []outlineRect{centeredRect(box, imgW*scale, imgH*scale)}
+	}
+}
+
+// centeredRect returns a w×h rectangle centered on a box.
+func centeredRect(box outlineRect, w, h float64) outlineRect {
+	return outlineRect{x: box.x + (box.w-w)/2, y: box.y + (box.h-h)/2, w: w, h: h}
+}
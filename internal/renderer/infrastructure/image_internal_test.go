@@ -0,0 +1,89 @@
+package infrastructure
+
+import (
+	"bytes"
+	"testing"
+
+	asset "github.com/vpedrosa/pen2pdf/internal/asset/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestImagePlacementsModes(t *testing.T) {
+	box := outlineRect{x: 10, y: 20, w: 200, h: 100}
+	crop := shared.ImageFill("a.png", shared.ImageModeCrop, 0, true)
+	crop.Offset = shared.ImageOffset{X: -30, Y: 5}
+	crop.Scale = 0.5
+
+	tests := map[string]struct {
+		fill *shared.Fill
+		want outlineRect
+	}{
+		"fill":    {shared.ImageFill("a.png", "", 0, true), outlineRect{x: 10, y: -30, w: 200, h: 200}},
+		"fit":     {shared.ImageFill("a.png", shared.ImageModeFit, 0, true), outlineRect{x: 60, y: 20, w: 100, h: 100}},
+		"stretch": {shared.ImageFill("a.png", shared.ImageModeStretch, 0, true), box},
+		"crop":    {crop, outlineRect{x: -20, y: 25, w: 25, h: 25}},
+	}
+	for name, tt := range tests {
+		got := imagePlacements(box, 50, 50, tt.fill)
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("%s: expected %+v, got %+v", name, tt.want, got)
+		}
+	}
+}
+
+func TestImagePlacementsTile(t *testing.T) {
+	box := outlineRect{x: 0, y: 0, w: 100, h: 50}
+	fill := shared.ImageFill("a.png", shared.ImageModeTile, 0, true)
+	fill.Scale = 0.5
+
+	// 40×40 pixels at half scale: 5 columns by 3 rows, the last ones cut off
+	tiles := imagePlacements(box, 40, 40, fill)
+	if len(tiles) != 15 {
+		t.Fatalf("expected 15 tiles, got %d", len(tiles))
+	}
+	if last := tiles[14]; last != (outlineRect{x: 80, y: 40, w: 20, h: 20}) {
+		t.Errorf("unexpected last tile %+v", last)
+	}
+}
+
+func TestImagePlacementsTileLimit(t *testing.T) {
+	box := outlineRect{w: 1000, h: 1000}
+	tiles := imagePlacements(box, 1, 1, shared.ImageFill("a.png", shared.ImageModeTile, 0, true))
+	if len(tiles) != maxImageTiles {
+		t.Errorf("expected %d tiles, got %d", maxImageTiles, len(tiles))
+	}
+}
+
+func TestVectorImagePDF(t *testing.T) {
+	square, err := shared.ParsePathData("M10 10 H30 V20 H10 Z")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gradient := shared.GradientFill(shared.FillLinearGradient, []shared.ColorStop{{Color: "#FFFFFF", Position: 0}, {Color: "#000000", Position: 1}})
+	img := &asset.VectorImage{
+		Width:  40,
+		Height: 30,
+		Shapes: []asset.VectorShape{
+			{Geometry: square, FillRule: shared.FillRuleEvenOdd, Fill: shared.SolidFill("#FF0000FF")},
+			{Geometry: square, Fill: gradient, Stroke: shared.SolidStroke("#000000FF", 1, shared.StrokeCenter)},
+		},
+	}
+
+	data, err := vectorImagePDF(img, 0.5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"/MediaBox [0 0 40 30]",
+		"1 0 0 -1 0 30 cm",
+		"h f* Q",
+		// Gradients cover the bounds of their shape
+		"h W n 20 0 0 10 10 10 cm",
+		"/ca 0.50196",
+		"0 0 0 RG 1 w",
+	} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("expected %q in:\n%s", want, data)
+		}
+	}
+}
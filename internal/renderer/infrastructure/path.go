@@ -0,0 +1,269 @@
+package infrastructure
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/signintech/gopdf"
+	layout "github.com/vpedrosa/pen2pdf/internal/layout/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// pathMiterLimit bounds how far miter joins reach past a path, in multiples
+// of half the line width.
+const pathMiterLimit = 4
+
+// renderPath paints a path node with native PDF path operators, so curves
+// and fill rules stay exact. gopdf only builds straight-edged polygons, so
+// the path is drawn into a one-page PDF that is imported as a form XObject,
+// like gradients are.
+func (r *PDFRenderer) renderPath(pdf *gopdf.GoPdf, box *layout.LayoutBox, path *shared.Path) error {
+	if path.Opacity <= 0 || path.Geometry == nil || len(path.Geometry.Segments) == 0 {
+		return nil
+	}
+
+	stroke, err := fadeStroke(path.Stroke, path.Opacity)
+	if err != nil {
+		return fmt.Errorf("stroke: %w", err)
+	}
+
+	var fills []*shared.Fill
+	for _, fill := range path.Fills {
+		if !fill.Enabled {
+			continue
+		}
+		if fill.Type == shared.FillImage {
+			if !r.warned["path-fill-image"] {
+				fmt.Fprintf(os.Stderr, "warning: image fills are not supported on paths, skipping\n")
+				r.warned["path-fill-image"] = true
+			}
+			continue
+		}
+		faded, err := fadeFill(fill, path.Opacity)
+		if err != nil {
+			return fmt.Errorf("fill: %w", err)
+		}
+		fills = append(fills, faded)
+	}
+
+	// Leave room around the box for strokes and their joins
+	margin := 0.0
+	if stroke != nil && stroke.Color != "" && stroke.Align != shared.StrokeInside {
+		margin = pathMiterLimit * strokeWidth(stroke)
+	}
+	w, h := box.Width+2*margin, box.Height+2*margin
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+
+	data, err := pathPDF(path.Geometry.Fit(box.Width, box.Height), path.FillRule, fills, stroke, margin, w, h)
+	if err != nil {
+		return err
+	}
+	tpl, err := r.importTemplate(pdf, data)
+	if err != nil {
+		return fmt.Errorf("import path: %w", err)
+	}
+	pdf.UseImportedTemplate(tpl, box.X-margin, box.Y-margin, w, h)
+	return nil
+}
+
+// pathPDF returns a one-page PDF of size w×h with the geometry painted by
+// each fill and then the stroke. The geometry is in box coordinates (y
+// pointing down), offset by margin from the page corner.
+func pathPDF(geometry *shared.PathGeometry, rule shared.FillRule, fills []*shared.Fill, stroke *shared.Stroke, margin, w, h float64) ([]byte, error) {
+	res := newPathResources()
+	var content strings.Builder
+	content.WriteString(pdfNumbers(1, 0, 0, -1, margin, h-margin) + " cm\n")
+
+	bounds := outlineRect{w: w - 2*margin, h: h - 2*margin}
+	page := outlineRect{x: -margin, y: -margin, w: w, h: h}
+	if err := writePathPaint(&content, res, pathOperators(geometry), rule, fills, stroke, bounds, page); err != nil {
+		return nil, err
+	}
+	return pagePDF(w, h, res, content.String()), nil
+}
+
+// writePathPaint writes the operators painting a path with each fill and
+// then the stroke. Gradients cover bounds; page is the area clipped to draw
+// outside strokes.
+func writePathPaint(content *strings.Builder, res *pathResources, shape string, rule shared.FillRule, fills []*shared.Fill, stroke *shared.Stroke, bounds, page outlineRect) error {
+	fillOp, clipOp := "f", "W n"
+	if rule == shared.FillRuleEvenOdd {
+		fillOp, clipOp = "f*", "W* n"
+	}
+
+	for i, fill := range fills {
+		switch {
+		case fill.Type == shared.FillSolid:
+			if fill.Color == "" {
+				continue
+			}
+			rgba, err := shared.ParseHexColor(fill.Color)
+			if err != nil {
+				return fmt.Errorf("fill %d: %w", i, err)
+			}
+			fmt.Fprintf(content, "q %s%s rg %s %s Q\n", res.alpha(rgba.A), rgbComponents(rgba), shape, fillOp)
+		case fill.IsGradient() && len(fill.Stops) > 0:
+			paint, err := res.gradient(fill)
+			if err != nil {
+				return fmt.Errorf("fill %d: %w", i, err)
+			}
+			// The gradient covers the bounds, scaled to the unit square
+			fmt.Fprintf(content, "q %s %s %s cm %s Q\n", shape, clipOp, pdfNumbers(bounds.w, 0, 0, bounds.h, bounds.x, bounds.y), paint)
+		}
+	}
+
+	if width := strokeWidth(stroke); width > 0 && stroke.Color != "" {
+		rgba, err := shared.ParseHexColor(stroke.Color)
+		if err != nil {
+			return fmt.Errorf("stroke: %w", err)
+		}
+		content.WriteString("q ")
+		// Inside and outside strokes are drawn twice as wide and clipped
+		switch stroke.Align {
+		case shared.StrokeInside:
+			fmt.Fprintf(content, "%s %s ", shape, clipOp)
+			width *= 2
+		case shared.StrokeOutside:
+			fmt.Fprintf(content, "%s re %s W* n ", pdfNumbers(page.x, page.y, page.w, page.h), shape)
+			width *= 2
+		}
+		fmt.Fprintf(content, "%s%s RG %s w %d M ", res.alpha(rgba.A), rgbComponents(rgba), pdfNumbers(width), pathMiterLimit)
+		if len(stroke.Dash) > 0 {
+			fmt.Fprintf(content, "[%s] 0 d ", pdfNumbers(stroke.Dash...))
+		}
+		fmt.Fprintf(content, "%s S Q\n", shape)
+	}
+	return nil
+}
+
+// pagePDF returns a one-page PDF of size w×h with the given content and
+// resources.
+func pagePDF(w, h float64, res *pathResources, content string) []byte {
+	var doc pdfBuilder
+	doc.add("<< /Type /Catalog /Pages 2 0 R >>")
+	doc.add("<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+	doc.add(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s] /Resources << %s >> /Contents 4 0 R >>",
+		pdfNumbers(w, h), res.dict()))
+	doc.addStream("", []byte(content))
+	doc.objects = append(doc.objects, res.objects.objects...)
+	return doc.bytes()
+}
+
+// pathOperators returns the PDF path construction operators of a geometry,
+// with coordinates rounded to a ten-thousandth of a point.
+func pathOperators(geometry *shared.PathGeometry) string {
+	round := func(v float64) float64 {
+		return math.Round(v*1e4) / 1e4
+	}
+	ops := make([]string, 0, len(geometry.Segments))
+	for _, seg := range geometry.Segments {
+		var coords []float64
+		for _, p := range seg.Points {
+			coords = append(coords, round(p.X), round(p.Y))
+		}
+		switch seg.Op {
+		case shared.PathMoveTo:
+			ops = append(ops, pdfNumbers(coords...)+" m")
+		case shared.PathLineTo:
+			ops = append(ops, pdfNumbers(coords...)+" l")
+		case shared.PathCubicTo:
+			ops = append(ops, pdfNumbers(coords...)+" c")
+		case shared.PathClose:
+			ops = append(ops, "h")
+		}
+	}
+	return strings.Join(ops, " ")
+}
+
+func rgbComponents(c shared.RGBA) string {
+	return pdfNumbers(float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+}
+
+// pathResources collects the objects referenced from a path page, numbered
+// after the catalog, page tree, page and content stream.
+type pathResources struct {
+	objects   pdfBuilder
+	extGState []string
+	shading   []string
+	alphas    map[float64]string
+}
+
+func newPathResources() *pathResources {
+	return &pathResources{alphas: make(map[float64]string)}
+}
+
+// next returns the number of the next object added.
+func (p *pathResources) next() int {
+	return 5 + len(p.objects.objects)
+}
+
+// alpha returns the operator setting a constant opacity, or nothing for
+// opaque colors.
+func (p *pathResources) alpha(a float64) string {
+	if a >= 1 {
+		return ""
+	}
+	name, ok := p.alphas[a]
+	if !ok {
+		name = fmt.Sprintf("/GS%d", len(p.extGState))
+		p.extGState = append(p.extGState, fmt.Sprintf("%s %d 0 R", name, p.next()))
+		p.objects.add(fmt.Sprintf("<< /Type /ExtGState /ca %s /CA %s >>", pdfNumbers(a), pdfNumbers(a)))
+		p.alphas[a] = name
+	}
+	return name + " gs "
+}
+
+// gradient adds the shadings of a gradient fill and returns the operators
+// painting it over the unit square. Transparent stops are honored with a
+// luminosity soft mask, as in gradientPDF.
+func (p *pathResources) gradient(fill *shared.Fill) (string, error) {
+	stops, err := parseGradientStops(fill)
+	if err != nil {
+		return "", err
+	}
+	matrix := gradientMatrix(fill)
+
+	name := fmt.Sprintf("/Sh%d", len(p.shading))
+	p.shading = append(p.shading, fmt.Sprintf("%s %d 0 R", name, p.next()))
+	p.objects.addShading(gradientShading(fill.Type, stops, "/DeviceRGB", func(c shared.RGBA) []float64 {
+		return []float64{float64(c.R) / 255, float64(c.G) / 255, float64(c.B) / 255}
+	}))
+	paint := matrix + " cm " + name + " sh"
+
+	opaque := true
+	for _, s := range stops {
+		if s.color.A < 1 {
+			opaque = false
+		}
+	}
+	if opaque {
+		return paint, nil
+	}
+
+	mask := fmt.Sprintf("/GS%d", len(p.extGState))
+	id := p.next()
+	p.extGState = append(p.extGState, fmt.Sprintf("%s %d 0 R", mask, id))
+	p.objects.add(fmt.Sprintf("<< /Type /ExtGState /SMask << /Type /Mask /S /Luminosity /G %d 0 R >> >>", id+1))
+	p.objects.addStream(fmt.Sprintf("/Type /XObject /Subtype /Form /BBox [0 0 1 1] /Group << /S /Transparency /CS /DeviceGray >> /Resources << /Shading << /Sh0 %d 0 R >> >>", id+2),
+		[]byte(matrix+" cm /Sh0 sh"))
+	p.objects.addShading(gradientShading(fill.Type, stops, "/DeviceGray", func(c shared.RGBA) []float64 {
+		return []float64{c.A}
+	}))
+	return mask + " gs " + paint, nil
+}
+
+// dict returns the entries of the page resource dictionary.
+func (p *pathResources) dict() string {
+	var entries []string
+	if len(p.extGState) > 0 {
+		entries = append(entries, "/ExtGState << "+strings.Join(p.extGState, " ")+" >>")
+	}
+	if len(p.shading) > 0 {
+		entries = append(entries, "/Shading << "+strings.Join(p.shading, " ")+" >>")
+	}
+	return strings.Join(entries, " ")
+}
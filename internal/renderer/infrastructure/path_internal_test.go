@@ -0,0 +1,71 @@
+package infrastructure
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestPathOperators(t *testing.T) {
+	geometry, err := shared.ParsePathData("M0 0 L10 0 C10 5 5 10 0.123456 10 Z")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "0 0 m 10 0 l 10 5 5 10 0.1235 10 c h"
+	if got := pathOperators(geometry); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestPathPDFFillRules(t *testing.T) {
+	geometry, err := shared.ParsePathData("M0 0 H10 V10 Z")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fills := []*shared.Fill{shared.SolidFill("#FF000080")}
+
+	nonzero, err := pathPDF(geometry, shared.FillRuleNonZero, fills, nil, 0, 10, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(nonzero, []byte("h f Q")) {
+		t.Errorf("expected nonzero fill operator, got:\n%s", nonzero)
+	}
+	if !bytes.Contains(nonzero, []byte("/ca 0.50196")) {
+		t.Errorf("expected fill alpha in an ExtGState, got:\n%s", nonzero)
+	}
+
+	evenodd, err := pathPDF(geometry, shared.FillRuleEvenOdd, fills, nil, 0, 10, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(evenodd, []byte("h f* Q")) {
+		t.Errorf("expected even-odd fill operator, got:\n%s", evenodd)
+	}
+}
+
+func TestPathPDFStrokeAlignment(t *testing.T) {
+	geometry, err := shared.ParsePathData("M0 0 H10 V10 Z")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		align shared.StrokeAlign
+		want  string
+	}{
+		{shared.StrokeCenter, "q 0 0 0 RG 2 w"},
+		{shared.StrokeInside, "h W n 0 0 0 RG 4 w"},
+		{shared.StrokeOutside, "-8 -8 26 26 re 0 0 m 10 0 l 10 10 l h W* n 0 0 0 RG 4 w"},
+	}
+	for _, tt := range tests {
+		data, err := pathPDF(geometry, shared.FillRuleNonZero, nil, shared.SolidStroke("#000000", 2, tt.align), 8, 26, 26)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.align, err)
+		}
+		if !strings.Contains(string(data), tt.want) {
+			t.Errorf("%s: expected %q, got:\n%s", tt.align, tt.want, data)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package infrastructure
+
+import (
+	"math"
+
+	"github.com/signintech/gopdf"
+)
+
+// arcSegments is the number of line segments used to approximate a quarter
+// circle. gopdf only exposes polygon paths for filling and clipping.
+const arcSegments = 16
+
+// clampRadius limits a corner radius to half the smallest side of the box.
+func clampRadius(w, h, radius float64) float64 {
+	maxRadius := math.Min(w, h) / 2
+	if radius > maxRadius {
+		return maxRadius
+	}
+	if radius < 0 {
+		return 0
+	}
+	return radius
+}
+
+// roundedRectPolygon returns the outline of a rectangle with rounded corners,
+// clockwise from the top-left corner. A zero radius yields the four corners.
+func roundedRectPolygon(x, y, w, h, radius float64) []gopdf.Point {
+	radius = clampRadius(w, h, radius)
+	if radius == 0 {
+		return []gopdf.Point{
+			{X: x, Y: y},
+			{X: x + w, Y: y},
+			{X: x + w, Y: y + h},
+			{X: x, Y: y + h},
+		}
+	}
+
+	points := make([]gopdf.Point, 0, 4*(arcSegments+1))
+	corners := []struct {
+		cx, cy, start float64
+	}{
+		{x + radius, y + radius, math.Pi},           // top-left
+		{x + w - radius, y + radius, 1.5 * math.Pi}, // top-right
+		{x + w - radius, y + h - radius, 0},         // bottom-right
+		{x + radius, y + h - radius, 0.5 * math.Pi}, // bottom-left
+	}
+	for _, c := range corners {
+		for i := 0; i <= arcSegments; i++ {
+			angle := c.start + float64(i)*(math.Pi/2)/arcSegments
+			points = append(points, gopdf.Point{
+				X: c.cx + radius*math.Cos(angle),
+				Y: c.cy + radius*math.Sin(angle),
+			})
+		}
+	}
+	return points
+}
+
+// ringPolygon returns a single closed outline covering the area between an
+// outer and an inner outline. The inner outline is walked in reverse and
+// joined to the outer one by a zero-width bridge, so a nonzero-winding fill
+// leaves the inner area empty.
+func ringPolygon(outer, inner []gopdf.Point) []gopdf.Point {
+	points := make([]gopdf.Point, 0, len(outer)+len(inner)+2)
+	points = append(points, outer...)
+	points = append(points, outer[0], inner[0])
+	for i := len(inner) - 1; i >= 0; i-- {
+		points = append(points, inner[i])
+	}
+	return points
+}
+
+// outlineRect describes a rectangle with a corner radius.
+type outlineRect struct {
+	x, y, w, h, radius float64
+}
+
+// expand grows (or shrinks, for negative values) the rectangle on each side
+// and adjusts the corner radius by the largest offset, keeping it positive.
+func (o outlineRect) expand(top, right, bottom, left float64) outlineRect {
+	radius := o.radius
+	if radius > 0 {
+		radius += math.Max(math.Max(top, right), math.Max(bottom, left))
+		radius = math.Max(radius, 0)
+	}
+	return outlineRect{
+		x:      o.x - left,
+		y:      o.y - top,
+		w:      math.Max(o.w+left+right, 0),
+		h:      math.Max(o.h+top+bottom, 0),
+		radius: radius,
+	}
+}
+
+func (o outlineRect) polygon() []gopdf.Point {
+	return roundedRectPolygon(o.x, o.y, o.w, o.h, o.radius)
+}
+
+// ellipsePolygon returns the outline of the ellipse inscribed in a box,
+// clockwise from its rightmost point.
+func ellipsePolygon(x, y, w, h float64) []gopdf.Point {
+	n := 4 * arcSegments
+	cx, cy := x+w/2, y+h/2
+	points := make([]gopdf.Point, n)
+	for i := range points {
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		points[i] = gopdf.Point{X: cx + w/2*math.Cos(angle), Y: cy + h/2*math.Sin(angle)}
+	}
+	return points
+}
+
+// regularPolygon returns the outline of a regular polygon with the given
+// number of sides, clockwise from its top vertex, stretched so its vertices
+// touch every side of the box.
+func regularPolygon(x, y, w, h float64, sides int) []gopdf.Point {
+	points := make([]gopdf.Point, sides)
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i := range points {
+		angle := -math.Pi/2 + 2*math.Pi*float64(i)/float64(sides)
+		p := gopdf.Point{X: math.Cos(angle), Y: math.Sin(angle)}
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+		points[i] = p
+	}
+	for i, p := range points {
+		points[i] = gopdf.Point{
+			X: x + (p.X-minX)/(maxX-minX)*w,
+			Y: y + (p.Y-minY)/(maxY-minY)*h,
+		}
+	}
+	return points
+}
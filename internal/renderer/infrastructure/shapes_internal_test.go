@@ -0,0 +1,127 @@
+package infrastructure
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRoundedRectPolygonWithoutRadius(t *testing.T) {
+	points := roundedRectPolygon(10, 20, 100, 50, 0)
+	if len(points) != 4 {
+		t.Fatalf("expected 4 corner points, got %d", len(points))
+	}
+	if points[0].X != 10 || points[0].Y != 20 || points[2].X != 110 || points[2].Y != 70 {
+		t.Errorf("unexpected corners: %+v", points)
+	}
+}
+
+func TestRoundedRectPolygonStaysInBounds(t *testing.T) {
+	points := roundedRectPolygon(10, 20, 100, 50, 12)
+	for _, p := range points {
+		if p.X < 10-1e-9 || p.X > 110+1e-9 || p.Y < 20-1e-9 || p.Y > 70+1e-9 {
+			t.Fatalf("point %+v outside box", p)
+		}
+	}
+	// The exact corner must be cut off by the arc
+	for _, p := range points {
+		if p.X == 10 && p.Y == 20 {
+			t.Error("expected top-left corner to be rounded")
+		}
+	}
+}
+
+func TestRoundedRectPolygonClampsRadius(t *testing.T) {
+	// Radius larger than half the height yields a pill shape whose
+	// leftmost point sits at mid-height
+	points := roundedRectPolygon(0, 0, 100, 40, 100)
+	first := points[0]
+	if first.X != 0 || math.Abs(first.Y-20) > 1e-9 {
+		t.Errorf("expected first point at (0,20), got %+v", first)
+	}
+}
+
+func TestClampRadius(t *testing.T) {
+	if got := clampRadius(100, 40, 30); got != 20 {
+		t.Errorf("expected 20, got %f", got)
+	}
+	if got := clampRadius(100, 40, 8); got != 8 {
+		t.Errorf("expected 8, got %f", got)
+	}
+	if got := clampRadius(100, 40, -5); got != 0 {
+		t.Errorf("expected 0, got %f", got)
+	}
+}
+
+func TestRingPolygonJoinsOuterAndReversedInner(t *testing.T) {
+	outer := roundedRectPolygon(0, 0, 100, 100, 0)
+	inner := roundedRectPolygon(10, 10, 80, 80, 0)
+	ring := ringPolygon(outer, inner)
+
+	if len(ring) != len(outer)+len(inner)+2 {
+		t.Fatalf("expected %d points, got %d", len(outer)+len(inner)+2, len(ring))
+	}
+	if ring[len(outer)] != outer[0] || ring[len(outer)+1] != inner[0] {
+		t.Error("expected bridge from outer start to inner start")
+	}
+	if ring[len(ring)-1] != inner[0] || ring[len(ring)-2] != inner[1] {
+		t.Error("expected inner outline walked in reverse")
+	}
+}
+
+func TestOutlineRectExpand(t *testing.T) {
+	o := outlineRect{x: 10, y: 10, w: 100, h: 50, radius: 8}
+	grown := o.expand(2, 2, 2, 2)
+	if grown.x != 8 || grown.y != 8 || grown.w != 104 || grown.h != 54 || grown.radius != 10 {
+		t.Errorf("unexpected grown outline: %+v", grown)
+	}
+	shrunk := o.expand(-10, -10, -10, -10)
+	if shrunk.radius != 0 {
+		t.Errorf("expected radius clamped to 0, got %f", shrunk.radius)
+	}
+}
+
+func TestEllipsePolygonFitsBox(t *testing.T) {
+	points := ellipsePolygon(10, 20, 100, 50)
+	if len(points) != 4*arcSegments {
+		t.Fatalf("expected %d points, got %d", 4*arcSegments, len(points))
+	}
+	for _, p := range points {
+		// Every point lies on the ellipse centered in the box
+		dx := (p.X - 60) / 50
+		dy := (p.Y - 45) / 25
+		if math.Abs(dx*dx+dy*dy-1) > 1e-9 {
+			t.Fatalf("point %+v not on the ellipse", p)
+		}
+	}
+	if points[0].X != 110 || points[0].Y != 45 {
+		t.Errorf("expected first point at the right edge (110,45), got %+v", points[0])
+	}
+}
+
+func TestRegularPolygonTouchesBoxEdges(t *testing.T) {
+	for _, sides := range []int{3, 5, 6} {
+		points := regularPolygon(10, 20, 100, 50, sides)
+		if len(points) != sides {
+			t.Fatalf("%d sides: expected %d points, got %d", sides, sides, len(points))
+		}
+		minX, minY := math.Inf(1), math.Inf(1)
+		maxX, maxY := math.Inf(-1), math.Inf(-1)
+		for _, p := range points {
+			minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+			minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+		}
+		if math.Abs(minX-10) > 1e-9 || math.Abs(maxX-110) > 1e-9 || math.Abs(minY-20) > 1e-9 || math.Abs(maxY-70) > 1e-9 {
+			t.Errorf("%d sides: expected bounds (10,20)-(110,70), got (%f,%f)-(%f,%f)", sides, minX, minY, maxX, maxY)
+		}
+	}
+}
+
+func TestRegularPolygonStartsAtTopCenter(t *testing.T) {
+	points := regularPolygon(0, 0, 90, 60, 3)
+	if math.Abs(points[0].X-45) > 1e-9 || points[0].Y != 0 {
+		t.Errorf("expected apex at (45,0), got %+v", points[0])
+	}
+	if math.Abs(points[1].Y-60) > 1e-9 || math.Abs(points[2].Y-60) > 1e-9 {
+		t.Errorf("expected base at y=60, got %+v and %+v", points[1], points[2])
+	}
+}
@@ -0,0 +1,182 @@
+package infrastructure
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/signintech/gopdf"
+	layout "github.com/vpedrosa/pen2pdf/internal/layout/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// textStrokeSteps is the number of offset copies used to approximate a glyph
+// outline, since gopdf cannot set the PDF text rendering mode.
+const textStrokeSteps = 16
+
+// drawStroke paints a stroke along the outline of a box. Uniform strokes are
+// drawn as a stroked path (supporting dash patterns); strokes with different
+// side widths are filled as a ring between an outer and an inner outline.
+func (r *PDFRenderer) drawStroke(pdf *gopdf.GoPdf, x, y, w, h, radius float64, stroke *shared.Stroke) error {
+	if stroke == nil || stroke.Color == "" || w <= 0 || h <= 0 {
+		return nil
+	}
+
+	rgba, err := shared.ParseHexColor(stroke.Color)
+	if err != nil {
+		return err
+	}
+
+	box := outlineRect{x: x, y: y, w: w, h: h, radius: radius}
+	t := stroke.Thickness
+
+	if t.IsUniform() {
+		if t.Top <= 0 {
+			return nil
+		}
+		// Move the path so the stroke lies inside, across or outside the edge
+		offset := 0.0
+		switch stroke.Align {
+		case shared.StrokeInside:
+			offset = -t.Top / 2
+		case shared.StrokeOutside:
+			offset = t.Top / 2
+		}
+		path := box.expand(offset, offset, offset, offset)
+		return strokePath(pdf, path.polygon(), true, t.Top, stroke.Dash, rgba)
+	}
+
+	var outer, inner outlineRect
+	switch stroke.Align {
+	case shared.StrokeOutside:
+		outer = box.expand(t.Top, t.Right, t.Bottom, t.Left)
+		inner = box
+	case shared.StrokeCenter:
+		outer = box.expand(t.Top/2, t.Right/2, t.Bottom/2, t.Left/2)
+		inner = box.expand(-t.Top/2, -t.Right/2, -t.Bottom/2, -t.Left/2)
+	default:
+		outer = box
+		inner = box.expand(-t.Top, -t.Right, -t.Bottom, -t.Left)
+	}
+
+	if inner.w <= 0 || inner.h <= 0 {
+		return fillPolygon(pdf, outer.polygon(), rgba)
+	}
+	return fillPolygon(pdf, ringPolygon(outer.polygon(), inner.polygon()), rgba)
+}
+
+// drawOutlineStroke paints a stroke along an arbitrary closed outline lying
+// within bounds. Per-side widths only apply to rectangles, so the widest side
+// is used. Inside and outside strokes are drawn twice as wide, centered on the
+// outline, and clipped to the matching side of it.
+func (r *PDFRenderer) drawOutlineStroke(pdf *gopdf.GoPdf, outline []gopdf.Point, bounds outlineRect, stroke *shared.Stroke) error {
+	if stroke == nil || stroke.Color == "" || len(outline) == 0 {
+		return nil
+	}
+	width := strokeWidth(stroke)
+	if width <= 0 {
+		return nil
+	}
+
+	rgba, err := shared.ParseHexColor(stroke.Color)
+	if err != nil {
+		return err
+	}
+
+	pdf.SaveGraphicsState()
+	switch stroke.Align {
+	case shared.StrokeInside:
+		pdf.ClipPolygon(outline)
+		width *= 2
+	case shared.StrokeOutside:
+		pdf.ClipPolygon(ringPolygon(bounds.expand(width, width, width, width).polygon(), outline))
+		width *= 2
+	}
+	err = strokePath(pdf, outline, true, width, stroke.Dash, rgba)
+	pdf.RestoreGraphicsState()
+	return err
+}
+
+// strokeWidth returns the width of the widest side of a stroke, or zero.
+func strokeWidth(stroke *shared.Stroke) float64 {
+	if stroke == nil {
+		return 0
+	}
+	t := stroke.Thickness
+	return math.Max(math.Max(t.Top, t.Right), math.Max(t.Bottom, t.Left))
+}
+
+// strokePath strokes an open or closed path with a color, honoring its alpha.
+func strokePath(pdf *gopdf.GoPdf, points []gopdf.Point, closed bool, width float64, dash []float64, rgba shared.RGBA) error {
+	if err := setAlpha(pdf, rgba.A); err != nil {
+		return err
+	}
+	pdf.SetStrokeColor(rgba.R, rgba.G, rgba.B)
+	pdf.SetLineWidth(width)
+	if len(dash) > 0 {
+		// gopdf converts the dash array in place
+		pdf.SetCustomLineType(append([]float64(nil), dash...), 0)
+	}
+	if closed {
+		pdf.Polygon(points, "D")
+	} else {
+		for i := 1; i < len(points); i++ {
+			pdf.Line(points[i-1].X, points[i-1].Y, points[i].X, points[i].Y)
+		}
+	}
+	if len(dash) > 0 {
+		pdf.SetLineType("solid")
+	}
+	return setAlpha(pdf, 1.0)
+}
+
+// drawTextStroke approximates a glyph outline by drawing the lines in the
+// stroke color at evenly spaced offsets around each glyph. It must run before
+// the text fill is drawn on top.
+func (r *PDFRenderer) drawTextStroke(pdf *gopdf.GoPdf, box *layout.LayoutBox, lines []layout.TextLine, lineHeight float64, stroke *shared.Stroke) error {
+	if stroke == nil || stroke.Color == "" || stroke.Thickness.Top <= 0 {
+		return nil
+	}
+
+	rgba, err := shared.ParseHexColor(stroke.Color)
+	if err != nil {
+		return err
+	}
+	if err := setAlpha(pdf, rgba.A); err != nil {
+		return err
+	}
+	pdf.SetTextColor(rgba.R, rgba.G, rgba.B)
+
+	distance := r.textStrokeDistance(stroke)
+	for i := 0; i < textStrokeSteps; i++ {
+		angle := 2 * math.Pi * float64(i) / textStrokeSteps
+		dx := distance * math.Cos(angle)
+		dy := distance * math.Sin(angle)
+		if err := drawLines(pdf, box.X+dx, box.Y+dy, lines, lineHeight); err != nil {
+			return err
+		}
+	}
+	return setAlpha(pdf, 1.0)
+}
+
+// textStrokeDistance returns how far from each glyph the copies of a text
+// stroke are drawn. Outlines straddle the glyph edge for centered strokes.
+// Glyphs cannot be used as a clipping path with gopdf, so inside strokes
+// are drawn as centered ones, with a warning.
+func (r *PDFRenderer) textStrokeDistance(stroke *shared.Stroke) float64 {
+	switch stroke.Align {
+	case shared.StrokeOutside:
+		return stroke.Thickness.Top
+	case shared.StrokeInside:
+		if !r.warned["text-stroke-inside"] {
+			fmt.Fprintf(os.Stderr, "warning: inside strokes are not supported on text, drawing them centered\n")
+			r.warned["text-stroke-inside"] = true
+		}
+	}
+	return stroke.Thickness.Top / 2
+}
+
+// setAlpha sets the constant opacity used by subsequent drawing operations.
+func setAlpha(pdf *gopdf.GoPdf, alpha float64) error {
+	return pdf.SetTransparency(gopdf.Transparency{Alpha: alpha, BlendModeType: gopdf.NormalBlendMode})
+}
@@ -0,0 +1,25 @@
+package infrastructure
+
+import (
+	"testing"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestTextStrokeDistance(t *testing.T) {
+	r := NewPDFRenderer(nil, nil)
+	tests := map[shared.StrokeAlign]float64{
+		shared.StrokeOutside: 4,
+		shared.StrokeCenter:  2,
+		// Glyphs cannot clip, so inside strokes fall back to centered ones
+		shared.StrokeInside: 2,
+	}
+	for align, want := range tests {
+		if got := r.textStrokeDistance(shared.SolidStroke("#000000", 4, align)); got != want {
+			t.Errorf("%s: expected %v, got %v", align, want, got)
+		}
+	}
+	if !r.warned["text-stroke-inside"] {
+		t.Error("expected a warning for the inside stroke")
+	}
+}
@@ -5,17 +5,23 @@ import (
 	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
 )
 
-// ResolveService orchestrates variable resolution.
+// ResolveService orchestrates document resolution.
 type ResolveService struct {
-	resolver resolver.Resolver
+	resolvers []resolver.Resolver
 }
 
-// NewResolveService creates a ResolveService with the given Resolver port.
-func NewResolveService(r resolver.Resolver) *ResolveService {
-	return &ResolveService{resolver: r}
+// NewResolveService creates a ResolveService that runs the given Resolver
+// ports in order.
+func NewResolveService(r ...resolver.Resolver) *ResolveService {
+	return &ResolveService{resolvers: r}
 }
 
-// Resolve replaces variable references in the document with their values.
+// Resolve expands the document in place, stopping at the first error.
 func (s *ResolveService) Resolve(doc *shared.Document) error {
-	return s.resolver.Resolve(doc)
+	for _, r := range s.resolvers {
+		if err := r.Resolve(doc); err != nil {
+			return err
+		}
+	}
+	return nil
 }
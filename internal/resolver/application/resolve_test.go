@@ -2,6 +2,7 @@ package application_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/vpedrosa/pen2pdf/internal/resolver/application"
@@ -9,10 +10,15 @@ import (
 )
 
 type stubResolver struct {
-	err error
+	err   error
+	calls *[]string
+	name  string
 }
 
 func (r *stubResolver) Resolve(_ *shared.Document) error {
+	if r.calls != nil {
+		*r.calls = append(*r.calls, r.name)
+	}
 	return r.err
 }
 
@@ -31,3 +37,18 @@ func TestResolveServiceError(t *testing.T) {
 		t.Fatal("expected error")
 	}
 }
+
+func TestResolveServiceRunsResolversInOrder(t *testing.T) {
+	var calls []string
+	svc := application.NewResolveService(
+		&stubResolver{calls: &calls, name: "components"},
+		&stubResolver{calls: &calls, name: "variables", err: fmt.Errorf("undefined variable")},
+		&stubResolver{calls: &calls, name: "unreached"},
+	)
+	if err := svc.Resolve(&shared.Document{}); err == nil {
+		t.Fatal("expected error")
+	}
+	if strings.Join(calls, ",") != "components,variables" {
+		t.Errorf("expected resolvers to run in order until the error, got %v", calls)
+	}
+}
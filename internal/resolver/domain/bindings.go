@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// bindableFields holds the properties of a node that can take their value
+// from a variable, by kind.
+type bindableFields struct {
+	numbers    map[string][]*float64
+	strings    map[string]*string
+	dimensions map[string]*shared.Dimension
+}
+
+func nodeFields(node shared.Node) bindableFields {
+	f := bindableFields{
+		numbers:    make(map[string][]*float64),
+		strings:    make(map[string]*string),
+		dimensions: make(map[string]*shared.Dimension),
+	}
+	var stroke *shared.Stroke
+	switch n := node.(type) {
+	case *shared.Frame:
+		f.numbers["x"] = []*float64{&n.X}
+		f.numbers["y"] = []*float64{&n.Y}
+		f.numbers["cornerRadius"] = []*float64{&n.CornerRadius}
+		f.numbers["gap"] = []*float64{&n.Gap}
+		// Row and column gaps default to gap, so they only exist once set
+		for property, gap := range map[string]**float64{"rowGap": &n.RowGap, "columnGap": &n.ColumnGap} {
+			if _, ok := n.Bindings[property]; ok && *gap == nil {
+				*gap = new(float64)
+			}
+			if *gap != nil {
+				f.numbers[property] = []*float64{*gap}
+			}
+		}
+		f.numbers["padding"] = []*float64{&n.Padding.Top, &n.Padding.Right, &n.Padding.Bottom, &n.Padding.Left}
+		f.numbers["padding.top"] = []*float64{&n.Padding.Top}
+		f.numbers["padding.right"] = []*float64{&n.Padding.Right}
+		f.numbers["padding.bottom"] = []*float64{&n.Padding.Bottom}
+		f.numbers["padding.left"] = []*float64{&n.Padding.Left}
+		f.dimensions["width"] = &n.Width
+		f.dimensions["height"] = &n.Height
+		f.strings["layout"] = &n.Layout
+		f.strings["justifyContent"] = &n.JustifyContent
+		f.strings["alignItems"] = &n.AlignItems
+		stroke = n.Stroke
+	case *shared.Text:
+		f.numbers["fontSize"] = []*float64{&n.FontSize}
+		f.numbers["letterSpacing"] = []*float64{&n.LetterSpacing}
+		f.numbers["lineHeight"] = []*float64{&n.LineHeight}
+		f.dimensions["width"] = &n.Width
+		f.strings["content"] = &n.Content
+		f.strings["fontFamily"] = &n.FontFamily
+		f.strings["fontWeight"] = &n.FontWeight
+		f.strings["fontStyle"] = &n.FontStyle
+		f.strings["textAlign"] = &n.TextAlign
+		stroke = n.Stroke
+	case *shared.Shape:
+		f.numbers["x"] = []*float64{&n.X}
+		f.numbers["y"] = []*float64{&n.Y}
+		f.numbers["opacity"] = []*float64{&n.Opacity}
+		f.numbers["cornerRadius"] = []*float64{&n.CornerRadius}
+		f.dimensions["width"] = &n.Width
+		f.dimensions["height"] = &n.Height
+		stroke = n.Stroke
+	case *shared.Path:
+		f.numbers["x"] = []*float64{&n.X}
+		f.numbers["y"] = []*float64{&n.Y}
+		f.numbers["opacity"] = []*float64{&n.Opacity}
+		f.dimensions["width"] = &n.Width
+		f.dimensions["height"] = &n.Height
+		stroke = n.Stroke
+	case *shared.Image:
+		f.numbers["x"] = []*float64{&n.X}
+		f.numbers["y"] = []*float64{&n.Y}
+		f.numbers["opacity"] = []*float64{&n.Opacity}
+		f.numbers["cornerRadius"] = []*float64{&n.CornerRadius}
+		f.numbers["scale"] = []*float64{&n.Scale}
+		f.dimensions["width"] = &n.Width
+		f.dimensions["height"] = &n.Height
+		f.strings["url"] = &n.URL
+		stroke = n.Stroke
+	}
+	if stroke != nil {
+		t := &stroke.Thickness
+		f.numbers["stroke.thickness"] = []*float64{&t.Top, &t.Right, &t.Bottom, &t.Left}
+	}
+	return f
+}
+
+// resolveBindings sets the properties of a node that reference variables,
+// checking each variable has the type of its property. Errors name the
+// property.
+func resolveBindings(node shared.Node, s *scope) error {
+	bindings := shared.NodeBindings(node)
+	if bindings == nil || len(*bindings) == 0 {
+		return nil
+	}
+
+	// Sorted, so whole padding is set before single sides
+	properties := make([]string, 0, len(*bindings))
+	for property := range *bindings {
+		properties = append(properties, property)
+	}
+	sort.Strings(properties)
+
+	fields := nodeFields(node)
+	for _, property := range properties {
+		name := (*bindings)[property]
+		value, v, err := s.variable(name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", property, err)
+		}
+
+		if targets, ok := fields.numbers[property]; ok {
+			n, ok := value.(float64)
+			if !ok {
+				return fmt.Errorf("%s: variable %q is not a number (type: %s)", property, name, v.Type)
+			}
+			if property == "opacity" && (n < 0 || n > 1) {
+				return fmt.Errorf("%s: variable %q must be between 0 and 1, got %v", property, name, n)
+			}
+			for _, target := range targets {
+				*target = n
+			}
+			continue
+		}
+		if target, ok := fields.dimensions[property]; ok {
+			n, ok := value.(float64)
+			if !ok {
+				return fmt.Errorf("%s: variable %q is not a number (type: %s)", property, name, v.Type)
+			}
+			*target = target.WithValue(shared.FixedDimension(n))
+			continue
+		}
+		if target, ok := fields.strings[property]; ok {
+			str, ok := value.(string)
+			if !ok || v.Type != shared.VariableString {
+				return fmt.Errorf("%s: variable %q is not a string (type: %s)", property, name, v.Type)
+			}
+			*target = str
+			continue
+		}
+		return fmt.Errorf("%s: cannot be set from a variable on a %s", property, node.GetType())
+	}
+	*bindings = nil
+	return nil
+}
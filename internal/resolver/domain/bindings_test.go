@@ -0,0 +1,179 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	resolver "github.com/vpedrosa/pen2pdf/internal/resolver/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func tokenVariables() map[string]shared.Variable {
+	return map[string]shared.Variable{
+		"space-sm":  {Type: shared.VariableNumber, Value: 8.0},
+		"space-lg":  {Type: shared.VariableNumber, Value: 24.0},
+		"size-lg":   {Type: shared.VariableNumber, Value: 32.0},
+		"font-body": {Type: shared.VariableString, Value: "Inter"},
+		"brand":     {Type: shared.VariableColor, Value: "#FF6B35"},
+		"fade":      {Type: shared.VariableNumber, Value: 0.5},
+	}
+}
+
+func TestResolveBindings(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID:     "card",
+				Stroke: &shared.Stroke{Color: "#000000", Thickness: shared.UniformThickness(1)},
+				Bindings: shared.Bindings{
+					"gap": "space-sm", "padding": "space-sm", "padding.top": "space-lg",
+					"width": "size-lg", "stroke.thickness": "space-sm",
+				},
+				Children: []shared.Node{
+					&shared.Text{ID: "title", Bindings: shared.Bindings{"fontSize": "size-lg", "fontFamily": "font-body"}},
+					&shared.Shape{ID: "dot", Type: shared.NodeTypeEllipse, Opacity: 1, Bindings: shared.Bindings{"opacity": "fade"}},
+				},
+			},
+		},
+		Variables: tokenVariables(),
+	}
+
+	if err := resolver.NewVariableResolver().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	card := doc.Children[0].(*shared.Frame)
+	if card.Gap != 8 || card.Width != shared.FixedDimension(32) || card.Stroke.Thickness != shared.UniformThickness(8) {
+		t.Errorf("unexpected frame: gap %v width %+v stroke %+v", card.Gap, card.Width, card.Stroke.Thickness)
+	}
+	if card.Padding != (shared.Padding{Top: 24, Right: 8, Bottom: 8, Left: 8}) {
+		t.Errorf("expected the side binding over the whole padding, got %+v", card.Padding)
+	}
+	if card.Bindings != nil {
+		t.Errorf("expected resolved bindings to be cleared, got %v", card.Bindings)
+	}
+
+	title := card.Children[0].(*shared.Text)
+	if title.FontSize != 32 || title.FontFamily != "Inter" {
+		t.Errorf("unexpected text: size %v family %q", title.FontSize, title.FontFamily)
+	}
+	if dot := card.Children[1].(*shared.Shape); dot.Opacity != 0.5 {
+		t.Errorf("expected opacity 0.5, got %v", dot.Opacity)
+	}
+}
+
+func TestResolveGapBindings(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{ID: "tags", Wrap: true, Gap: 4, Bindings: shared.Bindings{"rowGap": "space-lg"}},
+		},
+		Variables: tokenVariables(),
+	}
+
+	if err := resolver.NewVariableResolver().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The column gap is not set, so it still follows gap
+	if row, column := doc.Children[0].(*shared.Frame).Gaps(); row != 24 || column != 4 {
+		t.Errorf("expected gaps 24 and 4, got %v and %v", row, column)
+	}
+}
+
+func TestResolveDimensionBindingKeepsLimits(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{ID: "card", Width: shared.Dimension{Max: 400}, Bindings: shared.Bindings{"width": "size-lg"}},
+		},
+		Variables: tokenVariables(),
+	}
+
+	if err := resolver.NewVariableResolver().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w := doc.Children[0].(*shared.Frame).Width; w != (shared.Dimension{Value: 32, Max: 400}) {
+		t.Errorf("expected width 32 with its maximum, got %+v", w)
+	}
+}
+
+func TestResolveThemedBinding(t *testing.T) {
+	vars := tokenVariables()
+	vars["space-sm"] = shared.Variable{Type: shared.VariableNumber, Value: 8.0, Themed: []shared.ThemedValue{
+		{Value: 4.0, Theme: shared.Theme{"density": "compact"}},
+	}}
+	doc := &shared.Document{
+		Theme:     shared.Theme{"density": "compact"},
+		Children:  []shared.Node{&shared.Frame{ID: "f1", Bindings: shared.Bindings{"gap": "space-sm"}}},
+		Variables: vars,
+	}
+	if err := resolver.NewVariableResolver().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gap := doc.Children[0].(*shared.Frame).Gap; gap != 4 {
+		t.Errorf("expected the themed value, got %v", gap)
+	}
+}
+
+func TestResolveKeepsLiteralDollarContent(t *testing.T) {
+	vars := tokenVariables()
+	vars["AAPL"] = shared.Variable{Type: shared.VariableString, Value: "Apple"}
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Text{ID: "ticker", Content: "$AAPL"},
+			&shared.Text{ID: "offer", Content: "$5 off"},
+		},
+		Variables: vars,
+	}
+
+	if err := resolver.NewVariableResolver().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, want := range []string{"$AAPL", "$5 off"} {
+		if got := doc.Children[i].(*shared.Text).Content; got != want {
+			t.Errorf("expected %q to be kept, got %q", want, got)
+		}
+	}
+}
+
+func TestResolveBindingErrors(t *testing.T) {
+	tests := map[string]struct {
+		node shared.Node
+		want string
+	}{
+		"undefined": {
+			node: &shared.Frame{ID: "f1", Bindings: shared.Bindings{"gap": "space-xl"}},
+			want: `frame "f1" gap: undefined variable: "space-xl"`,
+		},
+		"string for number": {
+			node: &shared.Text{ID: "t1", Bindings: shared.Bindings{"fontSize": "font-body"}},
+			want: `text "t1" fontSize: variable "font-body" is not a number (type: string)`,
+		},
+		"color for string": {
+			node: &shared.Text{ID: "t1", Bindings: shared.Bindings{"fontFamily": "brand"}},
+			want: `text "t1" fontFamily: variable "brand" is not a string (type: color)`,
+		},
+		"number for dimension": {
+			node: &shared.Image{ID: "i1", Bindings: shared.Bindings{"width": "font-body"}},
+			want: `image "i1" width: variable "font-body" is not a number`,
+		},
+		"opacity range": {
+			node: &shared.Path{ID: "p1", Bindings: shared.Bindings{"opacity": "space-sm"}},
+			want: `path "p1" opacity: variable "space-sm" must be between 0 and 1`,
+		},
+		"unknown property": {
+			node: &shared.Frame{ID: "f1", Bindings: shared.Bindings{"fontSize": "size-lg"}},
+			want: `frame "f1" fontSize: cannot be set from a variable on a frame`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			doc := &shared.Document{Children: []shared.Node{tt.node}, Variables: tokenVariables()}
+			err := resolver.NewVariableResolver().Resolve(doc)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q in error, got: %s", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// ComponentExpander replaces ref nodes with copies of the reusable frames
+// they instantiate, applying each instance's overrides. Top-level reusable
+// frames are component definitions, not pages, and are removed from the
+// document.
+type ComponentExpander struct{}
+
+func NewComponentExpander() *ComponentExpander {
+	return &ComponentExpander{}
+}
+
+func (e *ComponentExpander) Resolve(doc *shared.Document) error {
+	components := make(map[string]*shared.Frame)
+	if err := indexComponents(doc.Children, components); err != nil {
+		return err
+	}
+
+	// Expand every node, definitions included, so cycles are reported even
+	// in unused components
+	x := &expansion{components: components}
+	children, err := x.expandNodes(doc.Children, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	doc.Children = children[:0]
+	for _, child := range children {
+		if frame, ok := child.(*shared.Frame); ok && frame.Reusable {
+			continue
+		}
+		doc.Children = append(doc.Children, child)
+	}
+	return nil
+}
+
+// indexComponents collects the reusable frames of a tree by ID.
+func indexComponents(nodes []shared.Node, components map[string]*shared.Frame) error {
+	for _, node := range nodes {
+		frame, ok := node.(*shared.Frame)
+		if !ok {
+			continue
+		}
+		if frame.Reusable {
+			if _, dup := components[frame.ID]; dup {
+				return fmt.Errorf("duplicate component %q", frame.ID)
+			}
+			components[frame.ID] = frame
+		}
+		if err := indexComponents(frame.Children, components); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type expansion struct {
+	components map[string]*shared.Frame
+}
+
+// expandNodes returns nodes with every ref replaced by its instance. path
+// holds the IDs of the ancestors and stack the components being expanded.
+func (x *expansion) expandNodes(nodes []shared.Node, path, stack []string) ([]shared.Node, error) {
+	out := make([]shared.Node, len(nodes))
+	for i, node := range nodes {
+		switch n := node.(type) {
+		case *shared.Ref:
+			instance, err := x.expandRef(n, path, stack)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = instance
+		case *shared.Frame:
+			if err := x.expandFrame(n, path, stack); err != nil {
+				return nil, err
+			}
+			out[i] = n
+		default:
+			out[i] = node
+		}
+	}
+	return out, nil
+}
+
+func (x *expansion) expandFrame(frame *shared.Frame, path, stack []string) error {
+	if frame.Reusable {
+		stack = append(stack, frame.ID)
+	}
+	children, err := x.expandNodes(frame.Children, append(path, frame.ID), stack)
+	if err != nil {
+		return err
+	}
+	frame.Children = children
+	return nil
+}
+
+func (x *expansion) expandRef(ref *shared.Ref, path, stack []string) (*shared.Frame, error) {
+	refPath := strings.Join(append(path, ref.ID), "/")
+
+	component, ok := x.components[ref.Component]
+	if !ok {
+		return nil, fmt.Errorf("ref %q: component %q not found", refPath, ref.Component)
+	}
+	for i, id := range stack {
+		if id == ref.Component {
+			cycle := append(append([]string(nil), stack[i:]...), id)
+			return nil, fmt.Errorf("ref %q: component cycle: %s", refPath, strings.Join(cycle, " > "))
+		}
+	}
+
+	instance := shared.CloneNode(component).(*shared.Frame)
+	instance.ID = ref.ID
+	if ref.Name != "" {
+		instance.Name = ref.Name
+	}
+	instance.Reusable = false
+
+	stack = append(stack, ref.Component)
+	children, err := x.expandNodes(instance.Children, append(path, ref.ID), stack)
+	if err != nil {
+		return nil, err
+	}
+	instance.Children = children
+
+	ref.Override.Apply(instance)
+	for id, override := range ref.Descendants {
+		node := findNode(instance.Children, id)
+		if node == nil {
+			return nil, fmt.Errorf("ref %q: descendant %q not found in component %q", refPath, id, ref.Component)
+		}
+		override.Apply(node)
+	}
+	return instance, nil
+}
+
+// findNode returns the first node with the given ID, depth first.
+func findNode(nodes []shared.Node, id string) shared.Node {
+	for _, node := range nodes {
+		if node.GetID() == id {
+			return node
+		}
+		if frame, ok := node.(*shared.Frame); ok {
+			if found := findNode(frame.Children, id); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
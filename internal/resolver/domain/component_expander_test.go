@@ -0,0 +1,180 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	resolver "github.com/vpedrosa/pen2pdf/internal/resolver/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestComponentExpanderImplementsPort(t *testing.T) {
+	var _ resolver.Resolver = resolver.NewComponentExpander()
+}
+
+func buttonComponent() *shared.Frame {
+	return &shared.Frame{
+		ID:       "button",
+		Name:     "Button",
+		Reusable: true,
+		Width:    shared.FixedDimension(120),
+		Fills:    []*shared.Fill{shared.SolidFill("#0000FF")},
+		Children: []shared.Node{
+			&shared.Text{ID: "label", Content: "Click", FontSize: 12},
+		},
+	}
+}
+
+func TestExpandRefClonesComponent(t *testing.T) {
+	content := "Buy now"
+	x := 40.0
+	doc := &shared.Document{
+		Children: []shared.Node{
+			buttonComponent(),
+			&shared.Frame{ID: "page", Children: []shared.Node{
+				&shared.Ref{
+					ID:          "cta",
+					Name:        "Call to action",
+					Component:   "button",
+					Override:    &shared.NodeOverride{X: &x, Fills: []*shared.Fill{shared.SolidFill("#FF0000")}},
+					Descendants: map[string]*shared.NodeOverride{"label": {Content: &content}},
+				},
+				&shared.Ref{ID: "plain", Component: "button"},
+			}},
+		},
+	}
+
+	if err := resolver.NewComponentExpander().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(doc.Children) != 1 || doc.Children[0].GetID() != "page" {
+		t.Fatalf("expected the component definition to be removed, got %d pages", len(doc.Children))
+	}
+	page := doc.Children[0].(*shared.Frame)
+
+	cta, ok := page.Children[0].(*shared.Frame)
+	if !ok {
+		t.Fatalf("expected *Frame, got %T", page.Children[0])
+	}
+	if cta.ID != "cta" || cta.Name != "Call to action" || cta.Reusable {
+		t.Errorf("expected the instance identity, got %q %q reusable=%v", cta.ID, cta.Name, cta.Reusable)
+	}
+	if cta.X != 40 || cta.Width.Value != 120 || cta.Fills[0].Color != "#FF0000" {
+		t.Errorf("expected overrides over component values, got x=%v width=%+v fill=%s", cta.X, cta.Width, cta.Fills[0].Color)
+	}
+	if label := cta.Children[0].(*shared.Text); label.Content != "Buy now" || label.FontSize != 12 {
+		t.Errorf("expected descendant override, got %+v", label)
+	}
+
+	plain := page.Children[1].(*shared.Frame)
+	if plain.Name != "Button" || plain.Fills[0].Color != "#0000FF" {
+		t.Errorf("expected component values, got %q %s", plain.Name, plain.Fills[0].Color)
+	}
+	if label := plain.Children[0].(*shared.Text); label.Content != "Click" {
+		t.Errorf("expected overrides not to leak between instances, got %q", label.Content)
+	}
+}
+
+func TestExpandNestedRefs(t *testing.T) {
+	content := "Nested"
+	doc := &shared.Document{
+		Children: []shared.Node{
+			buttonComponent(),
+			&shared.Frame{ID: "card", Reusable: true, Children: []shared.Node{
+				&shared.Ref{ID: "action", Component: "button"},
+			}},
+			&shared.Frame{ID: "page", Children: []shared.Node{
+				&shared.Ref{
+					ID:          "c1",
+					Component:   "card",
+					Descendants: map[string]*shared.NodeOverride{"label": {Content: &content}},
+				},
+			}},
+		},
+	}
+
+	if err := resolver.NewComponentExpander().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	card := doc.Children[0].(*shared.Frame).Children[0].(*shared.Frame)
+	action, ok := card.Children[0].(*shared.Frame)
+	if !ok || action.ID != "action" {
+		t.Fatalf("expected the nested instance, got %T", card.Children[0])
+	}
+	if label := action.Children[0].(*shared.Text); label.Content != "Nested" {
+		t.Errorf("expected override of a nested instance descendant, got %q", label.Content)
+	}
+}
+
+func TestExpandNestedReusableFrameStaysInPlace(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{ID: "page", Children: []shared.Node{
+				buttonComponent(),
+				&shared.Ref{ID: "copy", Component: "button"},
+			}},
+		},
+	}
+
+	if err := resolver.NewComponentExpander().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	page := doc.Children[0].(*shared.Frame)
+	if len(page.Children) != 2 || page.Children[0].GetID() != "button" || page.Children[1].GetID() != "copy" {
+		t.Errorf("expected the component and its instance, got %d children", len(page.Children))
+	}
+}
+
+func TestExpandRefErrors(t *testing.T) {
+	tests := map[string]struct {
+		children []shared.Node
+		want     string
+	}{
+		"missing component": {
+			children: []shared.Node{
+				&shared.Frame{ID: "page", Children: []shared.Node{
+					&shared.Frame{ID: "card", Children: []shared.Node{&shared.Ref{ID: "cta", Component: "link"}}},
+				}},
+			},
+			want: `ref "page/card/cta": component "link" not found`,
+		},
+		"cycle": {
+			children: []shared.Node{
+				&shared.Frame{ID: "a", Reusable: true, Children: []shared.Node{&shared.Ref{ID: "to-b", Component: "b"}}},
+				&shared.Frame{ID: "b", Reusable: true, Children: []shared.Node{&shared.Ref{ID: "to-a", Component: "a"}}},
+			},
+			want: "component cycle: a > b > a",
+		},
+		"self reference": {
+			children: []shared.Node{
+				&shared.Frame{ID: "a", Reusable: true, Children: []shared.Node{&shared.Ref{ID: "again", Component: "a"}}},
+			},
+			want: "component cycle: a > a",
+		},
+		"missing descendant": {
+			children: []shared.Node{
+				buttonComponent(),
+				&shared.Ref{ID: "cta", Component: "button", Descendants: map[string]*shared.NodeOverride{"icon": {}}},
+			},
+			want: `ref "cta": descendant "icon" not found`,
+		},
+		"duplicate component": {
+			children: []shared.Node{buttonComponent(), buttonComponent()},
+			want:     `duplicate component "button"`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := resolver.NewComponentExpander().Resolve(&shared.Document{Children: tt.children})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q in error, got: %s", tt.want, err)
+			}
+		})
+	}
+}
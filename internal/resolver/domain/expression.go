@@ -0,0 +1,539 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// isExpression reports whether the value of a variable of the given type
+// is computed rather than literal. Number and boolean values given as
+// strings always are; colors are when they reference variables or call
+// functions; strings only when they are a whole "$name" alias.
+func isExpression(varType shared.VariableType, value string) bool {
+	switch varType {
+	case shared.VariableNumber, shared.VariableBoolean:
+		return true
+	case shared.VariableColor:
+		return strings.Contains(value, "$") || strings.Contains(value, "(")
+	default:
+		name, ok := strings.CutPrefix(value, "$")
+		return ok && isVariableName(name)
+	}
+}
+
+// evaluateExpression computes an expression of numbers, percentages, hex
+// colors, quoted strings, true and false, $variable references, + - * /
+// with parentheses, comparisons, ! && ||, and the color functions lighten,
+// darken and alpha. Values are float64 numbers, strings or booleans; lookup
+// returns the value of a variable. Names may contain dashes, so subtraction
+// needs spaces around the minus sign.
+func evaluateExpression(src string, lookup func(name string) (any, error)) (any, error) {
+	p := &exprParser{src: src, lookup: lookup}
+	value, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, fmt.Errorf("unexpected %q at %d in %q", p.src[p.pos:], p.pos, src)
+	}
+	return value, nil
+}
+
+type exprParser struct {
+	src    string
+	pos    int
+	lookup func(name string) (any, error)
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// consume skips spaces and the given operator, if it comes next.
+func (p *exprParser) consume(op byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// consumeOp skips spaces and the given operator of one or more characters,
+// if it comes next.
+func (p *exprParser) consumeOp(op string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], op) {
+		p.pos += len(op)
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) or() (any, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeOp("||") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = truthy(left) || truthy(right)
+	}
+	return left, nil
+}
+
+func (p *exprParser) and() (any, error) {
+	left, err := p.comparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeOp("&&") {
+		right, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		left = truthy(left) && truthy(right)
+	}
+	return left, nil
+}
+
+func (p *exprParser) comparison() (any, error) {
+	left, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	// Two-character operators first, so <= is not read as <
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consumeOp(op) {
+			right, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			return compare(op, left, right)
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) expr() (any, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op byte
+		switch {
+		case p.consume('+'):
+			op = '+'
+		case p.consume('-'):
+			op = '-'
+		default:
+			return left, nil
+		}
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = arithmetic(op, left, right); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *exprParser) term() (any, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op byte
+		switch {
+		case p.consume('*'):
+			op = '*'
+		case p.consume('/'):
+			op = '/'
+		default:
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = arithmetic(op, left, right); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *exprParser) unary() (any, error) {
+	p.skipSpace()
+	if !strings.HasPrefix(p.src[p.pos:], "!=") && p.consume('!') {
+		value, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return !truthy(value), nil
+	}
+	if p.consume('-') {
+		value, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return arithmetic('-', 0.0, value)
+	}
+	return p.primary()
+}
+
+func (p *exprParser) primary() (any, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, fmt.Errorf("unexpected end of %q", p.src)
+	}
+
+	start := p.pos
+	c := p.src[p.pos]
+	switch {
+	case c == '(':
+		p.pos++
+		value, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(')') {
+			return nil, fmt.Errorf("missing ) in %q", p.src)
+		}
+		return value, nil
+	case c == '$':
+		p.pos++
+		name := p.name()
+		if name == "" {
+			return nil, fmt.Errorf("missing variable name at %d in %q", start, p.src)
+		}
+		return p.lookup(name)
+	case c == '#':
+		p.pos++
+		for p.pos < len(p.src) && isHexDigit(p.src[p.pos]) {
+			p.pos++
+		}
+		color := p.src[start:p.pos]
+		if _, err := shared.ParseHexColor(color); err != nil {
+			return nil, err
+		}
+		return color, nil
+	case c == '.' || unicode.IsDigit(rune(c)):
+		for p.pos < len(p.src) && (p.src[p.pos] == '.' || unicode.IsDigit(rune(p.src[p.pos]))) {
+			p.pos++
+		}
+		n, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", p.src[start:p.pos])
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == '%' {
+			p.pos++
+			n /= 100
+		}
+		return n, nil
+	case c == '"' || c == '\'':
+		return p.quoted()
+	case unicode.IsLetter(rune(c)):
+		return p.call()
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d in %q", string(c), start, p.src)
+	}
+}
+
+// name reads a variable name.
+func (p *exprParser) name() string {
+	start := p.pos
+	for p.pos < len(p.src) && isNameChar(p.src[p.pos]) {
+		p.pos++
+	}
+	// A trailing dash belongs to a subtraction
+	for p.pos > start && p.src[p.pos-1] == '-' {
+		p.pos--
+	}
+	return p.src[start:p.pos]
+}
+
+// quoted reads a string in double quotes, with Go escapes, or in single
+// quotes, without.
+func (p *exprParser) quoted() (any, error) {
+	start, quote := p.pos, p.src[p.pos]
+	for p.pos++; p.pos < len(p.src) && p.src[p.pos] != quote; p.pos++ {
+		if quote == '"' && p.src[p.pos] == '\\' {
+			p.pos++
+		}
+	}
+	if p.pos >= len(p.src) {
+		return nil, fmt.Errorf("unterminated string in %q", p.src)
+	}
+	p.pos++
+	if quote == '\'' {
+		return p.src[start+1 : p.pos-1], nil
+	}
+	s, err := strconv.Unquote(p.src[start:p.pos])
+	if err != nil {
+		return nil, fmt.Errorf("invalid string %s", p.src[start:p.pos])
+	}
+	return s, nil
+}
+
+func (p *exprParser) call() (any, error) {
+	start := p.pos
+	for p.pos < len(p.src) && unicode.IsLetter(rune(p.src[p.pos])) {
+		p.pos++
+	}
+	fn := p.src[start:p.pos]
+	if fn == "true" || fn == "false" {
+		return fn == "true", nil
+	}
+	if !p.consume('(') {
+		return nil, fmt.Errorf("unexpected %q in %q", fn, p.src)
+	}
+
+	var args []any
+	if !p.consume(')') {
+		for {
+			arg, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.consume(')') {
+				break
+			}
+			if !p.consume(',') {
+				return nil, fmt.Errorf("expected , or ) in %s() in %q", fn, p.src)
+			}
+		}
+	}
+	return callFunction(fn, args)
+}
+
+func arithmetic(op byte, left, right any) (any, error) {
+	a, okA := left.(float64)
+	b, okB := right.(float64)
+	if !okA || !okB {
+		return nil, fmt.Errorf("cannot apply %c to %v and %v", op, left, right)
+	}
+	switch op {
+	case '+':
+		return a + b, nil
+	case '-':
+		return a - b, nil
+	case '*':
+		return a * b, nil
+	default:
+		if b == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return a / b, nil
+	}
+}
+
+// compare applies a comparison operator. Numbers, and strings that hold
+// numbers such as filled placeholders, compare by value; other strings
+// compare as text and booleans only for equality. An empty string, such as
+// a blank data field, is neither less nor greater than anything.
+func compare(op string, left, right any) (any, error) {
+	a, okA := toNumber(left)
+	b, okB := toNumber(right)
+	if okA && okB {
+		switch op {
+		case "==":
+			return a == b, nil
+		case "!=":
+			return a != b, nil
+		case "<":
+			return a < b, nil
+		case "<=":
+			return a <= b, nil
+		case ">":
+			return a > b, nil
+		default:
+			return a >= b, nil
+		}
+	}
+
+	switch op {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+	if left == "" || right == "" {
+		return false, nil
+	}
+	x, okX := left.(string)
+	y, okY := right.(string)
+	if !okX || !okY {
+		return nil, fmt.Errorf("cannot apply %s to %v and %v", op, left, right)
+	}
+	switch op {
+	case "<":
+		return x < y, nil
+	case "<=":
+		return x <= y, nil
+	case ">":
+		return x > y, nil
+	default:
+		return x >= y, nil
+	}
+}
+
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// truthy reports whether a value counts as true in a condition: false, 0,
+// and the strings "", "0" and "false" do not.
+func truthy(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != "" && v != "0" && v != "false"
+	default:
+		return value != nil
+	}
+}
+
+// callFunction applies a color function. Amounts are fractions, so 20% and
+// 0.2 are the same; lighten and darken change the HSL lightness and alpha
+// sets the opacity.
+func callFunction(fn string, args []any) (any, error) {
+	if fn != "lighten" && fn != "darken" && fn != "alpha" {
+		return nil, fmt.Errorf("unknown function %s()", fn)
+	}
+	if len(args) != 2 {
+		return nil, fmt.Errorf("%s() takes a color and an amount, got %d arguments", fn, len(args))
+	}
+	hex, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("%s(): expected a color, got %v", fn, args[0])
+	}
+	color, err := shared.ParseHexColor(hex)
+	if err != nil {
+		return nil, fmt.Errorf("%s(): %w", fn, err)
+	}
+	amount, ok := args[1].(float64)
+	if !ok {
+		return nil, fmt.Errorf("%s(): expected an amount, got %v", fn, args[1])
+	}
+
+	switch fn {
+	case "alpha":
+		color.A = clamp01(amount)
+	case "lighten":
+		color = adjustLightness(color, amount)
+	case "darken":
+		color = adjustLightness(color, -amount)
+	}
+	return color.Hex(), nil
+}
+
+func adjustLightness(c shared.RGBA, delta float64) shared.RGBA {
+	h, s, l := rgbToHSL(c)
+	r, g, b := hslToRGB(h, s, clamp01(l+delta))
+	return shared.RGBA{R: r, G: g, B: b, A: c.A}
+}
+
+func rgbToHSL(c shared.RGBA) (h, s, l float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	maxC, minC := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l = (maxC + minC) / 2
+	d := maxC - minC
+	if d == 0 {
+		return 0, 0, l
+	}
+	if l > 0.5 {
+		s = d / (2 - maxC - minC)
+	} else {
+		s = d / (maxC + minC)
+	}
+	switch maxC {
+	case r:
+		h = math.Mod((g-b)/d+6, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h / 6, s, l
+}
+
+func hslToRGB(h, s, l float64) (uint8, uint8, uint8) {
+	if s == 0 {
+		v := toByte(l)
+		return v, v, v
+	}
+	q := l * (1 + s)
+	if l >= 0.5 {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+	return toByte(hueToRGB(p, q, h+1.0/3)), toByte(hueToRGB(p, q, h)), toByte(hueToRGB(p, q, h-1.0/3))
+}
+
+func hueToRGB(p, q, t float64) float64 {
+	t -= math.Floor(t)
+	switch {
+	case t < 1.0/6:
+		return p + (q-p)*6*t
+	case t < 0.5:
+		return q
+	case t < 2.0/3:
+		return p + (q-p)*(2.0/3-t)*6
+	default:
+		return p
+	}
+}
+
+func toByte(v float64) uint8 {
+	return uint8(math.Round(clamp01(v) * 255))
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func isHexDigit(c byte) bool {
+	return unicode.IsDigit(rune(c)) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+func isVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateExpression(t *testing.T) {
+	vars := map[string]any{"spacing-base": 8.0, "brand": "#3366CC", "ratio": 1.5}
+	lookup := func(name string) (any, error) {
+		if v, ok := vars[name]; ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("undefined variable: %q", name)
+	}
+
+	tests := map[string]any{
+		"12":                          12.0,
+		"$spacing-base * 2":           16.0,
+		"$spacing-base*2 + 4":         20.0,
+		"($spacing-base + 4) / 2":     6.0,
+		"-$spacing-base - -2":         -6.0,
+		"$spacing-base - $ratio":      6.5,
+		"50%":                         0.5,
+		"$brand":                      "#3366CC",
+		"alpha($brand, 50%)":          "#3366CC80",
+		"lighten(#000000, 0.5)":       "#808080",
+		"darken(#FFFFFF, 25%)":        "#BFBFBF",
+		"lighten(#3366CC, 0)":         "#3366CC",
+		"alpha(darken($brand, 0), 1)": "#3366CC",
+	}
+	for expr, want := range tests {
+		got, err := evaluateExpression(expr, lookup)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", expr, err)
+			continue
+		}
+		if n, ok := want.(float64); ok {
+			if g, ok := got.(float64); !ok || math.Abs(g-n) > 1e-9 {
+				t.Errorf("%s: expected %v, got %v", expr, want, got)
+			}
+			continue
+		}
+		if got != want {
+			t.Errorf("%s: expected %v, got %v", expr, want, got)
+		}
+	}
+}
+
+func TestEvaluateExpressionErrors(t *testing.T) {
+	lookup := func(name string) (any, error) {
+		if name == "brand" {
+			return "#3366CC", nil
+		}
+		return nil, fmt.Errorf("undefined variable: %q", name)
+	}
+	tests := map[string]string{
+		"$missing * 2":         `undefined variable: "missing"`,
+		"$brand * 2":           "cannot apply *",
+		"4 / 0":                "division by zero",
+		"(4 + 2":               "missing )",
+		"mix($brand, #FFFFFF)": "unknown function mix()",
+		"lighten($brand)":      "takes a color and an amount",
+		"alpha(2, 0.5)":        "expected a color",
+		"4 4":                  "unexpected",
+		"#GG0000":              "invalid hex color",
+	}
+	for expr, want := range tests {
+		_, err := evaluateExpression(expr, lookup)
+		if err == nil {
+			t.Errorf("%s: expected error", expr)
+			continue
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected %q in error, got: %s", expr, want, err)
+		}
+	}
+}
+
+func TestEvaluateConditions(t *testing.T) {
+	vars := map[string]any{"count": 3.0, "show": true, "label": "Sale"}
+	lookup := func(name string) (any, error) {
+		if v, ok := vars[name]; ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("undefined variable: %q", name)
+	}
+
+	tests := map[string]bool{
+		"$show":                      true,
+		"!$show":                     false,
+		"$count > 2 && $show":        true,
+		"$count >= 4 || !$show":      false,
+		"$count != 3":                false,
+		"$count * 2 == 6":            true,
+		`"20" > 0`:                   true,
+		`"" > 0 || "" == ''`:         true,
+		`$label == "Sale"`:           true,
+		`'apple' < 'banana'`:         true,
+		`"say \"hi\"" != 'say "hi"'`: false,
+		"(false || true) && !false":  true,
+	}
+	for expr, want := range tests {
+		got, err := evaluateExpression(expr, lookup)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", expr, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s: expected %v, got %v", expr, want, got)
+		}
+	}
+
+	for _, tt := range []struct {
+		expr string
+		want string
+	}{
+		{`"open`, "unterminated string"},
+		{"true < 1", "cannot apply <"},
+	} {
+		if _, err := evaluateExpression(tt.expr, lookup); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected %q, got %v", tt.expr, tt.want, err)
+		}
+	}
+}
+
+func TestTruthy(t *testing.T) {
+	for _, v := range []any{true, 1.0, "yes", "-1"} {
+		if !truthy(v) {
+			t.Errorf("expected %v to be true", v)
+		}
+	}
+	for _, v := range []any{false, 0.0, "", "0", "false", nil} {
+		if truthy(v) {
+			t.Errorf("expected %v to be false", v)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// withFilters wraps a placeholder lookup so placeholders can pipe their
+// value through formatting filters, as in {{price | currency:EUR}} or
+// {{date | date:"2 Jan 2006"}}. Numbers are written with the separators of
+// locale. Placeholders that lookup does not know are left whole, filters
+// included, for a later pass.
+func withFilters(lookup func(field string) (string, bool, error), locale string) func(placeholder string) (string, bool, error) {
+	return func(placeholder string) (string, bool, error) {
+		field, filters, err := parseFilters(placeholder)
+		if err != nil {
+			return "", false, fmt.Errorf("placeholder %q: %w", placeholder, err)
+		}
+		value, ok, err := lookup(field)
+		if !ok || err != nil {
+			return value, ok, err
+		}
+		for _, f := range filters {
+			if value, err = f.apply(value, locale); err != nil {
+				return "", false, fmt.Errorf("field %q: %w", field, err)
+			}
+		}
+		return value, true, nil
+	}
+}
+
+// filter is a formatting filter of a placeholder, with its optional
+// argument.
+type filter struct {
+	name   string
+	arg    string
+	hasArg bool
+}
+
+// parseFilters splits a placeholder into its field and filters. Arguments
+// follow a colon and may be quoted to hold spaces or pipes.
+func parseFilters(placeholder string) (string, []filter, error) {
+	parts, err := splitUnquoted(placeholder, '|')
+	if err != nil {
+		return "", nil, err
+	}
+	field := strings.TrimSpace(parts[0])
+	var filters []filter
+	for _, part := range parts[1:] {
+		name, arg, hasArg := strings.Cut(strings.TrimSpace(part), ":")
+		f := filter{name: strings.TrimSpace(name), hasArg: hasArg}
+		if f.name == "" {
+			return "", nil, fmt.Errorf("missing filter name")
+		}
+		if hasArg {
+			arg = strings.TrimSpace(arg)
+			if strings.HasPrefix(arg, `"`) || strings.HasPrefix(arg, "'") {
+				unquoted, err := unquoteArg(arg)
+				if err != nil {
+					return "", nil, fmt.Errorf("filter %s: %w", f.name, err)
+				}
+				arg = unquoted
+			}
+			f.arg = arg
+		}
+		filters = append(filters, f)
+	}
+	return field, filters, nil
+}
+
+// splitUnquoted splits s at each sep outside quotes.
+func splitUnquoted(s string, sep byte) ([]string, error) {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated string")
+	}
+	return append(parts, s[start:]), nil
+}
+
+// unquoteArg unquotes a double-quoted argument with Go escapes or a
+// single-quoted one taken as is.
+func unquoteArg(arg string) (string, error) {
+	if strings.HasPrefix(arg, "'") {
+		if len(arg) < 2 || !strings.HasSuffix(arg, "'") {
+			return "", fmt.Errorf("invalid argument %s", arg)
+		}
+		return arg[1 : len(arg)-1], nil
+	}
+	s, err := strconv.Unquote(arg)
+	if err != nil {
+		return "", fmt.Errorf("invalid argument %s", arg)
+	}
+	return s, nil
+}
+
+// apply formats a value. Empty values stay empty, so missing data does not
+// fail the render.
+func (f filter) apply(value, locale string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	switch f.name {
+	case "number":
+		n, err := parseNumber(value)
+		if err != nil {
+			return "", err
+		}
+		decimals := -1
+		if f.hasArg {
+			if decimals, err = strconv.Atoi(f.arg); err != nil || decimals < 0 {
+				return "", fmt.Errorf("number: invalid decimals %q", f.arg)
+			}
+		}
+		return formatNumber(n, decimals, numberFormatFor(locale)), nil
+	case "currency":
+		if !f.hasArg || f.arg == "" {
+			return "", fmt.Errorf("currency: missing currency code, e.g. currency:EUR")
+		}
+		n, err := parseNumber(value)
+		if err != nil {
+			return "", err
+		}
+		return formatCurrency(n, strings.ToUpper(f.arg), numberFormatFor(locale)), nil
+	case "date":
+		t, err := parseDate(value)
+		if err != nil {
+			return "", err
+		}
+		layout := defaultDateLayout
+		if f.hasArg {
+			layout = f.arg
+		}
+		return t.Format(layout), nil
+	default:
+		return "", fmt.Errorf("unknown filter %q", f.name)
+	}
+}
+
+func parseNumber(value string) (float64, error) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, fmt.Errorf("cannot format %q as a number", value)
+	}
+	return n, nil
+}
+
+// defaultDateLayout formats dates without an explicit layout. Month and day
+// names are always English, since only number separators follow the locale.
+const defaultDateLayout = "2 January 2006"
+
+// dateLayouts are the layouts dates are read in.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot format %q as a date, expected YYYY-MM-DD or RFC 3339", value)
+}
+
+// numberFormat holds the conventions numbers are written with in a locale.
+type numberFormat struct {
+	decimal        string
+	group          string
+	currencyBefore bool // the currency symbol precedes the amount, as in €5.00
+}
+
+var (
+	pointFormat = numberFormat{decimal: ".", group: ",", currencyBefore: true}
+	commaFormat = numberFormat{decimal: ",", group: "."}
+	spaceFormat = numberFormat{decimal: ",", group: " "}
+)
+
+// numberFormats maps locales, or their language, to their number format.
+// Locales not listed use pointFormat.
+var numberFormats = map[string]numberFormat{
+	"de": commaFormat, "es": commaFormat, "it": commaFormat, "pt": commaFormat, "nl": commaFormat,
+	"da": commaFormat, "el": commaFormat, "id": commaFormat, "tr": commaFormat, "ro": commaFormat,
+	"fr": spaceFormat, "ru": spaceFormat, "pl": spaceFormat, "cs": spaceFormat, "sk": spaceFormat,
+	"sv": spaceFormat, "fi": spaceFormat, "nb": spaceFormat, "no": spaceFormat, "uk": spaceFormat, "hu": spaceFormat,
+	"de-CH": {decimal: ".", group: "’"},
+	"es-MX": pointFormat, "es-US": pointFormat,
+	"pt-BR": {decimal: ",", group: ".", currencyBefore: true},
+}
+
+// numberFormatFor returns the number format of a locale such as es or
+// pt-BR (pt_BR is accepted too), falling back to that of its language.
+func numberFormatFor(locale string) numberFormat {
+	locale = strings.ReplaceAll(locale, "_", "-")
+	if f, ok := numberFormats[locale]; ok {
+		return f
+	}
+	language, _, _ := strings.Cut(locale, "-")
+	if f, ok := numberFormats[strings.ToLower(language)]; ok {
+		return f
+	}
+	return pointFormat
+}
+
+// formatNumber writes n with the separators of f and a fixed number of
+// decimals, or the shortest exact ones when decimals is negative.
+func formatNumber(n float64, decimals int, f numberFormat) string {
+	s := strconv.FormatFloat(math.Abs(n), 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if n < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(f.group)
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString(f.decimal)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// currencies maps currency codes to their symbol and decimals. Other codes
+// are written as is, with two decimals.
+var currencies = map[string]struct {
+	symbol   string
+	decimals int
+}{
+	"EUR": {"€", 2},
+	"USD": {"$", 2},
+	"GBP": {"£", 2},
+	"JPY": {"¥", 0},
+	"CNY": {"¥", 2},
+	"INR": {"₹", 2},
+	"BRL": {"R$", 2},
+	"MXN": {"$", 2},
+	"KRW": {"₩", 0},
+}
+
+// formatCurrency writes an amount with the symbol of a currency, before or
+// after it as the locale does. Spaces are non-breaking, so amounts are not
+// wrapped across lines.
+func formatCurrency(n float64, code string, f numberFormat) string {
+	symbol, decimals := code, 2
+	if c, ok := currencies[code]; ok {
+		symbol, decimals = c.symbol, c.decimals
+	}
+	amount := formatNumber(n, decimals, f)
+	if !f.currencyBefore {
+		return amount + " " + symbol
+	}
+	if len(symbol) == 3 && symbol == code {
+		symbol += " "
+	}
+	if sign, rest, ok := strings.Cut(amount, "-"); ok && sign == "" {
+		return "-" + symbol + rest
+	}
+	return symbol + amount
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestFormatFilters(t *testing.T) {
+	fields := map[string]string{
+		"price": "1234.5",
+		"debt":  "-0.004",
+		"big":   "1234567.891",
+		"date":  "2026-03-07",
+		"at":    "2026-03-07T18:30:00Z",
+		"empty": "",
+	}
+	lookup := func(field string) (string, bool, error) {
+		value, ok := fields[field]
+		return value, ok, nil
+	}
+
+	tests := []struct {
+		locale      string
+		placeholder string
+		want        string
+	}{
+		{"en", "price | number", "1,234.5"},
+		{"en", "price | number:2", "1,234.50"},
+		{"en", "big|number:0", "1,234,568"},
+		{"en", "debt | number:2", "0.00"},
+		{"es", "big | number:2", "1.234.567,89"},
+		{"fr", "big | number:1", "1 234 567,9"},
+		{"de-CH", "big | number:2", "1’234’567.89"},
+		{"pt_BR", "price | number:2", "1.234,50"},
+		{"en", "price | currency:EUR", "€1,234.50"},
+		{"en-US", "debt | currency:usd", "$0.00"},
+		{"en", "big | currency:JPY", "¥1,234,568"},
+		{"en", "price | currency:CHF", "CHF 1,234.50"},
+		{"es", "price | currency:EUR", "1.234,50 €"},
+		{"pt-BR", "price | currency:BRL", "R$1.234,50"},
+		{"en", `date | date:"2 Jan 2006"`, "7 Mar 2026"},
+		{"en", "date | date", "7 March 2026"},
+		{"en", "at | date:'15:04 | Mon'", "18:30 | Sat"},
+		{"en", "empty | currency:EUR", ""},
+		{"en", "price", "1234.5"},
+		{"en", "missing | number:2", "{{missing | number:2}}"},
+	}
+	for _, tt := range tests {
+		got, err := shared.ReplacePlaceholders("{{"+tt.placeholder+"}}", withFilters(lookup, tt.locale))
+		if err != nil {
+			t.Errorf("%s (%s): unexpected error: %v", tt.placeholder, tt.locale, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s (%s): expected %q, got %q", tt.placeholder, tt.locale, tt.want, got)
+		}
+	}
+}
+
+func TestFormatFilterErrors(t *testing.T) {
+	fields := map[string]string{"name": "Tea", "date": "07/03/2026", "price": "2.5"}
+	lookup := func(field string) (string, bool, error) {
+		value, ok := fields[field]
+		return value, ok, nil
+	}
+	tests := map[string]string{
+		"name | number":        `field "name": cannot format "Tea" as a number`,
+		"price | number:-1":    `number: invalid decimals "-1"`,
+		"price | currency":     "currency: missing currency code",
+		"date | date":          `cannot format "07/03/2026" as a date`,
+		"price | upper":        `unknown filter "upper"`,
+		"price | ":             "missing filter name",
+		`date | date:"2 Jan`:   "unterminated string",
+		`date | date:"2 Jan"x`: `invalid argument "2 Jan"x`,
+	}
+	for placeholder, want := range tests {
+		_, err := shared.ReplacePlaceholders("{{"+placeholder+"}}", withFilters(lookup, "en"))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected %q, got %v", placeholder, want, err)
+		}
+	}
+}
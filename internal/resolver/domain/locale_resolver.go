@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"fmt"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// LocaleResolver replaces the @{key} translation references of text content
+// and string variable values with the strings of a locale, falling back to
+// the strings of the document's source locale. It runs after components are
+// expanded and before repeaters and records are filled, so translations can
+// hold {{placeholders}}. The document's locale becomes the one translated
+// to, so placeholders format numbers as it does.
+type LocaleResolver struct {
+	locale string
+	tables map[string]shared.Translations
+}
+
+// NewLocaleResolver creates a LocaleResolver for a locale, given the string
+// tables of every locale. An empty locale selects the source locale.
+func NewLocaleResolver(locale string, tables map[string]shared.Translations) *LocaleResolver {
+	return &LocaleResolver{locale: locale, tables: tables}
+}
+
+func (r *LocaleResolver) Resolve(doc *shared.Document) error {
+	fallback := doc.TextLocale()
+	locale := r.locale
+	if locale == "" {
+		locale = fallback
+	}
+	if _, ok := r.tables[locale]; !ok && locale != fallback {
+		return fmt.Errorf("unknown locale %q", locale)
+	}
+
+	lookup := func(key string) (string, error) {
+		if s, ok := r.tables[locale][key]; ok {
+			return s, nil
+		}
+		if s, ok := r.tables[fallback][key]; ok {
+			return s, nil
+		}
+		return "", fmt.Errorf("missing translation %q", key)
+	}
+
+	for name, v := range doc.Variables {
+		var err error
+		if v.Value, err = translateValue(v.Value, lookup); err != nil {
+			return fmt.Errorf("variable %q: %w", name, err)
+		}
+		for i := range v.Themed {
+			if v.Themed[i].Value, err = translateValue(v.Themed[i].Value, lookup); err != nil {
+				return fmt.Errorf("variable %q: %w", name, err)
+			}
+		}
+		doc.Variables[name] = v
+	}
+
+	if err := translateNodes(doc.Children, lookup); err != nil {
+		return err
+	}
+	doc.Locale = locale
+	return nil
+}
+
+func translateNodes(nodes []shared.Node, lookup func(key string) (string, error)) error {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *shared.Text:
+			content, err := shared.Translate(n.Content, lookup)
+			if err != nil {
+				return fmt.Errorf("text %q content: %w", n.ID, err)
+			}
+			n.Content = content
+		case *shared.Frame:
+			if err := translateNodes(n.Children, lookup); err != nil {
+				return err
+			}
+			if n.Repeat != nil {
+				if err := translateNodes(n.Repeat.Empty, lookup); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func translateValue(value any, lookup func(key string) (string, error)) (any, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	return shared.Translate(s, lookup)
+}
@@ -0,0 +1,92 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	resolver "github.com/vpedrosa/pen2pdf/internal/resolver/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestLocaleResolverImplementsPort(t *testing.T) {
+	var _ resolver.Resolver = resolver.NewLocaleResolver("", nil)
+}
+
+func localizedDoc() *shared.Document {
+	return &shared.Document{
+		Children: []shared.Node{&shared.Frame{
+			ID: "cover",
+			Children: []shared.Node{
+				&shared.Text{ID: "title", Content: "@{title}"},
+				&shared.Text{ID: "offer", Content: "@{ offer.label }: 20% — @{offer.until}"},
+			},
+			Repeat: &shared.Repeat{Data: "prices", Empty: []shared.Node{&shared.Text{ID: "none", Content: "@{sold-out}"}}},
+		}},
+		Variables: map[string]shared.Variable{
+			"tagline": {Type: shared.VariableString, Value: "@{tagline}", Themed: []shared.ThemedValue{
+				{Value: "@{tagline.dark}", Theme: shared.Theme{"mode": "dark"}},
+			}},
+			"size": {Type: shared.VariableNumber, Value: 12.0},
+		},
+	}
+}
+
+func TestLocaleResolverTranslates(t *testing.T) {
+	tables := map[string]shared.Translations{
+		"en": {"title": "Welcome", "offer.label": "Offer", "offer.until": "until May", "sold-out": "Sold out",
+			"tagline": "Fresh", "tagline.dark": "Late night"},
+		"es": {"title": "Bienvenida", "offer.label": "Oferta", "sold-out": "Agotado", "tagline": "Fresco",
+			"tagline.dark": "Noche"},
+	}
+	doc := localizedDoc()
+	if err := resolver.NewLocaleResolver("es", tables).Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cover := doc.Children[0].(*shared.Frame)
+	if got := cover.Children[0].(*shared.Text).Content; got != "Bienvenida" {
+		t.Errorf("expected translated title, got %q", got)
+	}
+	// Keys the locale lacks fall back to the source locale
+	if got := cover.Children[1].(*shared.Text).Content; got != "Oferta: 20% — until May" {
+		t.Errorf("expected fallback for missing keys, got %q", got)
+	}
+	if got := cover.Repeat.Empty[0].(*shared.Text).Content; got != "Agotado" {
+		t.Errorf("expected translated empty state, got %q", got)
+	}
+	if v := doc.Variables["tagline"]; v.Value != "Fresco" || v.Themed[0].Value != "Noche" {
+		t.Errorf("expected translated variable, got %v", v)
+	}
+	if doc.Locale != "es" {
+		t.Errorf("expected the document locale to become es, got %q", doc.Locale)
+	}
+}
+
+func TestLocaleResolverTextLocale(t *testing.T) {
+	doc := localizedDoc()
+	doc.Locale = "es"
+	tables := map[string]shared.Translations{
+		"es": {"title": "Bienvenida", "offer.label": "Oferta", "offer.until": "hasta mayo", "sold-out": "Agotado",
+			"tagline": "Fresco", "tagline.dark": "Noche"},
+	}
+	if err := resolver.NewLocaleResolver("", tables).Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := doc.Children[0].(*shared.Frame).Children[0].(*shared.Text).Content; got != "Bienvenida" {
+		t.Errorf("expected the document locale, got %q", got)
+	}
+}
+
+func TestLocaleResolverErrors(t *testing.T) {
+	tables := map[string]shared.Translations{"en": {"title": "Welcome", "tagline": "Fresh", "tagline.dark": "Late night"}, "es": {}}
+	tests := map[string]string{
+		"fr": `unknown locale "fr"`,
+		"es": `text "offer" content: missing translation "offer.label"`,
+	}
+	for locale, want := range tests {
+		err := resolver.NewLocaleResolver(locale, tables).Resolve(localizedDoc())
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected %q, got %v", locale, want, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// RecordBinder fills the {{field}} placeholders of a template document with
+// the fields of a data record: in text content, image URLs (of image nodes
+// and image fills) and string variable values, formatting fields with the
+// filters of placeholders such as {{price | currency:EUR}}. It runs after
+// components are expanded and before variables are resolved, so
+// placeholders can come from instance overrides and reach any property
+// through variables.
+type RecordBinder struct {
+	record shared.Record
+}
+
+func NewRecordBinder(record shared.Record) *RecordBinder {
+	return &RecordBinder{record: record}
+}
+
+func (b *RecordBinder) Resolve(doc *shared.Document) error {
+	lookup := withFilters(b.lookup, doc.TextLocale())
+	for name, v := range doc.Variables {
+		value, err := expandValue(v.Value, lookup)
+		if err != nil {
+			return fmt.Errorf("variable %q: %w", name, err)
+		}
+		v.Value = value
+		for i := range v.Themed {
+			if v.Themed[i].Value, err = expandValue(v.Themed[i].Value, lookup); err != nil {
+				return fmt.Errorf("variable %q: %w", name, err)
+			}
+		}
+		doc.Variables[name] = v
+	}
+
+	for _, child := range doc.Children {
+		if err := bindNode(child, lookup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bindNode(node shared.Node, lookup func(field string) (string, bool, error)) error {
+	if err := fillPlaceholders(node, lookup); err != nil {
+		return err
+	}
+	if frame, ok := node.(*shared.Frame); ok {
+		for _, child := range frame.Children {
+			if err := bindNode(child, lookup); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *RecordBinder) lookup(field string) (string, bool, error) {
+	value, ok := b.record[field]
+	if !ok {
+		return "", false, fmt.Errorf("unknown field %q", field)
+	}
+	return value, true, nil
+}
+
+// fillPlaceholders replaces the {{field}} placeholders of a node, not of its
+// children: in text content, the URLs of images and image fills, and
+// visibility conditions, where values become quoted strings.
+func fillPlaceholders(node shared.Node, lookup func(field string) (string, bool, error)) error {
+	if v := shared.NodeVisibility(node); v != nil && v.Condition != "" {
+		condition, err := shared.ReplacePlaceholders(v.Condition, func(field string) (string, bool, error) {
+			value, ok, err := lookup(field)
+			return strconv.Quote(value), ok, err
+		})
+		if err != nil {
+			return fmt.Errorf("%s %q visible: %w", node.GetType(), node.GetID(), err)
+		}
+		v.Condition = condition
+	}
+
+	switch n := node.(type) {
+	case *shared.Frame:
+		if err := fillURLs(n.Fills, lookup); err != nil {
+			return fmt.Errorf("frame %q %w", n.ID, err)
+		}
+	case *shared.Text:
+		content, err := shared.ReplacePlaceholders(n.Content, lookup)
+		if err != nil {
+			return fmt.Errorf("text %q content: %w", n.ID, err)
+		}
+		n.Content = content
+	case *shared.Shape:
+		if err := fillURLs(n.Fills, lookup); err != nil {
+			return fmt.Errorf("%s %q %w", n.Type, n.ID, err)
+		}
+	case *shared.Path:
+		if err := fillURLs(n.Fills, lookup); err != nil {
+			return fmt.Errorf("path %q %w", n.ID, err)
+		}
+	case *shared.Image:
+		url, err := shared.ReplacePlaceholders(n.URL, lookup)
+		if err != nil {
+			return fmt.Errorf("image %q url: %w", n.ID, err)
+		}
+		n.URL = url
+	}
+	return nil
+}
+
+// fillURLs fills the URLs of image fills, naming the fill in errors as
+// resolveFills does.
+func fillURLs(fills []*shared.Fill, lookup func(field string) (string, bool, error)) error {
+	for i, fill := range fills {
+		if fill.Type != shared.FillImage {
+			continue
+		}
+		url, err := shared.ReplacePlaceholders(fill.URL, lookup)
+		if err != nil {
+			if len(fills) > 1 {
+				return fmt.Errorf("fill %d url: %w", i, err)
+			}
+			return fmt.Errorf("fill url: %w", err)
+		}
+		fill.URL = url
+	}
+	return nil
+}
+
+func expandValue(value any, lookup func(field string) (string, bool, error)) (any, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	return shared.ReplacePlaceholders(s, lookup)
+}
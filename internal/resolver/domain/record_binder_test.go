@@ -0,0 +1,107 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	resolver "github.com/vpedrosa/pen2pdf/internal/resolver/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestRecordBinderImplementsPort(t *testing.T) {
+	var _ resolver.Resolver = resolver.NewRecordBinder(nil)
+}
+
+func TestRecordBinderFillsPlaceholders(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID:    "badge",
+				Fills: []*shared.Fill{shared.ImageFill("./photos/{{id}}.png", "", 1, true)},
+				Children: []shared.Node{
+					&shared.Text{ID: "name", Content: "{{ name }}"},
+					&shared.Text{ID: "role", Bindings: shared.Bindings{"content": "role-label"}},
+					&shared.Image{ID: "logo", URL: "./logos/{{company}}.svg"},
+				},
+			},
+		},
+		Variables: map[string]shared.Variable{
+			"role-label": {Type: shared.VariableString, Value: "Role: {{role}}"},
+			"accent": {Type: shared.VariableColor, Value: "#000000", Themed: []shared.ThemedValue{
+				{Value: "{{color}}", Theme: shared.Theme{"mode": "dark"}},
+			}},
+			"size": {Type: shared.VariableNumber, Value: 12.0},
+		},
+	}
+	record := shared.Record{"id": "42", "name": "Ada", "role": "Speaker", "company": "acme", "color": "#FF0000"}
+
+	if err := resolver.NewRecordBinder(record).Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	badge := doc.Children[0].(*shared.Frame)
+	if badge.Fills[0].URL != "./photos/42.png" {
+		t.Errorf("expected image fill URL, got %q", badge.Fills[0].URL)
+	}
+	if text := badge.Children[0].(*shared.Text); text.Content != "Ada" {
+		t.Errorf("expected text content, got %q", text.Content)
+	}
+	if img := badge.Children[2].(*shared.Image); img.URL != "./logos/acme.svg" {
+		t.Errorf("expected image URL, got %q", img.URL)
+	}
+	if v := doc.Variables["role-label"]; v.Value != "Role: Speaker" {
+		t.Errorf("expected variable value, got %v", v.Value)
+	}
+	if v := doc.Variables["accent"]; v.Themed[0].Value != "#FF0000" {
+		t.Errorf("expected themed variable value, got %v", v.Themed[0].Value)
+	}
+}
+
+func TestRecordBinderUnknownField(t *testing.T) {
+	tests := map[string]struct {
+		doc  *shared.Document
+		want string
+	}{
+		"text": {
+			doc:  &shared.Document{Children: []shared.Node{&shared.Text{ID: "t1", Content: "Hi {{nickname}}"}}},
+			want: `text "t1" content: unknown field "nickname"`,
+		},
+		"variable": {
+			doc: &shared.Document{Variables: map[string]shared.Variable{
+				"title": {Type: shared.VariableString, Value: "{{title}}"},
+			}},
+			want: `variable "title": unknown field "title"`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := resolver.NewRecordBinder(shared.Record{"name": "Ada"}).Resolve(tt.doc)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRecordBinderFormatsFields(t *testing.T) {
+	doc := &shared.Document{
+		Locale: "es",
+		Children: []shared.Node{
+			&shared.Text{ID: "price", Content: "{{price | currency:EUR}} · {{date | date:\"2/1/2006\"}}"},
+		},
+		Variables: map[string]shared.Variable{
+			"total": {Type: shared.VariableString, Value: "{{total | number:2}}"},
+		},
+	}
+	record := shared.Record{"price": "1250", "date": "2026-03-07", "total": "9876.5"}
+
+	if err := resolver.NewRecordBinder(record).Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := doc.Children[0].(*shared.Text).Content; got != "1.250,00 € · 7/3/2026" {
+		t.Errorf("unexpected content %q", got)
+	}
+	if v := doc.Variables["total"]; v.Value != "9.876,50" {
+		t.Errorf("expected formatted variable, got %v", v.Value)
+	}
+}
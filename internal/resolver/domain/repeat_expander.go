@@ -0,0 +1,195 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+// RepeatExpander replaces the children of repeater frames with a copy of
+// them for each item of their data list, filling item placeholders such as
+// {{item.name}} and {{index}}. A frame whose list is empty shows its empty
+// state instead. It runs after components are expanded, so repeaters work
+// inside components and templates can instantiate them.
+type RepeatExpander struct{}
+
+func NewRepeatExpander() *RepeatExpander {
+	return &RepeatExpander{}
+}
+
+func (e *RepeatExpander) Resolve(doc *shared.Document) error {
+	for _, child := range doc.Children {
+		if err := expandRepeats(child, doc.Data, doc.TextLocale(), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// repeatItem is the current item of an enclosing repeater.
+type repeatItem struct {
+	alias string
+	value any
+	index int // 0-based
+	count int
+}
+
+// expandRepeats fills the placeholders of the enclosing items in a node and
+// expands the repeaters in its subtree. items runs from the outermost to
+// the innermost repeater, and locale formats numbers of placeholders with
+// filters.
+func expandRepeats(node shared.Node, data map[string]any, locale string, items []repeatItem) error {
+	if len(items) > 0 {
+		if err := fillPlaceholders(node, withFilters(itemLookup(items), locale)); err != nil {
+			return err
+		}
+	}
+
+	frame, ok := node.(*shared.Frame)
+	if !ok {
+		return nil
+	}
+	if frame.Repeat == nil {
+		for _, child := range frame.Children {
+			if err := expandRepeats(child, data, locale, items); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	repeat := frame.Repeat
+	frame.Repeat = nil
+	list, err := lookupList(repeat.Data, data, items)
+	if err != nil {
+		return fmt.Errorf("frame %q repeat: %w", frame.ID, err)
+	}
+
+	if len(list) == 0 {
+		frame.Children = repeat.Empty
+		for _, child := range frame.Children {
+			if err := expandRepeats(child, data, locale, items); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	template := frame.Children
+	frame.Children = make([]shared.Node, 0, len(list)*len(template))
+	for i, value := range list {
+		scope := append(items[:len(items):len(items)], repeatItem{
+			alias: repeat.Alias(),
+			value: value,
+			index: i,
+			count: len(list),
+		})
+		for _, node := range template {
+			clone := shared.CloneNode(node)
+			if err := expandRepeats(clone, data, locale, scope); err != nil {
+				return fmt.Errorf("frame %q item %d: %w", frame.ID, i+1, err)
+			}
+			frame.Children = append(frame.Children, clone)
+		}
+	}
+	return nil
+}
+
+// itemLookup resolves the placeholders of enclosing items: {{index}} (from
+// 1), {{index0}} (from 0) and {{count}} of the innermost repeater, and
+// {{alias}} or {{alias.field}} of any of them. Other placeholders are left
+// for a later pass.
+func itemLookup(items []repeatItem) func(field string) (string, bool, error) {
+	return func(field string) (string, bool, error) {
+		current := items[len(items)-1]
+		switch field {
+		case "index":
+			return strconv.Itoa(current.index + 1), true, nil
+		case "index0":
+			return strconv.Itoa(current.index), true, nil
+		case "count":
+			return strconv.Itoa(current.count), true, nil
+		}
+
+		for i := len(items) - 1; i >= 0; i-- {
+			item := items[i]
+			var path []string
+			if field != item.alias {
+				rest, ok := strings.CutPrefix(field, item.alias+".")
+				if !ok {
+					continue
+				}
+				path = strings.Split(rest, ".")
+			}
+			value, ok := dataField(item.value, path)
+			if !ok {
+				return "", false, fmt.Errorf("unknown field %q", field)
+			}
+			s, err := formatData(value)
+			if err != nil {
+				return "", false, fmt.Errorf("field %q: %w", field, err)
+			}
+			return s, true, nil
+		}
+		return "", false, nil
+	}
+}
+
+// lookupList returns the list at a dotted path, which starts at the alias
+// of an enclosing item or at the document data.
+func lookupList(path string, data map[string]any, items []repeatItem) ([]any, error) {
+	segments := strings.Split(path, ".")
+	var root any = data
+	for i := len(items) - 1; i >= 0; i-- {
+		if items[i].alias == segments[0] {
+			root, segments = items[i].value, segments[1:]
+			break
+		}
+	}
+
+	value, ok := dataField(root, segments)
+	if !ok {
+		return nil, fmt.Errorf("data %q not found", path)
+	}
+	switch v := value.(type) {
+	case []any:
+		return v, nil
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("data %q is not a list", path)
+	}
+}
+
+// dataField follows a path of object fields.
+func dataField(value any, path []string) (any, bool) {
+	for _, field := range path {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[field]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// formatData returns the text of a JSON value. Numbers are written without
+// trailing zeros.
+func formatData(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("expected a string, number or boolean")
+	}
+}
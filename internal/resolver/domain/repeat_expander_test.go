@@ -0,0 +1,203 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	resolver "github.com/vpedrosa/pen2pdf/internal/resolver/domain"
+	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestRepeatExpanderImplementsPort(t *testing.T) {
+	var _ resolver.Resolver = resolver.NewRepeatExpander()
+}
+
+func TestRepeatExpanderClonesTemplatePerItem(t *testing.T) {
+	list := &shared.Frame{
+		ID:     "list",
+		Layout: "vertical",
+		Repeat: &shared.Repeat{Data: "prices", As: "row"},
+		Children: []shared.Node{
+			&shared.Text{ID: "label", Content: "{{index}}/{{count}} {{row.name}}: {{row.price}} {{currency}}"},
+			&shared.Image{ID: "icon", URL: "./icons/{{row.icon.file}}"},
+		},
+	}
+	doc := &shared.Document{
+		Data: map[string]any{
+			"prices": []any{
+				map[string]any{"name": "Tea", "price": 2.5, "icon": map[string]any{"file": "tea.svg"}},
+				map[string]any{"name": "Coffee", "price": 3.0, "icon": map[string]any{"file": "coffee.svg"}},
+			},
+		},
+		Children: []shared.Node{list},
+	}
+
+	if err := resolver.NewRepeatExpander().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if list.Repeat != nil {
+		t.Error("expected the repeat to be consumed")
+	}
+	if len(list.Children) != 4 {
+		t.Fatalf("expected 4 children, got %d", len(list.Children))
+	}
+	// Placeholders that are not item fields are left for the record binder
+	if got := list.Children[0].(*shared.Text).Content; got != "1/2 Tea: 2.5 {{currency}}" {
+		t.Errorf("unexpected first label %q", got)
+	}
+	if got := list.Children[2].(*shared.Text).Content; got != "2/2 Coffee: 3 {{currency}}" {
+		t.Errorf("unexpected second label %q", got)
+	}
+	if got := list.Children[3].(*shared.Image).URL; got != "./icons/coffee.svg" {
+		t.Errorf("unexpected icon url %q", got)
+	}
+}
+
+func TestRepeatExpanderNestedLists(t *testing.T) {
+	stops := &shared.Frame{
+		ID:       "stops",
+		Repeat:   &shared.Repeat{Data: "day.stops", As: "stop"},
+		Children: []shared.Node{&shared.Text{ID: "stop", Content: "{{day.name}} #{{index}} {{stop}}"}},
+	}
+	days := &shared.Frame{
+		ID:       "days",
+		Repeat:   &shared.Repeat{Data: "trip.days", As: "day"},
+		Children: []shared.Node{stops},
+	}
+	doc := &shared.Document{
+		Data: map[string]any{"trip": map[string]any{"days": []any{
+			map[string]any{"name": "Mon", "stops": []any{"Rome", "Florence"}},
+			map[string]any{"name": "Tue", "stops": []any{"Venice"}},
+		}}},
+		Children: []shared.Node{days},
+	}
+
+	if err := resolver.NewRepeatExpander().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, day := range days.Children {
+		for _, stop := range day.(*shared.Frame).Children {
+			got = append(got, stop.(*shared.Text).Content)
+		}
+	}
+	want := "Mon #1 Rome, Mon #2 Florence, Tue #1 Venice"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("expected %q, got %q", want, strings.Join(got, ", "))
+	}
+}
+
+func TestRepeatExpanderEmptyState(t *testing.T) {
+	list := &shared.Frame{
+		ID: "list",
+		Repeat: &shared.Repeat{
+			Data:  "prices",
+			Empty: []shared.Node{&shared.Text{ID: "none", Content: "Nothing today"}},
+		},
+		Children: []shared.Node{&shared.Text{ID: "label", Content: "{{item.name}}"}},
+	}
+	bare := &shared.Frame{
+		ID:       "bare",
+		Repeat:   &shared.Repeat{Data: "missing-ok"},
+		Children: []shared.Node{&shared.Text{ID: "label", Content: "{{item}}"}},
+	}
+	doc := &shared.Document{
+		Data:     map[string]any{"prices": []any{}, "missing-ok": nil},
+		Children: []shared.Node{list, bare},
+	}
+
+	if err := resolver.NewRepeatExpander().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list.Children) != 1 || list.Children[0].GetID() != "none" {
+		t.Errorf("expected the empty state, got %v", list.Children)
+	}
+	if len(bare.Children) != 0 {
+		t.Errorf("expected no children without an empty state, got %v", bare.Children)
+	}
+}
+
+func TestRepeatExpanderErrors(t *testing.T) {
+	data := map[string]any{
+		"prices": []any{map[string]any{"name": "Tea", "sizes": []any{"S"}}},
+		"title":  "Menu",
+	}
+	tests := map[string]struct {
+		repeat  *shared.Repeat
+		content string
+	}{
+		`frame "list" repeat: data "drinks" not found`:                        {&shared.Repeat{Data: "drinks"}, ""},
+		`frame "list" repeat: data "title" is not a list`:                     {&shared.Repeat{Data: "title"}, ""},
+		`frame "list" item 1: text "label" content: unknown field "item.nme"`: {&shared.Repeat{Data: "prices"}, "{{item.nme}}"},
+		`field "item.sizes": expected a string, number or boolean`:            {&shared.Repeat{Data: "prices"}, "{{item.sizes}}"},
+	}
+	for want, tt := range tests {
+		doc := &shared.Document{
+			Data: data,
+			Children: []shared.Node{&shared.Frame{
+				ID:       "list",
+				Repeat:   tt.repeat,
+				Children: []shared.Node{&shared.Text{ID: "label", Content: tt.content}},
+			}},
+		}
+		err := resolver.NewRepeatExpander().Resolve(doc)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+}
+
+func TestRepeatExpanderFillsConditions(t *testing.T) {
+	list := &shared.Frame{
+		ID:     "list",
+		Repeat: &shared.Repeat{Data: "prices"},
+		Children: []shared.Node{&shared.Text{
+			ID:         "badge",
+			Content:    "-{{item.discount}}%",
+			Visibility: shared.Visibility{Condition: "{{item.discount}} > 0 && {{item.name}} != 'Water'"},
+		}},
+	}
+	doc := &shared.Document{
+		Data: map[string]any{"prices": []any{
+			map[string]any{"name": "Tea", "discount": 20.0},
+			map[string]any{"name": `Say "cheese"`, "discount": ""},
+			map[string]any{"name": "Water", "discount": 5.0},
+		}},
+		Children: []shared.Node{list},
+	}
+
+	resolvers := []resolver.Resolver{resolver.NewRepeatExpander(), resolver.NewVariableResolver()}
+	for _, r := range resolvers {
+		if err := r.Resolve(doc); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	var visible []bool
+	for _, child := range list.Children {
+		visible = append(visible, !shared.IsHidden(child))
+	}
+	if len(visible) != 3 || !visible[0] || visible[1] || visible[2] {
+		t.Errorf("expected only the discounted tea badge, got %v", visible)
+	}
+}
+
+func TestRepeatExpanderFormatsItems(t *testing.T) {
+	list := &shared.Frame{
+		ID:       "list",
+		Repeat:   &shared.Repeat{Data: "prices"},
+		Children: []shared.Node{&shared.Text{ID: "label", Content: "{{item.name}} {{item.price | currency:EUR}} {{tax | number:1}}"}},
+	}
+	doc := &shared.Document{
+		Data:     map[string]any{"prices": []any{map[string]any{"name": "Tea", "price": 2.5}}},
+		Children: []shared.Node{list},
+	}
+
+	if err := resolver.NewRepeatExpander().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Without a document locale amounts are written as in English, and
+	// fields left for the record binder keep their filters
+	if got := list.Children[0].(*shared.Text).Content; got != "Tea €2.50 {{tax | number:1}}" {
+		t.Errorf("unexpected label %q", got)
+	}
+}
@@ -4,8 +4,8 @@ import (
 	shared "github.com/vpedrosa/pen2pdf/internal/shared/domain"
 )
 
-// Resolver replaces $variable references in a document with their concrete
-// values from the document's variables map. It mutates the document in-place.
+// Resolver rewrites a document in place before layout, e.g. expanding
+// component instances or replacing $variable references with their values.
 type Resolver interface {
 	Resolve(doc *shared.Document) error
 }
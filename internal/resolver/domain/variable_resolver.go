@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -8,7 +9,9 @@ import (
 )
 
 // VariableResolver walks the document tree and replaces $variable references
-// with their concrete values from the document's variables map.
+// with their concrete values from the document's variables map. Themed
+// variables take the value of the theme active at each node: the document
+// theme, overridden by the themes of enclosing frames.
 type VariableResolver struct{}
 
 func NewVariableResolver() *VariableResolver {
@@ -16,66 +19,320 @@ func NewVariableResolver() *VariableResolver {
 }
 
 func (r *VariableResolver) Resolve(doc *shared.Document) error {
-	if doc.Variables == nil {
-		return nil
+	if err := doc.CheckTheme(doc.Theme); err != nil {
+		return fmt.Errorf("document theme: %w", err)
+	}
+	for name, v := range doc.Variables {
+		for _, themed := range v.Themed {
+			if err := doc.CheckTheme(themed.Theme); err != nil {
+				return fmt.Errorf("variable %q: %w", name, err)
+			}
+		}
 	}
+
+	s := newScope(doc, doc.ActiveTheme())
 	for _, child := range doc.Children {
-		if err := resolveNode(child, doc.Variables); err != nil {
+		if err := resolveNode(child, s); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func resolveNode(node shared.Node, vars map[string]shared.Variable) error {
+// scope holds what references resolve against at a node, and the values of
+// the variables evaluated under its theme.
+type scope struct {
+	doc    *shared.Document
+	theme  shared.Theme
+	values map[string]any
+	stack  []string // variables being evaluated, to detect cycles
+}
+
+func newScope(doc *shared.Document, theme shared.Theme) *scope {
+	return &scope{doc: doc, theme: theme, values: make(map[string]any)}
+}
+
+// withTheme returns the scope of the descendants of a node that overrides
+// the active theme.
+func (s *scope) withTheme(theme shared.Theme) (*scope, error) {
+	if len(theme) == 0 {
+		return s, nil
+	}
+	if err := s.doc.CheckTheme(theme); err != nil {
+		return nil, err
+	}
+	return newScope(s.doc, s.theme.Merge(theme)), nil
+}
+
+// variable returns a variable and its value under the active theme.
+func (s *scope) variable(name string) (any, shared.Variable, error) {
+	v, ok := s.doc.Variables[name]
+	if !ok {
+		return nil, v, fmt.Errorf("undefined variable: %q", name)
+	}
+	value, err := s.evaluate(name)
+	return value, v, err
+}
+
+// evaluate returns the value of a variable, computing aliases and
+// expressions the first time they are needed.
+func (s *scope) evaluate(name string) (any, error) {
+	if value, ok := s.values[name]; ok {
+		return value, nil
+	}
+	v, ok := s.doc.Variables[name]
+	if !ok {
+		return nil, fmt.Errorf("undefined variable: %q", name)
+	}
+	for i, n := range s.stack {
+		if n == name {
+			path := append(append([]string(nil), s.stack[i:]...), name)
+			return nil, &cycleError{path: path}
+		}
+	}
+
+	value := v.ValueFor(s.theme)
+	if expr, ok := value.(string); ok && isExpression(v.Type, expr) {
+		s.stack = append(s.stack, name)
+		computed, err := evaluateExpression(expr, s.evaluate)
+		s.stack = s.stack[:len(s.stack)-1]
+		if err != nil {
+			var cycle *cycleError
+			if errors.As(err, &cycle) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("variable %q: %w", name, err)
+		}
+		value = computed
+	}
+
+	switch value.(type) {
+	case float64:
+		if v.Type != shared.VariableNumber {
+			return nil, fmt.Errorf("variable %q: %s value expected, got number %v", name, v.Type, value)
+		}
+	case bool:
+		if v.Type != shared.VariableBoolean {
+			return nil, fmt.Errorf("variable %q: %s value expected, got boolean %v", name, v.Type, value)
+		}
+	case string:
+		if v.Type == shared.VariableNumber || v.Type == shared.VariableBoolean {
+			return nil, fmt.Errorf("variable %q: %s value expected, got %q", name, v.Type, value)
+		}
+	}
+	s.values[name] = value
+	return value, nil
+}
+
+// cycleError reports variables that reference each other.
+type cycleError struct {
+	path []string
+}
+
+func (e *cycleError) Error() string {
+	return "variable cycle: " + strings.Join(e.path, " > ")
+}
+
+func resolveNode(node shared.Node, s *scope) error {
 	switch n := node.(type) {
 	case *shared.Frame:
-		return resolveFrame(n, vars)
+		return resolveFrame(n, s)
 	case *shared.Text:
-		return resolveText(n, vars)
+		return resolveText(n, s)
+	case *shared.Shape:
+		return resolveShape(n, s)
+	case *shared.Path:
+		return resolvePath(n, s)
+	case *shared.Image:
+		return resolveImage(n, s)
 	default:
 		return fmt.Errorf("unsupported node type: %T", node)
 	}
 }
 
-func resolveFrame(frame *shared.Frame, vars map[string]shared.Variable) error {
-	if frame.Fill != nil && frame.Fill.Type == shared.FillSolid {
-		resolved, err := resolveColorString(frame.Fill.Color, vars)
+func resolveFrame(frame *shared.Frame, s *scope) error {
+	s, err := s.withTheme(frame.Theme)
+	if err != nil {
+		return fmt.Errorf("frame %q theme: %w", frame.ID, err)
+	}
+
+	if err := resolveVisibility(&frame.Visibility, s); err != nil {
+		return fmt.Errorf("frame %q visible: %w", frame.ID, err)
+	}
+
+	if err := resolveBindings(frame, s); err != nil {
+		return fmt.Errorf("frame %q %w", frame.ID, err)
+	}
+
+	if err := resolveFills(frame.Fills, s); err != nil {
+		return fmt.Errorf("frame %q %w", frame.ID, err)
+	}
+
+	if err := resolveStroke(frame.Stroke, s); err != nil {
+		return fmt.Errorf("frame %q stroke: %w", frame.ID, err)
+	}
+
+	for i, effect := range frame.Effects {
+		if !effect.IsShadow() {
+			continue
+		}
+		resolved, err := resolveColorString(effect.Color, s)
 		if err != nil {
-			return fmt.Errorf("frame %q fill: %w", frame.ID, err)
+			return fmt.Errorf("frame %q effect %d: %w", frame.ID, i, err)
 		}
-		frame.Fill.Color = resolved
+		effect.Color = resolved
 	}
 
 	for _, child := range frame.Children {
-		if err := resolveNode(child, vars); err != nil {
+		if err := resolveNode(child, s); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func resolveText(text *shared.Text, vars map[string]shared.Variable) error {
-	resolved, err := resolveColorString(text.Fill, vars)
+func resolveText(text *shared.Text, s *scope) error {
+	if err := resolveVisibility(&text.Visibility, s); err != nil {
+		return fmt.Errorf("text %q visible: %w", text.ID, err)
+	}
+
+	if err := resolveBindings(text, s); err != nil {
+		return fmt.Errorf("text %q %w", text.ID, err)
+	}
+
+	if err := resolveFills(text.Fills, s); err != nil {
+		return fmt.Errorf("text %q %w", text.ID, err)
+	}
+
+	if err := resolveStroke(text.Stroke, s); err != nil {
+		return fmt.Errorf("text %q stroke: %w", text.ID, err)
+	}
+	return nil
+}
+
+func resolveShape(shape *shared.Shape, s *scope) error {
+	if err := resolveVisibility(&shape.Visibility, s); err != nil {
+		return fmt.Errorf("%s %q visible: %w", shape.Type, shape.ID, err)
+	}
+
+	if err := resolveBindings(shape, s); err != nil {
+		return fmt.Errorf("%s %q %w", shape.Type, shape.ID, err)
+	}
+
+	if err := resolveFills(shape.Fills, s); err != nil {
+		return fmt.Errorf("%s %q %w", shape.Type, shape.ID, err)
+	}
+
+	if err := resolveStroke(shape.Stroke, s); err != nil {
+		return fmt.Errorf("%s %q stroke: %w", shape.Type, shape.ID, err)
+	}
+	return nil
+}
+
+func resolvePath(path *shared.Path, s *scope) error {
+	if err := resolveVisibility(&path.Visibility, s); err != nil {
+		return fmt.Errorf("path %q visible: %w", path.ID, err)
+	}
+
+	if err := resolveBindings(path, s); err != nil {
+		return fmt.Errorf("path %q %w", path.ID, err)
+	}
+
+	if err := resolveFills(path.Fills, s); err != nil {
+		return fmt.Errorf("path %q %w", path.ID, err)
+	}
+
+	if err := resolveStroke(path.Stroke, s); err != nil {
+		return fmt.Errorf("path %q stroke: %w", path.ID, err)
+	}
+	return nil
+}
+
+func resolveImage(image *shared.Image, s *scope) error {
+	if err := resolveVisibility(&image.Visibility, s); err != nil {
+		return fmt.Errorf("image %q visible: %w", image.ID, err)
+	}
+
+	if err := resolveBindings(image, s); err != nil {
+		return fmt.Errorf("image %q %w", image.ID, err)
+	}
+
+	if err := resolveStroke(image.Stroke, s); err != nil {
+		return fmt.Errorf("image %q stroke: %w", image.ID, err)
+	}
+	return nil
+}
+
+// resolveVisibility hides a node whose condition is false.
+func resolveVisibility(v *shared.Visibility, s *scope) error {
+	if v.Condition == "" {
+		return nil
+	}
+	value, err := evaluateExpression(v.Condition, s.evaluate)
+	if err != nil {
+		return err
+	}
+	v.Hidden = v.Hidden || !truthy(value)
+	v.Condition = ""
+	return nil
+}
+
+// resolveFills resolves every fill of a node. Errors name the fill, and its
+// index when the node has several.
+func resolveFills(fills []*shared.Fill, s *scope) error {
+	for i, fill := range fills {
+		if err := resolveFill(fill, s); err != nil {
+			if len(fills) > 1 {
+				return fmt.Errorf("fill %d: %w", i, err)
+			}
+			return fmt.Errorf("fill: %w", err)
+		}
+	}
+	return nil
+}
+
+func resolveFill(fill *shared.Fill, s *scope) error {
+	if fill.Type == shared.FillSolid {
+		resolved, err := resolveColorString(fill.Color, s)
+		if err != nil {
+			return err
+		}
+		fill.Color = resolved
+	}
+	for i := range fill.Stops {
+		resolved, err := resolveColorString(fill.Stops[i].Color, s)
+		if err != nil {
+			return fmt.Errorf("color stop %d: %w", i, err)
+		}
+		fill.Stops[i].Color = resolved
+	}
+	return nil
+}
+
+func resolveStroke(stroke *shared.Stroke, s *scope) error {
+	if stroke == nil {
+		return nil
+	}
+	resolved, err := resolveColorString(stroke.Color, s)
 	if err != nil {
-		return fmt.Errorf("text %q fill: %w", text.ID, err)
+		return err
 	}
-	text.Fill = resolved
+	stroke.Color = resolved
 	return nil
 }
 
-func resolveColorString(value string, vars map[string]shared.Variable) (string, error) {
+func resolveColorString(value string, s *scope) (string, error) {
 	if !strings.HasPrefix(value, "$") {
 		return value, nil
 	}
 
 	name := value[1:]
-	v, ok := vars[name]
-	if !ok {
-		return "", fmt.Errorf("undefined variable: %q", name)
+	resolved, v, err := s.variable(name)
+	if err != nil {
+		return "", err
 	}
 
-	str, ok := v.Value.(string)
+	str, ok := resolved.(string)
 	if !ok {
 		return "", fmt.Errorf("variable %q is not a string (type: %s)", name, v.Type)
 	}
@@ -16,9 +16,9 @@ func TestResolveFrameFillVariable(t *testing.T) {
 	doc := &shared.Document{
 		Children: []shared.Node{
 			&shared.Frame{
-				ID:   "f1",
-				Name: "box",
-				Fill: shared.SolidFill("$primary-color"),
+				ID:    "f1",
+				Name:  "box",
+				Fills: []*shared.Fill{shared.SolidFill("$primary-color")},
 			},
 		},
 		Variables: map[string]shared.Variable{
@@ -32,8 +32,8 @@ func TestResolveFrameFillVariable(t *testing.T) {
 	}
 
 	frame := doc.Children[0].(*shared.Frame)
-	if frame.Fill.Color != "#FF6B35" {
-		t.Errorf("expected '#FF6B35', got '%s'", frame.Fill.Color)
+	if frame.Fills[0].Color != "#FF6B35" {
+		t.Errorf("expected '#FF6B35', got '%s'", frame.Fills[0].Color)
 	}
 }
 
@@ -41,9 +41,9 @@ func TestResolveTextFillVariable(t *testing.T) {
 	doc := &shared.Document{
 		Children: []shared.Node{
 			&shared.Text{
-				ID:   "t1",
-				Name: "label",
-				Fill: "$text-primary",
+				ID:    "t1",
+				Name:  "label",
+				Fills: []*shared.Fill{shared.SolidFill("$text-primary")},
 			},
 		},
 		Variables: map[string]shared.Variable{
@@ -57,8 +57,8 @@ func TestResolveTextFillVariable(t *testing.T) {
 	}
 
 	text := doc.Children[0].(*shared.Text)
-	if text.Fill != "#2C3E50" {
-		t.Errorf("expected '#2C3E50', got '%s'", text.Fill)
+	if text.Fills[0].Color != "#2C3E50" {
+		t.Errorf("expected '#2C3E50', got '%s'", text.Fills[0].Color)
 	}
 }
 
@@ -66,16 +66,16 @@ func TestResolveNestedNodes(t *testing.T) {
 	doc := &shared.Document{
 		Children: []shared.Node{
 			&shared.Frame{
-				ID:   "parent",
-				Name: "page",
-				Fill: shared.SolidFill("$bg-color"),
+				ID:    "parent",
+				Name:  "page",
+				Fills: []*shared.Fill{shared.SolidFill("$bg-color")},
 				Children: []shared.Node{
 					&shared.Frame{
-						ID:   "child",
-						Name: "inner",
-						Fill: shared.SolidFill("$accent-color"),
+						ID:    "child",
+						Name:  "inner",
+						Fills: []*shared.Fill{shared.SolidFill("$accent-color")},
 						Children: []shared.Node{
-							&shared.Text{ID: "t1", Name: "label", Fill: "$text-color"},
+							&shared.Text{ID: "t1", Name: "label", Fills: []*shared.Fill{shared.SolidFill("$text-color")}},
 						},
 					},
 				},
@@ -94,18 +94,18 @@ func TestResolveNestedNodes(t *testing.T) {
 	}
 
 	parent := doc.Children[0].(*shared.Frame)
-	if parent.Fill.Color != "#FFFFFF" {
-		t.Errorf("expected parent fill '#FFFFFF', got '%s'", parent.Fill.Color)
+	if parent.Fills[0].Color != "#FFFFFF" {
+		t.Errorf("expected parent fill '#FFFFFF', got '%s'", parent.Fills[0].Color)
 	}
 
 	child := parent.Children[0].(*shared.Frame)
-	if child.Fill.Color != "#FF0000" {
-		t.Errorf("expected child fill '#FF0000', got '%s'", child.Fill.Color)
+	if child.Fills[0].Color != "#FF0000" {
+		t.Errorf("expected child fill '#FF0000', got '%s'", child.Fills[0].Color)
 	}
 
 	text := child.Children[0].(*shared.Text)
-	if text.Fill != "#000000" {
-		t.Errorf("expected text fill '#000000', got '%s'", text.Fill)
+	if text.Fills[0].Color != "#000000" {
+		t.Errorf("expected text fill '#000000', got '%s'", text.Fills[0].Color)
 	}
 }
 
@@ -113,11 +113,11 @@ func TestResolveNonVariableUnchanged(t *testing.T) {
 	doc := &shared.Document{
 		Children: []shared.Node{
 			&shared.Frame{
-				ID:   "f1",
-				Name: "box",
-				Fill: shared.SolidFill("#FF6B35"),
+				ID:    "f1",
+				Name:  "box",
+				Fills: []*shared.Fill{shared.SolidFill("#FF6B35")},
 			},
-			&shared.Text{ID: "t1", Name: "label", Fill: "#000000"},
+			&shared.Text{ID: "t1", Name: "label", Fills: []*shared.Fill{shared.SolidFill("#000000")}},
 		},
 		Variables: map[string]shared.Variable{},
 	}
@@ -128,13 +128,13 @@ func TestResolveNonVariableUnchanged(t *testing.T) {
 	}
 
 	frame := doc.Children[0].(*shared.Frame)
-	if frame.Fill.Color != "#FF6B35" {
-		t.Errorf("expected '#FF6B35', got '%s'", frame.Fill.Color)
+	if frame.Fills[0].Color != "#FF6B35" {
+		t.Errorf("expected '#FF6B35', got '%s'", frame.Fills[0].Color)
 	}
 
 	text := doc.Children[1].(*shared.Text)
-	if text.Fill != "#000000" {
-		t.Errorf("expected '#000000', got '%s'", text.Fill)
+	if text.Fills[0].Color != "#000000" {
+		t.Errorf("expected '#000000', got '%s'", text.Fills[0].Color)
 	}
 }
 
@@ -154,7 +154,7 @@ func TestResolveNoVariablesMap(t *testing.T) {
 func TestResolveUndefinedVariable(t *testing.T) {
 	doc := &shared.Document{
 		Children: []shared.Node{
-			&shared.Text{ID: "t1", Name: "label", Fill: "$missing-var"},
+			&shared.Text{ID: "t1", Name: "label", Fills: []*shared.Fill{shared.SolidFill("$missing-var")}},
 		},
 		Variables: map[string]shared.Variable{},
 	}
@@ -187,9 +187,9 @@ func TestResolveImageFillUnchanged(t *testing.T) {
 	doc := &shared.Document{
 		Children: []shared.Node{
 			&shared.Frame{
-				ID:   "f1",
-				Name: "bg",
-				Fill: shared.ImageFill("./bg.jpg", "fill", 1.0, true),
+				ID:    "f1",
+				Name:  "bg",
+				Fills: []*shared.Fill{shared.ImageFill("./bg.jpg", "fill", 1.0, true)},
 			},
 		},
 		Variables: map[string]shared.Variable{},
@@ -201,18 +201,18 @@ func TestResolveImageFillUnchanged(t *testing.T) {
 	}
 
 	frame := doc.Children[0].(*shared.Frame)
-	if frame.Fill.Type != shared.FillImage {
-		t.Errorf("expected FillImage, got '%s'", frame.Fill.Type)
+	if frame.Fills[0].Type != shared.FillImage {
+		t.Errorf("expected FillImage, got '%s'", frame.Fills[0].Type)
 	}
-	if frame.Fill.URL != "./bg.jpg" {
-		t.Errorf("expected URL unchanged, got '%s'", frame.Fill.URL)
+	if frame.Fills[0].URL != "./bg.jpg" {
+		t.Errorf("expected URL unchanged, got '%s'", frame.Fills[0].URL)
 	}
 }
 
 func TestResolveEmptyFillStringSkipped(t *testing.T) {
 	doc := &shared.Document{
 		Children: []shared.Node{
-			&shared.Text{ID: "t1", Name: "label", Fill: ""},
+			&shared.Text{ID: "t1", Name: "label", Fills: []*shared.Fill{shared.SolidFill("")}},
 		},
 		Variables: map[string]shared.Variable{},
 	}
@@ -223,8 +223,8 @@ func TestResolveEmptyFillStringSkipped(t *testing.T) {
 	}
 
 	text := doc.Children[0].(*shared.Text)
-	if text.Fill != "" {
-		t.Errorf("expected empty fill, got '%s'", text.Fill)
+	if text.Fills[0].Color != "" {
+		t.Errorf("expected empty fill, got '%s'", text.Fills[0].Color)
 	}
 }
 
@@ -232,13 +232,13 @@ func TestResolveMultipleVariablesSameDocument(t *testing.T) {
 	doc := &shared.Document{
 		Children: []shared.Node{
 			&shared.Frame{
-				ID:   "f1",
-				Name: "page",
-				Fill: shared.SolidFill("$primary-color"),
+				ID:    "f1",
+				Name:  "page",
+				Fills: []*shared.Fill{shared.SolidFill("$primary-color")},
 				Children: []shared.Node{
-					&shared.Frame{ID: "f2", Name: "div", Fill: shared.SolidFill("$secondary-color")},
-					&shared.Text{ID: "t1", Name: "title", Fill: "$text-primary"},
-					&shared.Text{ID: "t2", Name: "subtitle", Fill: "$text-muted"},
+					&shared.Frame{ID: "f2", Name: "div", Fills: []*shared.Fill{shared.SolidFill("$secondary-color")}},
+					&shared.Text{ID: "t1", Name: "title", Fills: []*shared.Fill{shared.SolidFill("$text-primary")}},
+					&shared.Text{ID: "t2", Name: "subtitle", Fills: []*shared.Fill{shared.SolidFill("$text-muted")}},
 				},
 			},
 		},
@@ -256,22 +256,554 @@ func TestResolveMultipleVariablesSameDocument(t *testing.T) {
 	}
 
 	frame := doc.Children[0].(*shared.Frame)
-	if frame.Fill.Color != "#FF6B35" {
-		t.Errorf("expected '#FF6B35', got '%s'", frame.Fill.Color)
+	if frame.Fills[0].Color != "#FF6B35" {
+		t.Errorf("expected '#FF6B35', got '%s'", frame.Fills[0].Color)
 	}
 
 	innerFrame := frame.Children[0].(*shared.Frame)
-	if innerFrame.Fill.Color != "#16A085" {
-		t.Errorf("expected '#16A085', got '%s'", innerFrame.Fill.Color)
+	if innerFrame.Fills[0].Color != "#16A085" {
+		t.Errorf("expected '#16A085', got '%s'", innerFrame.Fills[0].Color)
 	}
 
 	title := frame.Children[1].(*shared.Text)
-	if title.Fill != "#2C3E50" {
-		t.Errorf("expected '#2C3E50', got '%s'", title.Fill)
+	if title.Fills[0].Color != "#2C3E50" {
+		t.Errorf("expected '#2C3E50', got '%s'", title.Fills[0].Color)
 	}
 
 	subtitle := frame.Children[2].(*shared.Text)
-	if subtitle.Fill != "#7F8C8D" {
-		t.Errorf("expected '#7F8C8D', got '%s'", subtitle.Fill)
+	if subtitle.Fills[0].Color != "#7F8C8D" {
+		t.Errorf("expected '#7F8C8D', got '%s'", subtitle.Fills[0].Color)
+	}
+}
+
+func TestResolveStrokeColorVariables(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID:     "f1",
+				Name:   "card",
+				Stroke: shared.SolidStroke("$border-color", 1, shared.StrokeInside),
+				Children: []shared.Node{
+					&shared.Text{ID: "t1", Name: "title", Stroke: shared.SolidStroke("$outline", 2, shared.StrokeOutside)},
+				},
+			},
+		},
+		Variables: map[string]shared.Variable{
+			"border-color": {Type: shared.VariableColor, Value: "#E0E0E0"},
+			"outline":      {Type: shared.VariableColor, Value: "#000000"},
+		},
+	}
+
+	r := resolver.NewVariableResolver()
+	if err := r.Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	frame := doc.Children[0].(*shared.Frame)
+	if frame.Stroke.Color != "#E0E0E0" {
+		t.Errorf("expected '#E0E0E0', got '%s'", frame.Stroke.Color)
+	}
+	text := frame.Children[0].(*shared.Text)
+	if text.Stroke.Color != "#000000" {
+		t.Errorf("expected '#000000', got '%s'", text.Stroke.Color)
+	}
+}
+
+func TestResolveStrokeUndefinedVariable(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{ID: "f1", Name: "card", Stroke: shared.SolidStroke("$missing", 1, shared.StrokeInside)},
+		},
+		Variables: map[string]shared.Variable{},
+	}
+
+	r := resolver.NewVariableResolver()
+	err := r.Resolve(doc)
+	if err == nil {
+		t.Fatal("expected error for undefined stroke variable")
+	}
+	if !strings.Contains(err.Error(), "stroke") {
+		t.Errorf("expected 'stroke' in error, got: %s", err)
+	}
+}
+
+func TestResolveGradientStopVariables(t *testing.T) {
+	fill := shared.GradientFill(shared.FillLinearGradient, []shared.ColorStop{
+		{Color: "$brand", Position: 0},
+		{Color: "#FFFFFF00", Position: 1},
+	})
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{ID: "f1", Name: "hero", Fills: []*shared.Fill{fill}},
+		},
+		Variables: map[string]shared.Variable{
+			"brand": {Type: shared.VariableColor, Value: "#FF6B35"},
+		},
+	}
+
+	r := resolver.NewVariableResolver()
+	if err := r.Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fill.Stops[0].Color != "#FF6B35" {
+		t.Errorf("expected '#FF6B35', got '%s'", fill.Stops[0].Color)
+	}
+	if fill.Stops[1].Color != "#FFFFFF00" {
+		t.Errorf("expected literal stop untouched, got '%s'", fill.Stops[1].Color)
+	}
+}
+
+func TestResolveGradientStopUndefinedVariable(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{ID: "f1", Name: "hero", Fills: []*shared.Fill{shared.GradientFill(shared.FillRadialGradient, []shared.ColorStop{
+				{Color: "#000000", Position: 0},
+				{Color: "$missing", Position: 1},
+			})}},
+		},
+		Variables: map[string]shared.Variable{},
+	}
+
+	r := resolver.NewVariableResolver()
+	err := r.Resolve(doc)
+	if err == nil {
+		t.Fatal("expected error for undefined stop variable")
+	}
+	if !strings.Contains(err.Error(), `frame "f1" fill: color stop 1`) {
+		t.Errorf("expected frame and stop in error, got: %s", err)
+	}
+}
+
+func TestResolveStackedFills(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{ID: "f1", Name: "hero", Fills: []*shared.Fill{
+				shared.ImageFill("./bg.jpg", "fill", 1, true),
+				shared.SolidFill("$tint"),
+			}},
+			&shared.Text{ID: "t1", Name: "label", Fills: []*shared.Fill{
+				shared.SolidFill("$tint"),
+				shared.SolidFill("$missing"),
+			}},
+		},
+		Variables: map[string]shared.Variable{
+			"tint": {Type: shared.VariableColor, Value: "#00000080"},
+		},
+	}
+
+	r := resolver.NewVariableResolver()
+	err := r.Resolve(doc)
+	if err == nil {
+		t.Fatal("expected error for undefined variable in second text fill")
+	}
+	if !strings.Contains(err.Error(), `text "t1" fill 1:`) {
+		t.Errorf("expected text and fill index in error, got: %s", err)
+	}
+
+	frame := doc.Children[0].(*shared.Frame)
+	if frame.Fills[1].Color != "#00000080" {
+		t.Errorf("expected overlay fill resolved, got '%s'", frame.Fills[1].Color)
+	}
+}
+
+func TestResolveShadowColorVariables(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{ID: "f1", Name: "card", Effects: []*shared.Effect{
+				shared.LayerBlur(4),
+				shared.DropShadow("$shadow", 0, 4, 12, 0),
+				shared.InnerShadow("#FFFFFF40", 0, 1, 0, 0),
+			}},
+		},
+		Variables: map[string]shared.Variable{
+			"shadow": {Type: shared.VariableColor, Value: "#00000033"},
+		},
+	}
+
+	r := resolver.NewVariableResolver()
+	if err := r.Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	effects := doc.Children[0].(*shared.Frame).Effects
+	if effects[1].Color != "#00000033" {
+		t.Errorf("expected '#00000033', got '%s'", effects[1].Color)
+	}
+	if effects[2].Color != "#FFFFFF40" {
+		t.Errorf("expected literal color untouched, got '%s'", effects[2].Color)
+	}
+}
+
+func TestResolveShadowUndefinedVariable(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{ID: "f1", Name: "card", Effects: []*shared.Effect{shared.DropShadow("$missing", 0, 4, 12, 0)}},
+		},
+		Variables: map[string]shared.Variable{},
+	}
+
+	r := resolver.NewVariableResolver()
+	err := r.Resolve(doc)
+	if err == nil {
+		t.Fatal("expected error for undefined shadow variable")
+	}
+	if !strings.Contains(err.Error(), `frame "f1" effect 0`) {
+		t.Errorf("expected frame and effect in error, got: %s", err)
+	}
+}
+
+func TestResolveShapeVariables(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID: "f1",
+				Children: []shared.Node{
+					&shared.Shape{
+						ID:     "r1",
+						Type:   shared.NodeTypeRectangle,
+						Fills:  []*shared.Fill{shared.SolidFill("$primary")},
+						Stroke: shared.SolidStroke("$border-color", 1, shared.StrokeInside),
+					},
+				},
+			},
+		},
+		Variables: map[string]shared.Variable{
+			"primary":      {Type: shared.VariableColor, Value: "#FF0000"},
+			"border-color": {Type: shared.VariableColor, Value: "#E0E0E0"},
+		},
+	}
+
+	r := resolver.NewVariableResolver()
+	if err := r.Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	shape := doc.Children[0].(*shared.Frame).Children[0].(*shared.Shape)
+	if shape.Fills[0].Color != "#FF0000" {
+		t.Errorf("expected '#FF0000', got '%s'", shape.Fills[0].Color)
+	}
+	if shape.Stroke.Color != "#E0E0E0" {
+		t.Errorf("expected '#E0E0E0', got '%s'", shape.Stroke.Color)
+	}
+}
+
+func TestResolveShapeUndefinedVariable(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Shape{ID: "e1", Type: shared.NodeTypeEllipse, Fills: []*shared.Fill{shared.SolidFill("$missing")}},
+		},
+		Variables: map[string]shared.Variable{},
+	}
+
+	r := resolver.NewVariableResolver()
+	err := r.Resolve(doc)
+	if err == nil {
+		t.Fatal("expected error for undefined shape variable")
+	}
+	if !strings.Contains(err.Error(), `ellipse "e1" fill`) {
+		t.Errorf("expected shape type and ID in error, got: %s", err)
+	}
+}
+
+func TestResolvePathVariables(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Path{
+				ID:     "p1",
+				Fills:  []*shared.Fill{shared.SolidFill("$icon")},
+				Stroke: shared.SolidStroke("$outline", 1, shared.StrokeCenter),
+			},
+		},
+		Variables: map[string]shared.Variable{
+			"icon":    {Type: shared.VariableColor, Value: "#333333"},
+			"outline": {Type: shared.VariableColor, Value: "#000000"},
+		},
+	}
+
+	r := resolver.NewVariableResolver()
+	if err := r.Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path := doc.Children[0].(*shared.Path)
+	if path.Fills[0].Color != "#333333" {
+		t.Errorf("expected '#333333', got '%s'", path.Fills[0].Color)
+	}
+	if path.Stroke.Color != "#000000" {
+		t.Errorf("expected '#000000', got '%s'", path.Stroke.Color)
+	}
+}
+
+func TestResolvePathUndefinedVariable(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Path{ID: "p1", Stroke: shared.SolidStroke("$missing", 1, shared.StrokeCenter)},
+		},
+		Variables: map[string]shared.Variable{},
+	}
+
+	r := resolver.NewVariableResolver()
+	err := r.Resolve(doc)
+	if err == nil {
+		t.Fatal("expected error for undefined path variable")
+	}
+	if !strings.Contains(err.Error(), `path "p1" stroke`) {
+		t.Errorf("expected path and stroke in error, got: %s", err)
+	}
+}
+
+func TestResolveImageStrokeVariable(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Image{ID: "i1", URL: "./logo.png", Stroke: shared.SolidStroke("$border", 1, shared.StrokeInside)},
+		},
+		Variables: map[string]shared.Variable{
+			"border": {Type: shared.VariableColor, Value: "#E0E0E0"},
+		},
+	}
+
+	r := resolver.NewVariableResolver()
+	if err := r.Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	img := doc.Children[0].(*shared.Image)
+	if img.Stroke.Color != "#E0E0E0" {
+		t.Errorf("expected '#E0E0E0', got '%s'", img.Stroke.Color)
+	}
+	if img.URL != "./logo.png" {
+		t.Errorf("expected URL unchanged, got '%s'", img.URL)
+	}
+}
+
+func themedDocument() *shared.Document {
+	return &shared.Document{
+		Themes: map[string][]string{"mode": {"light", "dark"}},
+		Children: []shared.Node{
+			&shared.Frame{ID: "page", Fills: []*shared.Fill{shared.SolidFill("$bg")}, Children: []shared.Node{
+				&shared.Frame{ID: "inverted", Theme: shared.Theme{"mode": "dark"}, Children: []shared.Node{
+					&shared.Text{ID: "t1", Fills: []*shared.Fill{shared.SolidFill("$bg")}},
+				}},
+			}},
+		},
+		Variables: map[string]shared.Variable{
+			"bg": {Type: shared.VariableColor, Value: "#FFFFFF", Themed: []shared.ThemedValue{
+				{Value: "#000000", Theme: shared.Theme{"mode": "dark"}},
+			}},
+		},
+	}
+}
+
+func TestResolveThemedVariables(t *testing.T) {
+	doc := themedDocument()
+	if err := resolver.NewVariableResolver().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	page := doc.Children[0].(*shared.Frame)
+	if page.Fills[0].Color != "#FFFFFF" {
+		t.Errorf("expected the default theme on the page, got %s", page.Fills[0].Color)
+	}
+	text := page.Children[0].(*shared.Frame).Children[0].(*shared.Text)
+	if text.Fills[0].Color != "#000000" {
+		t.Errorf("expected the frame theme to apply to descendants, got %s", text.Fills[0].Color)
+	}
+}
+
+func TestResolveDocumentTheme(t *testing.T) {
+	doc := themedDocument()
+	doc.Theme = shared.Theme{"mode": "dark"}
+	if err := resolver.NewVariableResolver().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page := doc.Children[0].(*shared.Frame); page.Fills[0].Color != "#000000" {
+		t.Errorf("expected the document theme, got %s", page.Fills[0].Color)
+	}
+}
+
+func TestResolveUnknownTheme(t *testing.T) {
+	doc := themedDocument()
+	doc.Theme = shared.Theme{"mode": "sepia"}
+	err := resolver.NewVariableResolver().Resolve(doc)
+	if err == nil || !strings.Contains(err.Error(), `document theme: unknown mode theme "sepia"`) {
+		t.Errorf("expected unknown document theme error, got %v", err)
+	}
+
+	doc = themedDocument()
+	doc.Children[0].(*shared.Frame).Children[0].(*shared.Frame).Theme = shared.Theme{"contrast": "high"}
+	err = resolver.NewVariableResolver().Resolve(doc)
+	if err == nil || !strings.Contains(err.Error(), `frame "inverted" theme: unknown theme axis "contrast"`) {
+		t.Errorf("expected unknown frame theme error, got %v", err)
+	}
+}
+
+func TestResolveVariableAliasesAndExpressions(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{
+			&shared.Frame{
+				ID:       "card",
+				Fills:    []*shared.Fill{shared.SolidFill("$button-bg")},
+				Stroke:   &shared.Stroke{Color: "$border"},
+				Bindings: shared.Bindings{"gap": "spacing-lg"},
+				Children: []shared.Node{
+					&shared.Text{ID: "t1", Bindings: shared.Bindings{"fontFamily": "font-heading"}},
+				},
+			},
+		},
+		Variables: map[string]shared.Variable{
+			"brand-primary": {Type: shared.VariableColor, Value: "#3366CC"},
+			"button-bg":     {Type: shared.VariableColor, Value: "$brand-primary"},
+			"border":        {Type: shared.VariableColor, Value: "alpha(darken($button-bg, 10%), 0.5)"},
+			"spacing-base":  {Type: shared.VariableNumber, Value: 8.0},
+			"spacing-lg":    {Type: shared.VariableNumber, Value: "$spacing-base * 2 + 4"},
+			"font-body":     {Type: shared.VariableString, Value: "Inter"},
+			"font-heading":  {Type: shared.VariableString, Value: "$font-body"},
+		},
+	}
+
+	if err := resolver.NewVariableResolver().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	card := doc.Children[0].(*shared.Frame)
+	if card.Fills[0].Color != "#3366CC" {
+		t.Errorf("expected the aliased color, got %s", card.Fills[0].Color)
+	}
+	if card.Stroke.Color != "#2952A380" {
+		t.Errorf("expected a darker translucent border, got %s", card.Stroke.Color)
+	}
+	if card.Gap != 20 {
+		t.Errorf("expected gap 20, got %v", card.Gap)
+	}
+	if text := card.Children[0].(*shared.Text); text.FontFamily != "Inter" {
+		t.Errorf("expected the aliased font, got %q", text.FontFamily)
+	}
+}
+
+func TestResolveThemedAlias(t *testing.T) {
+	doc := &shared.Document{
+		Theme:    shared.Theme{"mode": "dark"},
+		Children: []shared.Node{&shared.Frame{ID: "f1", Fills: []*shared.Fill{shared.SolidFill("$surface")}}},
+		Variables: map[string]shared.Variable{
+			"gray-900": {Type: shared.VariableColor, Value: "#111111"},
+			"surface": {Type: shared.VariableColor, Value: "#FFFFFF", Themed: []shared.ThemedValue{
+				{Value: "$gray-900", Theme: shared.Theme{"mode": "dark"}},
+			}},
+		},
+	}
+	if err := resolver.NewVariableResolver().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := doc.Children[0].(*shared.Frame).Fills[0].Color; got != "#111111" {
+		t.Errorf("expected the themed alias, got %s", got)
+	}
+}
+
+func TestResolveVariableCycle(t *testing.T) {
+	doc := &shared.Document{
+		Children: []shared.Node{&shared.Frame{ID: "f1", Fills: []*shared.Fill{shared.SolidFill("$a")}}},
+		Variables: map[string]shared.Variable{
+			"a": {Type: shared.VariableColor, Value: "$b"},
+			"b": {Type: shared.VariableColor, Value: "lighten($c, 0.1)"},
+			"c": {Type: shared.VariableColor, Value: "$a"},
+		},
+	}
+	err := resolver.NewVariableResolver().Resolve(doc)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), `frame "f1" fill: variable cycle: a > b > c > a`) {
+		t.Errorf("expected the cycle path, got: %s", err)
+	}
+}
+
+func TestResolveExpressionTypeErrors(t *testing.T) {
+	tests := map[string]struct {
+		variable shared.Variable
+		want     string
+	}{
+		"number from color": {
+			variable: shared.Variable{Type: shared.VariableNumber, Value: "$brand"},
+			want:     `variable "v": number value expected, got "#3366CC"`,
+		},
+		"bad expression": {
+			variable: shared.Variable{Type: shared.VariableNumber, Value: "$size *"},
+			want:     `variable "v": unexpected end`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			doc := &shared.Document{
+				Children: []shared.Node{&shared.Frame{ID: "f1", Bindings: shared.Bindings{"gap": "v"}}},
+				Variables: map[string]shared.Variable{
+					"brand": {Type: shared.VariableColor, Value: "#3366CC"},
+					"size":  {Type: shared.VariableNumber, Value: 4.0},
+					"v":     tt.variable,
+				},
+			}
+			err := resolver.NewVariableResolver().Resolve(doc)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestResolveVisibilityConditions(t *testing.T) {
+	badge := &shared.Text{ID: "badge", Visibility: shared.Visibility{Condition: "$show-badge"}}
+	empty := &shared.Frame{ID: "empty", Visibility: shared.Visibility{Condition: "$count == 0"}}
+	dark := &shared.Shape{ID: "moon", Type: shared.NodeTypeEllipse, Visibility: shared.Visibility{Condition: "$dark"}}
+	doc := &shared.Document{
+		Themes: map[string][]string{"mode": {"light", "dark"}},
+		Children: []shared.Node{
+			&shared.Frame{ID: "page", Children: []shared.Node{
+				badge,
+				empty,
+				&shared.Frame{ID: "night", Theme: shared.Theme{"mode": "dark"}, Children: []shared.Node{dark}},
+			}},
+		},
+		Variables: map[string]shared.Variable{
+			"show-badge": {Type: shared.VariableBoolean, Value: "$count > 0 && $discount != ''"},
+			"count":      {Type: shared.VariableNumber, Value: 2.0},
+			"discount":   {Type: shared.VariableString, Value: "20%"},
+			"dark": {Type: shared.VariableBoolean, Value: false, Themed: []shared.ThemedValue{
+				{Value: true, Theme: shared.Theme{"mode": "dark"}},
+			}},
+		},
+	}
+
+	if err := resolver.NewVariableResolver().Resolve(doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if badge.Visibility.Hidden || badge.Visibility.Condition != "" {
+		t.Errorf("expected the badge to be shown, got %+v", badge.Visibility)
+	}
+	if !empty.Visibility.Hidden {
+		t.Error("expected the empty state to be hidden")
+	}
+	if dark.Visibility.Hidden {
+		t.Error("expected the condition to use the frame theme")
+	}
+}
+
+func TestResolveVisibilityErrors(t *testing.T) {
+	tests := map[string]struct {
+		condition string
+		variable  shared.Variable
+	}{
+		`text "t1" visible: undefined variable: "missing"`:                   {"$missing", shared.Variable{Type: shared.VariableBoolean, Value: true}},
+		`text "t1" visible: variable "v": boolean value expected, got "yes"`: {"$v", shared.Variable{Type: shared.VariableBoolean, Value: "'yes'"}},
+		`text "t1" visible: unexpected`:                                      {"$v ==", shared.Variable{Type: shared.VariableBoolean, Value: true}},
+	}
+	for want, tt := range tests {
+		doc := &shared.Document{
+			Children:  []shared.Node{&shared.Text{ID: "t1", Visibility: shared.Visibility{Condition: tt.condition}}},
+			Variables: map[string]shared.Variable{"v": tt.variable},
+		}
+		err := resolver.NewVariableResolver().Resolve(doc)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
 	}
 }
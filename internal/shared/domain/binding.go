@@ -0,0 +1,58 @@
+package domain
+
+import "strings"
+
+// Bindings maps the properties of a node to the variables they take their
+// value from, e.g. "fontSize" to "size". Properties are named as in .pen
+// files, nested ones with a dot ("padding.top", "stroke.thickness"). Colors
+// keep their "$name" references inline instead.
+type Bindings map[string]string
+
+// NodeBindings returns the bindings of a node, or nil for nodes that have
+// none.
+func NodeBindings(node Node) *Bindings {
+	switch n := node.(type) {
+	case *Frame:
+		return &n.Bindings
+	case *Text:
+		return &n.Bindings
+	case *Shape:
+		return &n.Bindings
+	case *Path:
+		return &n.Bindings
+	case *Image:
+		return &n.Bindings
+	default:
+		return nil
+	}
+}
+
+// Bind makes a property take its value from a variable.
+func (b *Bindings) Bind(property, variable string) {
+	if *b == nil {
+		*b = make(Bindings)
+	}
+	b.Unbind(property)
+	(*b)[property] = variable
+}
+
+// Unbind removes the bindings of a property and of its nested properties.
+func (b Bindings) Unbind(property string) {
+	for p := range b {
+		if p == property || strings.HasPrefix(p, property+".") {
+			delete(b, p)
+		}
+	}
+}
+
+// Clone returns a copy of the bindings.
+func (b Bindings) Clone() Bindings {
+	if b == nil {
+		return nil
+	}
+	c := make(Bindings, len(b))
+	for p, v := range b {
+		c[p] = v
+	}
+	return c
+}
@@ -0,0 +1,33 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestBindingsBindAndUnbind(t *testing.T) {
+	var b domain.Bindings
+	b.Bind("padding", "space-md")
+	b.Bind("padding.top", "space-lg")
+	b.Bind("gap", "space-sm")
+	if len(b) != 3 {
+		t.Fatalf("expected 3 bindings, got %v", b)
+	}
+
+	b.Unbind("padding")
+	if len(b) != 1 || b["gap"] != "space-sm" {
+		t.Errorf("expected nested bindings to be removed, got %v", b)
+	}
+}
+
+func TestNodeBindings(t *testing.T) {
+	frame := &domain.Frame{ID: "f1"}
+	domain.NodeBindings(frame).Bind("gap", "space")
+	if frame.Bindings["gap"] != "space" {
+		t.Errorf("expected the frame bindings, got %v", frame.Bindings)
+	}
+	if domain.NodeBindings(&domain.Ref{ID: "r1"}) != nil {
+		t.Error("expected refs to have no bindings")
+	}
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 )
 
@@ -55,3 +56,12 @@ func parseRGB(hex string) (uint8, uint8, uint8, error) {
 	}
 	return uint8(r), uint8(g), uint8(b), nil
 }
+
+// Hex formats the color as #RRGGBB, or #RRGGBBAA when it is translucent.
+func (c RGBA) Hex() string {
+	if c.A >= 1 {
+		return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+	}
+	a := math.Round(math.Max(c.A, 0) * 255)
+	return fmt.Sprintf("#%02X%02X%02X%02X", c.R, c.G, c.B, uint8(a))
+}
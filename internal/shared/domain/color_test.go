@@ -101,3 +101,17 @@ func TestParseHexColorEmpty(t *testing.T) {
 		t.Fatal("expected error for empty string")
 	}
 }
+
+func TestRGBAHex(t *testing.T) {
+	tests := map[shared.RGBA]string{
+		{R: 255, G: 107, B: 53, A: 1}:  "#FF6B35",
+		{R: 0, G: 0, B: 0, A: 0.5}:     "#00000080",
+		{R: 18, G: 52, B: 86, A: 0}:    "#12345600",
+		{R: 255, G: 255, B: 255, A: 2}: "#FFFFFF",
+	}
+	for c, want := range tests {
+		if got := c.Hex(); got != want {
+			t.Errorf("%+v: expected %s, got %s", c, want, got)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package domain
+
+// Ref is an instance of a reusable frame (a component). It is replaced by a
+// copy of the component's subtree, with Override applied to the copied root
+// and Descendants applied to the copied descendants by ID.
+type Ref struct {
+	ID          string
+	Name        string
+	Component   string // ID of the reusable frame
+	Override    *NodeOverride
+	Descendants map[string]*NodeOverride
+}
+
+func (r *Ref) GetID() string   { return r.ID }
+func (r *Ref) GetName() string { return r.Name }
+func (r *Ref) GetType() string { return NodeTypeRef }
+
+// NodeOverride holds the properties an instance changes on a node of its
+// component. Nil fields keep the component's value; a non-nil empty Fills
+// removes every fill. Bindings set properties from variables instead.
+// Properties a node does not have are ignored.
+type NodeOverride struct {
+	Name           *string
+	X              *float64
+	Y              *float64
+	Width          *Dimension
+	Height         *Dimension
+	MinWidth       *float64
+	MaxWidth       *float64
+	MinHeight      *float64
+	MaxHeight      *float64
+	Shrink         *float64
+	Fills          []*Fill
+	Stroke         *Stroke
+	Opacity        *float64
+	CornerRadius   *float64
+	Content        *string
+	FontFamily     *string
+	FontSize       *float64
+	FontWeight     *string
+	FontStyle      *string
+	LetterSpacing  *float64
+	LineHeight     *float64
+	TextAlign      *string
+	URL            *string
+	Layout         *string
+	Gap            *float64
+	RowGap         *float64
+	ColumnGap      *float64
+	Wrap           *bool
+	Columns        []GridTrack
+	Rows           []GridTrack
+	GridFlow       *string
+	GridColumn     *int
+	GridRow        *int
+	ColumnSpan     *int
+	RowSpan        *int
+	Absolute       *bool
+	Right          *float64
+	Bottom         *float64
+	Padding        *Padding
+	JustifyContent *string
+	AlignItems     *string
+	Hidden         *bool
+	Condition      *string
+	Bindings       Bindings
+}
+
+// Apply sets the overridden properties on a node.
+func (o *NodeOverride) Apply(node Node) {
+	if o == nil {
+		return
+	}
+	// Overridden values replace the variables of the component
+	if b := NodeBindings(node); b != nil {
+		for _, property := range o.properties() {
+			b.Unbind(property)
+		}
+		for property, variable := range o.Bindings {
+			b.Bind(property, variable)
+		}
+	}
+
+	if v := NodeVisibility(node); v != nil {
+		set(&v.Hidden, o.Hidden)
+		set(&v.Condition, o.Condition)
+	}
+	if c := NodeGridCell(node); c != nil {
+		set(&c.Column, o.GridColumn)
+		set(&c.Row, o.GridRow)
+		set(&c.ColumnSpan, o.ColumnSpan)
+		set(&c.RowSpan, o.RowSpan)
+	}
+	if p := NodePosition(node); p != nil {
+		set(&p.Absolute, o.Absolute)
+		setOptional(&p.Right, o.Right)
+		setOptional(&p.Bottom, o.Bottom)
+	}
+
+	switch n := node.(type) {
+	case *Frame:
+		set(&n.Name, o.Name)
+		set(&n.X, o.X)
+		set(&n.Y, o.Y)
+		o.setSize(&n.Width, &n.Height)
+		setFills(&n.Fills, o.Fills)
+		setStroke(&n.Stroke, o.Stroke)
+		set(&n.CornerRadius, o.CornerRadius)
+		set(&n.Layout, o.Layout)
+		set(&n.Gap, o.Gap)
+		setOptional(&n.RowGap, o.RowGap)
+		setOptional(&n.ColumnGap, o.ColumnGap)
+		set(&n.Wrap, o.Wrap)
+		setTracks(&n.Columns, o.Columns)
+		setTracks(&n.Rows, o.Rows)
+		set(&n.GridFlow, o.GridFlow)
+		set(&n.Padding, o.Padding)
+		set(&n.JustifyContent, o.JustifyContent)
+		set(&n.AlignItems, o.AlignItems)
+	case *Text:
+		set(&n.Name, o.Name)
+		set(&n.X, o.X)
+		set(&n.Y, o.Y)
+		o.setSize(&n.Width, nil)
+		setFills(&n.Fills, o.Fills)
+		setStroke(&n.Stroke, o.Stroke)
+		set(&n.Content, o.Content)
+		set(&n.FontFamily, o.FontFamily)
+		set(&n.FontSize, o.FontSize)
+		set(&n.FontWeight, o.FontWeight)
+		set(&n.FontStyle, o.FontStyle)
+		set(&n.LetterSpacing, o.LetterSpacing)
+		set(&n.LineHeight, o.LineHeight)
+		set(&n.TextAlign, o.TextAlign)
+	case *Shape:
+		set(&n.Name, o.Name)
+		set(&n.X, o.X)
+		set(&n.Y, o.Y)
+		o.setSize(&n.Width, &n.Height)
+		setFills(&n.Fills, o.Fills)
+		setStroke(&n.Stroke, o.Stroke)
+		set(&n.Opacity, o.Opacity)
+		set(&n.CornerRadius, o.CornerRadius)
+	case *Path:
+		set(&n.Name, o.Name)
+		set(&n.X, o.X)
+		set(&n.Y, o.Y)
+		o.setSize(&n.Width, &n.Height)
+		setFills(&n.Fills, o.Fills)
+		setStroke(&n.Stroke, o.Stroke)
+		set(&n.Opacity, o.Opacity)
+	case *Image:
+		set(&n.Name, o.Name)
+		set(&n.X, o.X)
+		set(&n.Y, o.Y)
+		o.setSize(&n.Width, &n.Height)
+		setStroke(&n.Stroke, o.Stroke)
+		set(&n.Opacity, o.Opacity)
+		set(&n.CornerRadius, o.CornerRadius)
+		set(&n.URL, o.URL)
+	}
+}
+
+// properties returns the names of the overridden properties.
+func (o *NodeOverride) properties() []string {
+	fields := []struct {
+		name string
+		set  bool
+	}{
+		{"name", o.Name != nil},
+		{"x", o.X != nil},
+		{"y", o.Y != nil},
+		{"width", o.Width != nil},
+		{"height", o.Height != nil},
+		{"stroke", o.Stroke != nil},
+		{"opacity", o.Opacity != nil},
+		{"cornerRadius", o.CornerRadius != nil},
+		{"content", o.Content != nil},
+		{"fontFamily", o.FontFamily != nil},
+		{"fontSize", o.FontSize != nil},
+		{"fontWeight", o.FontWeight != nil},
+		{"fontStyle", o.FontStyle != nil},
+		{"letterSpacing", o.LetterSpacing != nil},
+		{"lineHeight", o.LineHeight != nil},
+		{"textAlign", o.TextAlign != nil},
+		{"url", o.URL != nil},
+		{"layout", o.Layout != nil},
+		{"gap", o.Gap != nil},
+		{"rowGap", o.RowGap != nil},
+		{"columnGap", o.ColumnGap != nil},
+		{"padding", o.Padding != nil},
+		{"justifyContent", o.JustifyContent != nil},
+		{"alignItems", o.AlignItems != nil},
+	}
+	var names []string
+	for _, f := range fields {
+		if f.set {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}
+
+// setSize sets the overridden width and height of a node, or only its
+// width when height is nil. A new value keeps the limits and shrink weight
+// of the component unless they are overridden too.
+func (o *NodeOverride) setSize(width, height *Dimension) {
+	if o.Width != nil {
+		*width = width.WithValue(*o.Width)
+	}
+	set(&width.Min, o.MinWidth)
+	set(&width.Max, o.MaxWidth)
+	setOptional(&width.Shrink, o.Shrink)
+	if height == nil {
+		return
+	}
+	if o.Height != nil {
+		*height = height.WithValue(*o.Height)
+	}
+	set(&height.Min, o.MinHeight)
+	set(&height.Max, o.MaxHeight)
+	setOptional(&height.Shrink, o.Shrink)
+}
+
+func set[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+// setOptional sets an optional property to a copy of value, so nodes never
+// share it.
+func setOptional[T any](field **T, value *T) {
+	if value != nil {
+		v := *value
+		*field = &v
+	}
+}
+
+func setTracks(field *[]GridTrack, tracks []GridTrack) {
+	if tracks != nil {
+		*field = append([]GridTrack(nil), tracks...)
+	}
+}
+
+func setFills(field *[]*Fill, fills []*Fill) {
+	if fills != nil {
+		*field = CloneFills(fills)
+	}
+}
+
+func setStroke(field **Stroke, stroke *Stroke) {
+	if stroke != nil {
+		*field = stroke.Clone()
+	}
+}
+
+// CloneNode returns a deep copy of a node and its descendants, so the copy
+// can be changed without affecting the original. Path geometry is shared,
+// since it is never modified in place.
+func CloneNode(node Node) Node {
+	switch n := node.(type) {
+	case *Frame:
+		c := *n
+		c.Fills = CloneFills(n.Fills)
+		c.Stroke = n.Stroke.Clone()
+		c.Effects = nil
+		for _, effect := range n.Effects {
+			e := *effect
+			c.Effects = append(c.Effects, &e)
+		}
+		c.Bindings = n.Bindings.Clone()
+		c.Position = n.Position.Clone()
+		c.Repeat = n.Repeat.Clone()
+		c.RowGap, c.ColumnGap = nil, nil
+		setOptional(&c.RowGap, n.RowGap)
+		setOptional(&c.ColumnGap, n.ColumnGap)
+		c.Columns, c.Rows = nil, nil
+		setTracks(&c.Columns, n.Columns)
+		setTracks(&c.Rows, n.Rows)
+		c.Children = nil
+		for _, child := range n.Children {
+			c.Children = append(c.Children, CloneNode(child))
+		}
+		return &c
+	case *Text:
+		c := *n
+		c.Fills = CloneFills(n.Fills)
+		c.Stroke = n.Stroke.Clone()
+		c.Bindings = n.Bindings.Clone()
+		c.Position = n.Position.Clone()
+		return &c
+	case *Shape:
+		c := *n
+		c.Fills = CloneFills(n.Fills)
+		c.Stroke = n.Stroke.Clone()
+		c.Bindings = n.Bindings.Clone()
+		c.Position = n.Position.Clone()
+		return &c
+	case *Path:
+		c := *n
+		c.Fills = CloneFills(n.Fills)
+		c.Stroke = n.Stroke.Clone()
+		c.Bindings = n.Bindings.Clone()
+		c.Position = n.Position.Clone()
+		return &c
+	case *Image:
+		c := *n
+		c.Stroke = n.Stroke.Clone()
+		c.Bindings = n.Bindings.Clone()
+		c.Position = n.Position.Clone()
+		return &c
+	case *Ref:
+		c := *n
+		return &c
+	default:
+		return node
+	}
+}
+
+// CloneFills returns deep copies of fills.
+func CloneFills(fills []*Fill) []*Fill {
+	if fills == nil {
+		return nil
+	}
+	out := make([]*Fill, len(fills))
+	for i, fill := range fills {
+		c := *fill
+		c.Stops = append([]ColorStop(nil), fill.Stops...)
+		out[i] = &c
+	}
+	return out
+}
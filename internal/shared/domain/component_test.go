@@ -0,0 +1,247 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestRefImplementsNode(t *testing.T) {
+	var n domain.Node = &domain.Ref{ID: "r1", Name: "buy", Component: "button"}
+	if n.GetID() != "r1" || n.GetName() != "buy" {
+		t.Errorf("unexpected ID/Name: %s/%s", n.GetID(), n.GetName())
+	}
+	if n.GetType() != domain.NodeTypeRef {
+		t.Errorf("expected type '%s', got '%s'", domain.NodeTypeRef, n.GetType())
+	}
+}
+
+func TestNodeOverrideApply(t *testing.T) {
+	content := "Buy now"
+	size := 18.0
+	gap := 4.0
+	o := &domain.NodeOverride{
+		Content:  &content,
+		FontSize: &size,
+		Gap:      &gap,
+		Fills:    []*domain.Fill{domain.SolidFill("#FF0000")},
+	}
+
+	text := &domain.Text{ID: "t1", Content: "Label", FontSize: 14, FontFamily: "Inter"}
+	o.Apply(text)
+	if text.Content != "Buy now" || text.FontSize != 18 || text.FontFamily != "Inter" {
+		t.Errorf("unexpected text after override: %+v", text)
+	}
+	if len(text.Fills) != 1 || text.Fills[0].Color != "#FF0000" {
+		t.Errorf("expected overridden fill, got %+v", text.Fills)
+	}
+	if text.Fills[0] == o.Fills[0] {
+		t.Error("expected the override fill to be copied")
+	}
+
+	// Properties the node does not have are ignored
+	frame := &domain.Frame{ID: "f1", Gap: 8}
+	o.Apply(frame)
+	if frame.Gap != 4 {
+		t.Errorf("expected gap 4, got %v", frame.Gap)
+	}
+}
+
+func TestNodeOverrideClearsFills(t *testing.T) {
+	frame := &domain.Frame{ID: "f1", Fills: []*domain.Fill{domain.SolidFill("#FFFFFF")}}
+	(&domain.NodeOverride{Fills: []*domain.Fill{}}).Apply(frame)
+	if len(frame.Fills) != 0 {
+		t.Errorf("expected no fills, got %d", len(frame.Fills))
+	}
+
+	var nilOverride *domain.NodeOverride
+	nilOverride.Apply(frame)
+}
+
+func TestCloneNodeIsDeep(t *testing.T) {
+	original := &domain.Frame{
+		ID:     "card",
+		Fills:  []*domain.Fill{domain.SolidFill("$surface")},
+		Stroke: &domain.Stroke{Color: "#000000", Dash: []float64{2, 2}},
+		Children: []domain.Node{
+			&domain.Text{ID: "title", Content: "Title", Fills: []*domain.Fill{domain.SolidFill("#111111")}},
+		},
+	}
+
+	clone := domain.CloneNode(original).(*domain.Frame)
+	clone.Fills[0].Color = "#FFFFFF"
+	clone.Stroke.Dash[0] = 5
+	clone.Children[0].(*domain.Text).Content = "Changed"
+
+	if original.Fills[0].Color != "$surface" {
+		t.Error("expected original fill to be unchanged")
+	}
+	if original.Stroke.Dash[0] != 2 {
+		t.Error("expected original stroke dash to be unchanged")
+	}
+	if original.Children[0].(*domain.Text).Content != "Title" {
+		t.Error("expected original child to be unchanged")
+	}
+}
+
+func TestNodeOverrideGaps(t *testing.T) {
+	component := &domain.Frame{ID: "tags", Gap: 8}
+	rowGap, wrap := 16.0, true
+	o := &domain.NodeOverride{RowGap: &rowGap, Wrap: &wrap}
+
+	instance := domain.CloneNode(component).(*domain.Frame)
+	o.Apply(instance)
+	rowGap = 0
+	if row, column := instance.Gaps(); !instance.Wrap || row != 16 || column != 8 {
+		t.Errorf("expected a wrapping instance with gaps 16 and 8, got %v, %v and %v", instance.Wrap, row, column)
+	}
+
+	// Clones do not share gaps
+	clone := domain.CloneNode(instance).(*domain.Frame)
+	*clone.RowGap = 4
+	if *instance.RowGap != 16 {
+		t.Errorf("expected the clone's gap to be its own, got %v", *instance.RowGap)
+	}
+}
+
+func TestNodeOverrideGrid(t *testing.T) {
+	component := &domain.Frame{ID: "cards", Layout: "grid", Columns: []domain.GridTrack{domain.FractionTrack(1)}}
+	row, span, flow := 2, 3, "column"
+	o := &domain.NodeOverride{
+		Columns:    []domain.GridTrack{domain.FixedTrack(100), domain.AutoTrack()},
+		GridFlow:   &flow,
+		GridRow:    &row,
+		ColumnSpan: &span,
+	}
+
+	instance := domain.CloneNode(component).(*domain.Frame)
+	o.Apply(instance)
+	if len(instance.Columns) != 2 || instance.GridFlow != "column" {
+		t.Errorf("expected two columns flowing by column, got %v and %q", instance.Columns, instance.GridFlow)
+	}
+	if instance.Cell != (domain.GridCell{Row: 2, ColumnSpan: 3}) {
+		t.Errorf("unexpected cell %+v", instance.Cell)
+	}
+	if len(component.Columns) != 1 {
+		t.Errorf("expected the component to keep its columns, got %v", component.Columns)
+	}
+
+	// Clones do not share tracks
+	clone := domain.CloneNode(instance).(*domain.Frame)
+	clone.Columns[0] = domain.FractionTrack(2)
+	if instance.Columns[0] != domain.FixedTrack(100) {
+		t.Errorf("expected the clone's tracks to be their own, got %v", instance.Columns)
+	}
+}
+
+func TestNodeOverridePosition(t *testing.T) {
+	component := &domain.Text{ID: "note", X: 5, Position: domain.Position{Right: new(float64)}}
+	absolute, x, bottom := true, 12.0, 8.0
+	o := &domain.NodeOverride{Absolute: &absolute, X: &x, Bottom: &bottom}
+
+	instance := domain.CloneNode(component).(*domain.Text)
+	o.Apply(instance)
+	bottom = 0
+	if !instance.Position.Absolute || instance.X != 12 || *instance.Position.Bottom != 8 {
+		t.Errorf("unexpected instance position %+v at x %v", instance.Position, instance.X)
+	}
+
+	// Clones do not share edges
+	*instance.Position.Right = 30
+	if *component.Position.Right != 0 {
+		t.Errorf("expected the component's edge to be its own, got %v", *component.Position.Right)
+	}
+}
+
+func TestNodeOverrideSizeLimits(t *testing.T) {
+	width := domain.Dimension{FillContainer: true, Min: 120, Max: 400}
+	component := &domain.Frame{ID: "button", Width: width, Height: domain.Dimension{Min: 30}}
+	fill, maxHeight := domain.FixedDimension(200), 60.0
+	o := &domain.NodeOverride{Width: &fill, MaxHeight: &maxHeight}
+
+	instance := domain.CloneNode(component).(*domain.Frame)
+	o.Apply(instance)
+	if instance.Width != (domain.Dimension{Value: 200, Min: 120, Max: 400}) {
+		t.Errorf("expected the new width to keep its limits, got %+v", instance.Width)
+	}
+	if instance.Height != (domain.Dimension{Min: 30, Max: 60}) {
+		t.Errorf("expected the height limits to be merged, got %+v", instance.Height)
+	}
+	if component.Width != width {
+		t.Errorf("expected the component to be untouched, got %+v", component.Width)
+	}
+}
+
+func TestNodeOverrideShrink(t *testing.T) {
+	component := &domain.Shape{ID: "badge", Width: domain.FixedDimension(80), Height: domain.FixedDimension(20)}
+	shrink := 1.0
+	o := &domain.NodeOverride{Shrink: &shrink}
+
+	instance := domain.CloneNode(component).(*domain.Shape)
+	o.Apply(instance)
+	shrink = 3
+	if instance.Width.ShrinkWeight() != 1 || instance.Height.ShrinkWeight() != 1 {
+		t.Errorf("expected both dimensions to shrink, got %+v and %+v", instance.Width, instance.Height)
+	}
+	if component.Width.Shrink != nil {
+		t.Errorf("expected the component to be untouched, got %+v", component.Width)
+	}
+}
+
+func TestIsPositioned(t *testing.T) {
+	child := &domain.Shape{ID: "s1"}
+	if domain.IsPositioned(&domain.Frame{Layout: "vertical"}, child) {
+		t.Error("expected a stack child to be in flow")
+	}
+	if !domain.IsPositioned(&domain.Frame{Layout: "none"}, child) {
+		t.Error("expected children of a frame without layout to be positioned")
+	}
+	child.Position.Absolute = true
+	if !domain.IsPositioned(&domain.Frame{Layout: "grid"}, child) {
+		t.Error("expected an absolute child to be positioned")
+	}
+	if domain.IsPositioned(&domain.Frame{}, &domain.Ref{ID: "r1"}) {
+		t.Error("expected nodes without a position to be in flow")
+	}
+}
+
+func TestNodeOverrideReplacesBindings(t *testing.T) {
+	text := &domain.Text{ID: "t1", Bindings: domain.Bindings{"fontSize": "size-md", "fontFamily": "font-body"}}
+	size := 20.0
+	(&domain.NodeOverride{FontSize: &size, Bindings: domain.Bindings{"content": "headline"}}).Apply(text)
+
+	if text.FontSize != 20 {
+		t.Errorf("expected font size 20, got %v", text.FontSize)
+	}
+	if _, ok := text.Bindings["fontSize"]; ok {
+		t.Error("expected the overridden value to drop the component binding")
+	}
+	if text.Bindings["fontFamily"] != "font-body" || text.Bindings["content"] != "headline" {
+		t.Errorf("unexpected bindings %v", text.Bindings)
+	}
+
+	clone := domain.CloneNode(text).(*domain.Text)
+	clone.Bindings["fontFamily"] = "font-heading"
+	if text.Bindings["fontFamily"] != "font-body" {
+		t.Error("expected cloned bindings to be independent")
+	}
+}
+
+func TestNodeOverrideVisibility(t *testing.T) {
+	hidden := true
+	condition := "$show-price"
+	text := &domain.Text{ID: "t1"}
+	(&domain.NodeOverride{Hidden: &hidden, Condition: &condition}).Apply(text)
+	if !domain.IsHidden(text) || text.Visibility.Condition != "$show-price" {
+		t.Errorf("expected overridden visibility, got %+v", text.Visibility)
+	}
+
+	shown := false
+	(&domain.NodeOverride{Hidden: &shown}).Apply(text)
+	if domain.IsHidden(text) {
+		t.Error("expected enabled override to show the node")
+	}
+	if domain.IsHidden(&domain.Ref{ID: "r1"}) {
+		t.Error("expected nodes without visibility to be visible")
+	}
+}
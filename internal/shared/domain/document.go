@@ -1,7 +1,78 @@
 package domain
 
+import "fmt"
+
 type Document struct {
 	Version   string
 	Children  []Node
 	Variables map[string]Variable
+	Themes    map[string][]string // theme axes and their values, the first being the default
+	Theme     Theme               // theme selected for the whole document
+	Data      map[string]any      // JSON data that repeaters iterate over
+	Locale    string              // locale the text is written in: the fallback for translations, then the one translated to
+}
+
+// DefaultLocale is the fallback locale of documents that declare none.
+const DefaultLocale = "en"
+
+// TextLocale returns the locale the text is written in, which formats
+// numbers in placeholders and is the fallback for translations.
+func (d *Document) TextLocale() string {
+	if d.Locale == "" {
+		return DefaultLocale
+	}
+	return d.Locale
+}
+
+// ActiveTheme returns the theme pages start with: the default value of each
+// axis, overridden by the document's theme.
+func (d *Document) ActiveTheme() Theme {
+	defaults := make(Theme, len(d.Themes))
+	for axis, values := range d.Themes {
+		if len(values) > 0 {
+			defaults[axis] = values[0]
+		}
+	}
+	return defaults.Merge(d.Theme)
+}
+
+// CheckTheme reports axes or values not declared in Themes. Any theme is
+// accepted when the document declares none.
+func (d *Document) CheckTheme(theme Theme) error {
+	if len(d.Themes) == 0 {
+		return nil
+	}
+	for axis, value := range theme {
+		values, ok := d.Themes[axis]
+		if !ok {
+			return fmt.Errorf("unknown theme axis %q", axis)
+		}
+		found := false
+		for _, v := range values {
+			found = found || v == value
+		}
+		if !found {
+			return fmt.Errorf("unknown %s theme %q", axis, value)
+		}
+	}
+	return nil
+}
+
+// Clone returns a deep copy of the document, so a template can be filled
+// several times from one parse. Data is shared, since it is only read.
+func (d *Document) Clone() *Document {
+	c := *d
+	c.Children = make([]Node, len(d.Children))
+	for i, child := range d.Children {
+		c.Children[i] = CloneNode(child)
+	}
+	if d.Variables != nil {
+		c.Variables = make(map[string]Variable, len(d.Variables))
+		for name, v := range d.Variables {
+			v.Themed = append([]ThemedValue(nil), v.Themed...)
+			c.Variables[name] = v
+		}
+	}
+	c.Theme = Theme{}.Merge(d.Theme)
+	return &c
 }
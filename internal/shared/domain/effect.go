@@ -0,0 +1,53 @@
+package domain
+
+type EffectType string
+
+const (
+	EffectDropShadow     EffectType = "drop_shadow"
+	EffectInnerShadow    EffectType = "inner_shadow"
+	EffectLayerBlur      EffectType = "layer_blur"
+	EffectBackgroundBlur EffectType = "background_blur"
+)
+
+// Effect is a shadow or blur applied to a node. Blur is the blur radius, as
+// in CSS; shadows also use Color, the offset and Spread.
+type Effect struct {
+	Type    EffectType
+	Color   string
+	OffsetX float64
+	OffsetY float64
+	Blur    float64
+	Spread  float64
+	Enabled bool
+}
+
+func DropShadow(color string, offsetX, offsetY, blur, spread float64) *Effect {
+	return &Effect{
+		Type:    EffectDropShadow,
+		Color:   color,
+		OffsetX: offsetX,
+		OffsetY: offsetY,
+		Blur:    blur,
+		Spread:  spread,
+		Enabled: true,
+	}
+}
+
+func InnerShadow(color string, offsetX, offsetY, blur, spread float64) *Effect {
+	e := DropShadow(color, offsetX, offsetY, blur, spread)
+	e.Type = EffectInnerShadow
+	return e
+}
+
+func LayerBlur(radius float64) *Effect {
+	return &Effect{Type: EffectLayerBlur, Blur: radius, Enabled: true}
+}
+
+func BackgroundBlur(radius float64) *Effect {
+	return &Effect{Type: EffectBackgroundBlur, Blur: radius, Enabled: true}
+}
+
+// IsShadow reports whether the effect is a drop or inner shadow.
+func (e *Effect) IsShadow() bool {
+	return e.Type == EffectDropShadow || e.Type == EffectInnerShadow
+}
@@ -0,0 +1,41 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestDropShadow(t *testing.T) {
+	e := domain.DropShadow("#00000040", 0, 4, 12, 2)
+	if e.Type != domain.EffectDropShadow {
+		t.Errorf("expected type drop_shadow, got %s", e.Type)
+	}
+	if e.Color != "#00000040" || e.OffsetX != 0 || e.OffsetY != 4 || e.Blur != 12 || e.Spread != 2 {
+		t.Errorf("unexpected shadow: %+v", e)
+	}
+	if !e.Enabled || !e.IsShadow() {
+		t.Error("expected an enabled shadow")
+	}
+}
+
+func TestInnerShadow(t *testing.T) {
+	e := domain.InnerShadow("#000000", 1, 2, 3, 4)
+	if e.Type != domain.EffectInnerShadow || !e.IsShadow() {
+		t.Errorf("expected inner shadow, got %s", e.Type)
+	}
+	if e.OffsetX != 1 || e.OffsetY != 2 || e.Blur != 3 || e.Spread != 4 {
+		t.Errorf("unexpected shadow: %+v", e)
+	}
+}
+
+func TestBlurEffects(t *testing.T) {
+	layer := domain.LayerBlur(8)
+	if layer.Type != domain.EffectLayerBlur || layer.Blur != 8 || layer.IsShadow() {
+		t.Errorf("unexpected layer blur: %+v", layer)
+	}
+	background := domain.BackgroundBlur(20)
+	if background.Type != domain.EffectBackgroundBlur || background.Blur != 20 || background.IsShadow() {
+		t.Errorf("unexpected background blur: %+v", background)
+	}
+}
@@ -3,10 +3,48 @@ package domain
 type FillType string
 
 const (
-	FillSolid FillType = "solid"
-	FillImage FillType = "image"
+	FillSolid           FillType = "solid"
+	FillImage           FillType = "image"
+	FillLinearGradient  FillType = "linear_gradient"
+	FillRadialGradient  FillType = "radial_gradient"
+	FillAngularGradient FillType = "angular_gradient"
 )
 
+// Image fill modes: how an image is fitted to the box it fills.
+const (
+	ImageModeFill    = "fill"    // scale to cover the box, cropping the overflow
+	ImageModeFit     = "fit"     // scale to fit inside the box, leaving empty bands
+	ImageModeStretch = "stretch" // scale each axis to the box, ignoring the aspect ratio
+	ImageModeTile    = "tile"    // repeat the image from the top-left corner
+	ImageModeCrop    = "crop"    // place the image at Offset, cropped to the box
+)
+
+// ImageOffset is the position of a cropped image, in points from the
+// top-left corner of the box.
+type ImageOffset struct {
+	X float64
+	Y float64
+}
+
+// ColorStop is a gradient color at a position between 0 and 1.
+type ColorStop struct {
+	Color    string
+	Position float64
+}
+
+// GradientPoint is a point in the unit square of the filled box, where (0,0)
+// is the top-left and (1,1) the bottom-right corner.
+type GradientPoint struct {
+	X float64
+	Y float64
+}
+
+// GradientSize is a size relative to the filled box.
+type GradientSize struct {
+	Width  float64
+	Height float64
+}
+
 type Fill struct {
 	Type    FillType
 	Color   string
@@ -14,10 +52,24 @@ type Fill struct {
 	Mode    string
 	Opacity float64
 	Enabled bool
+
+	// Gradient fills only. Rotation is in degrees, clockwise. A linear
+	// gradient at 0° runs from top to bottom across Size.Height; radial and
+	// angular gradients are centered on Center, with Size as the diameters
+	// of the radial ellipse.
+	Stops    []ColorStop
+	Rotation float64
+	Center   GradientPoint
+	Size     GradientSize
+
+	// Image fills only. Scale resizes the image in tile and crop modes, where
+	// it is otherwise drawn at one point per pixel; zero means unset.
+	Scale  float64
+	Offset ImageOffset
 }
 
 func SolidFill(color string) *Fill {
-	return &Fill{Type: FillSolid, Color: color}
+	return &Fill{Type: FillSolid, Color: color, Enabled: true}
 }
 
 func ImageFill(url, mode string, opacity float64, enabled bool) *Fill {
@@ -29,3 +81,32 @@ func ImageFill(url, mode string, opacity float64, enabled bool) *Fill {
 		Enabled: enabled,
 	}
 }
+
+// GradientFill returns a gradient of the given type centered in the box and
+// spanning its full size.
+func GradientFill(fillType FillType, stops []ColorStop) *Fill {
+	return &Fill{
+		Type:    fillType,
+		Enabled: true,
+		Stops:   stops,
+		Center:  GradientPoint{X: 0.5, Y: 0.5},
+		Size:    GradientSize{Width: 1, Height: 1},
+	}
+}
+
+// ImageScale returns the scale of an image fill, defaulting to 1.
+func (f *Fill) ImageScale() float64 {
+	if f.Scale > 0 {
+		return f.Scale
+	}
+	return 1
+}
+
+// IsGradient reports whether the fill is a linear, radial or angular gradient.
+func (f *Fill) IsGradient() bool {
+	switch f.Type {
+	case FillLinearGradient, FillRadialGradient, FillAngularGradient:
+		return true
+	}
+	return false
+}
@@ -48,3 +48,42 @@ func TestImageFill(t *testing.T) {
 		t.Error("expected enabled to be true")
 	}
 }
+
+func TestGradientFillDefaults(t *testing.T) {
+	stops := []domain.ColorStop{{Color: "#FF0000", Position: 0}, {Color: "#0000FF", Position: 1}}
+	f := domain.GradientFill(domain.FillRadialGradient, stops)
+	if f.Type != domain.FillRadialGradient {
+		t.Errorf("expected type radial_gradient, got %s", f.Type)
+	}
+	if len(f.Stops) != 2 {
+		t.Fatalf("expected 2 stops, got %d", len(f.Stops))
+	}
+	if f.Center != (domain.GradientPoint{X: 0.5, Y: 0.5}) {
+		t.Errorf("expected centered gradient, got %+v", f.Center)
+	}
+	if f.Size != (domain.GradientSize{Width: 1, Height: 1}) {
+		t.Errorf("expected full-size gradient, got %+v", f.Size)
+	}
+}
+
+func TestFillIsGradient(t *testing.T) {
+	for _, ft := range []domain.FillType{domain.FillLinearGradient, domain.FillRadialGradient, domain.FillAngularGradient} {
+		if !domain.GradientFill(ft, nil).IsGradient() {
+			t.Errorf("expected %s to be a gradient", ft)
+		}
+	}
+	if domain.SolidFill("#000000").IsGradient() {
+		t.Error("expected solid fill not to be a gradient")
+	}
+}
+
+func TestFillImageScale(t *testing.T) {
+	f := domain.ImageFill("./logo.png", domain.ImageModeTile, 1, true)
+	if f.ImageScale() != 1 {
+		t.Errorf("expected default scale 1, got %f", f.ImageScale())
+	}
+	f.Scale = 0.5
+	if f.ImageScale() != 0.5 {
+		t.Errorf("expected scale 0.5, got %f", f.ImageScale())
+	}
+}
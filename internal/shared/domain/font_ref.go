@@ -37,6 +37,9 @@ func CollectFontFamilies(doc *Document) []string {
 }
 
 func collectFromNode(node Node, seen map[FontRef]bool, refs *[]FontRef) {
+	if IsHidden(node) {
+		return
+	}
 	switch n := node.(type) {
 	case *Text:
 		if n.FontFamily != "" {
@@ -0,0 +1,58 @@
+package domain
+
+// TrackKind is how a grid column or row is sized.
+type TrackKind string
+
+const (
+	TrackFixed    TrackKind = "fixed"    // a size in points
+	TrackFraction TrackKind = "fraction" // a share of the free space, as in 1fr
+	TrackAuto     TrackKind = "auto"     // the size of its content
+)
+
+// GridTrack is the size of a column or row of a grid frame. Value is the
+// size of fixed tracks and the share of fraction tracks.
+type GridTrack struct {
+	Kind  TrackKind
+	Value float64
+}
+
+func FixedTrack(v float64) GridTrack {
+	return GridTrack{Kind: TrackFixed, Value: v}
+}
+
+func FractionTrack(v float64) GridTrack {
+	return GridTrack{Kind: TrackFraction, Value: v}
+}
+
+func AutoTrack() GridTrack {
+	return GridTrack{Kind: TrackAuto}
+}
+
+// GridCell places a node in the grid of its parent frame. Column and Row
+// are 1-based, and zero places the node automatically along that axis;
+// spans of zero take one track.
+type GridCell struct {
+	Column     int
+	Row        int
+	ColumnSpan int
+	RowSpan    int
+}
+
+// NodeGridCell returns the grid placement of a node, or nil for nodes that
+// have none.
+func NodeGridCell(node Node) *GridCell {
+	switch n := node.(type) {
+	case *Frame:
+		return &n.Cell
+	case *Text:
+		return &n.Cell
+	case *Shape:
+		return &n.Cell
+	case *Path:
+		return &n.Cell
+	case *Image:
+		return &n.Cell
+	default:
+		return nil
+	}
+}
@@ -1,8 +1,15 @@
 package domain
 
 const (
-	NodeTypeFrame = "frame"
-	NodeTypeText  = "text"
+	NodeTypeFrame     = "frame"
+	NodeTypeText      = "text"
+	NodeTypeRectangle = "rectangle"
+	NodeTypeEllipse   = "ellipse"
+	NodeTypeLine      = "line"
+	NodeTypePolygon   = "polygon"
+	NodeTypePath      = "path"
+	NodeTypeImage     = "image"
+	NodeTypeRef       = "ref"
 )
 
 type Node interface {
@@ -18,17 +25,46 @@ type Frame struct {
 	Y              float64
 	Width          Dimension
 	Height         Dimension
-	Fill           *Fill
+	Fills          []*Fill // painted bottom to top
+	Stroke         *Stroke
+	Effects        []*Effect
 	CornerRadius   float64
 	Clip           bool
 	Layout         string
 	Gap            float64
+	RowGap         *float64    // space between rows; nil uses Gap
+	ColumnGap      *float64    // space between columns; nil uses Gap
+	Wrap           bool        // children flow onto new lines when the main axis is full
+	Columns        []GridTrack // column tracks of a grid layout
+	Rows           []GridTrack // row tracks of a grid layout; more auto rows are added as needed
+	GridFlow       string      // "row" (default) or "column": the axis children are placed along
 	Padding        Padding
 	JustifyContent string
 	AlignItems     string
+	Reusable       bool    // a component that ref nodes can instantiate
+	Theme          Theme   // overrides the active theme for the frame and its descendants
+	Repeat         *Repeat // repeats the children for each item of a data list
+	Cell           GridCell
+	Position       Position
+	Visibility     Visibility
+	Bindings       Bindings
 	Children       []Node
 }
 
+// Gaps returns the space between rows and between columns of children.
+// In a horizontal stack the column gap separates children and the row gap
+// wrapped lines; in a vertical one it is the other way round.
+func (f *Frame) Gaps() (row, column float64) {
+	row, column = f.Gap, f.Gap
+	if f.RowGap != nil {
+		row = *f.RowGap
+	}
+	if f.ColumnGap != nil {
+		column = *f.ColumnGap
+	}
+	return row, column
+}
+
 func (f *Frame) GetID() string   { return f.ID }
 func (f *Frame) GetName() string { return f.Name }
 func (f *Frame) GetType() string { return NodeTypeFrame }
@@ -36,8 +72,11 @@ func (f *Frame) GetType() string { return NodeTypeFrame }
 type Text struct {
 	ID            string
 	Name          string
+	X             float64
+	Y             float64
 	Content       string
-	Fill          string
+	Fills         []*Fill // painted bottom to top
+	Stroke        *Stroke
 	FontFamily    string
 	FontSize      float64
 	FontWeight    string
@@ -47,15 +86,117 @@ type Text struct {
 	TextAlign     string
 	Width         Dimension
 	TextGrowth    string
+	Cell          GridCell
+	Position      Position
+	Visibility    Visibility
+	Bindings      Bindings
 }
 
 func (t *Text) GetID() string   { return t.ID }
 func (t *Text) GetName() string { return t.Name }
 func (t *Text) GetType() string { return NodeTypeText }
 
+// Shape is a leaf node drawn as a geometric primitive fitted to its box. Type
+// is one of the rectangle, ellipse, line or polygon node types; a line runs
+// from the top-left to the bottom-right corner of its box.
+type Shape struct {
+	ID           string
+	Name         string
+	Type         string
+	X            float64
+	Y            float64
+	Width        Dimension
+	Height       Dimension
+	Fills        []*Fill // painted bottom to top
+	Stroke       *Stroke
+	Opacity      float64 // from 0 (invisible) to 1 (opaque)
+	CornerRadius float64 // rectangles only
+	Sides        int     // polygons only
+	Cell         GridCell
+	Position     Position
+	Visibility   Visibility
+	Bindings     Bindings
+}
+
+func (s *Shape) GetID() string   { return s.ID }
+func (s *Shape) GetName() string { return s.Name }
+func (s *Shape) GetType() string { return s.Type }
+
+// Path is a leaf node drawn from vector geometry, scaled so the bounds of the
+// geometry fill its box.
+type Path struct {
+	ID         string
+	Name       string
+	X          float64
+	Y          float64
+	Width      Dimension
+	Height     Dimension
+	Geometry   *PathGeometry
+	FillRule   FillRule
+	Fills      []*Fill // painted bottom to top
+	Stroke     *Stroke
+	Opacity    float64 // from 0 (invisible) to 1 (opaque)
+	Cell       GridCell
+	Position   Position
+	Visibility Visibility
+	Bindings   Bindings
+}
+
+func (p *Path) GetID() string   { return p.ID }
+func (p *Path) GetName() string { return p.Name }
+func (p *Path) GetType() string { return NodeTypePath }
+
+// Image is a leaf node showing an image file. A missing dimension follows
+// the aspect ratio of the image.
+type Image struct {
+	ID           string
+	Name         string
+	X            float64
+	Y            float64
+	Width        Dimension
+	Height       Dimension
+	URL          string
+	Mode         string // one of the image fill modes; empty means fill
+	Scale        float64
+	Offset       ImageOffset
+	Opacity      float64 // from 0 (invisible) to 1 (opaque)
+	CornerRadius float64
+	Stroke       *Stroke
+	Cell         GridCell
+	Position     Position
+	Visibility   Visibility
+	Bindings     Bindings
+}
+
+func (i *Image) GetID() string   { return i.ID }
+func (i *Image) GetName() string { return i.Name }
+func (i *Image) GetType() string { return NodeTypeImage }
+
+// Fill returns the image fill that paints the node.
+func (i *Image) Fill() *Fill {
+	fill := ImageFill(i.URL, i.Mode, i.Opacity, true)
+	fill.Scale = i.Scale
+	fill.Offset = i.Offset
+	return fill
+}
+
+// Dimension is the width or height of a node: a fixed Value, the space
+// left in its container, or the size of its content (fit_content, also
+// used when no value is set). Layout keeps the size between Min and Max;
+// a zero Max has no limit.
+//
+// Children that fill their container share the space left in proportion
+// to Grow, which counts as 1 when unset. When the children of a frame do
+// not fit, they give up space in proportion to their Shrink weight, 1 when
+// unset, and to their size.
 type Dimension struct {
 	Value         float64
 	FillContainer bool
+	FitContent    bool
+	Min           float64
+	Max           float64
+	Grow          float64
+	Shrink        *float64
 }
 
 func FixedDimension(v float64) Dimension {
@@ -66,6 +207,54 @@ func FillContainerDimension() Dimension {
 	return Dimension{FillContainer: true}
 }
 
+// WeightedFillDimension returns a fill_container dimension that takes grow
+// shares of the space left.
+func WeightedFillDimension(grow float64) Dimension {
+	return Dimension{FillContainer: true, Grow: grow}
+}
+
+func FitContentDimension() Dimension {
+	return Dimension{FitContent: true}
+}
+
+// Clamp limits a size to the minimum and maximum of the dimension. The
+// minimum wins when they conflict.
+func (d Dimension) Clamp(v float64) float64 {
+	if d.Max > 0 && v > d.Max {
+		v = d.Max
+	}
+	return max(v, d.Min)
+}
+
+// GrowWeight returns the share of the space left that the dimension takes
+// when it fills its container.
+func (d Dimension) GrowWeight() float64 {
+	if d.Grow > 0 {
+		return d.Grow
+	}
+	return 1
+}
+
+// ShrinkWeight returns how much the dimension shrinks, relative to its
+// size, when its node and its siblings do not fit.
+func (d Dimension) ShrinkWeight() float64 {
+	switch {
+	case d.Shrink != nil:
+		return *d.Shrink
+	case d.FillContainer:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// WithValue returns the dimension set to value, keeping its limits and its
+// shrink weight.
+func (d Dimension) WithValue(value Dimension) Dimension {
+	value.Min, value.Max, value.Shrink = d.Min, d.Max, d.Shrink
+	return value
+}
+
 type Padding struct {
 	Top    float64
 	Right  float64
@@ -32,6 +32,49 @@ func TestTextImplementsNode(t *testing.T) {
 	}
 }
 
+func TestShapeImplementsNode(t *testing.T) {
+	for _, nodeType := range []string{domain.NodeTypeRectangle, domain.NodeTypeEllipse, domain.NodeTypeLine, domain.NodeTypePolygon} {
+		var n domain.Node = &domain.Shape{ID: "s1", Name: "shape", Type: nodeType}
+		if n.GetID() != "s1" || n.GetName() != "shape" {
+			t.Errorf("unexpected ID/Name: %s/%s", n.GetID(), n.GetName())
+		}
+		if n.GetType() != nodeType {
+			t.Errorf("expected type '%s', got '%s'", nodeType, n.GetType())
+		}
+	}
+}
+
+func TestPathImplementsNode(t *testing.T) {
+	var n domain.Node = &domain.Path{ID: "p1", Name: "icon"}
+	if n.GetID() != "p1" || n.GetName() != "icon" {
+		t.Errorf("unexpected ID/Name: %s/%s", n.GetID(), n.GetName())
+	}
+	if n.GetType() != domain.NodeTypePath {
+		t.Errorf("expected type '%s', got '%s'", domain.NodeTypePath, n.GetType())
+	}
+}
+
+func TestImageImplementsNode(t *testing.T) {
+	var n domain.Node = &domain.Image{ID: "i1", Name: "photo"}
+	if n.GetID() != "i1" || n.GetName() != "photo" {
+		t.Errorf("unexpected ID/Name: %s/%s", n.GetID(), n.GetName())
+	}
+	if n.GetType() != domain.NodeTypeImage {
+		t.Errorf("expected type '%s', got '%s'", domain.NodeTypeImage, n.GetType())
+	}
+}
+
+func TestImageNodeFill(t *testing.T) {
+	img := &domain.Image{URL: "./logo.png", Mode: domain.ImageModeCrop, Opacity: 0.5, Scale: 2, Offset: domain.ImageOffset{X: -10, Y: 4}}
+	f := img.Fill()
+	if f.Type != domain.FillImage || f.URL != "./logo.png" || f.Mode != domain.ImageModeCrop || !f.Enabled {
+		t.Errorf("unexpected fill: %+v", f)
+	}
+	if f.Opacity != 0.5 || f.Scale != 2 || f.Offset != img.Offset {
+		t.Errorf("expected opacity, scale and offset to carry over, got %+v", f)
+	}
+}
+
 func TestFixedDimension(t *testing.T) {
 	d := domain.FixedDimension(800)
 	if d.Value != 800 {
@@ -49,6 +92,66 @@ func TestFillContainerDimension(t *testing.T) {
 	}
 }
 
+func TestFitContentDimension(t *testing.T) {
+	d := domain.FitContentDimension()
+	if !d.FitContent || d.FillContainer || d.Value != 0 {
+		t.Errorf("expected a fit_content dimension, got %+v", d)
+	}
+}
+
+func TestDimensionClamp(t *testing.T) {
+	d := domain.Dimension{Min: 50, Max: 200}
+	for v, want := range map[float64]float64{10: 50, 120: 120, 300: 200} {
+		if got := d.Clamp(v); got != want {
+			t.Errorf("Clamp(%v): expected %v, got %v", v, want, got)
+		}
+	}
+	if got := (domain.Dimension{Min: 80}).Clamp(500); got != 500 {
+		t.Errorf("expected no maximum, got %v", got)
+	}
+	if got := (domain.Dimension{Min: 80, Max: 40}).Clamp(60); got != 80 {
+		t.Errorf("expected the minimum to win, got %v", got)
+	}
+}
+
+func TestDimensionWithValue(t *testing.T) {
+	shrink := 2.0
+	d := domain.Dimension{Value: 100, Min: 50, Max: 200, Shrink: &shrink}.WithValue(domain.WeightedFillDimension(3))
+	if d != (domain.Dimension{FillContainer: true, Min: 50, Max: 200, Grow: 3, Shrink: &shrink}) {
+		t.Errorf("expected the limits and shrink weight to be kept, got %+v", d)
+	}
+}
+
+func TestDimensionGrowWeight(t *testing.T) {
+	if w := domain.FillContainerDimension().GrowWeight(); w != 1 {
+		t.Errorf("expected fill_container to weigh 1, got %v", w)
+	}
+	if w := domain.WeightedFillDimension(2.5).GrowWeight(); w != 2.5 {
+		t.Errorf("expected a weight of 2.5, got %v", w)
+	}
+}
+
+func TestDimensionShrinkWeight(t *testing.T) {
+	none, twice := 0.0, 2.0
+	tests := []struct {
+		name string
+		d    domain.Dimension
+		want float64
+	}{
+		{"auto", domain.Dimension{}, 1},
+		{"fit_content", domain.FitContentDimension(), 1},
+		{"fixed", domain.FixedDimension(100), 1},
+		{"fill_container", domain.FillContainerDimension(), 0},
+		{"fixed with a weight", domain.Dimension{Value: 100, Shrink: &twice}, 2},
+		{"fixed without shrinking", domain.Dimension{Value: 100, Shrink: &none}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.d.ShrinkWeight(); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
 func TestUniformPadding(t *testing.T) {
 	p := domain.UniformPadding(20)
 	if p.Top != 20 || p.Right != 20 || p.Bottom != 20 || p.Left != 20 {
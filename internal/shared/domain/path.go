@@ -0,0 +1,542 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// FillRule decides which areas of a compound or self-intersecting path are
+// inside it.
+type FillRule string
+
+const (
+	FillRuleNonZero FillRule = "nonzero"
+	FillRuleEvenOdd FillRule = "evenodd"
+)
+
+// PathOp is a drawing operator of a normalized path.
+type PathOp byte
+
+const (
+	PathMoveTo  PathOp = 'M'
+	PathLineTo  PathOp = 'L'
+	PathCubicTo PathOp = 'C'
+	PathClose   PathOp = 'Z'
+)
+
+type PathPoint struct {
+	X float64
+	Y float64
+}
+
+// PathSegment is an operator and its absolute points: the target point for
+// moves and lines, two control points and the target point for cubic curves,
+// and none for close.
+type PathSegment struct {
+	Op     PathOp
+	Points []PathPoint
+}
+
+// PathGeometry is SVG path data normalized to absolute moves, lines, cubic
+// Bézier curves and closes. Quadratic curves and elliptical arcs are
+// converted to cubic curves.
+type PathGeometry struct {
+	Segments []PathSegment
+}
+
+// PathBounds is the bounding box of a geometry.
+type PathBounds struct {
+	MinX float64
+	MinY float64
+	MaxX float64
+	MaxY float64
+}
+
+func (b PathBounds) Width() float64  { return b.MaxX - b.MinX }
+func (b PathBounds) Height() float64 { return b.MaxY - b.MinY }
+
+// ParsePathData parses the d attribute syntax of SVG paths: the M, L, H, V,
+// C, S, Q, T, A and Z commands, in absolute (upper case) and relative (lower
+// case) form.
+func ParsePathData(data string) (*PathGeometry, error) {
+	s := &pathScanner{data: data}
+	b := &pathBuilder{}
+
+	for {
+		s.skipSeparators()
+		if s.done() {
+			break
+		}
+		cmd := s.data[s.pos]
+		if !isPathCommand(cmd) {
+			return nil, fmt.Errorf("unexpected %q at offset %d", cmd, s.pos)
+		}
+		s.pos++
+
+		if cmd == 'Z' || cmd == 'z' {
+			b.close()
+			continue
+		}
+		if len(b.segments) == 0 && cmd != 'M' && cmd != 'm' {
+			return nil, fmt.Errorf("path must start with a move command, got %q", cmd)
+		}
+
+		// A command letter may be followed by several argument groups; extra
+		// groups after a move are implicit lines.
+		for first := true; first || s.hasNumber(); first = false {
+			if err := b.apply(cmd, s); err != nil {
+				return nil, fmt.Errorf("command %q: %w", cmd, err)
+			}
+			switch cmd {
+			case 'M':
+				cmd = 'L'
+			case 'm':
+				cmd = 'l'
+			}
+		}
+	}
+
+	return &PathGeometry{Segments: b.segments}, nil
+}
+
+// Bounds returns the tight bounding box of the geometry, including the
+// extremes of its curves. An empty geometry has zero bounds.
+func (g *PathGeometry) Bounds() PathBounds {
+	b := PathBounds{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+	include := func(p PathPoint) {
+		b.MinX, b.MaxX = math.Min(b.MinX, p.X), math.Max(b.MaxX, p.X)
+		b.MinY, b.MaxY = math.Min(b.MinY, p.Y), math.Max(b.MaxY, p.Y)
+	}
+
+	var cur PathPoint
+	for _, seg := range g.Segments {
+		switch seg.Op {
+		case PathMoveTo, PathLineTo:
+			cur = seg.Points[0]
+			include(cur)
+		case PathCubicTo:
+			c1, c2, end := seg.Points[0], seg.Points[1], seg.Points[2]
+			for _, t := range cubicExtrema(cur.X, c1.X, c2.X, end.X) {
+				include(cubicPoint(cur, c1, c2, end, t))
+			}
+			for _, t := range cubicExtrema(cur.Y, c1.Y, c2.Y, end.Y) {
+				include(cubicPoint(cur, c1, c2, end, t))
+			}
+			cur = end
+			include(cur)
+		}
+	}
+
+	if math.IsInf(b.MinX, 1) {
+		return PathBounds{}
+	}
+	return b
+}
+
+// Fit returns a copy of the geometry scaled and moved so its bounds fill a
+// box of the given size at the origin. A flat axis (a horizontal or vertical
+// line) is centered in the box instead of scaled.
+func (g *PathGeometry) Fit(width, height float64) *PathGeometry {
+	b := g.Bounds()
+	axis := func(v, min, extent, size float64) float64 {
+		if extent == 0 {
+			return size / 2
+		}
+		return (v - min) / extent * size
+	}
+
+	fitted := &PathGeometry{Segments: make([]PathSegment, len(g.Segments))}
+	for i, seg := range g.Segments {
+		points := make([]PathPoint, len(seg.Points))
+		for j, p := range seg.Points {
+			points[j] = PathPoint{
+				X: axis(p.X, b.MinX, b.Width(), width),
+				Y: axis(p.Y, b.MinY, b.Height(), height),
+			}
+		}
+		fitted.Segments[i] = PathSegment{Op: seg.Op, Points: points}
+	}
+	return fitted
+}
+
+// cubicExtrema returns the parameters in (0, 1) where a cubic Bézier
+// coordinate has a local minimum or maximum.
+func cubicExtrema(p0, p1, p2, p3 float64) []float64 {
+	// Roots of the derivative a·t² + b·t + c
+	a := -p0 + 3*p1 - 3*p2 + p3
+	b := 2 * (p0 - 2*p1 + p2)
+	c := p1 - p0
+
+	var roots []float64
+	if math.Abs(a) < 1e-12 {
+		if math.Abs(b) > 1e-12 {
+			roots = append(roots, -c/b)
+		}
+	} else if disc := b*b - 4*a*c; disc >= 0 {
+		sq := math.Sqrt(disc)
+		roots = append(roots, (-b+sq)/(2*a), (-b-sq)/(2*a))
+	}
+
+	inside := roots[:0]
+	for _, t := range roots {
+		if t > 0 && t < 1 {
+			inside = append(inside, t)
+		}
+	}
+	return inside
+}
+
+func cubicPoint(p0, p1, p2, p3 PathPoint, t float64) PathPoint {
+	u := 1 - t
+	return PathPoint{
+		X: u*u*u*p0.X + 3*u*u*t*p1.X + 3*u*t*t*p2.X + t*t*t*p3.X,
+		Y: u*u*u*p0.Y + 3*u*u*t*p1.Y + 3*u*t*t*p2.Y + t*t*t*p3.Y,
+	}
+}
+
+func isPathCommand(c byte) bool {
+	switch c {
+	case 'M', 'm', 'L', 'l', 'H', 'h', 'V', 'v', 'C', 'c', 'S', 's', 'Q', 'q', 'T', 't', 'A', 'a', 'Z', 'z':
+		return true
+	}
+	return false
+}
+
+// pathScanner reads the numbers and flags of SVG path data.
+type pathScanner struct {
+	data string
+	pos  int
+}
+
+func (s *pathScanner) done() bool {
+	return s.pos >= len(s.data)
+}
+
+func (s *pathScanner) skipSeparators() {
+	for !s.done() {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r', '\f', ',':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// hasNumber reports whether the next token is a number.
+func (s *pathScanner) hasNumber() bool {
+	s.skipSeparators()
+	if s.done() {
+		return false
+	}
+	c := s.data[s.pos]
+	return c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9')
+}
+
+// number reads a number. Numbers need no separator when the next one starts
+// with a sign or a second decimal point, as in "1-2" or "0.5.5".
+func (s *pathScanner) number() (float64, error) {
+	if !s.hasNumber() {
+		if s.done() {
+			return 0, fmt.Errorf("expected number at end of data")
+		}
+		return 0, fmt.Errorf("expected number at offset %d", s.pos)
+	}
+
+	start := s.pos
+	if c := s.data[s.pos]; c == '-' || c == '+' {
+		s.pos++
+	}
+	digits := func() {
+		for !s.done() && s.data[s.pos] >= '0' && s.data[s.pos] <= '9' {
+			s.pos++
+		}
+	}
+	digits()
+	if !s.done() && s.data[s.pos] == '.' {
+		s.pos++
+		digits()
+	}
+	if !s.done() && (s.data[s.pos] == 'e' || s.data[s.pos] == 'E') {
+		s.pos++
+		if !s.done() && (s.data[s.pos] == '-' || s.data[s.pos] == '+') {
+			s.pos++
+		}
+		digits()
+	}
+
+	v, err := strconv.ParseFloat(s.data[start:s.pos], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q at offset %d", s.data[start:s.pos], start)
+	}
+	return v, nil
+}
+
+// flag reads an arc flag, a single 0 or 1 that needs no separator.
+func (s *pathScanner) flag() (bool, error) {
+	s.skipSeparators()
+	if s.done() || (s.data[s.pos] != '0' && s.data[s.pos] != '1') {
+		return false, fmt.Errorf("expected flag at offset %d", s.pos)
+	}
+	s.pos++
+	return s.data[s.pos-1] == '1', nil
+}
+
+// numbers reads n numbers.
+func (s *pathScanner) numbers(n int) ([]float64, error) {
+	values := make([]float64, n)
+	for i := range values {
+		v, err := s.number()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// pathBuilder accumulates normalized segments, tracking the current point
+// and the control points reflected by the smooth curve commands.
+type pathBuilder struct {
+	segments []PathSegment
+	cur      PathPoint
+	start    PathPoint // start of the current subpath
+	closed   bool      // the last segment closed the subpath
+
+	prevCubic *PathPoint // second control point of the previous C or S
+	prevQuad  *PathPoint // control point of the previous Q or T
+}
+
+// apply reads the arguments of one command and appends its segments.
+func (b *pathBuilder) apply(cmd byte, s *pathScanner) error {
+	rel := cmd >= 'a'
+	at := func(x, y float64) PathPoint {
+		if rel {
+			return PathPoint{X: b.cur.X + x, Y: b.cur.Y + y}
+		}
+		return PathPoint{X: x, Y: y}
+	}
+
+	var cubic, quad *PathPoint
+	switch cmd {
+	case 'M', 'm':
+		v, err := s.numbers(2)
+		if err != nil {
+			return err
+		}
+		b.moveTo(at(v[0], v[1]))
+	case 'L', 'l':
+		v, err := s.numbers(2)
+		if err != nil {
+			return err
+		}
+		b.lineTo(at(v[0], v[1]))
+	case 'H', 'h':
+		v, err := s.number()
+		if err != nil {
+			return err
+		}
+		p := PathPoint{X: v, Y: b.cur.Y}
+		if rel {
+			p.X += b.cur.X
+		}
+		b.lineTo(p)
+	case 'V', 'v':
+		v, err := s.number()
+		if err != nil {
+			return err
+		}
+		p := PathPoint{X: b.cur.X, Y: v}
+		if rel {
+			p.Y += b.cur.Y
+		}
+		b.lineTo(p)
+	case 'C', 'c':
+		v, err := s.numbers(6)
+		if err != nil {
+			return err
+		}
+		c2 := at(v[2], v[3])
+		b.cubicTo(at(v[0], v[1]), c2, at(v[4], v[5]))
+		cubic = &c2
+	case 'S', 's':
+		v, err := s.numbers(4)
+		if err != nil {
+			return err
+		}
+		c1 := b.reflect(b.prevCubic)
+		c2 := at(v[0], v[1])
+		b.cubicTo(c1, c2, at(v[2], v[3]))
+		cubic = &c2
+	case 'Q', 'q':
+		v, err := s.numbers(4)
+		if err != nil {
+			return err
+		}
+		q := at(v[0], v[1])
+		b.quadTo(q, at(v[2], v[3]))
+		quad = &q
+	case 'T', 't':
+		v, err := s.numbers(2)
+		if err != nil {
+			return err
+		}
+		q := b.reflect(b.prevQuad)
+		b.quadTo(q, at(v[0], v[1]))
+		quad = &q
+	case 'A', 'a':
+		v, err := s.numbers(3)
+		if err != nil {
+			return err
+		}
+		large, err := s.flag()
+		if err != nil {
+			return err
+		}
+		sweep, err := s.flag()
+		if err != nil {
+			return err
+		}
+		end, err := s.numbers(2)
+		if err != nil {
+			return err
+		}
+		b.arcTo(v[0], v[1], v[2], large, sweep, at(end[0], end[1]))
+	}
+
+	b.prevCubic, b.prevQuad = cubic, quad
+	return nil
+}
+
+// reflect mirrors a previous control point around the current point, or
+// returns the current point when there is none.
+func (b *pathBuilder) reflect(control *PathPoint) PathPoint {
+	if control == nil {
+		return b.cur
+	}
+	return PathPoint{X: 2*b.cur.X - control.X, Y: 2*b.cur.Y - control.Y}
+}
+
+func (b *pathBuilder) add(op PathOp, points ...PathPoint) {
+	// Drawing after a close starts a new subpath at the closed one's start
+	if b.closed && op != PathMoveTo {
+		b.segments = append(b.segments, PathSegment{Op: PathMoveTo, Points: []PathPoint{b.start}})
+	}
+	b.closed = false
+	b.segments = append(b.segments, PathSegment{Op: op, Points: points})
+	b.cur = points[len(points)-1]
+}
+
+func (b *pathBuilder) moveTo(p PathPoint) {
+	b.add(PathMoveTo, p)
+	b.start = p
+}
+
+func (b *pathBuilder) lineTo(p PathPoint) {
+	b.add(PathLineTo, p)
+}
+
+func (b *pathBuilder) cubicTo(c1, c2, p PathPoint) {
+	b.add(PathCubicTo, c1, c2, p)
+}
+
+// quadTo appends a quadratic curve as the equivalent cubic curve.
+func (b *pathBuilder) quadTo(q, p PathPoint) {
+	c1 := PathPoint{X: b.cur.X + 2.0/3*(q.X-b.cur.X), Y: b.cur.Y + 2.0/3*(q.Y-b.cur.Y)}
+	c2 := PathPoint{X: p.X + 2.0/3*(q.X-p.X), Y: p.Y + 2.0/3*(q.Y-p.Y)}
+	b.cubicTo(c1, c2, p)
+}
+
+func (b *pathBuilder) close() {
+	if len(b.segments) == 0 || b.closed {
+		return
+	}
+	b.segments = append(b.segments, PathSegment{Op: PathClose})
+	b.cur = b.start
+	b.closed = true
+	b.prevCubic, b.prevQuad = nil, nil
+}
+
+// arcTo appends an elliptical arc as cubic curves of at most a quarter turn
+// each, following the endpoint to center conversion of the SVG
+// specification (appendix B.2.4).
+func (b *pathBuilder) arcTo(rx, ry, rotation float64, large, sweep bool, end PathPoint) {
+	start := b.cur
+	if start == end {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		b.lineTo(end)
+		return
+	}
+
+	phi := rotation * math.Pi / 180
+	cos, sin := math.Cos(phi), math.Sin(phi)
+
+	// Start point in the ellipse's own axes, relative to the chord midpoint
+	dx, dy := (start.X-end.X)/2, (start.Y-end.Y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+
+	// Scale up radii too small to reach the end point
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		rx *= math.Sqrt(lambda)
+		ry *= math.Sqrt(lambda)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(math.Max(num/den, 0))
+	if large == sweep {
+		coef = -coef
+	}
+	cx1 := coef * rx * y1 / ry
+	cy1 := -coef * ry * x1 / rx
+	cx := cos*cx1 - sin*cy1 + (start.X+end.X)/2
+	cy := sin*cx1 + cos*cy1 + (start.Y+end.Y)/2
+
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	ux, uy := (x1-cx1)/rx, (y1-cy1)/ry
+	theta := angle(1, 0, ux, uy)
+	delta := angle(ux, uy, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	point := func(t float64) PathPoint {
+		return PathPoint{
+			X: cx + rx*math.Cos(t)*cos - ry*math.Sin(t)*sin,
+			Y: cy + rx*math.Cos(t)*sin + ry*math.Sin(t)*cos,
+		}
+	}
+	tangent := func(t float64) PathPoint {
+		return PathPoint{
+			X: -rx*math.Sin(t)*cos - ry*math.Cos(t)*sin,
+			Y: -rx*math.Sin(t)*sin + ry*math.Cos(t)*cos,
+		}
+	}
+
+	n := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4)
+	for i := 0; i < n; i++ {
+		t0 := theta + float64(i)*step
+		t1 := t0 + step
+		p0, d0 := point(t0), tangent(t0)
+		p1, d1 := point(t1), tangent(t1)
+		if i == n-1 {
+			p1 = end
+		}
+		b.cubicTo(
+			PathPoint{X: p0.X + k*d0.X, Y: p0.Y + k*d0.Y},
+			PathPoint{X: p1.X - k*d1.X, Y: p1.Y - k*d1.Y},
+			p1,
+		)
+	}
+}
@@ -0,0 +1,208 @@
+package domain_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestParsePathDataLines(t *testing.T) {
+	g, err := domain.ParsePathData("M10 20 L30,40 h10 v-5 H0 V0 z")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []domain.PathSegment{
+		{Op: domain.PathMoveTo, Points: []domain.PathPoint{{X: 10, Y: 20}}},
+		{Op: domain.PathLineTo, Points: []domain.PathPoint{{X: 30, Y: 40}}},
+		{Op: domain.PathLineTo, Points: []domain.PathPoint{{X: 40, Y: 40}}},
+		{Op: domain.PathLineTo, Points: []domain.PathPoint{{X: 40, Y: 35}}},
+		{Op: domain.PathLineTo, Points: []domain.PathPoint{{X: 0, Y: 35}}},
+		{Op: domain.PathLineTo, Points: []domain.PathPoint{{X: 0, Y: 0}}},
+		{Op: domain.PathClose},
+	}
+	assertSegments(t, g, want)
+}
+
+func TestParsePathDataImplicitCommands(t *testing.T) {
+	// Extra pairs after a move are lines; numbers may run together
+	g, err := domain.ParsePathData("m1-2 3-4.5.5-1l1 1 1 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []domain.PathSegment{
+		{Op: domain.PathMoveTo, Points: []domain.PathPoint{{X: 1, Y: -2}}},
+		{Op: domain.PathLineTo, Points: []domain.PathPoint{{X: 4, Y: -6.5}}},
+		{Op: domain.PathLineTo, Points: []domain.PathPoint{{X: 4.5, Y: -7.5}}},
+		{Op: domain.PathLineTo, Points: []domain.PathPoint{{X: 5.5, Y: -6.5}}},
+		{Op: domain.PathLineTo, Points: []domain.PathPoint{{X: 6.5, Y: -5.5}}},
+	}
+	assertSegments(t, g, want)
+}
+
+func TestParsePathDataCurves(t *testing.T) {
+	g, err := domain.ParsePathData("M0 0 C0 10 10 10 10 0 S20 -10 20 0 Q25 10 30 0 T40 0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.Segments) != 5 {
+		t.Fatalf("expected 5 segments, got %d", len(g.Segments))
+	}
+	for _, seg := range g.Segments[1:] {
+		if seg.Op != domain.PathCubicTo {
+			t.Fatalf("expected cubic curves, got %c", seg.Op)
+		}
+	}
+
+	// S reflects the previous second control point (10,10) around (10,0)
+	if c1 := g.Segments[2].Points[0]; c1 != (domain.PathPoint{X: 10, Y: -10}) {
+		t.Errorf("expected reflected control point (10,-10), got %+v", c1)
+	}
+	// Q from (20,0) with control (25,10) becomes a cubic with controls at 2/3
+	if c1 := g.Segments[3].Points[0]; !near(c1.X, 20+2.0/3*5) || !near(c1.Y, 2.0/3*10) {
+		t.Errorf("unexpected quadratic control point %+v", c1)
+	}
+	// T reflects the quadratic control (25,10) around (30,0) to (35,-10)
+	if c1 := g.Segments[4].Points[0]; !near(c1.X, 30+2.0/3*5) || !near(c1.Y, -2.0/3*10) {
+		t.Errorf("unexpected smooth quadratic control point %+v", c1)
+	}
+}
+
+func TestParsePathDataArc(t *testing.T) {
+	// A half circle of radius 10 from (0,0) to (20,0), sweeping through y<0
+	g, err := domain.ParsePathData("M0 0 A10 10 0 0 1 20 0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.Segments) != 3 {
+		t.Fatalf("expected a move and two quarter arcs, got %d segments", len(g.Segments))
+	}
+	if end := g.Segments[2].Points[2]; end != (domain.PathPoint{X: 20, Y: 0}) {
+		t.Errorf("expected arc to end at (20,0), got %+v", end)
+	}
+	mid := g.Segments[1].Points[2]
+	if !near(mid.X, 10) || !near(mid.Y, -10) {
+		t.Errorf("expected arc to pass through (10,-10), got %+v", mid)
+	}
+}
+
+func TestParsePathDataArcPackedFlags(t *testing.T) {
+	g, err := domain.ParsePathData("M0 0a5 5 0 1120 0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := g.Segments[len(g.Segments)-1]
+	if end := last.Points[2]; end != (domain.PathPoint{X: 20, Y: 0}) {
+		t.Errorf("expected arc to end at (20,0), got %+v", end)
+	}
+}
+
+func TestParsePathDataDrawAfterClose(t *testing.T) {
+	g, err := domain.ParsePathData("M5 5 L10 5 Z L5 10")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seg := g.Segments[3]; seg.Op != domain.PathMoveTo || seg.Points[0] != (domain.PathPoint{X: 5, Y: 5}) {
+		t.Errorf("expected implicit move to the subpath start, got %+v", seg)
+	}
+}
+
+func TestParsePathDataErrors(t *testing.T) {
+	tests := map[string]string{
+		"L10 10":          "must start with a move",
+		"M0 0 X10":        "unexpected 'X'",
+		"M0 0 L10":        "expected number",
+		"M0 0 A1 1 0 2 0": "expected flag",
+	}
+	for data, want := range tests {
+		_, err := domain.ParsePathData(data)
+		if err == nil {
+			t.Fatalf("expected error for %q", data)
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected %q in error, got: %s", data, want, err)
+		}
+	}
+}
+
+func TestParsePathDataEmpty(t *testing.T) {
+	g, err := domain.ParsePathData("  ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.Segments) != 0 {
+		t.Errorf("expected no segments, got %d", len(g.Segments))
+	}
+	if b := g.Bounds(); b != (domain.PathBounds{}) {
+		t.Errorf("expected zero bounds, got %+v", b)
+	}
+}
+
+func TestPathBoundsIncludesCurveExtrema(t *testing.T) {
+	// The curve bulges to y=7.5, beyond its end points but short of its
+	// control points at y=10
+	g, err := domain.ParsePathData("M0 0 C0 10 10 10 10 0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b := g.Bounds()
+	if b.MinX != 0 || b.MinY != 0 || b.MaxX != 10 || !near(b.MaxY, 7.5) {
+		t.Errorf("expected bounds (0,0)-(10,7.5), got %+v", b)
+	}
+	if b.Width() != 10 || !near(b.Height(), 7.5) {
+		t.Errorf("expected size 10x7.5, got %fx%f", b.Width(), b.Height())
+	}
+}
+
+func TestPathFitScalesBoundsToBox(t *testing.T) {
+	g, err := domain.ParsePathData("M10 10 L30 20")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fitted := g.Fit(100, 50)
+	if p := fitted.Segments[0].Points[0]; p != (domain.PathPoint{X: 0, Y: 0}) {
+		t.Errorf("expected (0,0), got %+v", p)
+	}
+	if p := fitted.Segments[1].Points[0]; p != (domain.PathPoint{X: 100, Y: 50}) {
+		t.Errorf("expected (100,50), got %+v", p)
+	}
+	if g.Segments[1].Points[0].X != 30 {
+		t.Error("expected the original geometry to be unchanged")
+	}
+}
+
+func TestPathFitCentersFlatAxis(t *testing.T) {
+	g, err := domain.ParsePathData("M0 5 H20")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fitted := g.Fit(40, 10)
+	if p := fitted.Segments[1].Points[0]; p != (domain.PathPoint{X: 40, Y: 5}) {
+		t.Errorf("expected (40,5), got %+v", p)
+	}
+}
+
+func assertSegments(t *testing.T, g *domain.PathGeometry, want []domain.PathSegment) {
+	t.Helper()
+	if len(g.Segments) != len(want) {
+		t.Fatalf("expected %d segments, got %d: %+v", len(want), len(g.Segments), g.Segments)
+	}
+	for i, seg := range g.Segments {
+		if seg.Op != want[i].Op || len(seg.Points) != len(want[i].Points) {
+			t.Errorf("segment %d: expected %+v, got %+v", i, want[i], seg)
+			continue
+		}
+		for j, p := range seg.Points {
+			if !near(p.X, want[i].Points[j].X) || !near(p.Y, want[i].Points[j].Y) {
+				t.Errorf("segment %d: expected %+v, got %+v", i, want[i], seg)
+			}
+		}
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,68 @@
+package domain
+
+// Position places a node at its x and y, from the top-left corner of its
+// parent, instead of in the flow of the parent's layout. Children of frames
+// without a layout (layout "none") are always positioned; Absolute takes a
+// child of a stack or grid out of its flow. Right and Bottom, when set, pin
+// the node to those edges of its parent instead: a node that fills its
+// container then stretches from x (or y) to the pinned edge.
+type Position struct {
+	Absolute bool
+	Right    *float64
+	Bottom   *float64
+}
+
+// Clone returns a copy of the position that does not share its edges.
+func (p Position) Clone() Position {
+	c := Position{Absolute: p.Absolute}
+	setOptional(&c.Right, p.Right)
+	setOptional(&c.Bottom, p.Bottom)
+	return c
+}
+
+// NodePosition returns the position of a node, or nil for nodes that have
+// none.
+func NodePosition(node Node) *Position {
+	switch n := node.(type) {
+	case *Frame:
+		return &n.Position
+	case *Text:
+		return &n.Position
+	case *Shape:
+		return &n.Position
+	case *Path:
+		return &n.Position
+	case *Image:
+		return &n.Position
+	default:
+		return nil
+	}
+}
+
+// NodeOffset returns the x and y of a node within its parent.
+func NodeOffset(node Node) (float64, float64) {
+	switch n := node.(type) {
+	case *Frame:
+		return n.X, n.Y
+	case *Text:
+		return n.X, n.Y
+	case *Shape:
+		return n.X, n.Y
+	case *Path:
+		return n.X, n.Y
+	case *Image:
+		return n.X, n.Y
+	default:
+		return 0, 0
+	}
+}
+
+// IsPositioned reports whether a child of parent is placed at its position
+// rather than by the parent's layout.
+func IsPositioned(parent *Frame, child Node) bool {
+	if parent.Layout == "none" {
+		return true
+	}
+	p := NodePosition(child)
+	return p != nil && p.Absolute
+}
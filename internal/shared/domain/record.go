@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Record holds the fields of one data record, such as a row of a CSV file,
+// that fill the {{field}} placeholders of a template.
+type Record map[string]string
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// HasPlaceholders reports whether s contains {{field}} placeholders.
+func HasPlaceholders(s string) bool {
+	return placeholderPattern.MatchString(s)
+}
+
+// Expand replaces the {{field}} placeholders of s with the values of the
+// record. Fields the record does not have are an error.
+func (r Record) Expand(s string) (string, error) {
+	return ReplacePlaceholders(s, func(field string) (string, bool, error) {
+		value, ok := r[field]
+		if !ok {
+			return "", false, fmt.Errorf("unknown field %q", field)
+		}
+		return value, true, nil
+	})
+}
+
+// ReplacePlaceholders replaces each {{field}} placeholder of s with the value
+// returned by lookup. Placeholders lookup does not know (ok is false) are
+// left in place for a later pass; the first error stops the replacement.
+func ReplacePlaceholders(s string, lookup func(field string) (value string, ok bool, err error)) (string, error) {
+	var failed error
+	out := placeholderPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if failed != nil {
+			return m
+		}
+		value, ok, err := lookup(placeholderPattern.FindStringSubmatch(m)[1])
+		if err != nil {
+			failed = err
+		}
+		if !ok {
+			return m
+		}
+		return value
+	})
+	if failed != nil {
+		return "", failed
+	}
+	return out, nil
+}
@@ -0,0 +1,53 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestRecordExpand(t *testing.T) {
+	r := domain.Record{"name": "Ada Lovelace", "course": "Analytical Engines", "empty": ""}
+	tests := map[string]string{
+		"Certificate for {{name}}":                 "Certificate for Ada Lovelace",
+		"{{ name }} completed {{course}}{{empty}}": "Ada Lovelace completed Analytical Engines",
+		"No placeholders { here }":                 "No placeholders { here }",
+	}
+	for s, want := range tests {
+		got, err := r.Expand(s)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", s, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%q: expected %q, got %q", s, want, got)
+		}
+	}
+
+	if _, err := r.Expand("Dear {{title}} {{name}}"); err == nil || !strings.Contains(err.Error(), `unknown field "title"`) {
+		t.Errorf("expected unknown field error, got %v", err)
+	}
+	if !domain.HasPlaceholders("{{name}}.pdf") || domain.HasPlaceholders("name.pdf") {
+		t.Error("unexpected placeholder detection")
+	}
+}
+
+func TestDocumentCloneIsDeep(t *testing.T) {
+	doc := &domain.Document{
+		Children: []domain.Node{&domain.Text{ID: "t1", Content: "{{name}}"}},
+		Variables: map[string]domain.Variable{
+			"title": {Type: domain.VariableString, Value: "{{title}}"},
+		},
+		Theme: domain.Theme{"mode": "dark"},
+	}
+
+	clone := doc.Clone()
+	clone.Children[0].(*domain.Text).Content = "Ada"
+	clone.Variables["title"] = domain.Variable{Type: domain.VariableString, Value: "Dr"}
+	clone.Theme["mode"] = "light"
+
+	if doc.Children[0].(*domain.Text).Content != "{{name}}" || doc.Variables["title"].Value != "{{title}}" || doc.Theme["mode"] != "dark" {
+		t.Errorf("expected the original to be unchanged, got %+v", doc)
+	}
+}
@@ -0,0 +1,30 @@
+package domain
+
+// Repeat makes a frame a repeater: its children are a template copied once
+// for each item of a data list, before layout.
+type Repeat struct {
+	Data  string // dotted path of the list in the document data or an enclosing item, e.g. "prices" or "day.stops"
+	As    string // name of the item in placeholders, "item" by default
+	Empty []Node // shown instead of the template when the list is empty
+}
+
+// Alias returns the name placeholders use for the current item.
+func (r *Repeat) Alias() string {
+	if r.As == "" {
+		return "item"
+	}
+	return r.As
+}
+
+// Clone returns a deep copy of the repeat, or nil.
+func (r *Repeat) Clone() *Repeat {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	c.Empty = nil
+	for _, node := range r.Empty {
+		c.Empty = append(c.Empty, CloneNode(node))
+	}
+	return &c
+}
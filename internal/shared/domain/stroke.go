@@ -0,0 +1,62 @@
+package domain
+
+type StrokeAlign string
+
+const (
+	StrokeInside  StrokeAlign = "inside"
+	StrokeCenter  StrokeAlign = "center"
+	StrokeOutside StrokeAlign = "outside"
+)
+
+// StrokeThickness holds the stroke width of each side of a node.
+type StrokeThickness struct {
+	Top    float64
+	Right  float64
+	Bottom float64
+	Left   float64
+}
+
+func UniformThickness(v float64) StrokeThickness {
+	return StrokeThickness{Top: v, Right: v, Bottom: v, Left: v}
+}
+
+// IsUniform reports whether all four sides share the same width.
+func (t StrokeThickness) IsUniform() bool {
+	return t.Top == t.Right && t.Right == t.Bottom && t.Bottom == t.Left
+}
+
+// Stroke is a border painted along the outline of a node.
+type Stroke struct {
+	Color     string
+	Thickness StrokeThickness
+	Align     StrokeAlign
+	Dash      []float64
+}
+
+func SolidStroke(color string, thickness float64, align StrokeAlign) *Stroke {
+	return &Stroke{Color: color, Thickness: UniformThickness(thickness), Align: align}
+}
+
+// Clone returns a copy of the stroke, or nil.
+func (s *Stroke) Clone() *Stroke {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	c.Dash = append([]float64(nil), s.Dash...)
+	return &c
+}
+
+// InsideInsets returns the space an inside stroke takes from the content
+// box. Center and outside strokes do not affect layout.
+func (s *Stroke) InsideInsets() Padding {
+	if s == nil || s.Align != StrokeInside {
+		return Padding{}
+	}
+	return Padding{
+		Top:    s.Thickness.Top,
+		Right:  s.Thickness.Right,
+		Bottom: s.Thickness.Bottom,
+		Left:   s.Thickness.Left,
+	}
+}
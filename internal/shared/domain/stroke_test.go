@@ -0,0 +1,53 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestSolidStroke(t *testing.T) {
+	s := domain.SolidStroke("#000000", 2, domain.StrokeInside)
+	if s.Color != "#000000" {
+		t.Errorf("expected color '#000000', got '%s'", s.Color)
+	}
+	if s.Thickness != domain.UniformThickness(2) {
+		t.Errorf("expected uniform thickness 2, got %+v", s.Thickness)
+	}
+	if s.Align != domain.StrokeInside {
+		t.Errorf("expected align inside, got %s", s.Align)
+	}
+}
+
+func TestStrokeThicknessIsUniform(t *testing.T) {
+	if !domain.UniformThickness(3).IsUniform() {
+		t.Error("expected uniform thickness")
+	}
+	if (domain.StrokeThickness{Top: 1, Bottom: 1}).IsUniform() {
+		t.Error("expected non-uniform thickness")
+	}
+}
+
+func TestStrokeInsideInsets(t *testing.T) {
+	s := &domain.Stroke{Thickness: domain.StrokeThickness{Top: 1, Right: 2, Bottom: 3, Left: 4}, Align: domain.StrokeInside}
+	p := s.InsideInsets()
+	if p.Top != 1 || p.Right != 2 || p.Bottom != 3 || p.Left != 4 {
+		t.Errorf("expected insets [1,2,3,4], got %+v", p)
+	}
+}
+
+func TestStrokeInsideInsetsIgnoresOtherAlignments(t *testing.T) {
+	for _, align := range []domain.StrokeAlign{domain.StrokeCenter, domain.StrokeOutside} {
+		s := domain.SolidStroke("#000000", 4, align)
+		if p := s.InsideInsets(); p != (domain.Padding{}) {
+			t.Errorf("%s: expected no insets, got %+v", align, p)
+		}
+	}
+}
+
+func TestStrokeInsideInsetsNil(t *testing.T) {
+	var s *domain.Stroke
+	if p := s.InsideInsets(); p != (domain.Padding{}) {
+		t.Errorf("expected no insets for nil stroke, got %+v", p)
+	}
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Theme selects one value per theme axis, e.g. {"mode": "dark"}.
+type Theme map[string]string
+
+// ParseTheme parses axis=value pairs, as given on the command line.
+func ParseTheme(pairs []string) (Theme, error) {
+	theme := make(Theme, len(pairs))
+	for _, pair := range pairs {
+		axis, value, ok := strings.Cut(pair, "=")
+		axis, value = strings.TrimSpace(axis), strings.TrimSpace(value)
+		if !ok || axis == "" || value == "" {
+			return nil, fmt.Errorf("invalid theme %q, expected axis=value", pair)
+		}
+		theme[axis] = value
+	}
+	return theme, nil
+}
+
+// Merge returns a copy of t with the axes of other taking precedence.
+func (t Theme) Merge(other Theme) Theme {
+	merged := make(Theme, len(t)+len(other))
+	for axis, value := range t {
+		merged[axis] = value
+	}
+	for axis, value := range other {
+		merged[axis] = value
+	}
+	return merged
+}
+
+// Matches reports whether every axis of t has the same value in active.
+func (t Theme) Matches(active Theme) bool {
+	for axis, value := range t {
+		if active[axis] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestParseTheme(t *testing.T) {
+	theme, err := domain.ParseTheme([]string{"mode=dark", " brand = acme "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if theme["mode"] != "dark" || theme["brand"] != "acme" {
+		t.Errorf("unexpected theme %v", theme)
+	}
+
+	for _, pair := range []string{"dark", "=dark", "mode="} {
+		if _, err := domain.ParseTheme([]string{pair}); err == nil {
+			t.Errorf("%q: expected error", pair)
+		}
+	}
+}
+
+func TestThemeMergeAndMatch(t *testing.T) {
+	base := domain.Theme{"mode": "light", "brand": "acme"}
+	merged := base.Merge(domain.Theme{"mode": "dark"})
+	if merged["mode"] != "dark" || merged["brand"] != "acme" || base["mode"] != "light" {
+		t.Errorf("expected a merged copy, got %v (base %v)", merged, base)
+	}
+	if !(domain.Theme{"mode": "dark"}).Matches(merged) || (domain.Theme{"brand": "globex"}).Matches(merged) {
+		t.Error("unexpected theme match")
+	}
+}
+
+func TestDocumentActiveTheme(t *testing.T) {
+	doc := &domain.Document{
+		Themes: map[string][]string{"mode": {"light", "dark"}, "brand": {"acme", "globex"}},
+		Theme:  domain.Theme{"brand": "globex"},
+	}
+	active := doc.ActiveTheme()
+	if active["mode"] != "light" || active["brand"] != "globex" {
+		t.Errorf("expected axis defaults overridden by the document theme, got %v", active)
+	}
+}
+
+func TestDocumentCheckTheme(t *testing.T) {
+	doc := &domain.Document{Themes: map[string][]string{"mode": {"light", "dark"}}}
+	if err := doc.CheckTheme(domain.Theme{"mode": "dark"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	tests := map[string]domain.Theme{
+		`unknown theme axis "brand"`: {"brand": "acme"},
+		`unknown mode theme "dim"`:   {"mode": "dim"},
+	}
+	for want, theme := range tests {
+		err := doc.CheckTheme(theme)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+	if err := (&domain.Document{}).CheckTheme(domain.Theme{"any": "thing"}); err != nil {
+		t.Errorf("expected any theme without declared axes, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"regexp"
+	"sort"
+)
+
+// Translations maps translation keys to the strings of one locale.
+type Translations map[string]string
+
+var translationPattern = regexp.MustCompile(`@\{\s*([A-Za-z0-9_.-]+)\s*\}`)
+
+// Translate replaces the @{key} references of s with the strings returned
+// by lookup.
+func Translate(s string, lookup func(key string) (string, error)) (string, error) {
+	var failed error
+	out := translationPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if failed != nil {
+			return m
+		}
+		text, err := lookup(translationPattern.FindStringSubmatch(m)[1])
+		if err != nil {
+			failed = err
+			return m
+		}
+		return text
+	})
+	if failed != nil {
+		return "", failed
+	}
+	return out, nil
+}
+
+// CollectTranslationKeys walks the document, text contents and string
+// variables, and returns the sorted translation keys it references.
+func CollectTranslationKeys(doc *Document) []string {
+	seen := make(map[string]bool)
+	add := func(s string) {
+		for _, m := range translationPattern.FindAllStringSubmatch(s, -1) {
+			seen[m[1]] = true
+		}
+	}
+	for _, v := range doc.Variables {
+		if s, ok := v.Value.(string); ok {
+			add(s)
+		}
+		for _, themed := range v.Themed {
+			if s, ok := themed.Value.(string); ok {
+				add(s)
+			}
+		}
+	}
+	var walk func(nodes []Node)
+	walk = func(nodes []Node) {
+		for _, node := range nodes {
+			switch n := node.(type) {
+			case *Text:
+				add(n.Content)
+			case *Frame:
+				walk(n.Children)
+				if n.Repeat != nil {
+					walk(n.Repeat.Empty)
+				}
+			}
+		}
+	}
+	walk(doc.Children)
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MissingTranslations returns the keys a locale has no string for.
+func (t Translations) MissingTranslations(keys []string) []string {
+	var missing []string
+	for _, key := range keys {
+		if _, ok := t[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,61 @@
+package domain_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vpedrosa/pen2pdf/internal/shared/domain"
+)
+
+func TestTranslate(t *testing.T) {
+	table := domain.Translations{"greeting": "Hola", "menu.title": "Carta"}
+	lookup := func(key string) (string, error) {
+		if s, ok := table[key]; ok {
+			return s, nil
+		}
+		return "", fmt.Errorf("missing translation %q", key)
+	}
+
+	got, err := domain.Translate("@{greeting}, @{ menu.title } {{name}} user@example.com", lookup)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Hola, Carta {{name}} user@example.com" {
+		t.Errorf("unexpected translation %q", got)
+	}
+
+	if _, err := domain.Translate("@{greeting} @{footer}", lookup); err == nil || !strings.Contains(err.Error(), `"footer"`) {
+		t.Errorf("expected missing key error, got %v", err)
+	}
+}
+
+func TestCollectTranslationKeys(t *testing.T) {
+	doc := &domain.Document{
+		Children: []domain.Node{
+			&domain.Frame{
+				ID: "page",
+				Children: []domain.Node{
+					&domain.Text{ID: "t1", Content: "@{title} — @{subtitle}"},
+					&domain.Text{ID: "t2", Content: "@{title}"},
+				},
+				Repeat: &domain.Repeat{Data: "prices", Empty: []domain.Node{&domain.Text{ID: "none", Content: "@{sold-out}"}}},
+			},
+		},
+		Variables: map[string]domain.Variable{
+			"tagline": {Type: domain.VariableString, Value: "@{tagline}", Themed: []domain.ThemedValue{
+				{Value: "@{tagline.dark}", Theme: domain.Theme{"mode": "dark"}},
+			}},
+		},
+	}
+
+	keys := domain.CollectTranslationKeys(doc)
+	if strings.Join(keys, ",") != "sold-out,subtitle,tagline,tagline.dark,title" {
+		t.Errorf("unexpected keys %v", keys)
+	}
+
+	missing := domain.Translations{"title": "Título", "tagline": "Fresco"}.MissingTranslations(keys)
+	if strings.Join(missing, ",") != "sold-out,subtitle,tagline.dark" {
+		t.Errorf("unexpected missing keys %v", missing)
+	}
+}
@@ -1,14 +1,136 @@
 package domain
 
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
 type VariableType string
 
 const (
-	VariableColor  VariableType = "color"
-	VariableString VariableType = "string"
-	VariableNumber VariableType = "number"
+	VariableColor   VariableType = "color"
+	VariableString  VariableType = "string"
+	VariableNumber  VariableType = "number"
+	VariableBoolean VariableType = "boolean"
 )
 
 type Variable struct {
-	Type  VariableType
+	Type   VariableType
+	Value  any           // value used when no themed value matches
+	Themed []ThemedValue // values for specific themes
+}
+
+// ThemedValue is a variable value used under a theme.
+type ThemedValue struct {
 	Value any
+	Theme Theme
+}
+
+// ValueFor returns the value of the variable under the active theme: the
+// matching themed value with the most axes, later values winning ties, or
+// Value when none matches.
+func (v Variable) ValueFor(active Theme) any {
+	value, best := v.Value, 0
+	for _, themed := range v.Themed {
+		if len(themed.Theme) >= best && themed.Theme.Matches(active) {
+			value, best = themed.Value, len(themed.Theme)
+		}
+	}
+	return value
+}
+
+// OverrideVariables replaces the values of variables, e.g. with values given
+// on the command line. Values are float64 numbers, booleans or strings;
+// strings are converted to the declared type of the variable, and colors may
+// also reference variables or call functions. Overrides apply under every
+// theme. Undeclared variables are an error unless allowUndeclared is set, in
+// which case their type is inferred from the value.
+func (d *Document) OverrideVariables(values map[string]any, allowUndeclared bool) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		v, declared := d.Variables[name]
+		if !declared {
+			if !allowUndeclared {
+				return fmt.Errorf("cannot override undeclared variable %q", name)
+			}
+			v.Type = inferVariableType(values[name])
+		}
+		value, err := convertVariableValue(v.Type, values[name])
+		if err != nil {
+			return fmt.Errorf("variable %q: %w", name, err)
+		}
+		if d.Variables == nil {
+			d.Variables = make(map[string]Variable)
+		}
+		d.Variables[name] = Variable{Type: v.Type, Value: value}
+	}
+	return nil
+}
+
+func inferVariableType(value any) VariableType {
+	switch v := value.(type) {
+	case float64:
+		return VariableNumber
+	case bool:
+		return VariableBoolean
+	case string:
+		if v == "true" || v == "false" {
+			return VariableBoolean
+		}
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return VariableNumber
+		}
+		if _, err := ParseHexColor(v); err == nil {
+			return VariableColor
+		}
+	}
+	return VariableString
+}
+
+func convertVariableValue(varType VariableType, value any) (any, error) {
+	switch v := value.(type) {
+	case float64:
+		if varType != VariableNumber {
+			return nil, fmt.Errorf("expected a %s value, got number %v", varType, v)
+		}
+		return v, nil
+	case bool:
+		if varType != VariableBoolean {
+			return nil, fmt.Errorf("expected a %s value, got boolean %v", varType, v)
+		}
+		return v, nil
+	case string:
+		switch varType {
+		case VariableBoolean:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("expected true or false, got %q", v)
+			}
+			return b, nil
+		case VariableNumber:
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("expected a number, got %q", v)
+			}
+			return n, nil
+		case VariableColor:
+			// References and functions are computed with the other variables
+			if strings.ContainsAny(v, "$(") {
+				return v, nil
+			}
+			if _, err := ParseHexColor(v); err != nil {
+				return nil, err
+			}
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v", value)
+	}
 }
@@ -1,6 +1,7 @@
 package domain_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/vpedrosa/pen2pdf/internal/shared/domain"
@@ -35,3 +36,105 @@ func TestVariableNumber(t *testing.T) {
 		t.Errorf("expected value 16.0, got '%v'", v.Value)
 	}
 }
+
+func TestVariableValueFor(t *testing.T) {
+	v := domain.Variable{
+		Type:  domain.VariableColor,
+		Value: "#FFFFFF",
+		Themed: []domain.ThemedValue{
+			{Value: "#000000", Theme: domain.Theme{"mode": "dark"}},
+			{Value: "#111111", Theme: domain.Theme{"mode": "dark", "brand": "acme"}},
+			{Value: "#222222", Theme: domain.Theme{"mode": "dark"}},
+		},
+	}
+	tests := []struct {
+		theme domain.Theme
+		want  string
+	}{
+		{nil, "#FFFFFF"},
+		{domain.Theme{"mode": "light"}, "#FFFFFF"},
+		{domain.Theme{"mode": "dark"}, "#222222"},
+		{domain.Theme{"mode": "dark", "brand": "acme"}, "#111111"},
+		{domain.Theme{"mode": "dark", "brand": "globex"}, "#222222"},
+	}
+	for _, tt := range tests {
+		if got := v.ValueFor(tt.theme); got != tt.want {
+			t.Errorf("%v: expected %s, got %v", tt.theme, tt.want, got)
+		}
+	}
+}
+
+func TestOverrideVariables(t *testing.T) {
+	doc := &domain.Document{Variables: map[string]domain.Variable{
+		"primary-color": {Type: domain.VariableColor, Value: "#FF6B35", Themed: []domain.ThemedValue{
+			{Value: "#000000", Theme: domain.Theme{"mode": "dark"}},
+		}},
+		"gap":        {Type: domain.VariableNumber, Value: 8.0},
+		"font-body":  {Type: domain.VariableString, Value: "Inter"},
+		"show-badge": {Type: domain.VariableBoolean, Value: true},
+		"link-color": {Type: domain.VariableColor, Value: "#0000FF"},
+	}}
+
+	err := doc.OverrideVariables(map[string]any{
+		"primary-color": "#00AA00", "gap": "12", "font-body": "Roboto", "show-badge": "false",
+		"link-color": "darken($primary-color, 10%)",
+	}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if link := doc.Variables["link-color"]; link.Value != "darken($primary-color, 10%)" {
+		t.Errorf("expected the color expression to be kept, got %v", link.Value)
+	}
+	color := doc.Variables["primary-color"]
+	if color.Value != "#00AA00" || color.Themed != nil {
+		t.Errorf("expected the override under every theme, got %+v", color)
+	}
+	if doc.Variables["gap"].Value != 12.0 || doc.Variables["font-body"].Value != "Roboto" || doc.Variables["show-badge"].Value != false {
+		t.Errorf("unexpected variables %+v", doc.Variables)
+	}
+}
+
+func TestOverrideVariablesErrors(t *testing.T) {
+	tests := map[string]struct {
+		values map[string]any
+		want   string
+	}{
+		"undeclared":       {map[string]any{"accent": "#FF0000"}, `cannot override undeclared variable "accent"`},
+		"number":           {map[string]any{"gap": "wide"}, `variable "gap": expected a number, got "wide"`},
+		"color":            {map[string]any{"primary-color": "green"}, `variable "primary-color": invalid hex color`},
+		"number as string": {map[string]any{"font-body": 12.0}, `variable "font-body": expected a string value, got number 12`},
+		"boolean":          {map[string]any{"show-badge": "maybe"}, `variable "show-badge": expected true or false, got "maybe"`},
+		"boolean as gap":   {map[string]any{"gap": true}, `variable "gap": expected a number value, got boolean true`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			doc := &domain.Document{Variables: map[string]domain.Variable{
+				"primary-color": {Type: domain.VariableColor, Value: "#FF6B35"},
+				"gap":           {Type: domain.VariableNumber, Value: 8.0},
+				"font-body":     {Type: domain.VariableString, Value: "Inter"},
+				"show-badge":    {Type: domain.VariableBoolean, Value: true},
+			}}
+			err := doc.OverrideVariables(tt.values, false)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestOverrideUndeclaredVariables(t *testing.T) {
+	doc := &domain.Document{}
+	err := doc.OverrideVariables(map[string]any{"accent": "#FF0000", "gap": "12", "size": 4.0, "title": "Spring sale", "sale": "true"}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]domain.VariableType{
+		"accent": domain.VariableColor, "gap": domain.VariableNumber, "size": domain.VariableNumber, "title": domain.VariableString,
+		"sale": domain.VariableBoolean,
+	}
+	for name, varType := range want {
+		if got := doc.Variables[name].Type; got != varType {
+			t.Errorf("%s: expected type %s, got %s", name, varType, got)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package domain
+
+// Visibility decides whether a node is laid out and drawn. Nodes are visible
+// unless Hidden. Condition is an expression, such as "$show-badge" or
+// "{{item.discount}} > 0", that the resolver evaluates to hide the node when
+// it is false.
+type Visibility struct {
+	Hidden    bool
+	Condition string
+}
+
+// NodeVisibility returns the visibility of a node, or nil for nodes that
+// have none.
+func NodeVisibility(node Node) *Visibility {
+	switch n := node.(type) {
+	case *Frame:
+		return &n.Visibility
+	case *Text:
+		return &n.Visibility
+	case *Shape:
+		return &n.Visibility
+	case *Path:
+		return &n.Visibility
+	case *Image:
+		return &n.Visibility
+	default:
+		return nil
+	}
+}
+
+// IsHidden reports whether a node is hidden, and so takes no space in
+// layout and is not drawn.
+func IsHidden(node Node) bool {
+	v := NodeVisibility(node)
+	return v != nil && v.Hidden
+}